@@ -0,0 +1,258 @@
+package fontimg
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+	"strconv"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// Anchor is a GPOS mark attachment anchor point of a glyph, in font units.
+type Anchor struct {
+	// Type is the lookup type of the attachment.
+	Type AnchorType
+	// Class is the mark class attached at the anchor. Mark classes are local
+	// to the lookup subtable defining them.
+	Class int
+	// Mark is true when the anchor attaches the glyph as a mark, and false
+	// when the anchor is where marks attach to the glyph.
+	Mark bool
+	// Component is the ligature component of the anchor, for mark-to-ligature
+	// attachment.
+	Component int
+	X, Y      int
+}
+
+// AnchorType is a GPOS mark attachment lookup type.
+type AnchorType uint16
+
+// Anchor types.
+const (
+	MarkToBase     AnchorType = gposMarkToBase
+	MarkToLigature AnchorType = gposMarkToLigature
+	MarkToMark     AnchorType = gposMarkToMark
+)
+
+// String satisfies the [fmt.Stringer] interface.
+func (typ AnchorType) String() string {
+	switch typ {
+	case MarkToBase:
+		return "mark-to-base"
+	case MarkToLigature:
+		return "mark-to-ligature"
+	case MarkToMark:
+		return "mark-to-mark"
+	}
+	return "AnchorType(" + strconv.Itoa(int(typ)) + ")"
+}
+
+// Anchors returns the font's GPOS mark attachment anchors, by glyph ID.
+func (font *Font) Anchors() (_ map[uint16][]Anchor, err error) {
+	defer recoverError(&err)
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[uint16][]Anchor)
+	b, ok := sfnt.Tables["GPOS"]
+	if !ok {
+		return m, nil
+	}
+	if err := gposAnchors(b, m); err != nil {
+		return nil, fmt.Errorf("GPOS: %v", err)
+	}
+	return m, nil
+}
+
+// gposAnchors parses the mark attachment anchors of the raw GPOS table,
+// adding them to m.
+func gposAnchors(b []byte, m map[uint16][]Anchor) error {
+	add := func(id uint16, a Anchor) {
+		if !slices.Contains(m[id], a) {
+			m[id] = append(m[id], a)
+		}
+	}
+	return layoutSubtables(b, gposExtension, func(typ uint16, sub int) error {
+		if typ != gposMarkToBase && typ != gposMarkToLigature && typ != gposMarkToMark {
+			return nil
+		}
+		// all mark attachment subtables share the same header
+		var v [6]uint16
+		for i := range v {
+			var err error
+			if v[i], err = u16(b, sub+2*i); err != nil {
+				return err
+			}
+		}
+		if v[0] != 1 {
+			return fmt.Errorf("bad mark attachment format %d", v[0])
+		}
+		marks, err := coverage(b, sub+int(v[1]))
+		if err != nil {
+			return err
+		}
+		bases, err := coverage(b, sub+int(v[2]))
+		if err != nil {
+			return err
+		}
+		classes, markArray, baseArray := int(v[3]), sub+int(v[4]), sub+int(v[5])
+		// mark array
+		for i, id := range marks {
+			class, err := u16(b, markArray+2+4*i)
+			if err != nil {
+				return err
+			}
+			off, err := u16(b, markArray+4+4*i)
+			if err != nil {
+				return err
+			}
+			x, y, err := anchor(b, markArray+int(off))
+			if err != nil {
+				return err
+			}
+			add(id, Anchor{Type: AnchorType(typ), Class: int(class), Mark: true, X: x, Y: y})
+		}
+		// base, ligature, or mark2 array
+		for i, id := range bases {
+			// records is the offset of the glyph's records, and their count
+			records, components := baseArray+2+2*classes*i, 1
+			if typ == gposMarkToLigature {
+				off, err := u16(b, baseArray+2+2*i)
+				if err != nil {
+					return err
+				}
+				n, err := u16(b, baseArray+int(off))
+				if err != nil {
+					return err
+				}
+				records, components = baseArray+int(off)+2, int(n)
+			}
+			for component := range components {
+				for class := range classes {
+					i := records + 2*(component*classes+class)
+					off, err := u16(b, i)
+					switch {
+					case err != nil:
+						return err
+					case off == 0:
+						continue
+					}
+					// offsets are from the base array, or ligature attach table
+					base := baseArray
+					if typ == gposMarkToLigature {
+						base = records - 2
+					}
+					x, y, err := anchor(b, base+int(off))
+					if err != nil {
+						return err
+					}
+					add(id, Anchor{Type: AnchorType(typ), Class: class, Component: component, X: x, Y: y})
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// anchor parses the anchor table at i, returning its coordinates.
+func anchor(b []byte, i int) (int, int, error) {
+	x, err := u16(b, i+2)
+	if err != nil {
+		return 0, 0, err
+	}
+	y, err := u16(b, i+4)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(int16(x)), int(int16(y)), nil
+}
+
+// RasterizeAnchors rasterizes an anchor sheet using the options. See
+// [Font.AnchorSheet].
+func (font *Font) RasterizeAnchors(opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := font.AnchorSheet(opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// AnchorSheet lays out a glyph sheet (see [Font.GlyphSheet]) of the glyphs
+// of the options' text (or, when empty, a sample of base letters and
+// combining marks), with each glyph's GPOS mark attachment anchors drawn as
+// points colored by mark class and labeled with the class. Anchors where marks
+// attach are drawn filled, and anchors attaching the glyph as a mark are drawn
+// hollow. Best viewed at a large size (ie, 144). When opts is nil, the default
+// options will be used.
+func (font *Font) AnchorSheet(opts *Options) (*canvas.Canvas, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	anchors, err := font.Anchors()
+	if err != nil {
+		return nil, err
+	}
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	text := anchorsText
+	if opts.Text != "" {
+		text = opts.Text
+	}
+	var matches []GlyphMatch
+	for _, r := range text {
+		id := sfnt.GlyphIndex(r)
+		if id == 0 || slices.ContainsFunc(matches, func(m GlyphMatch) bool { return m.GlyphID == id }) {
+			continue
+		}
+		matches = append(matches, GlyphMatch{
+			GlyphID: id,
+			Name:    sfnt.GlyphName(id),
+			Runes:   []rune{r},
+		})
+	}
+	lff, err := LabelFont().Load(canvas.FontRegular)
+	if err != nil {
+		return nil, err
+	}
+	size := 0.15 * float64(opts.Size) * 25.4 / 72
+	return font.glyphSheet(matches, opts, func(ctx *canvas.Context, id uint16, x, y, scale float64) error {
+		for _, a := range anchors[id] {
+			c := anchorColors[a.Class%len(anchorColors)]
+			ax, ay := x+float64(a.X)*scale, y+float64(a.Y)*scale
+			ctx.SetFillColor(c)
+			p := canvas.Circle(size / 2)
+			if a.Mark {
+				p = p.Append(canvas.Circle(size / 4).Reverse())
+			}
+			ctx.DrawPath(ax, ay, p)
+			label := strconv.Itoa(a.Class)
+			if a.Type == MarkToMark {
+				label = "m" + label
+			}
+			ctx.DrawText(ax+size/2, ay+size/2, canvas.NewTextLine(lff.Face(0.15*float64(opts.Size), c), label, canvas.Left))
+		}
+		return nil
+	})
+}
+
+// anchorsText is the default anchor sheet text.
+const anchorsText = "AEOaeoiù̧́̂̃̈"
+
+// anchorColors are the anchor mark class colors.
+var anchorColors = []color.Color{
+	color.NRGBA{R: 0xd0, G: 0x30, B: 0x30, A: 0xff},
+	color.NRGBA{R: 0x30, G: 0x50, B: 0xd0, A: 0xff},
+	color.NRGBA{R: 0x30, G: 0x90, B: 0x30, A: 0xff},
+	color.NRGBA{R: 0xc0, G: 0x80, B: 0x00, A: 0xff},
+	color.NRGBA{R: 0x90, G: 0x30, B: 0xb0, A: 0xff},
+	color.NRGBA{R: 0x20, G: 0x90, B: 0xa0, A: 0xff},
+}
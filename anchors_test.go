@@ -0,0 +1,68 @@
+package fontimg
+
+import (
+	"encoding/binary"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGPOSAnchors(t *testing.T) {
+	var b []byte
+	for _, v := range []int{
+		// header, with lookup list at 10
+		1, 0, 0, 0, 10,
+		// lookup list, with lookup at 14
+		1, 4,
+		// mark-to-base lookup, with subtable at 22
+		gposMarkToBase, 0, 1, 8,
+		// subtable: mark coverage, base coverage, class count, mark array,
+		// base array
+		1, 12, 18, 1, 24, 36,
+		// mark coverage: glyph 10
+		1, 1, 10,
+		// base coverage: glyph 20
+		1, 1, 20,
+		// mark array: class 0 anchor
+		1, 0, 6,
+		// mark anchor
+		1, 100, 200,
+		// base array: class 0 anchor
+		1, 4,
+		// base anchor
+		1, 300, -50,
+	} {
+		b = binary.BigEndian.AppendUint16(b, uint16(v))
+	}
+	m := make(map[uint16][]Anchor)
+	if err := gposAnchors(b, m); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := map[uint16][]Anchor{
+		10: {{Type: MarkToBase, Mark: true, X: 100, Y: 200}},
+		20: {{Type: MarkToBase, X: 300, Y: -50}},
+	}
+	if !reflect.DeepEqual(m, exp) {
+		t.Errorf("expected %v, got: %v", exp, m)
+	}
+	// truncated tables must error, not panic
+	for n := 0; n < len(b); n += 2 {
+		if err := gposAnchors(b[:n], make(map[uint16][]Anchor)); err == nil {
+			t.Errorf("expected error for %d bytes", n)
+		}
+	}
+}
+
+func TestAnchorSheet(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if _, err := font.Anchors(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	img, err := font.RasterizeAnchors(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+		t.Errorf("expected non-empty image, got: %v", b)
+	}
+}
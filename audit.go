@@ -0,0 +1,75 @@
+package fontimg
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditRecord is a preview generation audit log record, recording the
+// provenance of a generated image.
+type AuditRecord struct {
+	// Time is the time the generation started.
+	Time time.Time `json:"time"`
+	// Source is the subsystem that generated the image (ie, "batch",
+	// "server").
+	Source string `json:"source"`
+	// Font is the font's path or name.
+	Font string `json:"font,omitempty"`
+	// FontHash is the hash of the font's content (see [Font.Hash]).
+	FontHash string `json:"font_hash"`
+	// OptionsHash is the hash of the rasterization options (see
+	// [OptionsHash]).
+	OptionsHash string `json:"options_hash"`
+	// Output is the output's name.
+	Output string `json:"output,omitempty"`
+	// OutputHash is the hex encoded SHA-256 hash of the output's content.
+	OutputHash string `json:"output_hash"`
+	// Duration is the time taken to generate the output, in nanoseconds.
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// AuditLog is an append-only JSONL audit log of generated images. It is safe
+// for concurrent use.
+type AuditLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewAuditLog creates an audit log writing records to w.
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w}
+}
+
+// OpenAuditLog opens the named audit log file for appending, creating it if
+// necessary. Each record is appended with a single write, so that processes
+// sharing the file do not interleave records.
+func OpenAuditLog(name string) (*AuditLog, error) {
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewAuditLog(f), nil
+}
+
+// Log appends the record to the audit log.
+func (l *AuditLog) Log(rec AuditRecord) error {
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(append(buf, '\n'))
+	return err
+}
+
+// Close closes the audit log's writer, when it is a [io.Closer].
+func (l *AuditLog) Close() error {
+	if c, ok := l.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package fontimg
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "audit.jsonl")
+	for range 2 {
+		log, err := OpenAuditLog(name)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Go(func() {
+				if err := log.Log(AuditRecord{
+					Time:        time.Now(),
+					Source:      "test",
+					FontHash:    "font",
+					OptionsHash: OptionsHash(nil),
+					OutputHash:  "output",
+					Duration:    time.Duration(i),
+				}); err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+			})
+		}
+		wg.Wait()
+		if err := log.Close(); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer f.Close()
+	var n int
+	for s := bufio.NewScanner(f); s.Scan(); n++ {
+		var rec AuditRecord
+		if err := json.Unmarshal(s.Bytes(), &rec); err != nil {
+			t.Fatalf("line %d: expected no error, got: %v", n+1, err)
+		}
+		if rec.Source != "test" || rec.OptionsHash != OptionsHash(nil) || rec.Time.IsZero() {
+			t.Errorf("line %d: unexpected record %+v", n+1, rec)
+		}
+	}
+	if n != 16 {
+		t.Errorf("expected 16 records, got: %d", n)
+	}
+}
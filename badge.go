@@ -0,0 +1,98 @@
+package fontimg
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// BadgeOptions are the badge options.
+type BadgeOptions struct {
+	// Label is the badge's label, shown on the left. When empty, the font's
+	// name is used.
+	Label string
+	// Message is the badge's message, shown on the right. When empty, the
+	// font's glyph count (ie, "1,234 glyphs") is used.
+	Message string
+	// Color is the message's background color. When nil, blue is used.
+	Color color.Color
+	// Height is the badge's height, in pixels. When zero, 20 pixels is used.
+	Height int
+}
+
+// Badge colors.
+var (
+	badgeLabelColor   = color.RGBA{0x55, 0x55, 0x55, 0xff}
+	badgeMessageColor = color.RGBA{0x00, 0x7e, 0xc6, 0xff}
+)
+
+// RasterizeBadge rasterizes a badge image using the options. See [Badge].
+func RasterizeBadge(font *Font, badge *BadgeOptions, opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := Badge(font, badge, opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// Badge lays out a shields-style badge on a canvas, with the label and
+// message set in white in the font on a gray and colored background, for
+// embedding in a font repository's README. Only the options' style, variant,
+// and DPI are used. When badge or opts is nil, the defaults will be used.
+func Badge(font *Font, badge *BadgeOptions, opts *Options) (_ *canvas.Canvas, err error) {
+	defer recoverError(&err)
+	if badge == nil {
+		badge = new(BadgeOptions)
+	}
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	// load font family
+	ff, err := font.Load(opts.Style)
+	if err != nil {
+		return nil, err
+	}
+	label, message, bg, height := badge.Label, badge.Message, badge.Color, badge.Height
+	if label == "" {
+		label = font.BestName()
+	}
+	if message == "" {
+		sfnt := ff.Face(16).Font.SFNT
+		message = labelPrinter(opts.Language).Sprintf("%d glyphs", sfnt.NumGlyphs())
+	}
+	if bg == nil {
+		bg = badgeMessageColor
+	}
+	if height <= 0 {
+		height = 20
+	}
+	// pixel size in millimeters, with text at 55% of the height
+	px := 25.4 / opts.DPI
+	face := ff.Face(0.55*float64(height)*72/opts.DPI, color.White, opts.Style, opts.Variant)
+	labelText := canvas.NewTextLine(face, label, canvas.Left)
+	messageText := canvas.NewTextLine(face, message, canvas.Left)
+	pad, h, r := 6*px, float64(height)*px, 3*px
+	lw := labelText.Bounds().W() + 2*pad
+	w := lw + messageText.Bounds().W() + 2*pad
+	// create canvas and context
+	c := canvas.New(w, h)
+	ctx := canvas.NewContext(c)
+	// draw background, squaring the label's right corners
+	ctx.SetFillColor(bg)
+	ctx.DrawPath(0, 0, canvas.RoundedRectangle(w, h, r))
+	ctx.SetFillColor(badgeLabelColor)
+	ctx.DrawPath(0, 0, canvas.RoundedRectangle(lw, h, r))
+	ctx.DrawPath(lw-r, 0, canvas.Rectangle(r, h))
+	// draw text, centered vertically on the cap height
+	y := (h - face.Metrics().CapHeight) / 2
+	ctx.DrawText(pad-labelText.Bounds().X0, y, labelText)
+	ctx.DrawText(lw+pad-messageText.Bounds().X0, y, messageText)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
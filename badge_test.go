@@ -0,0 +1,40 @@
+package fontimg
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestRasterizeBadge(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	img, err := RasterizeBadge(font, nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	b := img.Bounds()
+	if b.Dy() != 20 || b.Dx() <= b.Dy() {
+		t.Fatalf("expected 20 pixel high badge, got: %v", b)
+	}
+	if c := img.At(3, b.Dy()/2); c != badgeLabelColor {
+		t.Errorf("expected label color, got: %v", c)
+	}
+	if c := img.At(b.Dx()-3, b.Dy()/2); c != badgeMessageColor {
+		t.Errorf("expected message color, got: %v", c)
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a == 0xffff {
+		t.Errorf("expected rounded corners")
+	}
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	custom, err := RasterizeBadge(font, &BadgeOptions{Label: "x", Message: "y", Color: red, Height: 28}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cb := custom.Bounds()
+	if cb.Dy() != 28 || b.Dx() <= cb.Dx() {
+		t.Errorf("expected shorter 28 pixel high badge, got: %v", cb)
+	}
+	if c := custom.At(cb.Dx()-3, cb.Dy()/2); c != red {
+		t.Errorf("expected message color %v, got: %v", red, c)
+	}
+}
@@ -0,0 +1,486 @@
+// Package batch renders font preview images in batches, as described by a
+// manifest.
+package batch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kenshaw/fontimg"
+	fontpkg "github.com/tdewolff/font"
+)
+
+// Runner renders the items of a batch manifest.
+type Runner struct {
+	dir      string
+	sysfonts *fontpkg.SystemFonts
+	opts     fontimg.Options
+	prev     map[string]ItemResult
+	sidecars bool
+
+	retries    int
+	retryDelay time.Duration
+	fallback   FallbackFunc
+	stats      StatsFunc
+	audit      *fontimg.AuditLog
+}
+
+// New creates a new batch runner.
+func New(opts ...Option) *Runner {
+	r := &Runner{
+		dir:  ".",
+		opts: *fontimg.DefaultOptions(),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Run renders the manifest's items to the output directory, returning the
+// result manifest. An error is returned only when the batch could not be
+// run, or the context was canceled: per-item errors are reported in the
+// result. Runners in concurrent processes may share the output directory, as
+// outputs are written atomically while holding the directory's lock file.
+func (r *Runner) Run(ctx context.Context, m *Manifest) (*Result, error) {
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return nil, err
+	}
+	return r.run(ctx, m, false)
+}
+
+// Plan resolves the manifest's fonts and computes their cache keys without
+// rendering, returning a result manifest reporting which items would be
+// rendered ([StatusPlanned]) or skipped ([StatusSkipped]). Items that would
+// fail to render due to invalid parameters or unresolvable fonts are reported
+// with [StatusError].
+func (r *Runner) Plan(ctx context.Context, m *Manifest) (*Result, error) {
+	return r.run(ctx, m, true)
+}
+
+// run runs the manifest's items.
+func (r *Runner) run(ctx context.Context, m *Manifest, dry bool) (*Result, error) {
+	res := new(Result)
+	for _, item := range m.Items {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		res.Items = append(res.Items, r.item(ctx, m, item, dry)...)
+	}
+	return res, nil
+}
+
+// item renders a manifest item, which may resolve to multiple fonts (ie, a
+// directory).
+func (r *Runner) item(ctx context.Context, m *Manifest, item Item, dry bool) []ItemResult {
+	start := time.Now()
+	fail := func(err error) []ItemResult {
+		return []ItemResult{{
+			Font:     item.Font,
+			Output:   item.Output,
+			Status:   StatusError,
+			Error:    err.Error(),
+			Duration: Duration(time.Since(start)),
+		}}
+	}
+	if item.Output != "" && !filepath.IsLocal(item.Output) {
+		return fail(fmt.Errorf("invalid output %q", item.Output))
+	}
+	p := m.Defaults.merge(item.Params)
+	opts, err := r.options(m, p)
+	if err != nil {
+		return fail(err)
+	}
+	var tpl *template.Template
+	if p.OutputName != "" && item.Output == "" {
+		if tpl, err = template.New("output").Parse(p.OutputName); err != nil {
+			return fail(fmt.Errorf("invalid output name: %v", err))
+		}
+	}
+	sysfonts := r.sysfonts
+	if sysfonts == nil {
+		if sysfonts, err = fontimg.SystemFonts(); err != nil {
+			return fail(err)
+		}
+	}
+	name := item.Font
+	if _, err := os.Stat(m.path(name)); err == nil {
+		name = m.path(name)
+	}
+	fonts, err := fontimg.OpenContext(ctx, name, opts.Style, sysfonts)
+	var errs fontimg.Errors
+	switch {
+	case errors.As(err, &errs):
+	case err != nil:
+		return fail(err)
+	}
+	if item.Output != "" && len(fonts)+len(errs) != 1 {
+		return fail(fmt.Errorf("output %q specified for %d fonts", item.Output, len(fonts)+len(errs)))
+	}
+	var v []ItemResult
+	for _, err := range errs {
+		v = append(v, ItemResult{
+			Font:     item.Font,
+			Path:     err.Path,
+			Output:   item.Output,
+			Status:   StatusError,
+			Error:    err.Err.Error(),
+			Duration: Duration(time.Since(start)),
+		})
+	}
+	for _, font := range fonts {
+		output := item.Output
+		switch {
+		case tpl != nil:
+			if output, err = executeOutputName(tpl, font, opts); err != nil {
+				v = append(v, ItemResult{
+					Font:     item.Font,
+					Path:     font.Path,
+					Status:   StatusError,
+					Error:    err.Error(),
+					Duration: Duration(time.Since(start)),
+				})
+				continue
+			}
+		case output == "":
+			output = outputName(font)
+		}
+		v = append(v, r.render(ctx, item.Font, font, output, opts, dry))
+	}
+	return v
+}
+
+// render renders the font to the output file. When dry is true, the font is
+// not rendered.
+func (r *Runner) render(ctx context.Context, name string, font *fontimg.Font, output string, opts *fontimg.Options, dry bool) ItemResult {
+	start := time.Now()
+	res := ItemResult{
+		Font:   name,
+		Path:   font.Path,
+		Output: output,
+		Status: StatusOK,
+	}
+	err := func() error {
+		if err := r.retry(ctx, func() error {
+			ref, err := font.Ref()
+			if err != nil {
+				return err
+			}
+			res.Ref, res.FontHash = &ref, ref.Hash
+			return nil
+		}); err != nil {
+			return err
+		}
+		res.CacheKey = fontimg.CacheKeyRef(font, *res.Ref, opts)
+		unlock, err := r.lock(dry)
+		if err != nil {
+			return err
+		}
+		skip := r.skip(&res)
+		if err := unlock(); err != nil {
+			return err
+		}
+		switch {
+		case skip:
+			res.Status = StatusSkipped
+			return nil
+		case dry:
+			res.Status = StatusPlanned
+			return nil
+		}
+		var img image.Image
+		err = r.retry(ctx, func() error {
+			rgba, st, err := font.RasterizeStats(opts)
+			if err != nil {
+				return err
+			}
+			img = rgba
+			res.Stats = &Stats{
+				Glyphs: st.Glyphs,
+				Lines:  st.Lines,
+				Layout: Duration(st.Layout),
+				Raster: Duration(st.Raster),
+			}
+			if st.Scale != 1 {
+				res.Stats.Scale = st.Scale
+			}
+			if r.stats != nil {
+				r.stats(font, st)
+			}
+			return nil
+		})
+		if err != nil && r.fallback != nil {
+			var ferr error
+			if img, ferr = r.fallback(font, opts, err); ferr != nil {
+				return err
+			}
+			res.Status, res.Error = StatusFallback, err.Error()
+		} else if err != nil {
+			return err
+		}
+		b := img.Bounds()
+		res.Width, res.Height = b.Dx(), b.Dy()
+		// outputs and sidecars are written while holding the lock, so that
+		// concurrent processes sharing the directory do not interleave them
+		unlock, err = r.lock(false)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		if err := r.retry(ctx, func() error {
+			var err error
+			res.OutputHash, err = writePNG(filepath.Join(r.dir, output), img)
+			return err
+		}); err != nil {
+			return err
+		}
+		if err := r.log(start, font, opts, res); err != nil {
+			return err
+		}
+		if res.Status == StatusFallback {
+			return nil
+		}
+		return r.writeSidecar(res)
+	}()
+	if err != nil {
+		res.Status, res.Error = StatusError, err.Error()
+	}
+	res.Duration = Duration(time.Since(start))
+	return res
+}
+
+// log writes the audit log record for the written output, when the runner
+// has an audit log.
+func (r *Runner) log(start time.Time, font *fontimg.Font, opts *fontimg.Options, res ItemResult) error {
+	if r.audit == nil {
+		return nil
+	}
+	if err := r.audit.Log(fontimg.AuditRecord{
+		Time:        start,
+		Source:      "batch",
+		Font:        res.Path,
+		FontHash:    res.FontHash,
+		OptionsHash: fontimg.OptionsHash(opts),
+		Output:      res.Output,
+		OutputHash:  res.OutputHash,
+		Duration:    time.Since(start),
+	}); err != nil {
+		return fmt.Errorf("unable to write audit log: %v", err)
+	}
+	return nil
+}
+
+// options returns the rasterization options for the params.
+func (r *Runner) options(m *Manifest, p Params) (*fontimg.Options, error) {
+	opts := r.opts
+	switch {
+	case p.Template != "":
+		buf, err := os.ReadFile(m.path(p.Template))
+		if err != nil {
+			return nil, err
+		}
+		if opts.Template, err = fontimg.NewTemplate(string(buf)); err != nil {
+			return nil, err
+		}
+	case p.Preset != "":
+		preset, err := fontimg.LookupPreset(p.Preset)
+		if err != nil {
+			return nil, err
+		}
+		opts.Template = preset.Template
+	}
+	if p.Text != "" {
+		opts.Text = p.Text
+	}
+	if p.Style != "" {
+		var err error
+		if opts.Style, err = fontimg.ParseStyle(p.Style); err != nil {
+			return nil, err
+		}
+	}
+	if p.Size != 0 {
+		opts.Size = p.Size
+	}
+	if p.FG != "" {
+		var err error
+		if opts.FG, err = fontimg.ParseColor(p.FG); err != nil {
+			return nil, err
+		}
+	}
+	if p.BG != "" {
+		var err error
+		if opts.BG, err = fontimg.ParseColor(p.BG); err != nil {
+			return nil, err
+		}
+	}
+	if p.DPI != 0 {
+		opts.DPI = p.DPI
+	}
+	if p.Margin != nil {
+		opts.Margin = *p.Margin
+	}
+	if p.MaxDimension != 0 {
+		opts.MaxDimension = p.MaxDimension
+	}
+	if p.Language != "" {
+		opts.Language = p.Language
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return &opts, nil
+}
+
+// outputName returns the default output name for a font.
+func outputName(font *fontimg.Font) string {
+	if font.Path != "" {
+		return strings.TrimSuffix(filepath.Base(font.Path), filepath.Ext(font.Path)) + ".png"
+	}
+	return fontimg.SafeFilename(font.Family) + ".png"
+}
+
+// OutputData is the data passed to output name templates. Values are
+// sanitized for use in file names (see [fontimg.SafeFilename]).
+type OutputData struct {
+	// Family is the font family.
+	Family string
+	// Style is the font style, or the rasterized style when the font's style
+	// is not known.
+	Style string
+	// Name is the font's file name, without extension.
+	Name string
+	// Size is the font size.
+	Size int
+}
+
+// executeOutputName executes the output name template for the font.
+func executeOutputName(tpl *template.Template, font *fontimg.Font, opts *fontimg.Options) (string, error) {
+	style := font.Style
+	if style == "" {
+		style = opts.Style.String()
+	}
+	var b strings.Builder
+	if err := tpl.Execute(&b, OutputData{
+		Family: fontimg.SafeFilename(font.Family),
+		Style:  fontimg.SafeFilename(style),
+		Name:   fontimg.SafeFilename(strings.TrimSuffix(filepath.Base(font.Path), filepath.Ext(font.Path))),
+		Size:   opts.Size,
+	}); err != nil {
+		return "", fmt.Errorf("invalid output name: %v", err)
+	}
+	name := b.String()
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid output name %q", name)
+	}
+	return name, nil
+}
+
+// writePNG atomically writes the image as a png to the named file, returning
+// the hex encoded SHA-256 hash of the written file.
+func writePNG(name string, img image.Image) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if err := writeFile(name, func(w io.Writer) error {
+		return png.Encode(io.MultiWriter(w, h), img)
+	}); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeFile atomically writes the named file, by writing to a temporary file
+// in the same directory that is renamed to the name once complete. Readers
+// of the file never see a partially written file.
+func writeFile(name string, f func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	err = f(tmp)
+	if err == nil {
+		err = tmp.Chmod(0o644)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// lockName is the name of the lock file in the output directory.
+const lockName = ".fontimg.lock"
+
+// lock acquires the output directory's lock, shared with other processes
+// using the same output directory, returning a func releasing the lock. When
+// dry is true, no lock is acquired.
+func (r *Runner) lock(dry bool) (func() error, error) {
+	if dry {
+		return func() error { return nil }, nil
+	}
+	return lock(filepath.Join(r.dir, lockName))
+}
+
+// Option is a batch runner option.
+type Option func(*Runner)
+
+// WithDir is a batch runner option to set the output directory.
+func WithDir(dir string) Option {
+	return func(r *Runner) {
+		r.dir = dir
+	}
+}
+
+// WithSystemFonts is a batch runner option to set the system fonts. When not
+// specified, the default system fonts are used.
+func WithSystemFonts(sysfonts *fontpkg.SystemFonts) Option {
+	return func(r *Runner) {
+		r.sysfonts = sysfonts
+	}
+}
+
+// StatsFunc is a metrics hook, called with the rendering statistics of each
+// rendered font.
+type StatsFunc func(font *fontimg.Font, stats *fontimg.Stats)
+
+// WithStats is a batch runner option to set a metrics hook, called with the
+// rendering statistics of each rendered font. The statistics are also
+// reported in the result manifest.
+func WithStats(f StatsFunc) Option {
+	return func(r *Runner) {
+		r.stats = f
+	}
+}
+
+// WithOptions is a batch runner option to set the base rasterization options,
+// to which manifest parameters are applied.
+func WithOptions(opts *fontimg.Options) Option {
+	return func(r *Runner) {
+		r.opts = *opts
+	}
+}
+
+// WithAuditLog is a batch runner option to append a record to the audit log
+// for each written output.
+func WithAuditLog(log *fontimg.AuditLog) Option {
+	return func(r *Runner) {
+		r.audit = log
+	}
+}
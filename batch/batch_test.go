@@ -0,0 +1,308 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kenshaw/fontimg"
+)
+
+func TestReadManifest(t *testing.T) {
+	tests := []string{
+		`{"defaults": {"size": 24, "fg": "000"}, "items": [{"font": "a.ttf", "output": "a.png", "size": 12}, {"font": "b.ttf", "margin": 0}]}`,
+		"defaults:\n  size: 24\n  fg: '000'\nitems:\n  - font: a.ttf\n    output: a.png\n    size: 12\n  - font: b.ttf\n    margin: 0\n",
+	}
+	for i, test := range tests {
+		m, err := ReadManifest(strings.NewReader(test))
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if m.Defaults.Size != 24 || m.Defaults.FG != "000" {
+			t.Errorf("test %d expected defaults, got: %+v", i, m.Defaults)
+		}
+		if len(m.Items) != 2 {
+			t.Fatalf("test %d expected 2 items, got: %d", i, len(m.Items))
+		}
+		if item := m.Items[0]; item.Font != "a.ttf" || item.Output != "a.png" || item.Size != 12 {
+			t.Errorf("test %d expected item, got: %+v", i, item)
+		}
+		p := m.Defaults.merge(m.Items[1].Params)
+		if p.Size != 24 || p.Margin == nil || *p.Margin != 0 {
+			t.Errorf("test %d expected merged params, got: %+v", i, p)
+		}
+	}
+	if _, err := ReadManifest(strings.NewReader(`items: [{output: a.png}]`)); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "manifest.yaml")
+	testdata, err := filepath.Abs("../testdata")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := os.WriteFile(manifest, []byte(`defaults:
+  size: 16
+  text: abc
+items:
+  - font: `+filepath.Join(testdata, "Ubuntu-R.ttf")+`
+    output: ubuntu.png
+    fg: ff0000
+  - font: `+filepath.Join(testdata, "NotoMono-Regular.ttf")+`
+  - font: missing.ttf
+`), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	m, err := LoadManifest(manifest)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	out := filepath.Join(dir, "out")
+	res, err := New(WithDir(out)).Run(context.Background(), m)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(res.Items) != 3 {
+		t.Fatalf("expected 3 results, got: %d", len(res.Items))
+	}
+	for i, exp := range []struct {
+		output string
+		status Status
+	}{
+		{"ubuntu.png", StatusOK},
+		{"NotoMono-Regular.png", StatusOK},
+		{"", StatusError},
+	} {
+		item := res.Items[i]
+		if item.Status != exp.status {
+			t.Errorf("item %d expected status %q, got: %q (%s)", i, exp.status, item.Status, item.Error)
+		}
+		if item.Output != exp.output {
+			t.Errorf("item %d expected output %q, got: %q", i, exp.output, item.Output)
+		}
+		if exp.status != StatusOK {
+			continue
+		}
+		if item.FontHash == "" || item.CacheKey == "" || item.OutputHash == "" || item.Width == 0 || item.Height == 0 {
+			t.Errorf("item %d expected hashes and dimensions, got: %+v", i, item)
+		}
+		if item.Ref == nil || item.Ref.Path != item.Path || item.Ref.Hash != item.FontHash {
+			t.Errorf("item %d expected ref for %s, got: %v", i, item.Path, item.Ref)
+		}
+		if _, err := os.Stat(filepath.Join(out, item.Output)); err != nil {
+			t.Errorf("item %d expected no error, got: %v", i, err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := res.WriteJSON(&buf); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var v Result
+	if err := json.Unmarshal(buf.Bytes(), &v); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(v.Items) != 3 || v.Items[0].Duration == 0 || v.Items[0].Ref == nil || *v.Items[0].Ref != *res.Items[0].Ref {
+		t.Errorf("expected decoded result, got: %+v", v)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	m := testManifest(t)
+	m.Items = append(m.Items,
+		Item{Font: m.Items[0].Font, Params: Params{Style: "Blah"}},
+		Item{Font: m.Items[0].Font, Params: Params{FG: "fff", BG: "fff"}},
+	)
+	res, err := New(WithDir(dir)).Plan(context.Background(), m)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	testStatuses(t, res, StatusPlanned, StatusPlanned, StatusError, StatusError)
+	if s := res.Items[3].Error; !strings.Contains(s, "fg and bg are both ffffffff") {
+		t.Errorf("expected fg and bg error, got: %q", s)
+	}
+	for _, item := range res.Items[:2] {
+		if item.CacheKey == "" || item.FontHash == "" {
+			t.Errorf("expected cache key and font hash, got: %+v", item)
+		}
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected output directory to not exist, got: %v", err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manifest{
+		Defaults: Params{Size: 16, Text: "abc"},
+		Items:    []Item{{Font: testFontDir(t, dir)}},
+	}
+	res, err := New(WithDir(filepath.Join(dir, "out"))).Run(context.Background(), m)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	testStatuses(t, res, StatusError, StatusError, StatusOK)
+	var errs fontimg.Errors
+	if !errors.As(res.Err(), &errs) {
+		t.Fatalf("expected fontimg.Errors, got: %v", res.Err())
+	}
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors, got: %v", errs)
+	}
+}
+
+func TestStats(t *testing.T) {
+	var stats []*fontimg.Stats
+	m := testManifest(t)
+	m.Items[1].MaxDimension = 50
+	res, err := New(WithDir(t.TempDir()), WithStats(func(font *fontimg.Font, st *fontimg.Stats) {
+		stats = append(stats, st)
+	})).Run(context.Background(), m)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	testStatuses(t, res, StatusOK, StatusOK)
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 stats, got: %d", len(stats))
+	}
+	for i, item := range res.Items {
+		st := stats[i]
+		exp := Stats{Glyphs: st.Glyphs, Lines: st.Lines, Layout: Duration(st.Layout), Raster: Duration(st.Raster)}
+		if i == 1 {
+			exp.Scale = st.Scale
+		}
+		switch {
+		case (i == 1) != (st.Scale < 1):
+			t.Errorf("item %d expected scaled only with a max dimension, got scale: %g", i, st.Scale)
+		case item.Stats == nil || *item.Stats != exp:
+			t.Errorf("item %d expected stats %+v, got: %+v", i, exp, item.Stats)
+		case st.Glyphs == 0 || st.Lines == 0:
+			t.Errorf("item %d expected glyphs and lines, got: %+v", i, st)
+		case st.Width != item.Width || st.Height != item.Height:
+			t.Errorf("item %d expected %dx%d, got: %dx%d", i, item.Width, item.Height, st.Width, st.Height)
+		case i == 1 && (50 < item.Width || 50 < item.Height):
+			t.Errorf("item %d expected at most 50x50, got: %dx%d", i, item.Width, item.Height)
+		}
+	}
+}
+
+func TestOutputName(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name   string
+		output string
+		exp    string
+	}{
+		{"family", "{{ .Family }}-{{ .Style }}-{{ .Size }}.png", "Ubuntu R-Regular-16.png"},
+		{"dir", "{{ .Name }}/{{ .Size }}.png", filepath.Join("Ubuntu-R", "16.png")},
+		{"escape", "../{{ .Name }}.png", ""},
+		{"empty", "{{ if false }}x{{ end }}", ""},
+		{"missing field", "{{ .Missing }}.png", ""},
+		{"invalid", "{{ .Name", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := testManifest(t)
+			m.Items = m.Items[:1]
+			m.Defaults.OutputName = test.output
+			res, err := New(WithDir(filepath.Join(dir, test.name))).Run(context.Background(), m)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			item := res.Items[0]
+			switch {
+			case test.exp == "" && item.Status != StatusError:
+				t.Errorf("expected error, got: %q", item.Output)
+			case test.exp == "":
+			case item.Status != StatusOK:
+				t.Fatalf("expected status ok, got: %q (%s)", item.Status, item.Error)
+			case item.Output != test.exp:
+				t.Errorf("expected %q, got: %q", test.exp, item.Output)
+			}
+		})
+	}
+}
+
+func TestOutputEscape(t *testing.T) {
+	dir := t.TempDir()
+	for _, output := range []string{"../../x.png", "/x.png"} {
+		m := testManifest(t)
+		m.Items = m.Items[:1]
+		m.Items[0].Output = output
+		res, err := New(WithDir(filepath.Join(dir, "a", "out"))).Run(context.Background(), m)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if item := res.Items[0]; item.Status != StatusError {
+			t.Errorf("%q expected error, got: %q", output, item.Status)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "x.png")); err == nil {
+		t.Errorf("expected no output outside of dir")
+	}
+}
+
+// testFontDir creates a fonts directory in dir containing a valid font
+// (Ubuntu-R.ttf), an unrecognized font (bad.ttf), and a truncated font
+// (trunc.ttf).
+func testFontDir(t *testing.T, dir string) string {
+	t.Helper()
+	buf, err := os.ReadFile(filepath.Join("..", "testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fonts := filepath.Join(dir, "fonts")
+	if err := os.MkdirAll(fonts, 0o755); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for name, b := range map[string][]byte{
+		"Ubuntu-R.ttf": buf,
+		"bad.ttf":      []byte("not a font"),
+		"trunc.ttf":    buf[:128],
+	} {
+		if err := os.WriteFile(filepath.Join(fonts, name), b, 0o644); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	return fonts
+}
+
+func TestAuditLog(t *testing.T) {
+	m, dir := testManifest(t), t.TempDir()
+	var buf bytes.Buffer
+	r := New(WithDir(dir), WithSidecars(true), WithAuditLog(fontimg.NewAuditLog(&buf)))
+	for range 2 {
+		res, err := r.Run(context.Background(), m)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(res.Items) != 2 {
+			t.Fatalf("expected 2 results, got: %d", len(res.Items))
+		}
+		// skipped items are not logged
+		dec := json.NewDecoder(bytes.NewReader(buf.Bytes()))
+		for i, item := range res.Items {
+			var rec fontimg.AuditRecord
+			if err := dec.Decode(&rec); err != nil {
+				t.Fatalf("item %d: expected no error, got: %v", i, err)
+			}
+			switch {
+			case rec.Source != "batch", rec.Time.IsZero(), rec.OptionsHash == "":
+				t.Errorf("item %d: unexpected record %+v", i, rec)
+			case rec.Output != item.Output, rec.OutputHash != item.OutputHash, rec.FontHash != item.FontHash:
+				t.Errorf("item %d: expected record for %+v, got: %+v", i, item, rec)
+			}
+		}
+		if dec.More() {
+			t.Errorf("expected 2 records")
+		}
+	}
+}
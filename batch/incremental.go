@@ -0,0 +1,133 @@
+package batch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// sidecarExt is the extension of sidecar files written next to outputs.
+const sidecarExt = ".fontimg.json"
+
+// LoadResult loads a JSON or YAML result manifest from a file, for use with
+// [WithPrevious].
+func LoadResult(name string) (*Result, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadResult(f)
+}
+
+// ReadResult reads a JSON or YAML result manifest from r.
+func ReadResult(r io.Reader) (*Result, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	res := new(Result)
+	if err := unmarshal(buf, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// skip determines if rendering the item can be skipped, because the cache key
+// of its existing output matches either the previous result manifest or the
+// output's sidecar. When skipped, the result is updated with the existing
+// output's details.
+func (r *Runner) skip(res *ItemResult) bool {
+	var prev *ItemResult
+	if r.prev != nil {
+		if item, ok := r.prev[res.Output]; ok && item.Status != StatusError && item.Status != StatusFallback {
+			prev = &item
+		}
+	}
+	if prev == nil && r.sidecars {
+		prev = readSidecar(filepath.Join(r.dir, res.Output))
+	}
+	if prev == nil || prev.CacheKey != res.CacheKey {
+		return false
+	}
+	hash, err := fileHash(filepath.Join(r.dir, res.Output))
+	if err != nil || (prev.OutputHash != "" && prev.OutputHash != hash) {
+		return false
+	}
+	res.OutputHash, res.Width, res.Height = hash, prev.Width, prev.Height
+	return true
+}
+
+// writeSidecar writes the sidecar for the result.
+func (r *Runner) writeSidecar(res ItemResult) error {
+	if !r.sidecars {
+		return nil
+	}
+	buf, err := json.Marshal(ItemResult{
+		CacheKey:   res.CacheKey,
+		OutputHash: res.OutputHash,
+		Width:      res.Width,
+		Height:     res.Height,
+	})
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(r.dir, res.Output+sidecarExt), func(w io.Writer) error {
+		_, err := w.Write(buf)
+		return err
+	})
+}
+
+// readSidecar reads the sidecar for the output, returning nil when it does
+// not exist or is invalid.
+func readSidecar(output string) *ItemResult {
+	buf, err := os.ReadFile(output + sidecarExt)
+	if err != nil {
+		return nil
+	}
+	res := new(ItemResult)
+	if err := json.Unmarshal(buf, res); err != nil {
+		return nil
+	}
+	return res
+}
+
+// fileHash returns the hex encoded SHA-256 hash of the named file.
+func fileHash(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WithPrevious is a batch runner option to skip rendering items whose cache
+// key matches the item with the same output in a previous result manifest,
+// provided the output is unchanged since.
+func WithPrevious(prev *Result) Option {
+	return func(r *Runner) {
+		r.prev = make(map[string]ItemResult)
+		for _, item := range prev.Items {
+			if item.Output != "" {
+				r.prev[item.Output] = item
+			}
+		}
+	}
+}
+
+// WithSidecars is a batch runner option to write a sidecar file next to each
+// output containing its cache key, and to skip rendering items whose sidecar
+// cache key matches.
+func WithSidecars(sidecars bool) Option {
+	return func(r *Runner) {
+		r.sidecars = sidecars
+	}
+}
@@ -0,0 +1,91 @@
+package batch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIncremental(t *testing.T) {
+	m := testManifest(t)
+	for _, test := range []struct {
+		name string
+		opts func(*Result) []Option
+	}{
+		{"previous", func(prev *Result) []Option {
+			if prev == nil {
+				return nil
+			}
+			return []Option{WithPrevious(prev)}
+		}},
+		{"sidecars", func(*Result) []Option {
+			return []Option{WithSidecars(true)}
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			res, err := New(append(test.opts(nil), WithDir(dir))...).Run(context.Background(), m)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			testStatuses(t, res, StatusOK, StatusOK)
+			// plan
+			res, err = New(append(test.opts(res), WithDir(dir))...).Plan(context.Background(), m)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			testStatuses(t, res, StatusSkipped, StatusSkipped)
+			// rerun
+			res, err = New(append(test.opts(res), WithDir(dir))...).Run(context.Background(), m)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			testStatuses(t, res, StatusSkipped, StatusSkipped)
+			if res.Items[0].Width == 0 || res.Items[0].OutputHash == "" {
+				t.Errorf("expected skipped result to have output details, got: %+v", res.Items[0])
+			}
+			// change an item, and remove an output
+			m.Items[0].Size = 20
+			if err := os.Remove(filepath.Join(dir, res.Items[1].Output)); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			res, err = New(append(test.opts(res), WithDir(dir))...).Run(context.Background(), m)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			testStatuses(t, res, StatusOK, StatusOK)
+			m.Items[0].Size = 0
+		})
+	}
+}
+
+func testManifest(t *testing.T) *Manifest {
+	t.Helper()
+	testdata, err := filepath.Abs("../testdata")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return &Manifest{
+		Defaults: Params{
+			Size: 16,
+			Text: "abc",
+		},
+		Items: []Item{
+			{Font: filepath.Join(testdata, "Ubuntu-R.ttf")},
+			{Font: filepath.Join(testdata, "NotoMono-Regular.ttf")},
+		},
+	}
+}
+
+func testStatuses(t *testing.T, res *Result, exp ...Status) {
+	t.Helper()
+	if len(res.Items) != len(exp) {
+		t.Fatalf("expected %d results, got: %d", len(exp), len(res.Items))
+	}
+	for i, status := range exp {
+		if res.Items[i].Status != status {
+			t.Errorf("item %d expected status %q, got: %q (%s)", i, status, res.Items[i].Status, res.Items[i].Error)
+		}
+	}
+}
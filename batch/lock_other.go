@@ -0,0 +1,35 @@
+//go:build !unix
+
+package batch
+
+import (
+	"os"
+	"time"
+)
+
+// lockStale is the age after which a lock file is considered abandoned by a
+// crashed process.
+const lockStale = 5 * time.Minute
+
+// lock acquires an exclusive lock by exclusively creating the named lock
+// file, polling until the lock is available, as file locking is not
+// supported. The lock is released by the returned func.
+func lock(name string) (func() error, error) {
+	for {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		switch {
+		case err == nil:
+			return func() error {
+				f.Close()
+				return os.Remove(name)
+			}, nil
+		case !os.IsExist(err):
+			return nil, err
+		}
+		if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(name)
+			continue
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
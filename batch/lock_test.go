@@ -0,0 +1,91 @@
+package batch
+
+import (
+	"context"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	name := filepath.Join(t.TempDir(), lockName)
+	var held, max atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 10 {
+				unlock, err := lock(name)
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+					return
+				}
+				n := held.Add(1)
+				if n > max.Load() {
+					max.Store(n)
+				}
+				held.Add(-1)
+				if err := unlock(); err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	if n := max.Load(); n != 1 {
+		t.Errorf("expected lock to be held by 1, got: %d", n)
+	}
+}
+
+func TestConcurrentRun(t *testing.T) {
+	m, dir := testManifest(t), t.TempDir()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			res, err := New(WithDir(dir), WithSidecars(true)).Run(context.Background(), m)
+			if err != nil {
+				t.Errorf("expected no error, got: %v", err)
+				return
+			}
+			for i, item := range res.Items {
+				if item.Status != StatusOK && item.Status != StatusSkipped {
+					t.Errorf("item %d expected ok or skipped, got: %q (%s)", i, item.Status, item.Error)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case name == lockName:
+		case strings.HasSuffix(name, sidecarExt):
+			res := readSidecar(filepath.Join(dir, strings.TrimSuffix(name, sidecarExt)))
+			if res == nil {
+				t.Errorf("expected valid sidecar %s", name)
+				continue
+			}
+			if hash, err := fileHash(filepath.Join(dir, strings.TrimSuffix(name, sidecarExt))); err != nil || hash != res.OutputHash {
+				t.Errorf("expected sidecar %s to match output hash, got: %s %v", name, hash, err)
+			}
+		case strings.HasSuffix(name, ".png"):
+			f, err := os.Open(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if _, err := png.Decode(f); err != nil {
+				t.Errorf("expected valid png %s, got: %v", name, err)
+			}
+			f.Close()
+		default:
+			t.Errorf("unexpected file %s", name)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package batch
+
+import (
+	"os"
+	"syscall"
+)
+
+// lock acquires an exclusive lock on the named lock file, creating it if
+// necessary and blocking until the lock is available. The lock is released
+// by the returned func.
+func lock(name string) (func() error, error) {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	// closing the file releases the lock
+	return f.Close, nil
+}
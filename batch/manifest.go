@@ -0,0 +1,288 @@
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kenshaw/fontimg"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is a batch manifest, listing the fonts to render.
+type Manifest struct {
+	// Defaults are the default parameters for all items.
+	Defaults Params `json:"defaults" yaml:"defaults"`
+	// Items are the items to render.
+	Items []Item `json:"items" yaml:"items"`
+	// dir is the directory relative paths are resolved against.
+	dir string
+}
+
+// Item is a batch manifest item.
+type Item struct {
+	// Font is the font path or system font name.
+	Font string `json:"font" yaml:"font"`
+	// Output is the output file name, relative to the output directory. When
+	// empty, the output name is derived from the font's path.
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+	// Params are the item's parameters, overriding the manifest defaults.
+	Params `yaml:",inline"`
+}
+
+// Params are rasterization parameters. Zero values are not applied.
+type Params struct {
+	// Template is the path to a text template file.
+	Template string `json:"template,omitempty" yaml:"template,omitempty"`
+	// Preset is the name of a template preset (see [fontimg.Presets]), used
+	// when Template is empty.
+	Preset string `json:"preset,omitempty" yaml:"preset,omitempty"`
+	// Text is the sample text.
+	Text string `json:"text,omitempty" yaml:"text,omitempty"`
+	// Style is the font style (ie, "Bold Italic").
+	Style string `json:"style,omitempty" yaml:"style,omitempty"`
+	// Size is the font size.
+	Size int `json:"size,omitempty" yaml:"size,omitempty"`
+	// FG is the foreground color, as hex (ie, 000, ff0000).
+	FG string `json:"fg,omitempty" yaml:"fg,omitempty"`
+	// BG is the background color, as hex (ie, fff, 00000000).
+	BG string `json:"bg,omitempty" yaml:"bg,omitempty"`
+	// DPI is the rasterization DPI.
+	DPI float64 `json:"dpi,omitempty" yaml:"dpi,omitempty"`
+	// Margin is the margin.
+	Margin *float64 `json:"margin,omitempty" yaml:"margin,omitempty"`
+	// MaxDimension is the maximum image width and height, in pixels, to
+	// which content is scaled down to fit.
+	MaxDimension int `json:"max_dimension,omitempty" yaml:"max_dimension,omitempty"`
+	// Language is the language of built-in labels (ie, "de").
+	Language string `json:"language,omitempty" yaml:"language,omitempty"`
+	// OutputName is a text template for the output names of items without
+	// an output (ie, "{{ .Family }}-{{ .Style }}-{{ .Size }}.png"), executed
+	// with [OutputData]. When empty, the output name is derived from the
+	// font's path.
+	OutputName string `json:"output_name,omitempty" yaml:"output_name,omitempty"`
+}
+
+// merge returns a copy of p with the non-zero values of o applied.
+func (p Params) merge(o Params) Params {
+	if o.Template != "" {
+		p.Template = o.Template
+	}
+	if o.Preset != "" {
+		p.Preset = o.Preset
+	}
+	if o.Text != "" {
+		p.Text = o.Text
+	}
+	if o.Style != "" {
+		p.Style = o.Style
+	}
+	if o.Size != 0 {
+		p.Size = o.Size
+	}
+	if o.FG != "" {
+		p.FG = o.FG
+	}
+	if o.BG != "" {
+		p.BG = o.BG
+	}
+	if o.DPI != 0 {
+		p.DPI = o.DPI
+	}
+	if o.Margin != nil {
+		p.Margin = o.Margin
+	}
+	if o.MaxDimension != 0 {
+		p.MaxDimension = o.MaxDimension
+	}
+	if o.Language != "" {
+		p.Language = o.Language
+	}
+	if o.OutputName != "" {
+		p.OutputName = o.OutputName
+	}
+	return p
+}
+
+// ReadManifest reads a JSON or YAML manifest from r. Relative paths in the
+// manifest are resolved against the current working directory.
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	m := new(Manifest)
+	if err := unmarshal(buf, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	for i, item := range m.Items {
+		if item.Font == "" {
+			return nil, fmt.Errorf("invalid manifest: item %d: missing font", i)
+		}
+	}
+	return m, nil
+}
+
+// LoadManifest loads a JSON or YAML manifest from a file. Relative paths in
+// the manifest are resolved against the manifest's directory.
+func LoadManifest(name string) (*Manifest, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := ReadManifest(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	m.dir = filepath.Dir(name)
+	return m, nil
+}
+
+// unmarshal unmarshals JSON or YAML.
+func unmarshal(buf []byte, v any) error {
+	// yaml is a superset of json
+	return yaml.Unmarshal(buf, v)
+}
+
+// path resolves a manifest path.
+func (m *Manifest) path(name string) string {
+	if m.dir == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(m.dir, name)
+}
+
+// Status is a result status.
+type Status string
+
+// Result statuses.
+const (
+	StatusOK       Status = "ok"
+	StatusError    Status = "error"
+	StatusSkipped  Status = "skipped"
+	StatusPlanned  Status = "planned"
+	StatusFallback Status = "fallback"
+)
+
+// Result is a batch result manifest.
+type Result struct {
+	Items []ItemResult `json:"items" yaml:"items"`
+}
+
+// ItemResult is the result of rendering a single font.
+type ItemResult struct {
+	// Font is the item's font, as specified in the manifest.
+	Font string `json:"font" yaml:"font"`
+	// Path is the resolved font path.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Output is the output file name.
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+	// Status is the result status.
+	Status Status `json:"status" yaml:"status"`
+	// Error is the error message, when the status is error or fallback.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	// Ref is the font's reference (see [fontimg.Font.Ref]).
+	Ref *fontimg.Ref `json:"ref,omitempty" yaml:"ref,omitempty"`
+	// FontHash is the hash of the font's content.
+	FontHash string `json:"font_hash,omitempty" yaml:"font_hash,omitempty"`
+	// CacheKey is the cache key of the rendered image.
+	CacheKey string `json:"cache_key,omitempty" yaml:"cache_key,omitempty"`
+	// OutputHash is the hash of the output file's content.
+	OutputHash string `json:"output_hash,omitempty" yaml:"output_hash,omitempty"`
+	// Width is the width of the rendered image.
+	Width int `json:"width,omitempty" yaml:"width,omitempty"`
+	// Height is the height of the rendered image.
+	Height int `json:"height,omitempty" yaml:"height,omitempty"`
+	// Duration is the time taken to render the item.
+	Duration Duration `json:"duration" yaml:"duration"`
+	// Stats are the rendering statistics.
+	Stats *Stats `json:"stats,omitempty" yaml:"stats,omitempty"`
+}
+
+// Stats are rendering statistics (see [fontimg.Stats]).
+type Stats struct {
+	// Glyphs is the number of glyphs drawn.
+	Glyphs int `json:"glyphs" yaml:"glyphs"`
+	// Lines is the number of lines of text drawn.
+	Lines int `json:"lines" yaml:"lines"`
+	// Layout is the time taken to lay out the text.
+	Layout Duration `json:"layout" yaml:"layout"`
+	// Raster is the time taken to rasterize the image.
+	Raster Duration `json:"raster" yaml:"raster"`
+	// Scale is the factor the content was scaled down by to fit the max
+	// dimension, when scaled.
+	Scale float64 `json:"scale,omitempty" yaml:"scale,omitempty"`
+}
+
+// Err returns the error of the result, if any.
+func (res ItemResult) Err() error {
+	if res.Status == StatusError {
+		return fmt.Errorf("%s: %s", res.Font, res.Error)
+	}
+	return nil
+}
+
+// Err returns the failed items' errors as a [fontimg.Errors], or nil when no
+// items failed.
+func (r *Result) Err() error {
+	var errs fontimg.Errors
+	for _, res := range r.Items {
+		if res.Status != StatusError {
+			continue
+		}
+		path := res.Path
+		if path == "" {
+			path = res.Font
+		}
+		errs = append(errs, &fontimg.FontError{
+			Path: path,
+			Err:  errors.New(res.Error),
+		})
+	}
+	return errs.Err()
+}
+
+// WriteJSON writes the result manifest as JSON to w.
+func (r *Result) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteYAML writes the result manifest as YAML to w.
+func (r *Result) WriteYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(r); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// Duration is a [time.Duration] that marshals as a string (ie, "1.5s").
+type Duration time.Duration
+
+// String satisfies the [fmt.Stringer] interface.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText satisfies the [encoding.TextMarshaler] interface.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText satisfies the [encoding.TextUnmarshaler] interface.
+func (d *Duration) UnmarshalText(buf []byte) error {
+	v, err := time.ParseDuration(string(buf))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
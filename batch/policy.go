@@ -0,0 +1,94 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"text/template"
+	"time"
+
+	"github.com/kenshaw/fontimg"
+	"github.com/tdewolff/canvas"
+	fontpkg "github.com/tdewolff/font"
+)
+
+// FallbackFunc renders a fallback image for a font that failed to rasterize
+// with err.
+type FallbackFunc func(font *fontimg.Font, opts *fontimg.Options, err error) (image.Image, error)
+
+// NameCard returns a fallback that renders a plain card with the font's name,
+// using the named font (ie, "sans-serif") from the system fonts. When
+// sysfonts is nil, the default system fonts are used.
+func NameCard(name string, sysfonts *fontpkg.SystemFonts) FallbackFunc {
+	return func(font *fontimg.Font, opts *fontimg.Options, _ error) (image.Image, error) {
+		sys := sysfonts
+		if sys == nil {
+			var err error
+			if sys, err = fontimg.SystemFonts(); err != nil {
+				return nil, err
+			}
+		}
+		f := fontimg.Match(name, canvas.FontRegular, sys)
+		if f == nil {
+			return nil, fmt.Errorf("unable to locate fallback font %q", name)
+		}
+		o := *opts
+		o.Template, o.Text, o.Style = tplNameCard, font.BestName(), canvas.FontRegular
+		return f.RasterizeOptions(&o)
+	}
+}
+
+// tplNameCard is the name card template.
+var tplNameCard = template.Must(fontimg.NewTemplate("{{ .SampleText }}\n"))
+
+// retry calls f, retrying transient errors up to the runner's retry attempts.
+func (r *Runner) retry(ctx context.Context, f func() error) error {
+	for i := 0; ; i++ {
+		err := f()
+		if err == nil || r.retries <= i || !transient(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.retryDelay << i):
+		}
+	}
+}
+
+// transient returns true when err is a file system error other than the file
+// not existing or lacking permission.
+func transient(err error) bool {
+	var pe *fs.PathError
+	return errors.As(err, &pe) &&
+		!errors.Is(err, fs.ErrNotExist) &&
+		!errors.Is(err, fs.ErrPermission) &&
+		!errors.Is(err, fs.ErrInvalid)
+}
+
+// WithRetry is a batch runner option to retry transient I/O errors (ie,
+// reading fonts or writing outputs) up to the number of attempts, doubling
+// the delay between each attempt.
+func WithRetry(attempts int, delay time.Duration) Option {
+	return func(r *Runner) {
+		r.retries, r.retryDelay = attempts, delay
+	}
+}
+
+// WithFallback is a batch runner option to render a fallback image when a
+// font fails to rasterize, so that an output is always produced. Items
+// rendered by the fallback are reported with [StatusFallback], and are not
+// skipped on subsequent incremental runs.
+func WithFallback(f FallbackFunc) Option {
+	return func(r *Runner) {
+		r.fallback = f
+	}
+}
+
+// Placeholder is a fallback that renders a standardized "preview unavailable"
+// card for the font. See [fontimg.RenderPlaceholder].
+func Placeholder(font *fontimg.Font, opts *fontimg.Options, err error) (image.Image, error) {
+	return fontimg.RenderPlaceholder(font.BestName(), err.Error(), opts)
+}
@@ -0,0 +1,90 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback FallbackFunc
+	}{
+		{"namecard", NameCard("sans-serif", nil)},
+		{"placeholder", Placeholder},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			m := &Manifest{
+				Defaults: Params{Size: 16, Text: "abc"},
+				Items:    []Item{{Font: testFontDir(t, dir)}},
+			}
+			out := filepath.Join(dir, "out")
+			r := New(
+				WithDir(out),
+				WithSidecars(true),
+				WithFallback(test.fallback),
+			)
+			res, err := r.Run(context.Background(), m)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			testStatuses(t, res, StatusError, StatusFallback, StatusOK)
+			item := res.Items[1]
+			if item.Error == "" || item.Width == 0 || item.Height == 0 {
+				t.Errorf("expected error and dimensions, got: %+v", item)
+			}
+			if _, err := os.Stat(filepath.Join(out, item.Output)); err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(out, item.Output+sidecarExt)); !os.IsNotExist(err) {
+				t.Errorf("expected no sidecar for fallback, got: %v", err)
+			}
+			// fallbacks are not skipped
+			if res, err = r.Run(context.Background(), m); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			testStatuses(t, res, StatusError, StatusFallback, StatusSkipped)
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		err  error
+		exp  int
+		fail bool
+	}{
+		{&fs.PathError{Op: "read", Path: "a", Err: syscall.EIO}, 3, false},
+		{&fs.PathError{Op: "open", Path: "a", Err: fs.ErrNotExist}, 1, true},
+		{errors.New("parse error"), 1, true},
+	}
+	for _, test := range tests {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			r := New(WithRetry(3, time.Millisecond))
+			var n int
+			err := r.retry(context.Background(), func() error {
+				if n++; n < 3 {
+					return test.err
+				}
+				return nil
+			})
+			switch {
+			case test.fail && err == nil:
+				t.Errorf("expected error")
+			case !test.fail && err != nil:
+				t.Errorf("expected no error, got: %v", err)
+			}
+			if n != test.exp {
+				t.Errorf("expected %d attempts, got: %d", test.exp, n)
+			}
+		})
+	}
+}
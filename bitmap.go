@@ -0,0 +1,190 @@
+package fontimg
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/tdewolff/canvas"
+)
+
+// BitmapFont is a bitmap font, with one or more strikes.
+type BitmapFont struct {
+	Family  string
+	Style   string
+	Strikes []*Strike
+}
+
+// Strike is a bitmap font strike, the glyphs for a single pixel height.
+type Strike struct {
+	// Height is the height of the glyphs in pixels.
+	Height int
+	// Ascent is the number of pixels above the baseline.
+	Ascent int
+	// Glyphs are the glyph bitmaps, where each bitmap's width is the glyph's
+	// advance.
+	Glyphs map[rune]*image.Alpha
+	// Default is the rune drawn for runes without a glyph.
+	Default rune
+	// Cells are the glyph cells in font order, for formats with fixed glyph
+	// cells (ie, PSF console fonts).
+	Cells []Cell
+}
+
+// Cell is a glyph cell of a bitmap font strike.
+type Cell struct {
+	Glyph *image.Alpha
+	// Runes are the runes mapped to the cell.
+	Runes []rune
+}
+
+// strike returns the strike and integer scale best matching the pixel
+// height, preferring the least scaled strike.
+func (bf *BitmapFont) strike(height int) (*Strike, int) {
+	var strike *Strike
+	var scale, diff int
+	for _, s := range bf.Strikes {
+		n := max(1, int(math.Round(float64(height)/float64(s.Height))))
+		if d := abs(s.Height*n - height); strike == nil || d < diff || (d == diff && n < scale) {
+			strike, scale, diff = s, n, d
+		}
+	}
+	return strike, scale
+}
+
+// glyph returns the glyph for the rune, or the default glyph.
+func (s *Strike) glyph(r rune) *image.Alpha {
+	if img, ok := s.Glyphs[r]; ok {
+		return img
+	}
+	return s.Glyphs[s.Default]
+}
+
+// Bitmap decodes the font as a bitmap font using the registered decoders
+// (see [RegisterDecoder]). Returns an error when the font is not a recognized
+// bitmap font format.
+func (font *Font) Bitmap() (*BitmapFont, error) {
+	buf, err := font.data()
+	if err != nil {
+		return nil, err
+	}
+	d := decoder(buf)
+	if d == nil {
+		return nil, fmt.Errorf("not a bitmap font")
+	}
+	bf, err := d.Decode(buf)
+	switch {
+	case err != nil:
+		return nil, err
+	case len(bf.Strikes) == 0:
+		return nil, fmt.Errorf("bitmap font has no strikes")
+	}
+	font.once.Do(func() {
+		font.Name, font.Style = bf.Family, bf.Style
+	})
+	return bf, nil
+}
+
+// header returns the first bytes of the font's data.
+func (font *Font) header() ([]byte, error) {
+	if font.Buf != nil {
+		return font.Buf, nil
+	}
+	if font.Path == "" {
+		return nil, fmt.Errorf("font.Buf and font.Path not set")
+	}
+	return readHeader(font.Path)
+}
+
+// bitmapCanvas lays out the bitmap font image on a canvas using the options.
+// Each line is drawn with the strike best matching the line's size, scaled
+// by a whole number so pixels remain square. The drawn lines and glyphs are
+// counted in st when not nil.
+func (font *Font) bitmapCanvas(bf *BitmapFont, opts *Options, st *Stats) (*canvas.Canvas, error) {
+	buf, err := font.text(opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	// create canvas and context
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	ctx.SetFillColor(opts.FG)
+	// draw text
+	lines, sizes, _ := breakLines(buf, opts.Size)
+	var missing []rune
+	for i, y := 0, float64(0); i < len(lines); i++ {
+		strike, scale := bf.strike(int(math.Round(float64(sizes[i]) * opts.DPI / 72)))
+		px := float64(scale) * 25.4 / opts.DPI
+		x, line := float64(0), strings.TrimSpace(lines[i])
+		if st != nil && line != "" {
+			st.Lines++
+		}
+		for _, r := range line {
+			if _, ok := strike.Glyphs[r]; !ok && !unicode.IsControl(r) {
+				missing = append(missing, r)
+			}
+			img := strike.glyph(r)
+			if img == nil {
+				continue
+			}
+			ctx.DrawPath(x, y, bitmapPath(img).Scale(px, px))
+			x += float64(img.Rect.Dx()) * px
+			if st != nil && !unicode.IsSpace(r) {
+				st.Glyphs++
+			}
+		}
+		y -= float64(strike.Height) * px
+	}
+	if opts.Strict {
+		if err := missingRunes(missing); err != nil {
+			return nil, err
+		}
+	}
+	// draw footer
+	if err := drawFooter(c, ctx, nil, opts); err != nil {
+		return nil, err
+	}
+	// fit canvas to context
+	fitCanvas(c, opts, st)
+	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
+
+// bitmapPath returns the path of the bitmap's set pixels, in pixel units
+// below the origin.
+func bitmapPath(img *image.Alpha) *canvas.Path {
+	p := new(canvas.Path)
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	for y := range h {
+		for x := 0; x < w; x++ {
+			if img.Pix[y*img.Stride+x] < 0x80 {
+				continue
+			}
+			// extend run
+			x0 := x
+			for x < w && 0x80 <= img.Pix[y*img.Stride+x] {
+				x++
+			}
+			p.MoveTo(float64(x0), float64(-y))
+			p.LineTo(float64(x0), float64(-y-1))
+			p.LineTo(float64(x), float64(-y-1))
+			p.LineTo(float64(x), float64(-y))
+			p.Close()
+		}
+	}
+	return p
+}
+
+// abs returns the absolute value of i.
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
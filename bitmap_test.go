@@ -0,0 +1,41 @@
+package fontimg
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestBitmap(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		buf  []byte
+	}{
+		{"test.fon", testFON()},
+		{"test.fnt", testFNT(0x0300, 16, 400, false)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			font := New(test.buf, test.name)
+			opts := DefaultOptions()
+			opts.Text, opts.BG = "ABC", color.White
+			img, err := font.RasterizeOptions(opts)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if font.Name != "Test" {
+				t.Errorf("expected Test, got: %q", font.Name)
+			}
+			var dark int
+			for i := 0; i < len(img.Pix); i += 4 {
+				if img.Pix[i] < 0x80 {
+					dark++
+				}
+			}
+			if dark == 0 {
+				t.Errorf("expected glyph pixels")
+			}
+		})
+	}
+	if _, err := New(nil, "testdata/Ubuntu-R.ttf").Bitmap(); err == nil {
+		t.Errorf("expected error for non bitmap font")
+	}
+}
@@ -0,0 +1,116 @@
+package fontimg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/pdf"
+)
+
+// Book page dimensions and margin, in millimeters (A4).
+const (
+	bookWidth  = 210
+	bookHeight = 297
+	bookMargin = 15
+)
+
+// Book writes a multi-page PDF specimen book of the fonts to w, with an A4
+// page per font showing the font image (see [Font.Canvas]) above an
+// overview of the font's first 256 glyphs, and a document outline entry per
+// font. Content is scaled down to fit the page. Fonts that cannot be laid
+// out are skipped, and their errors are returned joined after the book is
+// written. When opts is nil, the default options will be used.
+func Book(w io.Writer, fonts []*Font, opts *Options) error {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	var pages [][]*canvas.Canvas
+	var names []string
+	var errs []error
+	for _, font := range fonts {
+		page, err := bookPage(font, opts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", font, err))
+			continue
+		}
+		pages, names = append(pages, page), append(names, font.String())
+	}
+	if len(pages) == 0 {
+		return errors.Join(append([]error{errors.New("no fonts to write")}, errs...)...)
+	}
+	r := pdf.New(w, bookWidth, bookHeight, nil)
+	r.SetInfo("Specimen book", "", "", "", "")
+	for i, page := range pages {
+		if i != 0 {
+			r.NewPage(bookWidth, bookHeight)
+		}
+		r.AddOutline(names[i], 0, bookHeight-bookMargin)
+		renderBookPage(r, page)
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// bookPage lays out the canvases of a font's specimen book page.
+func bookPage(font *Font, opts *Options) ([]*canvas.Canvas, error) {
+	c, err := font.Canvas(opts)
+	if err != nil {
+		return nil, err
+	}
+	// bitmap fonts have no glyph overview
+	runes, err := font.Runes()
+	if err != nil {
+		return []*canvas.Canvas{c}, nil
+	}
+	runes = slices.DeleteFunc(runes, func(r rune) bool {
+		return !unicode.IsGraphic(r) || unicode.IsSpace(r)
+	})
+	if len(runes) == 0 {
+		return []*canvas.Canvas{c}, nil
+	}
+	var sb strings.Builder
+	for i, r := range runes[:min(len(runes), 256)] {
+		switch {
+		case i == 0:
+		case i%16 == 0:
+			sb.WriteByte('\n')
+		default:
+			sb.WriteByte(' ')
+		}
+		sb.WriteRune(r)
+	}
+	o := *opts
+	o.Template, o.Text = tplBookGlyphs, sb.String()
+	glyphs, err := font.Canvas(&o)
+	if err != nil {
+		return nil, err
+	}
+	return []*canvas.Canvas{c, glyphs}, nil
+}
+
+// renderBookPage renders the canvases of a specimen book page to r, stacked
+// from the top of the page and scaled down to fit within the page's margins.
+func renderBookPage(r canvas.Renderer, page []*canvas.Canvas) {
+	width, height := float64(0), float64(bookMargin)*float64(len(page)-1)
+	for _, c := range page {
+		width, height = max(width, c.W), height+c.H
+	}
+	scale := min(1, (bookWidth-2*bookMargin)/width, (bookHeight-2*bookMargin)/height)
+	y := float64(bookHeight - bookMargin)
+	for _, c := range page {
+		y -= c.H * scale
+		c.RenderViewTo(r, canvas.Identity.Translate(bookMargin, y).Scale(scale, scale))
+		y -= bookMargin * scale
+	}
+}
+
+// tplBookGlyphs is the specimen book glyph overview template.
+var tplBookGlyphs = template.Must(NewTemplate(`{{ size .Size }}{{ .SampleText }}`))
@@ -0,0 +1,33 @@
+package fontimg
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestBook(t *testing.T) {
+	fonts := []*Font{
+		New(nil, filepath.Join("testdata", "Ubuntu-R.ttf")),
+		{Buf: []byte("invalid"), Path: "invalid.ttf"},
+		New(nil, filepath.Join("testdata", "NotoMono-Regular.ttf")),
+		{Buf: testPSF2(true)},
+	}
+	var buf bytes.Buffer
+	err := Book(&buf, fonts, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid.ttf") {
+		t.Errorf("expected invalid font error, got: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("expected pdf, got: %q", buf.Bytes()[:min(buf.Len(), 16)])
+	}
+	if n := len(regexp.MustCompile(`/Type\s*/Page\b`).FindAll(buf.Bytes(), -1)); n != 3 {
+		t.Errorf("expected 3 pages, got: %d", n)
+	}
+	if err := Book(io.Discard, fonts[1:2], nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
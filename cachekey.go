@@ -0,0 +1,189 @@
+package fontimg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"sort"
+)
+
+// cacheKeyVersion is the version of the cache key format. It should be
+// incremented whenever the rendered output changes for otherwise identical
+// fonts and options.
+const cacheKeyVersion = 3
+
+// CacheKey returns a stable cache key for the font rendered with the options.
+// The key is derived from the hash of the font's content (see [Font.Ref])
+// and the normalized options, and can be used by external caches to
+// determine whether a previously rendered image is still valid. When opts is
+// nil, the default options are used.
+func CacheKey(font *Font, opts *Options) string {
+	if ref, err := font.Ref(); err == nil {
+		return cacheKey(font, &ref, opts)
+	}
+	return cacheKey(font, nil, opts)
+}
+
+// CacheKeyRef returns the cache key as with [CacheKey], using the font's
+// reference previously returned by [Font.Ref], so that the font's content is
+// not hashed again.
+func CacheKeyRef(font *Font, ref Ref, opts *Options) string {
+	return cacheKey(font, &ref, opts)
+}
+
+// cacheKey returns the cache key for the font with the reference. When ref
+// is nil, the key is derived from the font's path.
+func cacheKey(font *Font, ref *Ref, opts *Options) string {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "fontimg/v%d\n", cacheKeyVersion)
+	if ref != nil {
+		fmt.Fprintf(h, "font=%s\n", ref.Hash)
+		if ref.Index != 0 {
+			fmt.Fprintf(h, "index=%d\n", ref.Index)
+		}
+	} else {
+		fmt.Fprintf(h, "path=%s\n", font.Path)
+	}
+	fmt.Fprintf(h, "family=%s\n", font.Family)
+	writeOptions(h, opts)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// OptionsHash returns a stable hash of the normalized options, independent of
+// the font. When opts is nil, the default options are used.
+func OptionsHash(opts *Options) string {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "fontimg/v%d\n", cacheKeyVersion)
+	writeOptions(h, opts)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeOptions writes the normalized options to the hash.
+func writeOptions(h io.Writer, opts *Options) {
+	fmt.Fprintf(h, "template=%s\n", templateHash(opts))
+	fmt.Fprintf(h, "text=%q\n", opts.Text)
+	fmt.Fprintf(h, "size=%d\n", opts.Size)
+	fmt.Fprintf(h, "style=%d\n", opts.Style)
+	fmt.Fprintf(h, "variant=%d\n", opts.Variant)
+	if opts.Instance != "" {
+		fmt.Fprintf(h, "instance=%q\n", opts.Instance)
+	}
+	if len(opts.Variations) != 0 {
+		tags := make([]string, 0, len(opts.Variations))
+		for tag := range opts.Variations {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			fmt.Fprintf(h, "variation=%q,%g\n", tag, opts.Variations[tag])
+		}
+	}
+	fmt.Fprintf(h, "fg=%s\n", colorHex(opts.FG))
+	fmt.Fprintf(h, "bg=%s\n", colorHex(opts.BG))
+	if opts.NoBackground {
+		fmt.Fprintln(h, "background=false")
+	}
+	fmt.Fprintf(h, "dpi=%g\n", opts.DPI)
+	fmt.Fprintf(h, "margin=%g\n", opts.Margin)
+	if opts.Trim {
+		fmt.Fprintln(h, "trim=true")
+	}
+	if opts.MaxDimension != 0 {
+		fmt.Fprintf(h, "max=%d\n", opts.MaxDimension)
+	}
+	if f := opts.Footer; f != nil {
+		fmt.Fprintf(h, "footer=%q,%q\n", f.Text, f.URL)
+	}
+	if p := opts.Paragraph; p != nil {
+		fmt.Fprintf(h, "paragraph=%g,%t,%q,%t,%g,%t\n", p.Width, p.Justify, p.Language, p.ShowStretch, p.MaxStretch, p.HangPunctuation)
+	}
+	if opts.OpticalMargin {
+		fmt.Fprintln(h, "optical_margin=true")
+	}
+	if opts.BaselineGrid != 0 {
+		fmt.Fprintf(h, "baseline_grid=%g\n", opts.BaselineGrid)
+	}
+	if opts.ShowSpacing {
+		fmt.Fprintln(h, "spacing=true")
+	}
+	if opts.SymbolGlyphs != 0 {
+		fmt.Fprintf(h, "symbol_glyphs=%d\n", opts.SymbolGlyphs)
+	}
+	if opts.Notdef != "" {
+		fmt.Fprintf(h, "notdef=%s\n", opts.Notdef)
+	}
+	if opts.EmojiFallback {
+		fmt.Fprintln(h, "emoji_fallback=true")
+	}
+	if opts.Language != "" {
+		fmt.Fprintf(h, "language=%s\n", opts.Language)
+	}
+}
+
+// Hash returns the hex encoded SHA-256 hash of the font's content. The hash is
+// calculated from the font's buffer when set, otherwise from the file
+// contents at the font's path. The hash of the font's buffer is only
+// calculated once.
+func (font *Font) Hash() (string, error) {
+	h := sha256.New()
+	switch {
+	case font.Buf != nil:
+		font.hashOnce.Do(func() {
+			sum := sha256.Sum256(font.Buf)
+			font.hash = hex.EncodeToString(sum[:])
+		})
+		return font.hash, nil
+	case font.Path != "":
+		f, err := os.Open(font.Path)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		if _, err := io.Copy(h, f); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("font.Buf and font.Path not set")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// templateHash returns a hash of the options' template. The default template
+// hashes to an empty string.
+func templateHash(opts *Options) string {
+	if opts.Template == nil || opts.Template == tplDefault {
+		return ""
+	}
+	var names []string
+	trees := make(map[string]string)
+	for _, t := range opts.Template.Templates() {
+		if t.Tree != nil && t.Tree.Root != nil {
+			names, trees[t.Name()] = append(names, t.Name()), t.Tree.Root.String()
+		}
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%q:%s\n", name, trees[name])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// colorHex returns the normalized, non-premultiplied hex representation of
+// the color.
+func colorHex(c color.Color) string {
+	if c == nil {
+		return ""
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("%02x%02x%02x%02x", n.R, n.G, n.B, n.A)
+}
@@ -0,0 +1,61 @@
+package fontimg
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestCacheKey(t *testing.T) {
+	for _, test := range testFonts(t) {
+		t.Run(test.name, func(t *testing.T) {
+			f := New(nil, test.path)
+			key := CacheKey(f, nil)
+			if len(key) != 64 {
+				t.Fatalf("expected 64 character key, got: %q", key)
+			}
+			if s := CacheKey(New(nil, test.path), DefaultOptions()); s != key {
+				t.Errorf("expected %q, got: %q", key, s)
+			}
+			ref, err := f.Ref()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if s := CacheKeyRef(f, ref, nil); s != key {
+				t.Errorf("expected %q, got: %q", key, s)
+			}
+			opts := DefaultOptions()
+			opts.FG = color.RGBA{A: 0xff}
+			if s := CacheKey(f, opts); s != key {
+				t.Errorf("expected equivalent colors to produce %q, got: %q", key, s)
+			}
+			opts.Size++
+			if s := CacheKey(f, opts); s == key {
+				t.Errorf("expected different size to produce a different key")
+			}
+			tpl, err := NewTemplate("{{ .Name }}")
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			opts = DefaultOptions()
+			opts.Template = tpl
+			if s := CacheKey(f, opts); s == key {
+				t.Errorf("expected different template to produce a different key")
+			}
+		})
+	}
+}
+
+func TestOptionsHash(t *testing.T) {
+	hash := OptionsHash(nil)
+	if len(hash) != 64 {
+		t.Fatalf("expected 64 character hash, got: %q", hash)
+	}
+	if s := OptionsHash(DefaultOptions()); s != hash {
+		t.Errorf("expected %q, got: %q", hash, s)
+	}
+	opts := DefaultOptions()
+	opts.Size++
+	if s := OptionsHash(opts); s == hash {
+		t.Errorf("expected different size to produce a different hash")
+	}
+}
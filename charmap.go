@@ -0,0 +1,196 @@
+package fontimg
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+	fontpkg "github.com/tdewolff/font"
+)
+
+// Runes returns the sorted runes mapped by the font's character map.
+func (font *Font) Runes() ([]rune, error) {
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	return sfntRunes(sfnt), nil
+}
+
+// sfntRunes returns the sorted runes mapped by the font's character map.
+func sfntRunes(sfnt *fontpkg.SFNT) []rune {
+	m := make(map[rune]bool)
+	for id := range sfnt.NumGlyphs() {
+		for _, r := range sfnt.GlyphToUnicode(id) {
+			m[r] = true
+		}
+	}
+	runes := make([]rune, 0, len(m))
+	for r := range m {
+		runes = append(runes, r)
+	}
+	slices.Sort(runes)
+	return runes
+}
+
+// CmapEntry is a character map entry, mapping a rune to a glyph.
+type CmapEntry struct {
+	Rune      rune   `json:"codepoint"`
+	GlyphID   uint16 `json:"glyph_id"`
+	GlyphName string `json:"glyph_name,omitempty"`
+}
+
+// Cmap returns the font's character map, sorted by rune, excluding runes
+// mapped to the .notdef glyph. Glyph names are from the font's post or CFF
+// table, when available.
+func (font *Font) Cmap() (_ []CmapEntry, err error) {
+	defer recoverError(&err)
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	runes, err := font.Runes()
+	if err != nil {
+		return nil, err
+	}
+	v := make([]CmapEntry, 0, len(runes))
+	for _, r := range runes {
+		id := sfnt.GlyphIndex(r)
+		if id == 0 {
+			continue
+		}
+		v = append(v, CmapEntry{
+			Rune:      r,
+			GlyphID:   id,
+			GlyphName: sfnt.GlyphName(id),
+		})
+	}
+	return v, nil
+}
+
+// WriteCmapJSON writes the font's character map (see [Font.Cmap]) as JSON to
+// w.
+func (font *Font) WriteCmapJSON(w io.Writer) error {
+	v, err := font.Cmap()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// GlyphRunes returns the sorted runes mapped to the glyph by the font's
+// character map, the reverse of the character map lookup.
+func (font *Font) GlyphRunes(id uint16) (_ []rune, err error) {
+	defer recoverError(&err)
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	if sfnt.NumGlyphs() <= id {
+		return nil, fmt.Errorf("invalid glyph %d", id)
+	}
+	// only include runes the character map maps to the glyph, as subtables
+	// may use other encodings (ie, Mac Roman)
+	runes := slices.DeleteFunc(slices.Clone(sfnt.GlyphToUnicode(id)), func(r rune) bool {
+		return sfnt.GlyphIndex(r) != id
+	})
+	slices.Sort(runes)
+	return runes, nil
+}
+
+// sfnt loads and returns the parsed font.
+func (font *Font) sfnt() (*fontpkg.SFNT, error) {
+	ff, err := font.Load(canvas.FontRegular)
+	if err != nil {
+		return nil, err
+	}
+	return ff.Face(16).Font.SFNT, nil
+}
+
+// CharMap is a paginated character map of a font, where each page is
+// rasterized on demand.
+type CharMap struct {
+	font    *Font
+	opts    Options
+	runes   []rune
+	perLine int
+	perPage int
+}
+
+// NewCharMap creates a paginated character map for the font's graphic runes,
+// with perLine runes per line and perPage runes per page. When opts is nil,
+// the default options will be used.
+func NewCharMap(font *Font, opts *Options, perLine, perPage int) (*CharMap, error) {
+	if perLine <= 0 || perPage <= 0 {
+		return nil, fmt.Errorf("invalid runes per line %d or page %d", perLine, perPage)
+	}
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	runes, err := font.Runes()
+	if err != nil {
+		return nil, err
+	}
+	runes = slices.DeleteFunc(runes, func(r rune) bool {
+		return !unicode.IsGraphic(r) || unicode.IsSpace(r)
+	})
+	m := &CharMap{
+		font:    font,
+		opts:    *opts,
+		runes:   runes,
+		perLine: perLine,
+		perPage: perPage,
+	}
+	m.opts.Template = tplCharMap
+	return m, nil
+}
+
+// Len returns the number of pages.
+func (m *CharMap) Len() int {
+	return (len(m.runes) + m.perPage - 1) / m.perPage
+}
+
+// Page rasterizes page i of the character map.
+func (m *CharMap) Page(i int) (*image.RGBA, error) {
+	c, err := m.Canvas(i)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(m.opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// Canvas lays out page i of the character map on a canvas.
+func (m *CharMap) Canvas(i int) (*canvas.Canvas, error) {
+	if i < 0 || m.Len() <= i {
+		return nil, fmt.Errorf("invalid page %d", i)
+	}
+	runes := m.runes[i*m.perPage : min((i+1)*m.perPage, len(m.runes))]
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "U+%04X–U+%04X ", runes[0], runes[len(runes)-1])
+	labelPrinter(m.opts.Language).Fprintf(&sb, "(%d/%d)", i+1, m.Len())
+	for j, r := range runes {
+		switch {
+		case j%m.perLine == 0:
+			sb.WriteByte('\n')
+		default:
+			sb.WriteByte(' ')
+		}
+		sb.WriteRune(r)
+	}
+	opts := m.opts
+	opts.Text = sb.String()
+	return m.font.Canvas(&opts)
+}
+
+// tplCharMap is the character map page template.
+var tplCharMap = template.Must(NewTemplate(`{{ size (inc .Size 2) }}{{ .Name }}, {{ .Style }}
+{{ size .Size }}{{ .SampleText }}`))
@@ -0,0 +1,83 @@
+package fontimg
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestCharMap(t *testing.T) {
+	for _, test := range testFonts(t) {
+		t.Run(test.name, func(t *testing.T) {
+			f := New(nil, test.path)
+			runes, err := f.Runes()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if len(runes) == 0 {
+				t.Fatalf("expected runes")
+			}
+			for i := 1; i < len(runes); i++ {
+				if runes[i-1] >= runes[i] {
+					t.Fatalf("expected sorted runes, got %U >= %U", runes[i-1], runes[i])
+				}
+			}
+			m, err := NewCharMap(f, nil, 16, 256)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			t.Logf("runes: %d pages: %d", len(runes), m.Len())
+			if m.Len() == 0 {
+				t.Fatalf("expected pages")
+			}
+			img, err := m.Page(m.Len() - 1)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+				t.Errorf("expected non-empty image, got: %v", b)
+			}
+			if _, err := m.Page(m.Len()); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestCmap(t *testing.T) {
+	for _, test := range testFonts(t) {
+		t.Run(test.name, func(t *testing.T) {
+			f := New(nil, test.path)
+			v, err := f.Cmap()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			i := slices.IndexFunc(v, func(e CmapEntry) bool { return e.Rune == 'A' })
+			if i == -1 || v[i].GlyphID == 0 {
+				t.Fatalf("expected entry for A, got: %d", i)
+			}
+			runes, err := f.GlyphRunes(v[i].GlyphID)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !slices.Equal(runes, []rune{'A'}) {
+				t.Errorf("expected [A], got: %q", runes)
+			}
+			if _, err := f.GlyphRunes(0xffff); err == nil {
+				t.Errorf("expected error for invalid glyph")
+			}
+			var buf bytes.Buffer
+			if err := f.WriteCmapJSON(&buf); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			var entries []CmapEntry
+			if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !slices.Equal(entries, v) {
+				t.Errorf("expected decoded entries to equal cmap")
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+// Command fontimg renders font preview images.
+//
+// Usage:
+//
+//	fontimg preview [flags] <name|path>...
+//	fontimg grid [flags] <name|path>
+//	fontimg list [-json]
+//
+// Fonts are either a path (a font file, directory, zip archive, URL, or "-"
+// for standard input) or the name of a system font. Images are written to
+// the -o file, or to standard output. When standard output is a terminal and
+// no output or format is set, the image is displayed inline using the
+// terminal's image protocol (sixel, kitty, or iTerm2).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kenshaw/fontimg"
+	"github.com/kenshaw/fontimg/termimg"
+)
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// usage is the command usage.
+const usage = `usage: fontimg <command> [flags] [args]
+
+commands:
+  preview <name|path>...  render a font preview image
+  grid <name|path>        render a sheet of the font's glyphs
+  list                    list the system font families
+
+Run 'fontimg <command> -h' for the command's flags.
+`
+
+// run runs the command.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return errors.New("missing command")
+	}
+	switch args[0] {
+	case "preview":
+		return preview(ctx, args[1:], stdout, stderr)
+	case "grid":
+		return grid(args[1:], stdout, stderr)
+	case "list":
+		return list(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	}
+	fmt.Fprint(stderr, usage)
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+// preview renders preview images of the fonts.
+func preview(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("preview", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	p := newParams(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("missing font")
+	}
+	opts, err := p.options()
+	if err != nil {
+		return err
+	}
+	var fonts []*fontimg.Font
+	for _, name := range fs.Args() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		v, err := fontimg.OpenContext(ctx, name, opts.Style, nil)
+		if err != nil {
+			return err
+		}
+		fonts = append(fonts, v...)
+	}
+	if p.out != "" && len(fonts) != 1 {
+		return fmt.Errorf("-o set for %d fonts", len(fonts))
+	}
+	for _, font := range fonts {
+		img, err := font.RasterizeOptions(opts)
+		if err != nil {
+			return fmt.Errorf("%s: %v", font.BestName(), err)
+		}
+		if err := p.write(stdout, img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// grid renders a glyph sheet of the font.
+func grid(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("grid", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	p := newParams(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected exactly one font")
+	}
+	opts, err := p.options()
+	if err != nil {
+		return err
+	}
+	fonts, err := fontimg.Open(fs.Arg(0), opts.Style, nil)
+	if err != nil {
+		return err
+	}
+	img, err := fonts[0].GlyphGrid(opts)
+	if err != nil {
+		return err
+	}
+	return p.write(stdout, img)
+}
+
+// list lists the system font families.
+func list(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	asJSON := fs.Bool("json", false, "write the families as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	sysfonts, err := fontimg.SystemFonts()
+	if err != nil {
+		return err
+	}
+	families := fontimg.Families(sysfonts)
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(families)
+	}
+	for _, f := range families {
+		if _, err := fmt.Fprintf(stdout, "%s: %s\n", f.Family, strings.Join(f.Styles, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// params are the rendering and output flags.
+type params struct {
+	out      string
+	format   string
+	template string
+	preset   string
+	text     string
+	style    string
+	size     int
+	fg       string
+	bg       string
+	dpi      float64
+	margin   float64
+	lang     string
+}
+
+// newParams creates the rendering and output flags on the flag set.
+func newParams(fs *flag.FlagSet) *params {
+	p := new(params)
+	defaults := fontimg.DefaultOptions()
+	fs.StringVar(&p.out, "o", "", "output file (default standard output)")
+	fs.StringVar(&p.format, "format", "", "output format: png, jpeg, gif, sixel, kitty or iterm2 (default from the output file's extension, or png)")
+	fs.StringVar(&p.template, "template", "", "text template file")
+	fs.StringVar(&p.preset, "preset", "", "template preset (ie, article, poster)")
+	fs.StringVar(&p.text, "text", "", "sample text")
+	fs.StringVar(&p.style, "style", "", "font style (ie, \"Bold Italic\")")
+	fs.IntVar(&p.size, "size", defaults.Size, "font size")
+	fs.StringVar(&p.fg, "fg", "", "foreground color, as hex (ie, 000, ff0000)")
+	fs.StringVar(&p.bg, "bg", "", "background color, as hex (ie, fff, 00000000)")
+	fs.Float64Var(&p.dpi, "dpi", defaults.DPI, "rasterization dpi")
+	fs.Float64Var(&p.margin, "margin", defaults.Margin, "margin, in millimeters")
+	fs.StringVar(&p.lang, "lang", "", "language of built-in labels (ie, de)")
+	return p
+}
+
+// options returns the rasterization options for the flags.
+func (p *params) options() (*fontimg.Options, error) {
+	opts := fontimg.DefaultOptions()
+	opts.Text, opts.Size, opts.DPI, opts.Margin, opts.Language = p.text, p.size, p.dpi, p.margin, p.lang
+	var err error
+	if p.style != "" {
+		if opts.Style, err = fontimg.ParseStyle(p.style); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case p.template != "":
+		buf, err := os.ReadFile(p.template)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Template, err = fontimg.NewTemplate(string(buf)); err != nil {
+			return nil, fmt.Errorf("invalid template %s: %v", p.template, err)
+		}
+	case p.preset != "":
+		preset, err := fontimg.LookupPreset(p.preset)
+		if err != nil {
+			return nil, err
+		}
+		opts.Template = preset.Template
+	}
+	if p.fg != "" {
+		if opts.FG, err = fontimg.ParseColor(p.fg); err != nil {
+			return nil, err
+		}
+	}
+	if p.bg != "" {
+		if opts.BG, err = fontimg.ParseColor(p.bg); err != nil {
+			return nil, err
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// write writes the image to the output file, or to stdout.
+func (p *params) write(stdout io.Writer, img image.Image) error {
+	format := p.format
+	switch {
+	case format != "":
+	case p.out != "":
+		format = strings.TrimPrefix(filepath.Ext(p.out), ".")
+	case isTerminal(stdout):
+		proto := termimg.Detect()
+		if proto == termimg.None {
+			return errors.New("standard output is a terminal without inline image support: set -o or -format")
+		}
+		format = proto.String()
+	default:
+		format = "png"
+	}
+	if p.out == "" {
+		return encode(stdout, img, format)
+	}
+	f, err := os.Create(p.out)
+	if err != nil {
+		return err
+	}
+	if err := encode(f, img, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// encode encodes the image in the format to w. Terminal image protocol
+// formats are followed by a newline.
+func encode(w io.Writer, img image.Image, format string) error {
+	if proto, err := termimg.ParseProtocol(format); err == nil && proto != termimg.None {
+		if err := termimg.Encode(w, img, proto); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(w)
+		return err
+	}
+	return fontimg.Encode(img, fontimg.Output{W: w, Format: format})
+}
+
+// isTerminal returns whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	_ "image/gif"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kenshaw/fontimg"
+)
+
+func TestRun(t *testing.T) {
+	font := filepath.Join("..", "..", "testdata", "Ubuntu-R.ttf")
+	out := filepath.Join(t.TempDir(), "out.png")
+	tpl := filepath.Join(t.TempDir(), "text.tpl")
+	if err := os.WriteFile(tpl, []byte(`{{ size .Size }}{{ .Name }}`), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		args   []string
+		format string
+		prefix string
+	}{
+		{[]string{"preview", "-size", "24", "-fg", "f00", "-o", out, font}, "png", ""},
+		{[]string{"preview", "-format", "gif", "-template", tpl, font}, "gif", ""},
+		{[]string{"preview", "-preset", "poster", "-dpi", "50", font, font}, "png", ""},
+		{[]string{"grid", "-size", "12", "-format", "kitty", font}, "", "\x1b_Ga=T,f=100,"},
+		{[]string{"preview", "-format", "iterm2", font}, "", "\x1b]1337;File=inline=1;"},
+	}
+	for i, test := range tests {
+		var stdout, stderr bytes.Buffer
+		if err := run(context.Background(), test.args, &stdout, &stderr); err != nil {
+			t.Fatalf("test %d expected no error, got: %v (%s)", i, err, stderr.String())
+		}
+		buf := stdout.Bytes()
+		if slices.Contains(test.args, "-o") {
+			var err error
+			if buf, err = os.ReadFile(out); err != nil {
+				t.Fatalf("test %d expected no error, got: %v", i, err)
+			}
+		}
+		if test.prefix != "" {
+			if !bytes.HasPrefix(buf, []byte(test.prefix)) {
+				t.Errorf("test %d expected prefix %q, got: %q", i, test.prefix, buf[:min(len(buf), 32)])
+			}
+			continue
+		}
+		_, format, err := image.Decode(bytes.NewReader(buf))
+		switch {
+		case err != nil:
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		case format != test.format:
+			t.Errorf("test %d expected %s, got: %s", i, test.format, format)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	font := filepath.Join("..", "..", "testdata", "Ubuntu-R.ttf")
+	tests := []struct {
+		args []string
+		exp  string
+	}{
+		{nil, "missing command"},
+		{[]string{"bogus"}, `unknown command "bogus"`},
+		{[]string{"preview"}, "missing font"},
+		{[]string{"preview", "-o", "out.png", font, font}, "-o set for 2 fonts"},
+		{[]string{"preview", "-size", "0", font}, "invalid size 0"},
+		{[]string{"preview", "-format", "bmp", font}, `unknown image format "bmp"`},
+		{[]string{"grid", font, font}, "expected exactly one font"},
+		{[]string{"list", "x"}, "unexpected arguments"},
+	}
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		switch err := run(context.Background(), test.args, &stdout, &stderr); {
+		case err == nil:
+			t.Errorf("%q: expected error, got nil", test.args)
+		case !strings.Contains(err.Error(), test.exp):
+			t.Errorf("%q: expected error %q, got: %v", test.args, test.exp, err)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := run(context.Background(), []string{"list", "-json"}, &stdout, &stderr); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var families []fontimg.FamilyInfo
+	if err := json.Unmarshal(stdout.Bytes(), &families); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
@@ -0,0 +1,448 @@
+package fontimg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+
+	gofont "github.com/go-text/typesetting/font"
+	ot "github.com/go-text/typesetting/font/opentype"
+	"github.com/go-text/typesetting/font/opentype/tables"
+	"github.com/tdewolff/canvas"
+	fontpkg "github.com/tdewolff/font"
+	_ "golang.org/x/image/tiff"
+)
+
+// colorFormats are the supported color glyph formats, by preference.
+var colorFormats = []string{"COLR", "sbix", "CBDT"}
+
+// ColorFormats returns the font's color glyph formats ("COLR", "sbix", or
+// "CBDT"), in the order they are preferred when rendering. Returns nil when
+// the font has no color glyphs.
+func (font *Font) ColorFormats() ([]string, error) {
+	ld, err := font.loader()
+	if err != nil {
+		return nil, err
+	}
+	var formats []string
+	for _, tag := range colorFormats {
+		if ld.HasTable(ot.MustNewTag(tag)) {
+			formats = append(formats, tag)
+		}
+	}
+	return formats, nil
+}
+
+// colorFace is a color font's face, drawing the color glyphs of text laid
+// out with the font.
+type colorFace struct {
+	face    *gofont.Face
+	palette []color.NRGBA
+}
+
+// colorFace returns the font's color face, with bitmap glyphs chosen for
+// the pixels per em. Returns nil when the font has no color glyphs.
+func (font *Font) colorFace(ppem int) (*colorFace, error) {
+	formats, err := font.ColorFormats()
+	if err != nil || len(formats) == 0 {
+		return nil, err
+	}
+	ld, err := font.loader()
+	if err != nil {
+		return nil, err
+	}
+	f, err := gofont.NewFont(ld)
+	if err != nil {
+		return nil, err
+	}
+	cf := &colorFace{face: gofont.NewFace(f)}
+	cf.face.SetPpem(uint16(min(ppem, math.MaxUint16)), uint16(min(ppem, math.MaxUint16)))
+	if 0 < len(f.CPAL) {
+		for _, c := range f.CPAL[0] {
+			cf.palette = append(cf.palette, color.NRGBA{R: c.Red, G: c.Green, B: c.Blue, A: c.Alpha})
+		}
+	}
+	return cf, nil
+}
+
+// drawText draws the color glyphs of the text at x, y. Glyphs without color
+// glyph data are drawn with their outlines in fg. The text itself should be
+// drawn transparent, so that it is still used to fit the canvas.
+func (cf *colorFace) drawText(ctx *canvas.Context, x, y float64, txt *canvas.Text, fg color.Color) {
+	txt.WalkSpans(func(sx, sy float64, span canvas.TextSpan) {
+		sfnt, f := span.Face.Font.SFNT, span.Face.MmPerEm
+		var gx, gy int32
+		for _, g := range span.Glyphs {
+			m := canvas.Identity.Translate(x+sx+f*float64(gx+g.XOffset), y+sy+f*float64(gy+g.YOffset)).Scale(f, f)
+			if !cf.drawGlyph(ctx, sfnt, g.ID, m, fg) {
+				ctx.SetFillColor(fg)
+				ctx.DrawPath(0, 0, glyphPath(sfnt, g.ID).Transform(m))
+			}
+			gx, gy = gx+g.XAdvance, gy+g.YAdvance
+		}
+	})
+}
+
+// drawGlyph draws the color glyph, with m the transform from font units to
+// the context's coordinates, returning false when the glyph has no color
+// glyph data.
+func (cf *colorFace) drawGlyph(ctx *canvas.Context, sfnt *fontpkg.SFNT, id uint16, m canvas.Matrix, fg color.Color) bool {
+	if g, ok := cf.face.GlyphDataColor(tables.GlyphID(id)); ok {
+		ctx.Push()
+		cf.drawPaint(ctx, sfnt, g.Paint, m, nil, fg, 0)
+		ctx.Pop()
+		return true
+	}
+	g, ok := cf.face.GlyphDataBitmap(tables.GlyphID(id))
+	if !ok {
+		return false
+	}
+	ext, ok := cf.face.GlyphExtents(gofont.GID(id))
+	if !ok || ext.Width <= 0 || ext.Height >= 0 {
+		return false
+	}
+	var img image.Image
+	switch g.Format {
+	case gofont.BlackAndWhite:
+		img = bitmapImage(g, fg)
+	case gofont.PNG, gofont.JPG, gofont.TIFF:
+		var err error
+		if img, _, err = image.Decode(bytes.NewReader(g.Data)); err != nil {
+			return false
+		}
+	default:
+		return false
+	}
+	// scale the image to the glyph's extents
+	p := m.Dot(canvas.Point{X: float64(ext.XBearing), Y: float64(ext.YBearing + ext.Height)})
+	w := m.Dot(canvas.Point{X: float64(ext.XBearing + ext.Width), Y: float64(ext.YBearing + ext.Height)}).X - p.X
+	ctx.DrawImage(p.X, p.Y, img, canvas.Resolution(float64(img.Bounds().Dx())/w))
+	return true
+}
+
+// bitmapImage returns the black and white bitmap glyph in fg.
+func bitmapImage(g gofont.GlyphBitmap, fg color.Color) image.Image {
+	mask := image.NewAlpha(image.Rect(0, 0, g.Width, g.Height))
+	for i := range g.Width * g.Height {
+		if g.Data[i/8]&(0x80>>(i%8)) != 0 {
+			mask.Pix[i] = 0xff
+		}
+	}
+	img := image.NewRGBA(mask.Rect)
+	draw.DrawMask(img, img.Rect, image.NewUniform(fg), image.Point{}, mask, image.Point{}, draw.Src)
+	return img
+}
+
+// drawPaint draws the COLR paint, filling the clip path (in the context's
+// coordinates), with m the transform from the paint's font units to the
+// context's coordinates. The composite modes of composite paints are not
+// supported, and the source is drawn over the backdrop.
+func (cf *colorFace) drawPaint(ctx *canvas.Context, sfnt *fontpkg.SFNT, paint tables.PaintTable, m canvas.Matrix, clip *canvas.Path, fg color.Color, depth int) {
+	if depth == 64 {
+		return
+	}
+	fill := func(paint any) {
+		if clip == nil {
+			return
+		}
+		ctx.SetFill(paint)
+		ctx.DrawPath(0, 0, clip)
+	}
+	next := func(paint tables.PaintTable, m canvas.Matrix) {
+		cf.drawPaint(ctx, sfnt, paint, m, clip, fg, depth+1)
+	}
+	switch p := paint.(type) {
+	// version 0 layers
+	case tables.PaintColrLayersResolved:
+		for _, layer := range p {
+			ctx.SetFillColor(cf.color(layer.PaletteIndex, 1<<14, fg))
+			ctx.DrawPath(0, 0, glyphPath(sfnt, uint16(layer.GlyphID)).Transform(m))
+		}
+	// layers, glyphs and composites
+	case tables.PaintColrLayers:
+		layers, err := cf.face.COLR.LayerList.Resolve(p)
+		if err != nil {
+			return
+		}
+		for _, layer := range layers {
+			next(layer, m)
+		}
+	case tables.PaintGlyph:
+		path := glyphPath(sfnt, p.GlyphID).Transform(m)
+		if clip != nil {
+			path = path.And(clip)
+		}
+		cf.drawPaint(ctx, sfnt, p.Paint, m, path, fg, depth+1)
+	case tables.PaintColrGlyph:
+		if paint, ok := cf.face.COLR.Search(tables.GlyphID(p.GlyphID)); ok {
+			next(paint, m)
+		}
+	case tables.PaintComposite:
+		next(p.BackdropPaint, m)
+		next(p.SourcePaint, m)
+	// fills
+	case tables.PaintSolid:
+		fill(cf.color(p.PaletteIndex, p.Alpha, fg))
+	case tables.PaintVarSolid:
+		fill(cf.color(p.PaletteIndex, p.Alpha, fg))
+	case tables.PaintLinearGradient:
+		fill(cf.linearGradient(p.ColorLine, p.X0, p.Y0, p.X1, p.Y1, p.X2, p.Y2, m, fg))
+	case tables.PaintVarLinearGradient:
+		fill(cf.linearGradient(varColorLine(p.ColorLine), p.X0, p.Y0, p.X1, p.Y1, p.X2, p.Y2, m, fg))
+	case tables.PaintRadialGradient:
+		fill(cf.radialGradient(p.ColorLine, p.X0, p.Y0, p.Radius0, p.X1, p.Y1, p.Radius1, m, fg))
+	case tables.PaintVarRadialGradient:
+		fill(cf.radialGradient(varColorLine(p.ColorLine), p.X0, p.Y0, p.Radius0, p.X1, p.Y1, p.Radius1, m, fg))
+	case tables.PaintSweepGradient:
+		fill(cf.sweepGradient(p.ColorLine, p.CenterX, p.CenterY, p.StartAngle, p.EndAngle, m, fg))
+	case tables.PaintVarSweepGradient:
+		fill(cf.sweepGradient(varColorLine(p.ColorLine), p.CenterX, p.CenterY, p.StartAngle, p.EndAngle, m, fg))
+	// transforms
+	case tables.PaintTransform:
+		next(p.Paint, m.Mul(affine(p.Transform)))
+	case tables.PaintVarTransform:
+		next(p.Paint, m.Mul(affine(tables.Affine2x3{Xx: p.Transform.Xx, Yx: p.Transform.Yx, Xy: p.Transform.Xy, Yy: p.Transform.Yy, Dx: p.Transform.Dx, Dy: p.Transform.Dy})))
+	case tables.PaintTranslate:
+		next(p.Paint, m.Translate(float64(p.Dx), float64(p.Dy)))
+	case tables.PaintVarTranslate:
+		next(p.Paint, m.Translate(float64(p.Dx), float64(p.Dy)))
+	case tables.PaintScale:
+		next(p.Paint, m.Scale(f2dot14(p.ScaleX), f2dot14(p.ScaleY)))
+	case tables.PaintVarScale:
+		next(p.Paint, m.Scale(f2dot14(p.ScaleX), f2dot14(p.ScaleY)))
+	case tables.PaintScaleAroundCenter:
+		next(p.Paint, m.ScaleAbout(f2dot14(p.ScaleX), f2dot14(p.ScaleY), float64(p.CenterX), float64(p.CenterY)))
+	case tables.PaintVarScaleAroundCenter:
+		next(p.Paint, m.ScaleAbout(f2dot14(p.ScaleX), f2dot14(p.ScaleY), float64(p.CenterX), float64(p.CenterY)))
+	case tables.PaintScaleUniform:
+		next(p.Paint, m.Scale(f2dot14(p.Scale), f2dot14(p.Scale)))
+	case tables.PaintVarScaleUniform:
+		next(p.Paint, m.Scale(f2dot14(p.Scale), f2dot14(p.Scale)))
+	case tables.PaintScaleUniformAroundCenter:
+		next(p.Paint, m.ScaleAbout(f2dot14(p.Scale), f2dot14(p.Scale), float64(p.CenterX), float64(p.CenterY)))
+	case tables.PaintVarScaleUniformAroundCenter:
+		next(p.Paint, m.ScaleAbout(f2dot14(p.Scale), f2dot14(p.Scale), float64(p.CenterX), float64(p.CenterY)))
+	case tables.PaintRotate:
+		next(p.Paint, m.Rotate(180*f2dot14(p.Angle)))
+	case tables.PaintVarRotate:
+		next(p.Paint, m.Rotate(180*f2dot14(p.Angle)))
+	case tables.PaintRotateAroundCenter:
+		next(p.Paint, m.RotateAbout(180*f2dot14(p.Angle), float64(p.CenterX), float64(p.CenterY)))
+	case tables.PaintVarRotateAroundCenter:
+		next(p.Paint, m.RotateAbout(180*f2dot14(p.Angle), float64(p.CenterX), float64(p.CenterY)))
+	case tables.PaintSkew:
+		next(p.Paint, m.Mul(skew(p.XSkewAngle, p.YSkewAngle)))
+	case tables.PaintVarSkew:
+		next(p.Paint, m.Mul(skew(p.XSkewAngle, p.YSkewAngle)))
+	case tables.PaintSkewAroundCenter:
+		x, y := float64(p.CenterX), float64(p.CenterY)
+		next(p.Paint, m.Translate(x, y).Mul(skew(p.XSkewAngle, p.YSkewAngle)).Translate(-x, -y))
+	case tables.PaintVarSkewAroundCenter:
+		x, y := float64(p.CenterX), float64(p.CenterY)
+		next(p.Paint, m.Translate(x, y).Mul(skew(p.XSkewAngle, p.YSkewAngle)).Translate(-x, -y))
+	}
+}
+
+// color returns the palette color with the alpha applied. The palette index
+// 0xffff is the foreground color.
+func (cf *colorFace) color(i uint16, alpha tables.Fixed214, fg color.Color) color.RGBA {
+	var c color.NRGBA
+	switch {
+	case i == 0xffff:
+		c = color.NRGBAModel.Convert(fg).(color.NRGBA)
+	case int(i) < len(cf.palette):
+		c = cf.palette[i]
+	}
+	c.A = uint8(math.Round(float64(c.A) * min(max(f2dot14(alpha), 0), 1)))
+	return color.RGBAModel.Convert(c).(color.RGBA)
+}
+
+// linearGradient returns the linear gradient through p0 and p1, rotated to
+// be perpendicular to the line through p0 and p2.
+func (cf *colorFace) linearGradient(line tables.ColorLine, x0, y0, x1, y1, x2, y2 int16, m canvas.Matrix, fg color.Color) canvas.Gradient {
+	p0 := canvas.Point{X: float64(x0), Y: float64(y0)}
+	d := canvas.Point{X: float64(x1), Y: float64(y1)}.Sub(p0)
+	if n := (canvas.Point{X: float64(y0 - y2), Y: float64(x2 - x0)}); n.Dot(n) != 0 {
+		d = n.Mul(d.Dot(n) / n.Dot(n))
+	}
+	return cf.gradient(line, m, fg, func(p canvas.Point) (float64, bool) {
+		if d.Dot(d) == 0 {
+			return 0, false
+		}
+		return p.Sub(p0).Dot(d) / d.Dot(d), true
+	})
+}
+
+// radialGradient returns the two point conical gradient between the circles
+// c0, r0 and c1, r1.
+func (cf *colorFace) radialGradient(line tables.ColorLine, x0, y0 int16, r0 uint16, x1, y1 int16, r1 uint16, m canvas.Matrix, fg color.Color) canvas.Gradient {
+	c0 := canvas.Point{X: float64(x0), Y: float64(y0)}
+	cd, dr := canvas.Point{X: float64(x1), Y: float64(y1)}.Sub(c0), float64(r1)-float64(r0)
+	return cf.gradient(line, m, fg, func(p canvas.Point) (float64, bool) {
+		pd := p.Sub(c0)
+		a, b, c := cd.Dot(cd)-dr*dr, pd.Dot(cd)+float64(r0)*dr, pd.Dot(pd)-float64(r0)*float64(r0)
+		if a == 0 {
+			if b == 0 {
+				return 0, false
+			}
+			t := c / (2 * b)
+			return t, 0 <= float64(r0)+t*dr
+		}
+		disc := b*b - a*c
+		if disc < 0 {
+			return 0, false
+		}
+		// the largest t with a non-negative radius
+		for _, t := range []float64{(b + math.Sqrt(disc)) / a, (b - math.Sqrt(disc)) / a} {
+			if 0 <= float64(r0)+t*dr {
+				return t, true
+			}
+		}
+		return 0, false
+	})
+}
+
+// sweepGradient returns the sweep gradient around the center, between the
+// start and end angles.
+func (cf *colorFace) sweepGradient(line tables.ColorLine, cx, cy int16, start, end tables.Fixed214, m canvas.Matrix, fg color.Color) canvas.Gradient {
+	a0, a1 := 180*(f2dot14(start)+1), 180*(f2dot14(end)+1)
+	return cf.gradient(line, m, fg, func(p canvas.Point) (float64, bool) {
+		if a0 == a1 {
+			return 0, false
+		}
+		a := math.Atan2(p.Y-float64(cy), p.X-float64(cx)) * 180 / math.Pi
+		if a < 0 {
+			a += 360
+		}
+		return (a - a0) / (a1 - a0), true
+	})
+}
+
+// gradient returns a gradient with the color line's stops, with t returning
+// the color line position of a point in the paint's font units.
+func (cf *colorFace) gradient(line tables.ColorLine, m canvas.Matrix, fg color.Color, t func(canvas.Point) (float64, bool)) canvas.Gradient {
+	g := &colrGradient{inv: m.Inv(), extend: line.Extend, t: t}
+	for _, stop := range line.ColorStops {
+		g.stops.Add(f2dot14(stop.StopOffset), cf.color(stop.PaletteIndex, stop.Alpha, fg))
+	}
+	return g
+}
+
+// colrGradient is a COLR gradient, in the paint's font units.
+type colrGradient struct {
+	inv    canvas.Matrix
+	stops  canvas.Grad
+	extend tables.Extend
+	t      func(canvas.Point) (float64, bool)
+}
+
+// SetColorSpace satisfies the [canvas.Gradient] interface.
+func (g *colrGradient) SetColorSpace(colorSpace canvas.ColorSpace) canvas.Gradient {
+	if _, ok := colorSpace.(canvas.LinearColorSpace); ok {
+		return g
+	}
+	h := *g
+	h.stops = make(canvas.Grad, len(g.stops))
+	for i, stop := range g.stops {
+		h.stops[i] = canvas.Stop{Offset: stop.Offset, Color: colorSpace.ToLinear(stop.Color)}
+	}
+	return &h
+}
+
+// At satisfies the [canvas.Gradient] interface.
+func (g *colrGradient) At(x, y float64) color.RGBA {
+	t, ok := g.t(g.inv.Dot(canvas.Point{X: x, Y: y}))
+	if !ok {
+		return canvas.Transparent
+	}
+	switch g.extend {
+	case tables.ExtendRepeat:
+		t -= math.Floor(t)
+	case tables.ExtendReflect:
+		if t = math.Mod(math.Abs(t), 2); 1 < t {
+			t = 2 - t
+		}
+	}
+	return g.stops.At(t)
+}
+
+// varColorLine returns the variable color line without its variations.
+func varColorLine(line tables.VarColorLine) tables.ColorLine {
+	stops := make([]tables.ColorStop, len(line.ColorStops))
+	for i, stop := range line.ColorStops {
+		stops[i] = tables.ColorStop{StopOffset: stop.StopOffset, PaletteIndex: stop.PaletteIndex, Alpha: stop.Alpha}
+	}
+	return tables.ColorLine{Extend: line.Extend, ColorStops: stops}
+}
+
+// affine returns the affine transform as a matrix.
+func affine(t tables.Affine2x3) canvas.Matrix {
+	return canvas.Matrix{
+		{float64(t.Xx), float64(t.Xy), float64(t.Dx)},
+		{float64(t.Yx), float64(t.Yy), float64(t.Dy)},
+	}
+}
+
+// skew returns the skew transform for the angles, 180° counter-clockwise per
+// 1.0 of value.
+func skew(x, y tables.Fixed214) canvas.Matrix {
+	return canvas.Matrix{
+		{1, math.Tan(-math.Pi * f2dot14(x)), 0},
+		{math.Tan(math.Pi * f2dot14(y)), 1, 0},
+	}
+}
+
+// f2dot14 returns the 2.14 fixed point value.
+func f2dot14(v tables.Fixed214) float64 {
+	return float64(v) / (1 << 14)
+}
+
+// glyphPath returns the glyph's outline, in font units.
+func glyphPath(sfnt *fontpkg.SFNT, id uint16) *canvas.Path {
+	p := new(canvas.Path)
+	if err := sfnt.GlyphPath(p, id, 0, 0, 0, 1, fontpkg.NoHinting); err != nil {
+		return new(canvas.Path)
+	}
+	return p
+}
+
+// bitmapOutlines adds empty glyf and loca tables to the SFNT data of a
+// bitmap-only color font (ie, CBDT or sbix without outlines), so that it
+// can be laid out. Other fonts are returned unchanged.
+func bitmapOutlines(b []byte) ([]byte, error) {
+	switch {
+	case hasTable(b, "glyf"), hasTable(b, "CFF "), hasTable(b, "CFF2"),
+		!hasTable(b, "CBDT") && !hasTable(b, "sbix"):
+		return b, nil
+	}
+	tables := make(map[string][]byte)
+	for i := range int(binary.BigEndian.Uint16(b[4:])) {
+		rec := 12 + 16*i
+		if len(b) < rec+16 {
+			return nil, fmt.Errorf("bad SFNT header")
+		}
+		offset, length := int(binary.BigEndian.Uint32(b[rec+8:])), int(binary.BigEndian.Uint32(b[rec+12:]))
+		if offset < 0 || length < 0 || len(b) < offset+length {
+			return nil, fmt.Errorf("%s: bad table", b[rec:rec+4])
+		}
+		tables[string(b[rec:rec+4])] = b[offset : offset+length]
+	}
+	head, maxp := tables["head"], tables["maxp"]
+	if len(head) < 54 || len(maxp) < 6 {
+		return nil, fmt.Errorf("bad font tables")
+	}
+	n := int(binary.BigEndian.Uint16(maxp[4:])) + 1
+	if binary.BigEndian.Uint16(head[50:]) == 0 {
+		tables["loca"] = make([]byte, 2*n)
+	} else {
+		tables["loca"] = make([]byte, 4*n)
+	}
+	tables["glyf"] = []byte{}
+	return (&fontpkg.SFNT{IsTrueType: true, Tables: tables}).Write(), nil
+}
@@ -0,0 +1,241 @@
+package fontimg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kenshaw/fontimg/testfonts"
+	fontpkg "github.com/tdewolff/font"
+)
+
+func TestColorFormats(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		name string
+		buf  []byte
+		exp  []string
+	}{
+		{"monochrome", buf, nil},
+		{"colr", testCOLR(t, buf), []string{"COLR"}},
+		{"colr1", testCOLR1(t, buf), []string{"COLR"}},
+		{"sbix", testSbix(t, buf), []string{"sbix"}},
+		{"cbdt", testCBDT(t, buf, false), []string{"CBDT"}},
+		{"testfonts", testfonts.Bytes(testfonts.Color), []string{"COLR"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			formats, err := New(test.buf, "").ColorFormats()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !slices.Equal(formats, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, formats)
+			}
+		})
+	}
+}
+
+func TestRasterizeColor(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	colors := map[string]func(color.RGBA) bool{
+		"red":   func(c color.RGBA) bool { return 0xc0 < c.R && c.G < 0x40 && c.B < 0x40 },
+		"green": func(c color.RGBA) bool { return c.R < 0x40 && 0xc0 < c.G && c.B < 0x40 },
+		"blue":  func(c color.RGBA) bool { return c.R < 0x40 && c.G < 0x40 && 0xc0 < c.B },
+		"black": func(c color.RGBA) bool { return c.R < 0x20 && c.G < 0x20 && c.B < 0x20 },
+	}
+	tests := []struct {
+		name string
+		buf  []byte
+		exp  []string
+	}{
+		{"monochrome", buf, []string{"black"}},
+		{"colr", testCOLR(t, buf), []string{"black", "blue", "red"}},
+		{"colr1", testCOLR1(t, buf), []string{"black", "blue", "red"}},
+		{"sbix", testSbix(t, buf), []string{"black", "red"}},
+		{"cbdt", testCBDT(t, buf, false), []string{"black", "green"}},
+		{"cbdt bitmap only", testCBDT(t, buf, true), []string{"green"}},
+		{"testfonts", testfonts.Bytes(testfonts.Color), []string{"black", "blue", "green", "red"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Text = "AO Hello"
+			img, err := New(test.buf, "").RasterizeOptions(opts)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			for name, f := range colors {
+				exp := slices.Contains(test.exp, name)
+				if n := colorCount(img, f); exp != (n != 0) {
+					t.Errorf("%s: expected %t, got %d pixels", name, exp, n)
+				}
+			}
+		})
+	}
+}
+
+// colorCount returns the number of the image's pixels matching f.
+func colorCount(img *image.RGBA, f func(color.RGBA) bool) int {
+	var n int
+	for i := 0; i < len(img.Pix); i += 4 {
+		if f(color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}) {
+			n++
+		}
+	}
+	return n
+}
+
+// testColorTables returns the tables of the font and the glyph ids of 'A' and
+// 'O'.
+func testColorTables(tb testing.TB, buf []byte) (map[string][]byte, uint16, uint16) {
+	tb.Helper()
+	sfnt, err := fontpkg.ParseSFNT(buf, 0)
+	if err != nil {
+		tb.Fatalf("expected no error, got: %v", err)
+	}
+	tables := make(map[string][]byte)
+	for tag, b := range sfnt.Tables {
+		tables[tag] = b
+	}
+	return tables, sfnt.GlyphIndex('A'), sfnt.GlyphIndex('O')
+}
+
+// testCPAL returns a CPAL table with a palette of red and blue.
+func testCPAL() []byte {
+	b := []byte{0, 0, 0, 2, 0, 1, 0, 2, 0, 0, 0, 14, 0, 0}
+	// BGRA
+	return append(b, 0, 0, 0xff, 0xff, 0xff, 0, 0, 0xff)
+}
+
+// testCOLR adds version 0 COLR and CPAL tables to the font, drawing 'A' as
+// a red 'O' under a blue 'A'.
+func testCOLR(tb testing.TB, buf []byte) []byte {
+	tb.Helper()
+	tables, a, o := testColorTables(tb, buf)
+	// version, base glyphs, base glyphs offset, layers offset, layers
+	b := []byte{0, 0, 0, 1, 0, 0, 0, 14, 0, 0, 0, 20, 0, 2}
+	b = binary.BigEndian.AppendUint16(b, a)
+	b = append(b, 0, 0, 0, 2)
+	b = binary.BigEndian.AppendUint16(b, o)
+	b = append(b, 0, 0)
+	b = binary.BigEndian.AppendUint16(b, a)
+	b = append(b, 0, 1)
+	tables["COLR"], tables["CPAL"] = b, testCPAL()
+	return (&fontpkg.SFNT{IsTrueType: true, Tables: tables}).Write()
+}
+
+// testCOLR1 adds version 1 COLR and CPAL tables to the font, drawing 'A' as
+// an 'O' filled with a red to blue linear gradient.
+func testCOLR1(tb testing.TB, buf []byte) []byte {
+	tb.Helper()
+	tables, a, o := testColorTables(tb, buf)
+	// version 0 header, base glyph list offset, and null offsets
+	b := []byte{0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 34}
+	b = append(b, make([]byte, 16)...)
+	// base glyph list, with the paint offset from the list
+	b = append(b, 0, 0, 0, 1)
+	b = binary.BigEndian.AppendUint16(b, a)
+	b = append(b, 0, 0, 0, 10)
+	// paint glyph, with the paint offset from the paint glyph
+	b = append(b, 10, 0, 0, 6)
+	b = binary.BigEndian.AppendUint16(b, o)
+	// linear gradient, with the color line offset from the gradient
+	b = append(b, 4, 0, 0, 16)
+	for _, v := range []int16{100, 0, 400, 0, 100, 1000} {
+		b = binary.BigEndian.AppendUint16(b, uint16(v))
+	}
+	// color line: pad, 2 stops of offset, palette index, alpha
+	b = append(b, 0, 0, 2)
+	b = append(b, 0, 0, 0, 0, 0x40, 0)
+	b = append(b, 0x40, 0, 0, 1, 0x40, 0)
+	tables["COLR"], tables["CPAL"] = b, testCPAL()
+	return (&fontpkg.SFNT{IsTrueType: true, Tables: tables}).Write()
+}
+
+// testPNG returns a square PNG image of the color.
+func testPNG(tb testing.TB, c color.Color, size int) []byte {
+	tb.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := range size {
+		for x := range size {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		tb.Fatalf("expected no error, got: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// testSbix adds a sbix table to the font, with a red square PNG for 'A'.
+func testSbix(tb testing.TB, buf []byte) []byte {
+	tb.Helper()
+	tables, a, _ := testColorTables(tb, buf)
+	n := int(binary.BigEndian.Uint16(tables["maxp"][4:]))
+	// origin offset and graphic type
+	glyph := append([]byte{0, 0, 0, 0}, "png "...)
+	glyph = append(glyph, testPNG(tb, color.RGBA{0xff, 0, 0, 0xff}, 32)...)
+	// version, flags, strikes, strike offset
+	b := []byte{0, 1, 0, 1, 0, 0, 0, 1, 0, 0, 0, 12}
+	// ppem, ppi, glyph data offsets from the strike
+	b = append(b, 0, 32, 0, 72)
+	offset := uint32(4 + 4*(n+1))
+	for id := range n + 1 {
+		b = binary.BigEndian.AppendUint32(b, offset)
+		if id == int(a) {
+			offset += uint32(len(glyph))
+		}
+	}
+	b = append(b, glyph...)
+	tables["sbix"] = b
+	return (&fontpkg.SFNT{IsTrueType: true, Tables: tables}).Write()
+}
+
+// testCBDT adds CBLC and CBDT tables to the font, with a green square PNG
+// for 'A', optionally dropping the glyph outlines.
+func testCBDT(tb testing.TB, buf []byte, bitmapOnly bool) []byte {
+	tb.Helper()
+	tables, a, _ := testColorTables(tb, buf)
+	img := testPNG(tb, color.RGBA{0, 0xff, 0, 0xff}, 32)
+	// version, then format 17 glyph: small metrics, data length, data
+	cbdt := []byte{0, 3, 0, 0, 32, 32, 0, 28, 32}
+	cbdt = binary.BigEndian.AppendUint32(cbdt, uint32(len(img)))
+	cbdt = append(cbdt, img...)
+	// version, sizes, then bitmap size: index subtable array offset, size,
+	// count, color ref, hori and vert line metrics, glyph range, ppem, bit
+	// depth, flags
+	cblc := []byte{0, 3, 0, 0, 0, 0, 0, 1, 0, 0, 0, 56, 0, 0, 0, 20, 0, 0, 0, 1, 0, 0, 0, 0}
+	cblc = append(cblc, 28, 0xfc, 32, 1, 0, 0, 0, 0, 0, 0, 0, 0)
+	cblc = append(cblc, make([]byte, 12)...)
+	cblc = binary.BigEndian.AppendUint16(cblc, a)
+	cblc = binary.BigEndian.AppendUint16(cblc, a)
+	cblc = append(cblc, 32, 32, 32, 1)
+	// index subtable array: glyph range, subtable offset from the array
+	cblc = binary.BigEndian.AppendUint16(cblc, a)
+	cblc = binary.BigEndian.AppendUint16(cblc, a)
+	cblc = append(cblc, 0, 0, 0, 8)
+	// index subtable format 1: index format, image format, image data
+	// offset, glyph offsets
+	cblc = append(cblc, 0, 1, 0, 17, 0, 0, 0, 4, 0, 0, 0, 0)
+	cblc = binary.BigEndian.AppendUint32(cblc, uint32(len(cbdt)-4))
+	tables["CBDT"], tables["CBLC"] = cbdt, cblc
+	if bitmapOnly {
+		delete(tables, "glyf")
+		delete(tables, "loca")
+	}
+	return (&fontpkg.SFNT{IsTrueType: true, Tables: tables}).Write()
+}
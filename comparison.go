@@ -0,0 +1,230 @@
+package fontimg
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+	fontpkg "github.com/tdewolff/font"
+)
+
+// ShapingToggle is the shaping step toggled by a shaping comparison.
+type ShapingToggle int
+
+// Shaping toggles.
+const (
+	// ToggleKerning toggles kerning (ie, the kern feature).
+	ToggleKerning ShapingToggle = iota
+	// ToggleShaping toggles all shaping, laying out the nominal glyph of each
+	// character (from the character map) by its advance when off.
+	ToggleShaping
+)
+
+// String satisfies the [fmt.Stringer] interface.
+func (t ShapingToggle) String() string {
+	switch t {
+	case ToggleKerning:
+		return "kerning"
+	case ToggleShaping:
+		return "shaping"
+	}
+	return fmt.Sprintf("ShapingToggle(%d)", int(t))
+}
+
+// RasterizeShapingComparison rasterizes a shaping comparison using the
+// options. See [Font.ShapingComparison].
+func (font *Font) RasterizeShapingComparison(toggle ShapingToggle, opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := font.ShapingComparison(toggle, opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// ShapingComparison lays out a comparison of text rendered with and without
+// the shaping step on a canvas, for evaluating how much the font relies on
+// it (ie, on GPOS kerning). Each line of text is drawn with the step on,
+// above the line with the step off, with glyphs whose glyph, advance or
+// offset changed highlighted, and the change in advance of each glyph shaded
+// on the line with the step on: red when tightened, and green when loosened.
+// The rows are labeled, and summarized with the number of changed glyphs and
+// the change in total advance, using the embedded label font. The options'
+// text (when not empty) is used in place of a kerning sample. When opts is
+// nil, the default options will be used.
+func (font *Font) ShapingComparison(toggle ShapingToggle, opts *Options) (_ *canvas.Canvas, err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if toggle != ToggleKerning && toggle != ToggleShaping {
+		return nil, fmt.Errorf("invalid shaping toggle %d", int(toggle))
+	}
+	text := opts.Text
+	if strings.TrimSpace(text) == "" {
+		text = comparisonText
+	}
+	ff, err := font.Load(opts.Style)
+	if err != nil {
+		return nil, err
+	}
+	lff, err := LabelFont().Load(canvas.FontRegular)
+	if err != nil {
+		return nil, err
+	}
+	label, p := lff.Face(0.4*float64(opts.Size), opts.FG), labelPrinter(opts.Language)
+	face := ff.Face(float64(opts.Size), opts.FG, opts.Style, opts.Variant)
+	sfnt, scale := face.Font.SFNT, face.MmPerEm
+	// create canvas and context
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	lh, m := label.Metrics().LineHeight, face.Metrics()
+	rows := [2]string{p.Sprintf("kerning on"), p.Sprintf("kerning off")}
+	if toggle == ToggleShaping {
+		rows = [2]string{p.Sprintf("shaping on"), p.Sprintf("shaping off")}
+	}
+	var n, changed, advance int
+	y := 0.0
+	for line := range strings.SplitSeq(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		on, off := comparisonGlyphs(face, line, toggle, true), comparisonGlyphs(face, line, toggle, false)
+		diff := diffGlyphs(on, off)
+		n += len(on)
+		for i, glyphs := range [2][]Glyph{on, off} {
+			ctx.SetFillColor(opts.FG)
+			ctx.DrawText(0, y, canvas.NewTextLine(label, rows[i], canvas.Left))
+			y -= lh + m.Ascent
+			x := 0.0
+			for j, g := range glyphs {
+				adv := float64(g.XAdvance) * scale
+				d, ok := diff[g.Cluster]
+				if i == 0 {
+					advance += g.XAdvance
+					if ok {
+						changed++
+					}
+				} else {
+					advance -= g.XAdvance
+				}
+				if ok {
+					// shade the change in advance
+					if dx := float64(d) * scale; i == 0 && dx != 0 {
+						ctx.SetFillColor(spacingColors[0])
+						if dx < 0 {
+							ctx.SetFillColor(spacingColors[1])
+						}
+						ctx.DrawPath(x+min(adv, adv-dx), y-m.Descent, canvas.Rectangle(math.Abs(dx), m.Ascent+m.Descent))
+					}
+					ctx.SetFillColor(comparisonChanged)
+				} else {
+					ctx.SetFillColor(opts.FG)
+				}
+				path := new(canvas.Path)
+				if err := sfnt.GlyphPath(path, g.ID, 0, x+float64(g.XOffset)*scale, y+float64(g.YOffset)*scale, scale, fontpkg.NoHinting); err != nil {
+					return nil, fmt.Errorf("glyph %d: %v", j, err)
+				}
+				ctx.DrawPath(0, 0, path)
+				x += adv
+			}
+			y -= m.Descent + lh
+		}
+		y -= lh
+	}
+	// draw summary
+	ctx.SetFillColor(opts.FG)
+	em := float64(advance) / float64(sfnt.Head.UnitsPerEm)
+	ctx.DrawText(0, y, canvas.NewTextLine(label, p.Sprintf("%d of %d glyphs changed, advance %+.2f em", changed, n, em), canvas.Left))
+	// fit canvas to context
+	c.Fit(opts.Margin)
+	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
+
+// comparisonGlyphs returns the glyphs of the line shaped by the face with
+// the toggled step on or off.
+func comparisonGlyphs(face *canvas.FontFace, line string, toggle ShapingToggle, on bool) []Glyph {
+	var v []Glyph
+	if toggle == ToggleShaping && !on {
+		sfnt := face.Font.SFNT
+		for i, r := range line {
+			id := sfnt.GlyphIndex(r)
+			v = append(v, Glyph{
+				ID:       id,
+				Cluster:  i,
+				Text:     string(r),
+				XAdvance: int(sfnt.GlyphAdvance(id)),
+			})
+		}
+		return v
+	}
+	features := ""
+	if toggle == ToggleKerning && !on {
+		features = "-kern"
+	}
+	face.Font.SetFeatures(features)
+	for _, g := range face.Glyphs(line) {
+		v = append(v, Glyph{
+			ID:       g.ID,
+			Cluster:  int(g.Cluster),
+			Text:     g.Text,
+			XAdvance: int(g.XAdvance),
+			YAdvance: int(g.YAdvance),
+			XOffset:  int(g.XOffset),
+			YOffset:  int(g.YOffset),
+		})
+	}
+	return v
+}
+
+// diffGlyphs returns the changed clusters of the glyphs shaped with the step
+// on and off, with the difference in advance (on less off) of the cluster's
+// first glyph. Clusters are changed when their first glyph's glyph, advance
+// or offsets differ, or when only shaped with the step on or off (ie, a
+// ligature), in which case the difference is 0.
+func diffGlyphs(on, off []Glyph) map[int]int {
+	first := func(glyphs []Glyph) map[int]Glyph {
+		m := make(map[int]Glyph, len(glyphs))
+		for _, g := range glyphs {
+			if _, ok := m[g.Cluster]; !ok {
+				m[g.Cluster] = g
+			}
+		}
+		return m
+	}
+	a, b := first(on), first(off)
+	diff := make(map[int]int)
+	for cluster, g := range a {
+		o, ok := b[cluster]
+		switch {
+		case !ok:
+			diff[cluster] = 0
+		case o.ID != g.ID, o.XAdvance != g.XAdvance, o.XOffset != g.XOffset, o.YOffset != g.YOffset:
+			diff[cluster] = g.XAdvance - o.XAdvance
+		}
+	}
+	for cluster := range b {
+		if _, ok := a[cluster]; !ok {
+			diff[cluster] = 0
+		}
+	}
+	return diff
+}
+
+// comparisonText is the default shaping comparison text, of common kerning
+// pairs.
+const comparisonText = "AVATAR Tokyo WAVE\nYves P. LT \"Type\" F, r."
+
+// comparisonChanged is the color of changed glyphs in a shaping comparison.
+var comparisonChanged color.Color = color.NRGBA{R: 0xd0, G: 0x30, B: 0x30, A: 0xff}
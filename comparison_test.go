@@ -0,0 +1,73 @@
+package fontimg
+
+import (
+	"image/color"
+	"maps"
+	"path/filepath"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+)
+
+func TestShapingComparison(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	for _, toggle := range []ShapingToggle{ToggleKerning, ToggleShaping} {
+		t.Run(toggle.String(), func(t *testing.T) {
+			c, err := font.ShapingComparison(toggle, nil)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if n := countGlyphs(c); n == 0 {
+				t.Errorf("expected glyphs")
+			}
+			img, err := font.RasterizeShapingComparison(toggle, nil)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+				t.Errorf("expected non-empty image, got: %v", b)
+			}
+		})
+	}
+	if _, err := font.ShapingComparison(ShapingToggle(5), nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	ff, err := font.Load(canvas.FontRegular)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	face := ff.Face(48, color.Black)
+	for _, test := range []struct {
+		toggle ShapingToggle
+		text   string
+		exp    bool
+	}{
+		{ToggleKerning, "AV", true},
+		{ToggleKerning, "HH", false},
+		{ToggleShaping, "AV", true},
+		{ToggleShaping, "HH", false},
+	} {
+		on, off := comparisonGlyphs(face, test.text, test.toggle, true), comparisonGlyphs(face, test.text, test.toggle, false)
+		if diff := diffGlyphs(on, off); (len(diff) != 0) != test.exp {
+			t.Errorf("%v %q expected changed %t, got: %v", test.toggle, test.text, test.exp, diff)
+		}
+	}
+}
+
+func TestDiffGlyphs(t *testing.T) {
+	on := []Glyph{
+		{ID: 1, Cluster: 0, XAdvance: 500},
+		{ID: 2, Cluster: 1, XAdvance: 480},
+		{ID: 9, Cluster: 2, XAdvance: 800},
+	}
+	off := []Glyph{
+		{ID: 1, Cluster: 0, XAdvance: 500},
+		{ID: 2, Cluster: 1, XAdvance: 520},
+		{ID: 3, Cluster: 2, XAdvance: 300},
+		{ID: 4, Cluster: 3, XAdvance: 300},
+	}
+	exp := map[int]int{1: -40, 2: 500, 3: 0}
+	if diff := diffGlyphs(on, off); !maps.Equal(diff, exp) {
+		t.Errorf("expected %v, got: %v", exp, diff)
+	}
+}
@@ -0,0 +1,79 @@
+package fontimg
+
+import (
+	"sync"
+
+	fontpkg "github.com/tdewolff/font"
+)
+
+// Decoder decodes a bitmap font format (ie, Amiga bitmap fonts or Linux PSF
+// console fonts), for formats not supported by the underlying font parser.
+// Decoded fonts are rendered from their bitmap strikes. See
+// [RegisterDecoder].
+type Decoder interface {
+	// Match returns true when the header (the first 64 bytes of the font
+	// data, or fewer when the data is shorter) is in the decoder's format.
+	Match(header []byte) bool
+	// Decode decodes the font data.
+	Decode(buf []byte) (*BitmapFont, error)
+}
+
+// RegisterDecoder registers the named decoder, replacing any decoder
+// previously registered with the same name. Decoders are matched in
+// registration order, after the built-in formats (TrueType, OpenType, WOFF,
+// WOFF2 and EOT).
+//
+// The fon (Windows .fon), fnt (Windows .fnt) and psf (Linux PC Screen Font)
+// decoders are registered by default.
+func RegisterDecoder(name string, d Decoder) {
+	decoders.Lock()
+	defer decoders.Unlock()
+	for i, nd := range decoders.v {
+		if nd.name == name {
+			decoders.v[i].d = d
+			return
+		}
+	}
+	decoders.v = append(decoders.v, namedDecoder{name, d})
+}
+
+// decoder returns the registered decoder matching the header, or nil when
+// the header is a built-in format or no decoder matches.
+func decoder(header []byte) Decoder {
+	_, d := matchDecoder(header)
+	return d
+}
+
+// matchDecoder returns the name and registered decoder matching the header,
+// or nil when the header is a built-in format or no decoder matches.
+func matchDecoder(header []byte) (string, Decoder) {
+	if _, err := fontpkg.MediaType(header); err == nil {
+		return "", nil
+	}
+	decoders.RLock()
+	defer decoders.RUnlock()
+	for _, nd := range decoders.v {
+		if nd.d.Match(header) {
+			return nd.name, nd.d
+		}
+	}
+	return "", nil
+}
+
+// namedDecoder is a registered decoder.
+type namedDecoder struct {
+	name string
+	d    Decoder
+}
+
+// decoders are the registered decoders.
+var decoders = struct {
+	sync.RWMutex
+	v []namedDecoder
+}{
+	v: []namedDecoder{
+		{"fon", fonDecoder{}},
+		{"fnt", fntDecoder{}},
+		{"psf", psfDecoder{}},
+	},
+}
@@ -0,0 +1,96 @@
+package fontimg
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegisterDecoder(t *testing.T) {
+	if d := decoder(testFON()); d != (fonDecoder{}) {
+		t.Errorf("expected fon decoder, got: %T", d)
+	}
+	if d := decoder(testPSF2(false)); d != (psfDecoder{}) {
+		t.Errorf("expected psf decoder, got: %T", d)
+	}
+	// built-in formats are not decoded
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if d := decoder(buf); d != nil {
+		t.Errorf("expected no decoder, got: %T", d)
+	}
+	// register a decoder for a custom format, opened from a directory
+	RegisterDecoder("test", testDecoder{})
+	defer func() {
+		decoders.Lock()
+		defer decoders.Unlock()
+		decoders.v = decoders.v[:len(decoders.v)-1]
+	}()
+	dir := t.TempDir()
+	for name, b := range map[string][]byte{
+		"a.test":  []byte("TEST\x08"),
+		"b.txt":   []byte("not a font"),
+		"c.psf":   testPSF1(true),
+		"d.other": nil,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0o644); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	fonts, err := Open(dir, 0, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var names []string
+	for _, font := range fonts {
+		names = append(names, filepath.Base(font.Path))
+	}
+	if fmt.Sprint(names) != "[a.test c.psf]" {
+		t.Errorf("expected [a.test c.psf], got: %v", names)
+	}
+	for _, font := range fonts {
+		if _, err := font.RasterizeOptions(nil); err != nil {
+			t.Errorf("%s expected no error, got: %v", font.Path, err)
+		}
+	}
+	bf, err := fonts[0].Bitmap()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if bf.Strikes[0].Height != 8 || fonts[0].Name != "Test" {
+		t.Errorf("expected Test with height 8, got: %q %d", fonts[0].Name, bf.Strikes[0].Height)
+	}
+}
+
+// testDecoder decodes a "TEST" header followed by the height, as a font
+// with a single square glyph for every printable ASCII rune.
+type testDecoder struct{}
+
+func (testDecoder) Match(header []byte) bool {
+	return bytes.HasPrefix(header, []byte("TEST"))
+}
+
+func (testDecoder) Decode(buf []byte) (*BitmapFont, error) {
+	if len(buf) < 5 {
+		return nil, fmt.Errorf("bad header")
+	}
+	height := int(buf[4])
+	s := &Strike{
+		Height: height,
+		Ascent: height,
+		Glyphs: make(map[rune]*image.Alpha),
+	}
+	for r := rune(' '); r < 0x7f; r++ {
+		img := image.NewAlpha(image.Rect(0, 0, height, height))
+		for i := range img.Pix {
+			img.Pix[i] = 0xff
+		}
+		s.Glyphs[r] = img
+	}
+	return &BitmapFont{Family: "Test", Strikes: []*Strike{s}}, nil
+}
@@ -0,0 +1,223 @@
+package fontimg
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tdewolff/canvas"
+	fontpkg "github.com/tdewolff/font"
+)
+
+// EmojiSequence is a composed emoji sequence.
+type EmojiSequence struct {
+	// Kind is the kind of sequence: "modifier" for skin tone modifier
+	// sequences, "zwj" for zero width joiner sequences, or "flag" for
+	// regional indicator flag sequences.
+	Kind string
+	// Name is the sequence's name.
+	Name string
+	// Text is the sequence's text.
+	Text string
+}
+
+// DefaultEmojiSequences are the default emoji sequences.
+var DefaultEmojiSequences = []EmojiSequence{
+	{"modifier", "thumbs up: medium skin tone", "\U0001F44D\U0001F3FD"},
+	{"modifier", "waving hand: dark skin tone", "\U0001F44B\U0001F3FF"},
+	{"modifier", "person: light skin tone", "\U0001F9D1\U0001F3FB"},
+	{"modifier", "raised hand: medium-dark skin tone", "\u270b\U0001F3FE"},
+	{"modifier", "clapping hands: medium-light skin tone", "\U0001F44F\U0001F3FC"},
+	{"zwj", "family: man, woman, girl", "\U0001F468\u200d\U0001F469\u200d\U0001F467"},
+	{"zwj", "family: woman, woman, boy, boy", "\U0001F469\u200d\U0001F469\u200d\U0001F466\u200d\U0001F466"},
+	{"zwj", "couple with heart: woman, man", "\U0001F469\u200d\u2764\ufe0f\u200d\U0001F468"},
+	{"zwj", "technologist", "\U0001F9D1\u200d\U0001F4BB"},
+	{"zwj", "rainbow flag", "\U0001F3F3\ufe0f\u200d\U0001F308"},
+	{"flag", "flag: United States", "\U0001F1FA\U0001F1F8"},
+	{"flag", "flag: Japan", "\U0001F1EF\U0001F1F5"},
+	{"flag", "flag: Germany", "\U0001F1E9\U0001F1EA"},
+	{"flag", "flag: Brazil", "\U0001F1E7\U0001F1F7"},
+	{"flag", "flag: United Nations", "\U0001F1FA\U0001F1F3"},
+}
+
+// EmojiResult is the result of shaping an emoji sequence with a font.
+type EmojiResult struct {
+	EmojiSequence
+	// Glyphs are the sequence's shaped glyph IDs, excluding the glyphs of
+	// default ignorable characters (ie, the zero width joiner, or variation
+	// selectors) left unjoined.
+	Glyphs []uint16
+}
+
+// Resolved returns whether the sequence resolved to a single glyph, instead
+// of falling apart into its components.
+func (res EmojiResult) Resolved() bool {
+	return len(res.Glyphs) == 1 && res.Glyphs[0] != 0
+}
+
+// ResolveEmoji shapes each of the emoji sequences with the font, reporting which
+// sequences the font resolves to a single glyph, and which fall apart into
+// their components. When seqs is empty, [DefaultEmojiSequences] are used.
+// The "emoji" preset renders the default sequences, composed and decomposed.
+func (font *Font) ResolveEmoji(seqs ...EmojiSequence) ([]EmojiResult, error) {
+	if len(seqs) == 0 {
+		seqs = DefaultEmojiSequences
+	}
+	var v []EmojiResult
+	for _, seq := range seqs {
+		s, err := font.Shape(seq.Text, "", nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", seq.Name, err)
+		}
+		res := EmojiResult{EmojiSequence: seq}
+		for _, g := range s.Glyphs {
+			if strings.TrimFunc(g.Text, emojiIgnorable) != "" {
+				res.Glyphs = append(res.Glyphs, g.ID)
+			}
+		}
+		v = append(v, res)
+	}
+	return v, nil
+}
+
+// emoji returns the text of the default emoji sequences of the kind,
+// separated by spaces.
+func emoji(kind string) string {
+	var v []string
+	for _, seq := range DefaultEmojiSequences {
+		if seq.Kind == kind {
+			v = append(v, seq.Text)
+		}
+	}
+	return strings.Join(v, " ")
+}
+
+// decompose returns the text with the characters of each space separated
+// sequence separated by a space, and the sequences separated by three
+// spaces, dropping default ignorable characters, for rendering emoji
+// sequences as their components.
+func decompose(text string) string {
+	var v []string
+	for _, seq := range strings.Fields(text) {
+		var runes []string
+		for _, r := range seq {
+			if !emojiIgnorable(r) {
+				runes = append(runes, string(r))
+			}
+		}
+		v = append(v, strings.Join(runes, " "))
+	}
+	return strings.Join(v, "   ")
+}
+
+// emojiIgnorable returns whether r is a default ignorable character of emoji
+// sequences: the zero width joiner, a variation selector, or a tag.
+func emojiIgnorable(r rune) bool {
+	return r == '\u200d' || r == '\ufe0e' || r == '\ufe0f' || 0xE0020 <= r && r <= 0xE007F
+}
+
+// EmojiFonts are the names of the platform emoji fonts, in order of
+// preference, used for [Options.EmojiFallback].
+var EmojiFonts = []string{
+	"Noto Color Emoji",
+	"Apple Color Emoji",
+	"Segoe UI Emoji",
+	"Twemoji Mozilla",
+	"Noto Emoji",
+}
+
+// SystemEmojiFont returns the first of the [EmojiFonts] in the system fonts,
+// or nil when none are installed. When sysfonts is nil, the default system
+// fonts will be loaded.
+func SystemEmojiFont(sysfonts *fontpkg.SystemFonts) (*Font, error) {
+	if sysfonts == nil {
+		var err error
+		if sysfonts, err = SystemFonts(); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range EmojiFonts {
+		if font := Match(name, canvas.FontRegular, sysfonts); font != nil {
+			return font, nil
+		}
+	}
+	return nil, nil
+}
+
+// emojiFont returns the emoji font used for [Options.EmojiFallback].
+var emojiFont = func() (*Font, error) {
+	return SystemEmojiFont(nil)
+}
+
+// emojiFallback draws runs of emoji missing from a font with an emoji font.
+type emojiFallback struct {
+	ff   *canvas.FontFamily
+	sfnt *fontpkg.SFNT
+	cf   *colorFace
+	// runs is the number of runs drawn.
+	runs int
+}
+
+// newEmojiFallback loads the emoji font for drawing at ppem, returning nil
+// when there is no emoji font.
+func newEmojiFallback(ppem int) (*emojiFallback, error) {
+	font, err := emojiFont()
+	if err != nil || font == nil {
+		return nil, err
+	}
+	ff, err := font.Load(canvas.FontRegular)
+	if err != nil {
+		return nil, fmt.Errorf("emoji font %s: %v", font.BestName(), err)
+	}
+	cf, err := font.colorFace(ppem)
+	if err != nil {
+		return nil, fmt.Errorf("emoji font %s: %v", font.BestName(), err)
+	}
+	return &emojiFallback{
+		ff:   ff,
+		sfnt: ff.Face(16).Font.SFNT,
+		cf:   cf,
+	}, nil
+}
+
+// span returns the end of the run of emoji at the start of line missing
+// from the face's font, or 0 when the line does not start with an emoji
+// mapped by the emoji font. Default ignorable characters (ie, the zero width
+// joiner) continue the run.
+func (ef *emojiFallback) span(sfnt *fontpkg.SFNT, line string) int {
+	end := 0
+	for i, r := range line {
+		if !(notdef(sfnt, r) && ef.sfnt.GlyphIndex(r) != 0) && !(end != 0 && emojiIgnorable(r)) {
+			break
+		}
+		end = i + utf8.RuneLen(r)
+	}
+	return end
+}
+
+// draw draws the run with the emoji font at the size and color of the face at
+// x and the baseline, flagged with an underline, returning the run's advance.
+func (ef *emojiFallback) draw(ctx *canvas.Context, face *canvas.FontFace, run string, x, baseline float64) float64 {
+	ef.runs++
+	eface := ef.ff.Face(face.Size*72/25.4, face.Fill.Color)
+	if ef.cf != nil {
+		// color glyphs are drawn over the transparent text
+		eface.Fill = canvas.Paint{Color: canvas.Transparent}
+	}
+	txt := canvas.NewTextLine(eface, run, canvas.Left)
+	ctx.DrawText(x, baseline, txt)
+	if ef.cf != nil {
+		ef.cf.drawText(ctx, x, baseline, txt, face.Fill.Color)
+	}
+	w, h := txt.Bounds().X1, face.Metrics().CapHeight/12
+	ctx.Push()
+	ctx.SetFillColor(emojiFallbackColor)
+	ctx.DrawPath(x, baseline-face.Metrics().Descent/2-h, canvas.Rectangle(w, h))
+	ctx.Pop()
+	return w
+}
+
+// emojiFallbackColor is the color of the underline flagging runs drawn with
+// the emoji font.
+var emojiFallbackColor color.Color = color.NRGBA{R: 0xe0, G: 0x80, B: 0x00, A: 0xff}
@@ -0,0 +1,138 @@
+package fontimg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveEmoji(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	v, err := font.ResolveEmoji()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(v) != len(DefaultEmojiSequences) {
+		t.Fatalf("expected %d results, got: %d", len(DefaultEmojiSequences), len(v))
+	}
+	exp := map[string]int{
+		"thumbs up: medium skin tone":    2,
+		"family: man, woman, girl":       3,
+		"couple with heart: woman, man":  3,
+		"rainbow flag":                   2,
+		"flag: United States":            2,
+		"family: woman, woman, boy, boy": 4,
+	}
+	for _, res := range v {
+		switch n, ok := exp[res.Name]; {
+		case res.Resolved():
+			t.Errorf("%s expected not resolved, got: %v", res.Name, res.Glyphs)
+		case ok && len(res.Glyphs) != n:
+			t.Errorf("%s expected %d glyphs, got: %v", res.Name, n, res.Glyphs)
+		}
+	}
+	seq := EmojiSequence{Kind: "zwj", Name: "ab", Text: "a\u200db"}
+	if v, err := font.ResolveEmoji(seq); err != nil || len(v) != 1 || v[0].Name != "ab" || len(v[0].Glyphs) != 2 {
+		t.Errorf("expected 2 glyphs for %q, got: %v (%v)", seq.Text, v, err)
+	}
+	for _, test := range []struct {
+		glyphs []uint16
+		exp    bool
+	}{
+		{[]uint16{12}, true},
+		{[]uint16{0}, false},
+		{[]uint16{12, 13}, false},
+		{nil, false},
+	} {
+		if res := (EmojiResult{Glyphs: test.glyphs}); res.Resolved() != test.exp {
+			t.Errorf("%v expected resolved %t, got: %t", test.glyphs, test.exp, res.Resolved())
+		}
+	}
+}
+
+func TestDecompose(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp string
+	}{
+		{"", ""},
+		{"\U0001F44D\U0001F3FD", "\U0001F44D \U0001F3FD"},
+		{"\U0001F3F3\ufe0f\u200d\U0001F308 \U0001F1FA\U0001F1F8", "\U0001F3F3 \U0001F308   \U0001F1FA \U0001F1F8"},
+	}
+	for i, test := range tests {
+		if s := decompose(test.s); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+	}
+	if s, exp := emoji("flag"), "\U0001F1FA\U0001F1F8 \U0001F1EF\U0001F1F5 \U0001F1E9\U0001F1EA \U0001F1E7\U0001F1F7 \U0001F1FA\U0001F1F3"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+}
+
+func TestEmojiFallback(t *testing.T) {
+	defer func(f func() (*Font, error)) { emojiFont = f }(emojiFont)
+	// stand in for the emoji font with a font mapping characters missing from
+	// the previewed font
+	emojiFont = func() (*Font, error) {
+		return New(nil, filepath.Join("testdata", "NotoMono-Regular.ttf")), nil
+	}
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	ef, err := newEmojiFallback(48)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	sfnt, err := font.sfnt()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, test := range []struct {
+		s   string
+		exp int
+	}{
+		{"", 0},
+		{"abc", 0},
+		{"aѠ", 0},
+		{"\u200dѠ", 0},
+		{"Ѡ", 2},
+		{"Ѡѡ a", 4},
+		{"Ѡ\u200dѡ\ufe0f a", 10},
+	} {
+		if n := ef.span(sfnt, test.s); n != test.exp {
+			t.Errorf("%q expected %d, got: %d", test.s, test.exp, n)
+		}
+	}
+	for _, test := range []struct {
+		fallback bool
+		strict   bool
+		exp      int
+		err      bool
+	}{
+		{false, false, 0, false},
+		{true, false, 2, false},
+		{false, true, 0, true},
+		{true, true, 2, false},
+	} {
+		opts := DefaultOptions()
+		opts.Template, _ = NewTemplate("Hello ѠѡѤ there Ѫ")
+		opts.EmojiFallback, opts.Strict = test.fallback, test.strict
+		_, st, err := font.RasterizeStats(opts)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("fallback %t expected error, got nil", test.fallback)
+		case test.err:
+		case err != nil:
+			t.Errorf("fallback %t expected no error, got: %v", test.fallback, err)
+		case st.EmojiRuns != test.exp:
+			t.Errorf("fallback %t expected %d emoji runs, got: %d", test.fallback, test.exp, st.EmojiRuns)
+		}
+	}
+	// no emoji font
+	emojiFont = func() (*Font, error) {
+		return nil, nil
+	}
+	opts := DefaultOptions()
+	opts.Template, _ = NewTemplate("Hello Ѡ")
+	opts.EmojiFallback = true
+	if _, st, err := font.RasterizeStats(opts); err != nil || st.EmojiRuns != 0 {
+		t.Errorf("expected no emoji runs, got: %v (%v)", st, err)
+	}
+}
@@ -0,0 +1,149 @@
+package fontimg
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// EncodeFunc encodes an image to w. Quality is the output's quality (see
+// [Output.Quality]), for lossy formats.
+type EncodeFunc func(w io.Writer, img image.Image, quality int) error
+
+// encoders are the registered image encoders.
+var encoders = struct {
+	sync.RWMutex
+	m map[string]EncodeFunc
+}{
+	m: map[string]EncodeFunc{
+		"png": func(w io.Writer, img image.Image, _ int) error {
+			return png.Encode(w, img)
+		},
+		"jpeg": func(w io.Writer, img image.Image, quality int) error {
+			if quality == 0 {
+				quality = jpeg.DefaultQuality
+			}
+			return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+		},
+		"gif": func(w io.Writer, img image.Image, _ int) error {
+			return gif.Encode(w, img, nil)
+		},
+	},
+}
+
+// RegisterEncoder registers an image encoder for the format (ie, webp, avif),
+// replacing any encoder previously registered for the format. The png, jpeg,
+// and gif formats are registered by default.
+func RegisterEncoder(format string, f EncodeFunc) {
+	encoders.Lock()
+	defer encoders.Unlock()
+	encoders.m[strings.ToLower(format)] = f
+}
+
+// EncoderFormats returns the sorted formats of the registered image encoders.
+func EncoderFormats() []string {
+	encoders.RLock()
+	defer encoders.RUnlock()
+	return slices.Sorted(maps.Keys(encoders.m))
+}
+
+// encoder returns the encoder for the format.
+func encoder(format string) (EncodeFunc, bool) {
+	encoders.RLock()
+	defer encoders.RUnlock()
+	switch format = strings.ToLower(format); format {
+	case "jpg":
+		format = "jpeg"
+	}
+	f, ok := encoders.m[format]
+	return f, ok
+}
+
+// Output is an image encoding output.
+type Output struct {
+	// W is the writer the encoded image is written to.
+	W io.Writer
+	// Format is the image format (ie, png, jpeg), as registered with
+	// [RegisterEncoder].
+	Format string
+	// Width and Height are the maximum dimensions of the output image, in
+	// pixels. Larger images are scaled down to fit (ie, for thumbnails),
+	// keeping the aspect ratio. When zero, the dimension is not limited.
+	Width, Height int
+	// Quality is the encoding quality, from 1 to 100, for lossy formats. When
+	// zero, the encoder's default quality is used.
+	Quality int
+}
+
+// Encode encodes the image to each of the outputs, reusing the image for all
+// outputs, and scaling it once per distinct output size. All outputs are
+// checked before any are encoded.
+func Encode(img image.Image, outputs ...Output) error {
+	fs := make([]EncodeFunc, len(outputs))
+	for i, out := range outputs {
+		var ok bool
+		switch fs[i], ok = encoder(out.Format); {
+		case out.W == nil:
+			return fmt.Errorf("output %d: writer not set", i)
+		case !ok:
+			return fmt.Errorf("output %d: unknown image format %q", i, out.Format)
+		case out.Width < 0 || out.Height < 0:
+			return fmt.Errorf("output %d: invalid size %dx%d", i, out.Width, out.Height)
+		case out.Quality < 0 || 100 < out.Quality:
+			return fmt.Errorf("output %d: invalid quality %d: must be 1 to 100", i, out.Quality)
+		}
+	}
+	scaled := make(map[image.Point]image.Image)
+	for i, out := range outputs {
+		size := fitSize(img.Bounds().Size(), out.Width, out.Height)
+		v, ok := scaled[size]
+		if !ok {
+			v = scaleImage(img, size)
+			scaled[size] = v
+		}
+		if err := fs[i](out.W, v, out.Quality); err != nil {
+			return fmt.Errorf("output %d: unable to encode %s: %v", i, out.Format, err)
+		}
+	}
+	return nil
+}
+
+// fitSize returns the size scaled down to fit within the width and height,
+// keeping the aspect ratio. A zero width or height is not limited.
+func fitSize(size image.Point, width, height int) image.Point {
+	scale := 1.0
+	if 0 < width && width < size.X {
+		scale = float64(width) / float64(size.X)
+	}
+	if 0 < height && height < size.Y {
+		scale = min(scale, float64(height)/float64(size.Y))
+	}
+	if scale == 1 {
+		return size
+	}
+	return image.Pt(
+		max(1, int(float64(size.X)*scale+0.5)),
+		max(1, int(float64(size.Y)*scale+0.5)),
+	)
+}
+
+// scaleImage returns the image scaled to the size, or the image when it is
+// already the size.
+func scaleImage(img image.Image, size image.Point) image.Image {
+	b := img.Bounds()
+	if b.Size() == size {
+		return img
+	}
+	dst := image.NewRGBA(image.Rectangle{Max: size})
+	xdraw.CatmullRom.Scale(dst, dst.Rect, img, b, xdraw.Src, nil)
+	return dst
+}
@@ -0,0 +1,82 @@
+package fontimg
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i)
+	}
+	RegisterEncoder("test", func(w io.Writer, img image.Image, quality int) error {
+		return png.Encode(w, img)
+	})
+	tests := []struct {
+		out    Output
+		decode func(io.Reader) (image.Image, error)
+		exp    image.Point
+	}{
+		{Output{Format: "png"}, png.Decode, image.Pt(400, 200)},
+		{Output{Format: "JPG", Quality: 50}, jpeg.Decode, image.Pt(400, 200)},
+		{Output{Format: "gif", Width: 100}, gif.Decode, image.Pt(100, 50)},
+		{Output{Format: "png", Width: 100, Height: 10}, png.Decode, image.Pt(20, 10)},
+		{Output{Format: "png", Width: 800, Height: 800}, png.Decode, image.Pt(400, 200)},
+		{Output{Format: "test", Height: 50}, png.Decode, image.Pt(100, 50)},
+	}
+	outputs := make([]Output, len(tests))
+	bufs := make([]*bytes.Buffer, len(tests))
+	for i, test := range tests {
+		bufs[i] = new(bytes.Buffer)
+		outputs[i] = test.out
+		outputs[i].W = bufs[i]
+	}
+	if err := Encode(img, outputs...); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, test := range tests {
+		v, err := test.decode(bufs[i])
+		if err != nil {
+			t.Fatalf("output %d expected no error, got: %v", i, err)
+		}
+		if size := v.Bounds().Size(); size != test.exp {
+			t.Errorf("output %d expected %v, got: %v", i, test.exp, size)
+		}
+	}
+	for i, out := range []Output{
+		{Format: "png"},
+		{W: io.Discard, Format: "bmp"},
+		{W: io.Discard, Format: "png", Width: -1},
+		{W: io.Discard, Format: "jpeg", Quality: 101},
+	} {
+		var buf bytes.Buffer
+		if err := Encode(img, Output{W: &buf, Format: "png"}, out); err == nil {
+			t.Errorf("test %d expected error, got nil", i)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("test %d expected no output to be encoded", i)
+		}
+	}
+}
+
+func TestEncoderFormats(t *testing.T) {
+	RegisterEncoder("Test", func(w io.Writer, img image.Image, quality int) error {
+		return png.Encode(w, img)
+	})
+	formats := EncoderFormats()
+	for _, exp := range []string{"gif", "jpeg", "png", "test"} {
+		if !slices.Contains(formats, exp) {
+			t.Errorf("expected %q in %q", exp, formats)
+		}
+	}
+	if !slices.IsSorted(formats) {
+		t.Errorf("expected sorted formats, got: %q", formats)
+	}
+}
@@ -0,0 +1,154 @@
+package fontimg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+
+	fontpkg "github.com/tdewolff/font"
+)
+
+// EOTHeader is the header of an Embedded OpenType (EOT) font, the legacy web
+// font container used by Internet Explorer.
+type EOTHeader struct {
+	Version     uint32
+	FamilyName  string
+	StyleName   string
+	VersionName string
+	FullName    string
+	Weight      int
+	Italic      bool
+	// Compressed is true when the font data is MicroType Express compressed.
+	Compressed bool
+	// XOR is true when the font data is obfuscated.
+	XOR  bool
+	data []byte
+}
+
+// EOT flags.
+const (
+	eotCompressed = 0x00000004
+	eotXOR        = 0x10000000
+)
+
+// EOT returns the font's EOT header. Returns an error when the font is not an
+// EOT font.
+func (font *Font) EOT() (*EOTHeader, error) {
+	buf, err := font.data()
+	if err != nil {
+		return nil, err
+	}
+	return parseEOT(buf)
+}
+
+// parseEOT parses the EOT header in b.
+func parseEOT(b []byte) (*EOTHeader, error) {
+	if typ, _ := fontpkg.MediaType(b); typ != "application/vnd.ms-fontobject" {
+		return nil, fmt.Errorf("not an EOT font")
+	}
+	r := &leReader{b: b, i: 4}
+	size, version, flags := r.u32(), r.u32(), r.u32()
+	switch version {
+	case 0x00010000, 0x00020001, 0x00020002:
+	default:
+		return nil, fmt.Errorf("EOT: unsupported version %#08x", version)
+	}
+	r.i += 11 // panose, charset
+	h := &EOTHeader{
+		Version:    version,
+		Italic:     r.bytes(1)[0] != 0,
+		Weight:     int(r.u32()),
+		Compressed: flags&eotCompressed != 0,
+		XOR:        flags&eotXOR != 0,
+	}
+	r.i = 80 // magic, ranges, checksum adjustment, reserved
+	for _, s := range []*string{&h.FamilyName, &h.StyleName, &h.VersionName, &h.FullName} {
+		r.u16() // padding
+		*s = r.string()
+	}
+	if version != 0x00010000 {
+		r.u16() // padding
+		r.bytes(int(r.u16()))
+	}
+	if version == 0x00020002 {
+		r.i += 10 // root string checksum, eudc code page, padding
+		r.bytes(int(r.u16()))
+		r.u32() // eudc flags
+		r.bytes(int(r.u32()))
+	}
+	h.data = r.bytes(int(size))
+	if r.err != nil {
+		return nil, fmt.Errorf("EOT: %v", r.err)
+	}
+	return h, nil
+}
+
+// FontData returns the embedded SFNT font data. Unless the font data is
+// obfuscated, the returned data aliases the EOT font data.
+func (h *EOTHeader) FontData() ([]byte, error) {
+	switch {
+	case h.Compressed:
+		return nil, fmt.Errorf("EOT: MicroType Express compression not supported")
+	case !h.XOR:
+		return h.data, nil
+	}
+	buf := bytes.Clone(h.data)
+	for i := range buf {
+		buf[i] ^= 0x50
+	}
+	return buf, nil
+}
+
+// leReader reads little endian fields, recording the first out of bounds
+// read.
+type leReader struct {
+	b   []byte
+	i   int
+	err error
+}
+
+// bytes reads n bytes.
+func (r *leReader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || len(r.b) < r.i+n {
+		if r.err == nil {
+			r.err = fmt.Errorf("offset %d out of bounds", r.i)
+		}
+		return make([]byte, 4)
+	}
+	b := r.b[r.i : r.i+n : r.i+n]
+	r.i += n
+	return b
+}
+
+// u16 reads a uint16.
+func (r *leReader) u16() uint16 {
+	return binary.LittleEndian.Uint16(r.bytes(2))
+}
+
+// u32 reads a uint32.
+func (r *leReader) u32() uint32 {
+	return binary.LittleEndian.Uint32(r.bytes(4))
+}
+
+// string reads a size prefixed UTF-16 string.
+func (r *leReader) string() string {
+	b := r.bytes(int(r.u16()))
+	v := make([]uint16, len(b)/2)
+	for i := range v {
+		v[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(v))
+}
+
+// data returns the font's data, reading it from the font's path when the
+// font's buffer is not set.
+func (font *Font) data() ([]byte, error) {
+	switch {
+	case font.Buf != nil:
+		return font.Buf, nil
+	case font.Path != "":
+		return readFile(font.Path, newBudget())
+	}
+	return nil, fmt.Errorf("font.Buf and font.Path not set")
+}
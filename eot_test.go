@@ -0,0 +1,121 @@
+package fontimg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/tdewolff/canvas"
+)
+
+func TestEOT(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		name    string
+		version uint32
+		flags   uint32
+		err     bool
+	}{
+		{"v1", 0x00010000, 0, false},
+		{"v2.1", 0x00020001, 0, false},
+		{"v2.2 xor", 0x00020002, eotXOR, false},
+		{"compressed", 0x00020001, eotCompressed, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := testEOT(data, test.version, test.flags)
+			orig := bytes.Clone(buf)
+			font := New(buf, "")
+			h, err := font.EOT()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if h.FamilyName != "Ubuntu" || h.StyleName != "Regular" || h.FullName != "Ubuntu Regular" || h.Weight != 400 {
+				t.Errorf("expected Ubuntu Regular, got: %+v", h)
+			}
+			for range 2 {
+				_, err := font.Load(canvas.FontRegular)
+				if (err != nil) != test.err {
+					t.Fatalf("expected error %t, got: %v", test.err, err)
+				}
+			}
+			if !bytes.Equal(buf, orig) {
+				t.Errorf("expected buf to be unmodified")
+			}
+			if test.err {
+				return
+			}
+			if font.Name != "Ubuntu" {
+				t.Errorf("expected Ubuntu, got: %q", font.Name)
+			}
+			var w strings.Builder
+			font.WriteYAML(&w)
+			if !strings.Contains(w.String(), "eot:\n  family: \"Ubuntu\"\n") {
+				t.Errorf("expected eot, got:\n%s", w.String())
+			}
+		})
+	}
+	if _, err := New(data, "").EOT(); err == nil {
+		t.Errorf("expected error for non EOT font")
+	}
+	if _, err := parseEOT(testEOT(data, 0x00020001, 0)[:100]); err == nil {
+		t.Errorf("expected error for truncated EOT font")
+	}
+}
+
+// testEOT wraps the font data in an EOT header.
+func testEOT(data []byte, version, flags uint32) []byte {
+	var b []byte
+	u16 := func(v uint16) { b = binary.LittleEndian.AppendUint16(b, v) }
+	u32 := func(v uint32) { b = binary.LittleEndian.AppendUint32(b, v) }
+	str := func(s string) {
+		v := utf16.Encode([]rune(s))
+		u16(uint16(2 * len(v)))
+		for _, c := range v {
+			u16(c)
+		}
+	}
+	u32(0) // eot size
+	u32(uint32(len(data)))
+	u32(version)
+	u32(flags)
+	b = append(b, make([]byte, 10)...) // panose
+	b = append(b, 1, 0)                // charset, italic
+	u32(400)
+	u16(0)      // fstype
+	u16(0x504c) // magic
+	b = append(b, make([]byte, 24+4+16)...)
+	for _, s := range []string{"Ubuntu", "Regular", "Version 0.80", "Ubuntu Regular"} {
+		u16(0)
+		str(s)
+	}
+	if version != 0x00010000 {
+		u16(0)
+		str("example.com")
+	}
+	if version == 0x00020002 {
+		u32(0)
+		u32(0)
+		u16(0)
+		u16(0) // signature
+		u32(0)
+		u32(3) // eudc font
+		b = append(b, 1, 2, 3)
+	}
+	if flags&eotXOR != 0 {
+		data = bytes.Clone(data)
+		for i := range data {
+			data[i] ^= 0x50
+		}
+	}
+	b = append(b, data...)
+	binary.LittleEndian.PutUint32(b, uint32(len(b)))
+	return b
+}
@@ -0,0 +1,143 @@
+package fontimg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// FontError is an error for a specific font.
+type FontError struct {
+	Path string
+	Err  error
+}
+
+// Error satisfies the [error] interface.
+func (err *FontError) Error() string {
+	return fmt.Sprintf("%s: %v", err.Path, err.Err)
+}
+
+// Unwrap satisfies the [errors.Unwrap] interface.
+func (err *FontError) Unwrap() error {
+	return err.Err
+}
+
+// MissingRunesError is the error returned when rendering in strict mode
+// (see [Options.Strict]) and the text contains runes missing from the font.
+type MissingRunesError struct {
+	// Runes are the missing runes, in order of first appearance.
+	Runes []rune
+}
+
+// Error satisfies the [error] interface.
+func (err *MissingRunesError) Error() string {
+	v := make([]string, len(err.Runes))
+	for i, r := range err.Runes {
+		v[i] = fmt.Sprintf("%U", r)
+	}
+	return fmt.Sprintf("missing %d runes: %s", len(err.Runes), strings.Join(v, " "))
+}
+
+// missingRunes returns a [MissingRunesError] for the unique runes, or nil
+// when there are no runes.
+func missingRunes(runes []rune) error {
+	if len(runes) == 0 {
+		return nil
+	}
+	seen, err := make(map[rune]bool), new(MissingRunesError)
+	for _, r := range runes {
+		if !seen[r] {
+			seen[r], err.Runes = true, append(err.Runes, r)
+		}
+	}
+	return err
+}
+
+// Errors is a collection of per-font errors, returned alongside partial
+// results when operating on multiple fonts.
+type Errors []*FontError
+
+// Error satisfies the [error] interface.
+func (errs Errors) Error() string {
+	switch len(errs) {
+	case 0:
+		return "no errors"
+	case 1:
+		return errs[0].Error()
+	}
+	v := make([]string, len(errs))
+	for i, err := range errs {
+		v[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors: %s", len(errs), strings.Join(v, "; "))
+}
+
+// Unwrap returns the individual errors.
+func (errs Errors) Unwrap() []error {
+	v := make([]error, len(errs))
+	for i, err := range errs {
+		v[i] = err
+	}
+	return v
+}
+
+// Err returns errs as an error, or nil when empty.
+func (errs Errors) Err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// recoverError recovers a panic, setting err. Used to isolate panics caused
+// by malformed fonts.
+func recoverError(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("malformed font: %v", r)
+	}
+}
+
+// UnsupportedError is the error returned when a font could not be loaded
+// because it uses an unsupported format, such as CFF2 outlines (used by
+// variable OpenType fonts with PostScript outlines).
+type UnsupportedError struct {
+	Format string
+	Err    error
+}
+
+// Error satisfies the [error] interface.
+func (err *UnsupportedError) Error() string {
+	return fmt.Sprintf("unsupported font format %s: %v", err.Format, err.Err)
+}
+
+// Unwrap satisfies the [errors.Unwrap] interface.
+func (err *UnsupportedError) Unwrap() error {
+	return err.Err
+}
+
+// unsupported returns err as an [UnsupportedError] when the SFNT data uses
+// an unsupported format, otherwise returns err.
+func unsupported(buf []byte, err error) error {
+	if hasTable(buf, "CFF2") {
+		return &UnsupportedError{Format: "CFF2", Err: err}
+	}
+	return err
+}
+
+// hasTable returns true when the SFNT data's table directory contains the
+// table. Font collections are not checked.
+func hasTable(b []byte, tag string) bool {
+	if len(b) < 12 || string(b[:4]) == "ttcf" {
+		return false
+	}
+	for i := range int(binary.BigEndian.Uint16(b[4:])) {
+		rec := 12 + 16*i
+		if len(b) < rec+16 {
+			break
+		}
+		if string(b[rec:rec+4]) == tag {
+			return true
+		}
+	}
+	return false
+}
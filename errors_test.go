@@ -0,0 +1,108 @@
+package fontimg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	fontpkg "github.com/tdewolff/font"
+)
+
+func TestOpenErrors(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	dir := t.TempDir()
+	for name, b := range map[string][]byte{
+		"Ubuntu-R.ttf": buf,
+		"bad.ttf":      []byte("not a font"),
+		"trunc.ttf":    buf[:128],
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0o644); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	fonts, err := Open(dir, canvas.FontRegular, nil)
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected Errors, got: %v", err)
+	}
+	if len(errs) != 1 || errs[0].Path != filepath.Join(dir, "bad.ttf") {
+		t.Errorf("expected error for bad.ttf, got: %v", errs)
+	}
+	if len(fonts) != 2 {
+		t.Fatalf("expected 2 fonts, got: %d", len(fonts))
+	}
+	for _, font := range fonts {
+		_, err := font.RasterizeOptions(nil)
+		switch exp := filepath.Base(font.Path) != "trunc.ttf"; {
+		case exp && err != nil:
+			t.Errorf("expected no error for %s, got: %v", font.Path, err)
+		case !exp && err == nil:
+			t.Errorf("expected error for %s", font.Path)
+		}
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cff2 := testCFF2(t, buf)
+	path := filepath.Join(t.TempDir(), "cff2.otf")
+	if err := os.WriteFile(path, cff2, 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		name string
+		font *Font
+		exp  bool
+	}{
+		{"ttf", &Font{Buf: buf}, false},
+		{"buf", &Font{Buf: cff2}, true},
+		{"path", &Font{Path: path}, true},
+		{"lenient", &Font{Buf: cff2, Lenient: true}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.font.Load(canvas.FontRegular)
+			var uerr *UnsupportedError
+			switch {
+			case !test.exp && err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case test.exp && !errors.As(err, &uerr):
+				t.Fatalf("expected UnsupportedError, got: %v", err)
+			case test.exp && uerr.Format != "CFF2":
+				t.Errorf("expected format CFF2, got: %q", uerr.Format)
+			}
+			if _, err := test.font.RasterizeOptions(nil); test.exp && !errors.As(err, &uerr) {
+				t.Errorf("expected UnsupportedError, got: %v", err)
+			}
+		})
+	}
+}
+
+// testCFF2 rebuilds the TrueType font as an OpenType font with a (minimal)
+// CFF2 table in place of its glyf and loca tables.
+func testCFF2(tb testing.TB, buf []byte) []byte {
+	tb.Helper()
+	sfnt, err := fontpkg.ParseSFNT(buf, 0)
+	if err != nil {
+		tb.Fatalf("expected no error, got: %v", err)
+	}
+	tables := make(map[string][]byte)
+	for tag, b := range sfnt.Tables {
+		tables[tag] = b
+	}
+	delete(tables, "glyf")
+	delete(tables, "loca")
+	// version 0.5, num glyphs
+	tables["maxp"] = append([]byte{0, 0, 0x50, 0}, tables["maxp"][4:6]...)
+	// major, minor, header size, top dict length
+	tables["CFF2"] = []byte{2, 0, 5, 0, 0}
+	return (&fontpkg.SFNT{IsCFF: true, Tables: tables}).Write()
+}
@@ -0,0 +1,116 @@
+package fontimg
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+	fontpkg "github.com/tdewolff/font"
+)
+
+// WriteExplorer writes a static font explorer site for the font to dir,
+// creating it when needed. The site is an index.html page listing the
+// font's character map, with a client-side search by glyph name, code point
+// or character, and a lazily loaded image of each mapped glyph. The
+// character map (see [Font.WriteCmapJSON]) is written to cmap.json, and
+// embedded in the page so that the site can be opened from disk. Glyph
+// images are written to glyphs/<id>.png, each a 1.5 em square at the
+// options' size. When opts is nil, the default options will be used.
+func (font *Font) WriteExplorer(dir string, opts *Options) (err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	cmap, err := font.Cmap()
+	if err != nil {
+		return err
+	}
+	ff, err := font.Load(opts.Style)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "glyphs"), 0o755); err != nil {
+		return err
+	}
+	// write glyph images
+	face := ff.Face(float64(opts.Size), opts.FG, opts.Style, opts.Variant)
+	seen := make(map[uint16]bool)
+	for _, e := range cmap {
+		if seen[e.GlyphID] {
+			continue
+		}
+		seen[e.GlyphID] = true
+		c, err := glyphCanvas(face, e.GlyphID, opts)
+		if err != nil {
+			return err
+		}
+		if err := writeExplorerFile(filepath.Join(dir, "glyphs", fmt.Sprintf("%d.png", e.GlyphID)), func(f *os.File) error {
+			return png.Encode(f, rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace))
+		}); err != nil {
+			return err
+		}
+	}
+	// write character map and page
+	if err := writeExplorerFile(filepath.Join(dir, "cmap.json"), func(f *os.File) error {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cmap)
+	}); err != nil {
+		return err
+	}
+	return writeExplorerFile(filepath.Join(dir, "index.html"), func(f *os.File) error {
+		return explorerTpl.Execute(f, map[string]any{
+			"Name":    font.BestName(),
+			"Style":   font.Style,
+			"Version": font.Version,
+			"Cmap":    cmap,
+		})
+	})
+}
+
+// writeExplorerFile creates the named file, writing it with f.
+func writeExplorerFile(name string, f func(*os.File) error) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := f(file); err != nil {
+		file.Close()
+		return fmt.Errorf("unable to write %s: %v", name, err)
+	}
+	return file.Close()
+}
+
+// glyphCanvas lays out the glyph on a 1.5 em square canvas, centered, on a
+// baseline at 0.5 em from the bottom, as in a glyph sheet (see
+// [Font.GlyphSheet]).
+func glyphCanvas(face *canvas.FontFace, id uint16, opts *Options) (*canvas.Canvas, error) {
+	sfnt := face.Font.SFNT
+	em := float64(opts.Size) * 25.4 / 72
+	cell := 1.5 * em
+	c := canvas.New(cell, cell)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	ctx.SetFillColor(opts.FG)
+	p := new(canvas.Path)
+	adv := float64(sfnt.GlyphAdvance(id)) * face.MmPerEm
+	if err := sfnt.GlyphPath(p, id, 0, (cell-adv)/2, em/2, face.MmPerEm, fontpkg.NoHinting); err != nil {
+		return nil, err
+	}
+	ctx.DrawPath(0, 0, p)
+	drawBackground(ctx, opts)
+	ctx.Close()
+	return c, nil
+}
+
+// explorerTpl is the font explorer page template.
+var explorerTpl = template.Must(template.New("explorer.html").Parse(string(explorerHTML)))
+
+//go:embed explorer.html
+var explorerHTML []byte
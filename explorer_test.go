@@ -0,0 +1,61 @@
+package fontimg
+
+import (
+	"encoding/json"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteExplorer(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	dir := t.TempDir()
+	opts := DefaultOptions()
+	opts.Size = 24
+	if err := font.WriteExplorer(dir, opts); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	buf, err := os.ReadFile(filepath.Join(dir, "cmap.json"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var cmap []CmapEntry
+	if err := json.Unmarshal(buf, &cmap); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cmap) == 0 {
+		t.Fatalf("expected character map entries")
+	}
+	for _, e := range cmap {
+		f, err := os.Open(filepath.Join(dir, "glyphs", strconv.Itoa(int(e.GlyphID))+".png"))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		cfg, err := png.DecodeConfig(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if cfg.Width == 0 || cfg.Width != cfg.Height {
+			t.Errorf("glyph %d: expected square image, got: %dx%d", e.GlyphID, cfg.Width, cfg.Height)
+		}
+	}
+	buf, err = os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	page := string(buf)
+	for _, s := range []string{
+		"<title>Ubuntu</title>",
+		`<script id="cmap" type="application/json">[{"codepoint":`,
+		`"glyph_name":"A"`,
+		`img.src = "glyphs/"`,
+	} {
+		if !strings.Contains(page, s) {
+			t.Errorf("expected page to contain %q", s)
+		}
+	}
+}
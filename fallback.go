@@ -0,0 +1,174 @@
+package fontimg
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"unicode"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// FallbackRun is a run of text served by a single font of a fallback stack.
+type FallbackRun struct {
+	// Text is the run's text.
+	Text string
+	// Font is the index of the font in the stack serving the run, or -1 when
+	// no font in the stack maps the run's text.
+	Font int
+}
+
+// ResolveFallback resolves the font serving each character of the text from
+// the ordered fallback stack, as a browser would for a CSS font stack,
+// returning the runs of text served by each font. Combining marks and
+// whitespace are served by the font of the preceding character when mapped by
+// it.
+func ResolveFallback(fonts []*Font, text string) (_ []FallbackRun, err error) {
+	defer recoverError(&err)
+	if len(fonts) == 0 {
+		return nil, fmt.Errorf("no fonts")
+	}
+	var v []func(rune) bool
+	for _, font := range fonts {
+		sfnt, err := font.sfnt()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", font.BestName(), err)
+		}
+		v = append(v, func(r rune) bool {
+			return sfnt.GlyphIndex(r) != 0
+		})
+	}
+	var runs []FallbackRun
+	for _, r := range text {
+		i := -1
+		if n := len(runs); n != 0 && runs[n-1].Font != -1 && (unicode.IsMark(r) || unicode.IsSpace(r)) && v[runs[n-1].Font](r) {
+			i = runs[n-1].Font
+		} else {
+			for j, mapped := range v {
+				if mapped(r) {
+					i = j
+					break
+				}
+			}
+		}
+		if n := len(runs); n != 0 && runs[n-1].Font == i {
+			runs[n-1].Text += string(r)
+			continue
+		}
+		runs = append(runs, FallbackRun{Text: string(r), Font: i})
+	}
+	return runs, nil
+}
+
+// RasterizeFallback rasterizes a fallback stack simulation of the fonts using
+// the options. See [FallbackStack].
+func RasterizeFallback(fonts []*Font, opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := FallbackStack(fonts, opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// FallbackStack lays out a simulation of the ordered fallback stack of fonts
+// on a canvas, rendering sample multilingual text with each character served
+// by the resolved font (see [ResolveFallback]) and colored by font, above a
+// legend labeled using the embedded label font. Characters not mapped by any
+// font are rendered with the first font. The options' text (when not empty)
+// is used as the sample text. When opts is nil, the default options will be
+// used.
+func FallbackStack(fonts []*Font, opts *Options) (_ *canvas.Canvas, err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	text := fallbackText
+	if opts.Text != "" {
+		text = opts.Text
+	}
+	// load font families
+	var families []*canvas.FontFamily
+	for _, font := range fonts {
+		ff, err := font.Load(opts.Style)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", font.BestName(), err)
+		}
+		families = append(families, ff)
+	}
+	lff, err := LabelFont().Load(canvas.FontRegular)
+	if err != nil {
+		return nil, err
+	}
+	label := lff.Face(0.4*float64(opts.Size), opts.FG)
+	// create canvas and context
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	// draw lines, with runs colored by font
+	var lineHeight float64
+	for _, ff := range families {
+		lineHeight = max(lineHeight, ff.Face(float64(opts.Size)).Metrics().LineHeight)
+	}
+	y := float64(0)
+	for line := range strings.SplitSeq(text, "\n") {
+		runs, err := ResolveFallback(fonts, line)
+		if err != nil {
+			return nil, err
+		}
+		x := float64(0)
+		for _, run := range runs {
+			ff, c := families[0], fallbackMissing
+			if run.Font != -1 {
+				ff, c = families[run.Font], fallbackColors[run.Font%len(fallbackColors)]
+			}
+			// draw trailing space separately, as right-to-left runs are
+			// reordered
+			face := ff.Face(float64(opts.Size), c, opts.Style, opts.Variant)
+			text := strings.TrimRightFunc(run.Text, unicode.IsSpace)
+			txt := canvas.NewTextLine(face, text, canvas.Left)
+			ctx.DrawText(x, y, txt)
+			x += txt.Bounds().X1 + face.TextWidth(run.Text[len(text):])
+		}
+		y -= lineHeight
+	}
+	// draw legend
+	lh := label.Metrics().LineHeight
+	y -= lh
+	for i, font := range fonts {
+		ctx.SetFillColor(fallbackColors[i%len(fallbackColors)])
+		ctx.DrawPath(0, y, canvas.Rectangle(lh/2, lh/2))
+		ctx.DrawText(lh, y, canvas.NewTextLine(label, fmt.Sprintf("%d. %s", i+1, font.BestName()), canvas.Left))
+		y -= lh
+	}
+	ctx.SetFillColor(fallbackMissing)
+	ctx.DrawPath(0, y, canvas.Rectangle(lh/2, lh/2))
+	ctx.DrawText(lh, y, canvas.NewTextLine(label, labelPrinter(opts.Language).Sprintf("not mapped"), canvas.Left))
+	// fit canvas to context
+	c.Fit(opts.Margin)
+	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
+
+// fallbackText is the default fallback stack sample text.
+const fallbackText = "The quick brown fox — Ελληνικά — Кириллица\nעברית — العربية — हिन्दी — 日本語 — 한국어"
+
+// fallbackColors are the fallback stack font colors.
+var fallbackColors = []color.Color{
+	color.NRGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff},
+	color.NRGBA{R: 0x30, G: 0x50, B: 0xd0, A: 0xff},
+	color.NRGBA{R: 0x30, G: 0x90, B: 0x30, A: 0xff},
+	color.NRGBA{R: 0xc0, G: 0x80, B: 0x00, A: 0xff},
+	color.NRGBA{R: 0x90, G: 0x30, B: 0xb0, A: 0xff},
+	color.NRGBA{R: 0x20, G: 0x90, B: 0xa0, A: 0xff},
+}
+
+// fallbackMissing is the color of characters not mapped by any font.
+var fallbackMissing color.Color = color.NRGBA{R: 0xe0, G: 0x20, B: 0x20, A: 0xff}
@@ -0,0 +1,58 @@
+package fontimg
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kenshaw/fontimg/testfonts"
+)
+
+func TestResolveFallback(t *testing.T) {
+	fonts := []*Font{
+		New(nil, filepath.Join("testdata", "NotoMono-Regular.ttf")),
+		New(nil, filepath.Join("testdata", "Ubuntu-R.ttf")),
+	}
+	tests := []struct {
+		text string
+		exp  []FallbackRun
+	}{
+		{"", nil},
+		{"abc def", []FallbackRun{{"abc def", 0}}},
+		{"abc 日本", []FallbackRun{{"abc ", 0}, {"日本", -1}}},
+		{"日本 abc", []FallbackRun{{"日本", -1}, {" abc", 0}}},
+	}
+	for _, test := range tests {
+		runs, err := ResolveFallback(fonts, test.text)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if !reflect.DeepEqual(runs, test.exp) {
+			t.Errorf("%q expected %v, got: %v", test.text, test.exp, runs)
+		}
+	}
+	runs, err := ResolveFallback(append(fonts, New(testfonts.Bytes(testfonts.CJK), testfonts.CJK)), "abc 日本")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exp := []FallbackRun{{"abc ", 0}, {"日本", 2}}; !reflect.DeepEqual(runs, exp) {
+		t.Errorf("expected %v, got: %v", exp, runs)
+	}
+	if _, err := ResolveFallback(nil, "abc"); err == nil {
+		t.Errorf("expected error for no fonts")
+	}
+}
+
+func TestRasterizeFallback(t *testing.T) {
+	fonts := []*Font{
+		New(nil, filepath.Join("testdata", "Ubuntu-R.ttf")),
+		New(nil, filepath.Join("testdata", "NotoMono-Regular.ttf")),
+	}
+	img, err := RasterizeFallback(fonts, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+		t.Errorf("expected non-empty image, got: %v", b)
+	}
+}
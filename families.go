@@ -0,0 +1,94 @@
+package fontimg
+
+import (
+	"encoding/binary"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	fontpkg "github.com/tdewolff/font"
+)
+
+// FamilyInfo is a font family.
+type FamilyInfo struct {
+	// Family is the family name.
+	Family string `json:"family"`
+	// Styles are the family's styles (ie, "Bold Italic"), ordered by weight,
+	// with the upright style before the italic style.
+	Styles []string `json:"styles"`
+	// Paths are the font file paths of the family's styles, in the same order
+	// as the styles.
+	Paths []string `json:"paths"`
+	// Monospace is whether the family's fonts are fixed pitch.
+	Monospace bool `json:"monospace"`
+}
+
+// Families returns the system font families, sorted by name, case
+// insensitively, for building font pickers. Whether a family is monospace is
+// read from the post table of its first style's font file. Font files that
+// cannot be read are treated as proportional.
+func Families(sysfonts *fontpkg.SystemFonts) []FamilyInfo {
+	families := make([]FamilyInfo, 0, len(sysfonts.Fonts))
+	for family, m := range sysfonts.Fonts {
+		styles := slices.Sorted(maps.Keys(m))
+		info := FamilyInfo{
+			Family: family,
+			Styles: make([]string, len(styles)),
+			Paths:  make([]string, len(styles)),
+		}
+		for i, style := range styles {
+			info.Styles[i], info.Paths[i] = style.String(), m[style].Filename
+		}
+		if 0 < len(info.Paths) {
+			info.Monospace = fixedPitch(info.Paths[0])
+		}
+		families = append(families, info)
+	}
+	slices.SortFunc(families, func(a, b FamilyInfo) int {
+		if n := strings.Compare(strings.ToLower(a.Family), strings.ToLower(b.Family)); n != 0 {
+			return n
+		}
+		return strings.Compare(a.Family, b.Family)
+	})
+	return families
+}
+
+// fixedPitch returns whether the SFNT font (or the first font of the font
+// collection) at path is fixed pitch, reading only the font's table
+// directory and post table.
+func fixedPitch(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	b := make([]byte, 16)
+	if _, err := f.ReadAt(b[:12], 0); err != nil {
+		return false
+	}
+	offset := int64(0)
+	if string(b[:4]) == "ttcf" {
+		offset = int64(binary.BigEndian.Uint32(b[8:]))
+		if _, err := f.ReadAt(b[:12], offset); err != nil {
+			return false
+		}
+	}
+	n := int(binary.BigEndian.Uint16(b[4:]))
+	dir := make([]byte, 16*n)
+	if _, err := f.ReadAt(dir, offset+12); err != nil {
+		return false
+	}
+	for i := range n {
+		rec := dir[16*i:]
+		if string(rec[:4]) != "post" {
+			continue
+		}
+		// isFixedPitch is at offset 12 of the post table
+		if _, err := f.ReadAt(b, int64(binary.BigEndian.Uint32(rec[8:]))); err != nil {
+			return false
+		}
+		return binary.BigEndian.Uint32(b[12:]) != 0
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package fontimg
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	fontpkg "github.com/tdewolff/font"
+)
+
+func TestFamilies(t *testing.T) {
+	sysfonts := &fontpkg.SystemFonts{
+		Fonts: make(map[string]map[fontpkg.Style]fontpkg.FontMetadata),
+	}
+	for _, md := range []fontpkg.FontMetadata{
+		{Filename: filepath.Join("testdata", "Ubuntu-R.ttf"), Family: "Ubuntu", Style: fontpkg.Bold | fontpkg.Italic},
+		{Filename: filepath.Join("testdata", "Ubuntu-R.ttf"), Family: "Ubuntu", Style: fontpkg.Regular},
+		{Filename: filepath.Join("testdata", "Ubuntu-R.ttf"), Family: "Ubuntu", Style: fontpkg.Bold},
+		{Filename: filepath.Join("testdata", "NotoMono-Regular.ttf"), Family: "noto Mono", Style: fontpkg.Regular},
+		{Filename: filepath.Join("testdata", "missing.ttf"), Family: "Missing", Style: fontpkg.Regular},
+	} {
+		sysfonts.Add(md)
+	}
+	exp := []FamilyInfo{
+		{"Missing", []string{"Regular"}, []string{filepath.Join("testdata", "missing.ttf")}, false},
+		{"noto Mono", []string{"Regular"}, []string{filepath.Join("testdata", "NotoMono-Regular.ttf")}, true},
+		{"Ubuntu", []string{"Regular", "Bold", "Bold Italic"}, []string{
+			filepath.Join("testdata", "Ubuntu-R.ttf"),
+			filepath.Join("testdata", "Ubuntu-R.ttf"),
+			filepath.Join("testdata", "Ubuntu-R.ttf"),
+		}, false},
+	}
+	if families := Families(sysfonts); !reflect.DeepEqual(families, exp) {
+		t.Errorf("expected %+v, got: %+v", exp, families)
+	}
+}
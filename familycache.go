@@ -0,0 +1,191 @@
+package fontimg
+
+import (
+	"container/list"
+	"context"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/tdewolff/canvas"
+)
+
+// DefaultFamilyCache is the cache of font families loaded by [Font.Load].
+// When nil (the default), every load parses the font. See [FamilyCache].
+var DefaultFamilyCache *FamilyCache
+
+// FamilyCache is a least recently used cache of parsed font families, keyed
+// by the font's content (the modification time and size of the file at the
+// font's path, or the hash of the font's buffer), so that repeatedly
+// rendering the same font, including with different [Font] instances, reuses
+// the parsed font family instead of parsing the font again.
+//
+// A cache is safe for concurrent use. As a font family is not safe for
+// concurrent use, a cached font family is used by one load at a time:
+// rendering a font image returns its font family to the cache once rendered,
+// and [Font.Load] takes a font family out of the cache for the caller's
+// exclusive use. Concurrent renders of the same font parse the font until
+// enough font families are cached. The data of a font loaded from its buffer
+// must not be modified while its font families are cached. Memory mapped
+// fonts (see [Mmap]) are not cached.
+type FamilyCache struct {
+	mu   sync.Mutex
+	size int
+	l    *list.List
+	m    map[familyKey]*list.Element
+}
+
+// NewFamilyCache creates a font family cache holding the font families of at
+// most size fonts and styles. When size is 0 or less, the font families of a
+// single font and style are held.
+func NewFamilyCache(size int) *FamilyCache {
+	return &FamilyCache{
+		size: max(size, 1),
+		l:    list.New(),
+		m:    make(map[familyKey]*list.Element),
+	}
+}
+
+// Len returns the number of cached font families.
+func (c *FamilyCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for e := c.l.Front(); e != nil; e = e.Next() {
+		n += len(e.Value.(*familyEntry).ffs)
+	}
+	return n
+}
+
+// Purge removes all the cached font families.
+func (c *FamilyCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.l.Init()
+	clear(c.m)
+}
+
+// get takes a cached font family for the key out of the cache, marking the
+// key as most recently used.
+func (c *FamilyCache) get(key familyKey) (*canvas.FontFamily, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.m[key]
+	if !ok {
+		return nil, false
+	}
+	c.l.MoveToFront(e)
+	entry := e.Value.(*familyEntry)
+	n := len(entry.ffs)
+	if n == 0 {
+		return nil, false
+	}
+	ff := entry.ffs[n-1]
+	entry.ffs[n-1], entry.ffs = nil, entry.ffs[:n-1]
+	return ff, true
+}
+
+// put returns the font family for the key to the cache, evicting the least
+// recently used keys when the cache is full. At most [runtime.GOMAXPROCS]
+// font families are held per key.
+func (c *FamilyCache) put(key familyKey, ff *canvas.FontFamily) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.m[key]; ok {
+		c.l.MoveToFront(e)
+		if entry := e.Value.(*familyEntry); len(entry.ffs) < runtime.GOMAXPROCS(0) {
+			entry.ffs = append(entry.ffs, ff)
+		}
+		return
+	}
+	c.m[key] = c.l.PushFront(&familyEntry{key: key, ffs: []*canvas.FontFamily{ff}})
+	for c.size < c.l.Len() {
+		e := c.l.Back()
+		delete(c.m, e.Value.(*familyEntry).key)
+		c.l.Remove(e)
+	}
+}
+
+// familyEntry is the cached font families of a key.
+type familyEntry struct {
+	key familyKey
+	ffs []*canvas.FontFamily
+}
+
+// familyKey is the key of a cached font family.
+type familyKey struct {
+	path    string
+	modTime int64
+	size    int64
+	hash    string
+	family  string
+	style   canvas.FontStyle
+	lenient bool
+}
+
+// familyKey returns the cache key of the font's family loaded with the
+// style. Returns false when the font cannot be cached.
+func (font *Font) familyKey(style canvas.FontStyle) (familyKey, bool) {
+	key := familyKey{
+		family:  font.Family,
+		style:   style,
+		lenient: font.Lenient,
+	}
+	switch {
+	case font.unmap != nil:
+		return familyKey{}, false
+	case font.Buf != nil:
+		ref, err := font.Ref()
+		if err != nil {
+			return familyKey{}, false
+		}
+		key.hash = ref.Hash
+	case font.Path != "":
+		fi, err := os.Stat(font.Path)
+		if err != nil || !fi.Mode().IsRegular() {
+			return familyKey{}, false
+		}
+		key.path, key.modTime, key.size = font.Path, fi.ModTime().UnixNano(), fi.Size()
+	default:
+		return familyKey{}, false
+	}
+	return key, true
+}
+
+// family returns the font's family loaded with the style, taken from the
+// [DefaultFamilyCache] when set, and a func returning the font family to the
+// cache once it is no longer used.
+func (font *Font) family(ctx context.Context, style canvas.FontStyle) (*canvas.FontFamily, func(), error) {
+	c := DefaultFamilyCache
+	if c == nil {
+		ff, err := font.newFamily(ctx, style)
+		return ff, func() {}, err
+	}
+	key, ok := font.familyKey(style)
+	if !ok {
+		ff, err := font.newFamily(ctx, style)
+		return ff, func() {}, err
+	}
+	ff, ok := c.get(key)
+	if !ok {
+		var err error
+		if ff, err = font.newFamily(ctx, style); err != nil {
+			return nil, nil, err
+		}
+	}
+	return ff, func() {
+		// reset the features and variations set by the render
+		ff.SetFeatures("")
+		ff.SetVariations("")
+		c.put(key, ff)
+	}, nil
+}
+
+// newFamily loads the font's family with the style.
+func (font *Font) newFamily(ctx context.Context, style canvas.FontStyle) (*canvas.FontFamily, error) {
+	ff := canvas.NewFontFamily(font.Family)
+	if err := font.load(ctx, ff, style); err != nil {
+		return nil, err
+	}
+	return ff, nil
+}
@@ -0,0 +1,151 @@
+package fontimg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tdewolff/canvas"
+)
+
+func TestFamilyCache(t *testing.T) {
+	defer func(c *FamilyCache) { DefaultFamilyCache = c }(DefaultFamilyCache)
+	DefaultFamilyCache = NewFamilyCache(2)
+	path := filepath.Join(t.TempDir(), "Ubuntu-R.ttf")
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := os.WriteFile(path, buf, 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	load := func(font *Font, style canvas.FontStyle) *canvas.FontFamily {
+		t.Helper()
+		ff, err := font.Load(style)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		return ff
+	}
+	// family loads the font family as when rendering, returning it to the
+	// cache
+	family := func(font *Font, style canvas.FontStyle) *canvas.FontFamily {
+		t.Helper()
+		ff, release, err := font.loadContext(context.Background(), style)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		ff.SetFeatures("-kern")
+		release()
+		return ff
+	}
+	a := family(New(nil, path), canvas.FontRegular)
+	if b := load(New(nil, path), canvas.FontRegular); b != a {
+		t.Errorf("expected load to reuse the cached font family without parsing")
+	}
+	if b := load(New(nil, path), canvas.FontRegular); b == a {
+		t.Errorf("expected loaded font family to be taken out of the cache")
+	}
+	if n := DefaultFamilyCache.Len(); n != 0 {
+		t.Errorf("expected no cached font families, got: %d", n)
+	}
+	a = family(New(nil, path), canvas.FontRegular)
+	if b := family(New(nil, path), canvas.FontRegular); b != a {
+		t.Errorf("expected cached font family for same path")
+	}
+	if b := family(New(nil, path), canvas.FontBold); b == a {
+		t.Errorf("expected different font family for different style")
+	}
+	if n := DefaultFamilyCache.Len(); n != 2 {
+		t.Errorf("expected 2 cached font families, got: %d", n)
+	}
+	// buffer, evicting the regular style
+	c := family(New(buf, "Ubuntu-R.ttf"), canvas.FontRegular)
+	if d := family(New(buf, "Ubuntu-R.ttf"), canvas.FontRegular); d != c {
+		t.Errorf("expected cached font family for same buffer")
+	}
+	if b := family(New(nil, path), canvas.FontRegular); b == a {
+		t.Errorf("expected evicted font family")
+	}
+	// modified file
+	a = family(New(nil, path), canvas.FontRegular)
+	mtime := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if b := family(New(nil, path), canvas.FontRegular); b == a {
+		t.Errorf("expected new font family for modified file")
+	}
+	DefaultFamilyCache.Purge()
+	if n := DefaultFamilyCache.Len(); n != 0 {
+		t.Errorf("expected no cached font families, got: %d", n)
+	}
+	// rendering with a cached font family matches parsing the font, and is
+	// not changed by the features of previous renders
+	tpl, err := NewTemplate("AV Ta")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	kern, err := NewTemplate(`{{ features "-kern" }}AV Ta`)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	opts := DefaultOptions()
+	opts.Template = tpl
+	exp, err := New(nil, path).RasterizeOptions(opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	o := *opts
+	o.Template = kern
+	if _, err := New(nil, path).RasterizeOptions(&o); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := DefaultFamilyCache.Len(); n != 1 {
+		t.Errorf("expected 1 cached font family, got: %d", n)
+	}
+	for range 2 {
+		img, err := New(nil, path).RasterizeOptions(opts)
+		switch {
+		case err != nil:
+			t.Fatalf("expected no error, got: %v", err)
+		case img.Bounds() != exp.Bounds() || string(img.Pix) != string(exp.Pix):
+			t.Errorf("expected identical image, got: %v", img.Bounds())
+		}
+	}
+}
+
+func TestFamilyCacheConcurrent(t *testing.T) {
+	defer func(c *FamilyCache) { DefaultFamilyCache = c }(DefaultFamilyCache)
+	DefaultFamilyCache = NewFamilyCache(4)
+	path := filepath.Join("testdata", "Ubuntu-R.ttf")
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp, err := New(nil, path).RasterizeOptions(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var fonts []*Font
+	for range 8 {
+		fonts = append(fonts, New(nil, path), New(buf, "Ubuntu-R.ttf"))
+	}
+	opts := DefaultOptions()
+	opts.Size = 24
+	for _, o := range []*Options{nil, opts, nil} {
+		for i, res := range RasterizeAll(context.Background(), fonts, o, 8) {
+			switch {
+			case res.Err != nil:
+				t.Fatalf("%d expected no error, got: %v", i, res.Err)
+			case o == nil && (res.Image.Bounds() != exp.Bounds() || string(res.Image.Pix) != string(exp.Pix)):
+				t.Errorf("%d expected identical image, got: %v", i, res.Image.Bounds())
+			}
+		}
+	}
+	if n := DefaultFamilyCache.Len(); n != 2 {
+		t.Errorf("expected 2 cached fonts, got: %d", n)
+	}
+}
@@ -0,0 +1,50 @@
+package fontimg
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SafeFilename converts the name (ie, a font family or style name) into a
+// file name that is safe on all common file systems. The name is normalized
+// to Unicode NFC, path separators and characters reserved on Windows are
+// replaced with '-', leading and trailing dots and spaces are removed,
+// reserved Windows device names (ie, CON, NUL, COM1) are prefixed with '_',
+// and the name is truncated to 255 bytes. An empty name is returned as "_".
+func SafeFilename(name string) string {
+	s := strings.Map(func(r rune) rune {
+		switch {
+		case r == utf8.RuneError, unicode.IsControl(r), strings.ContainsRune(`/\:*?"<>|`, r):
+			return '-'
+		}
+		return r
+	}, norm.NFC.String(name))
+	s = strings.Trim(s, ". ")
+	base, _, _ := strings.Cut(s, ".")
+	if reservedNames[strings.ToUpper(strings.TrimRight(base, " "))] {
+		s = "_" + s
+	}
+	if len(s) > 255 {
+		i := 255
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		s = strings.TrimRight(s[:i], ". ")
+	}
+	if s == "" {
+		return "_"
+	}
+	return s
+}
+
+// reservedNames are the reserved Windows device names.
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
@@ -0,0 +1,35 @@
+package fontimg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSafeFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		exp  string
+	}{
+		{"Ubuntu", "Ubuntu"},
+		{"Noto Sans CJK JP", "Noto Sans CJK JP"},
+		{"AC/DC: Bold", "AC-DC- Bold"},
+		{`a\b*c?"<>|`, "a-b-c-----"},
+		{"..hidden. ", "hidden"},
+		{"tab\tname", "tab-name"},
+		{"bad\xffutf8", "bad-utf8"},
+		{"Café", "Café"},
+		{"CON", "_CON"},
+		{"nul.png", "_nul.png"},
+		{"Com1 .txt", "_Com1 .txt"},
+		{"CONSOLE", "CONSOLE"},
+		{"", "_"},
+		{"...", "_"},
+		{strings.Repeat("a", 300), strings.Repeat("a", 255)},
+		{strings.Repeat("a", 254) + "é", strings.Repeat("a", 254)},
+	}
+	for _, test := range tests {
+		if s := SafeFilename(test.name); s != test.exp {
+			t.Errorf("%q: expected %q, got: %q", test.name, test.exp, s)
+		}
+	}
+}
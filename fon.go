@@ -0,0 +1,236 @@
+package fontimg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"slices"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ParseFON parses a Windows .fon bitmap font, a NE executable containing one
+// or more .fnt resources, each a strike. Vector fonts are not supported.
+// Returns [ErrResourceLimit] when the decoded glyphs exceed the
+// [ParseBudget].
+func ParseFON(b []byte) (*BitmapFont, error) {
+	bud := newBudget()
+	if err := bud.alloc(int64(len(b))); err != nil {
+		return nil, err
+	}
+	if len(b) < 64 || string(b[:2]) != "MZ" {
+		return nil, fmt.Errorf("FON: bad MZ header")
+	}
+	ne := int(binary.LittleEndian.Uint32(b[0x3c:]))
+	if ne < 0 || len(b) < ne+0x26 || string(b[ne:ne+2]) != "NE" {
+		return nil, fmt.Errorf("FON: bad NE header")
+	}
+	r := &leReader{b: b, i: ne + int(binary.LittleEndian.Uint16(b[ne+0x24:]))}
+	shift := r.u16()
+	bf := new(BitmapFont)
+	for r.err == nil {
+		typ := r.u16()
+		if typ == 0 {
+			break
+		}
+		count := int(r.u16())
+		r.u32() // reserved
+		for range count {
+			off, n := int(r.u16())<<shift, int(r.u16())<<shift
+			r.bytes(8) // flags, id, reserved
+			if typ != fonFontResource || r.err != nil {
+				continue
+			}
+			if len(b) <= off {
+				return nil, fmt.Errorf("FON: resource offset %d out of bounds", off)
+			}
+			// lengths are rounded up to the alignment
+			fnt, err := parseFNT(b[off:min(off+n, len(b))], bud)
+			switch {
+			case err == errVectorFont:
+				continue
+			case err != nil:
+				return nil, err
+			}
+			bf.Family, bf.Style = fnt.Family, fnt.Style
+			bf.Strikes = append(bf.Strikes, fnt.Strikes...)
+		}
+	}
+	switch {
+	case r.err != nil:
+		return nil, fmt.Errorf("FON: %v", r.err)
+	case len(bf.Strikes) == 0:
+		return nil, fmt.Errorf("FON: no bitmap fonts")
+	}
+	slices.SortStableFunc(bf.Strikes, func(a, b *Strike) int {
+		return a.Height - b.Height
+	})
+	return bf, nil
+}
+
+// fonDecoder is the Windows .fon bitmap font decoder.
+type fonDecoder struct{}
+
+// Match satisfies the [Decoder] interface.
+func (fonDecoder) Match(header []byte) bool {
+	return 2 <= len(header) && string(header[:2]) == "MZ"
+}
+
+// Decode satisfies the [Decoder] interface.
+func (fonDecoder) Decode(buf []byte) (*BitmapFont, error) {
+	return ParseFON(buf)
+}
+
+// fonFontResource is the NE resource type of .fnt fonts.
+const fonFontResource = 0x8008
+
+// errVectorFont is the vector font error.
+var errVectorFont = fmt.Errorf("FNT: vector fonts not supported")
+
+// fntDecoder is the Windows .fnt bitmap font decoder.
+type fntDecoder struct{}
+
+// Match satisfies the [Decoder] interface.
+func (fntDecoder) Match(header []byte) bool {
+	if len(header) < 6 {
+		return false
+	}
+	version, size := binary.LittleEndian.Uint16(header), binary.LittleEndian.Uint32(header[2:])
+	return (version == 0x0200 || version == 0x0300) && 118 <= size
+}
+
+// Decode satisfies the [Decoder] interface.
+func (fntDecoder) Decode(buf []byte) (*BitmapFont, error) {
+	return ParseFNT(buf)
+}
+
+// ParseFNT parses a Windows .fnt (version 2 or 3) bitmap font. Vector fonts
+// are not supported. Returns [ErrResourceLimit] when the decoded glyphs
+// exceed the [ParseBudget].
+func ParseFNT(b []byte) (*BitmapFont, error) {
+	bud := newBudget()
+	if err := bud.alloc(int64(len(b))); err != nil {
+		return nil, err
+	}
+	return parseFNT(b, bud)
+}
+
+// parseFNT parses a Windows .fnt bitmap font, charging the decoded glyphs to
+// the budget.
+func parseFNT(b []byte, bud *budget) (*BitmapFont, error) {
+	r := &leReader{b: b}
+	version := r.u16()
+	if version != 0x0200 && version != 0x0300 {
+		return nil, fmt.Errorf("FNT: unsupported version %#04x", version)
+	}
+	r.i = 66
+	if r.u16()&1 != 0 {
+		return nil, errVectorFont
+	}
+	r.i = 74
+	ascent := int(r.u16())
+	r.i = 80
+	italic := r.bytes(1)[0] != 0
+	r.i = 83
+	weight := int(r.u16())
+	charset := r.bytes(1)[0]
+	r.i = 88
+	height := int(r.u16())
+	r.i = 95
+	chars := r.bytes(3)
+	first, last, def := int(chars[0]), int(chars[1]), int(chars[0])+int(chars[2])
+	r.i = 105
+	face := int(r.u32())
+	if r.err != nil {
+		return nil, fmt.Errorf("FNT: %v", r.err)
+	}
+	// read char table
+	r.i = 118
+	if version == 0x0300 {
+		r.i = 148
+	}
+	decode := fntCharset(charset)
+	s := &Strike{
+		Height: height,
+		Ascent: ascent,
+		Glyphs: make(map[rune]*image.Alpha),
+	}
+	for c := first; c <= last; c++ {
+		width, off := int(r.u16()), 0
+		if version == 0x0200 {
+			off = int(r.u16())
+		} else {
+			off = int(r.u32())
+		}
+		if r.err != nil {
+			return nil, fmt.Errorf("FNT: %v", r.err)
+		}
+		// glyphs are stored in columns of 8 pixels, top to bottom
+		cols := (width + 7) / 8
+		if len(b) < off+cols*height {
+			return nil, fmt.Errorf("FNT: glyph %d offset %d out of bounds", c, off)
+		}
+		if err := bud.alloc(int64(width * height)); err != nil {
+			return nil, err
+		}
+		img := image.NewAlpha(image.Rect(0, 0, width, height))
+		for x := range width {
+			for y := range height {
+				if b[off+(x/8)*height+y]&(0x80>>(x%8)) != 0 {
+					img.Pix[y*img.Stride+x] = 0xff
+				}
+			}
+		}
+		s.Glyphs[decode(byte(c))] = img
+		if c == def {
+			s.Default = decode(byte(c))
+		}
+	}
+	bf := &BitmapFont{
+		Style:   fntStyle(weight, italic),
+		Strikes: []*Strike{s},
+	}
+	if 0 < face && face < len(b) {
+		name, _, _ := bytes.Cut(b[face:], []byte{0})
+		bf.Family = string(name)
+	}
+	return bf, nil
+}
+
+// fntStyle returns the style name for the weight and italic.
+func fntStyle(weight int, italic bool) string {
+	switch {
+	case 600 <= weight && italic:
+		return "Bold Italic"
+	case 600 <= weight:
+		return "Bold"
+	case italic:
+		return "Italic"
+	}
+	return "Regular"
+}
+
+// fntCharsets are the single byte Windows charsets.
+var fntCharsets = map[byte]*charmap.Charmap{
+	0:   charmap.Windows1252,
+	161: charmap.Windows1253,
+	162: charmap.Windows1254,
+	177: charmap.Windows1255,
+	178: charmap.Windows1256,
+	186: charmap.Windows1257,
+	204: charmap.Windows1251,
+	238: charmap.Windows1250,
+	255: charmap.CodePage437,
+}
+
+// fntCharset returns a func decoding bytes in the Windows charset to runes.
+// Unknown charsets (including the symbol charset) are decoded as Latin-1.
+func fntCharset(charset byte) func(byte) rune {
+	if m, ok := fntCharsets[charset]; ok {
+		return m.DecodeByte
+	}
+	return func(c byte) rune {
+		return rune(c)
+	}
+}
@@ -0,0 +1,142 @@
+package fontimg
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestParseFNT(t *testing.T) {
+	for _, version := range []uint16{0x0200, 0x0300} {
+		bf, err := ParseFNT(testFNT(version, 8, 700, false))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if bf.Family != "Test" || bf.Style != "Bold" {
+			t.Errorf("expected Test Bold, got: %q %q", bf.Family, bf.Style)
+		}
+		if len(bf.Strikes) != 1 {
+			t.Fatalf("expected 1 strike, got: %d", len(bf.Strikes))
+		}
+		s := bf.Strikes[0]
+		if s.Height != 8 || s.Ascent != 7 || len(s.Glyphs) != 96 || s.Default != '?' {
+			t.Errorf("expected 8/7/96/?, got: %d/%d/%d/%q", s.Height, s.Ascent, len(s.Glyphs), s.Default)
+		}
+		// glyphs are width 10 (spanning 2 byte columns), with the rune on
+		// each row and the rightmost pixel set
+		img := s.Glyphs['A']
+		if img == nil || img.Rect.Dx() != 10 {
+			t.Fatalf("expected glyph A with width 10, got: %v", img)
+		}
+		for y := range 8 {
+			for x := range 10 {
+				exp := x == 9 || (x < 8 && 'A'&(0x80>>x) != 0)
+				if set := img.AlphaAt(x, y).A != 0; set != exp {
+					t.Errorf("%#04x pixel %d,%d expected %t, got: %t", version, x, y, exp, set)
+				}
+			}
+		}
+		if s.glyph('é') != s.Glyphs['?'] {
+			t.Errorf("expected default glyph for missing rune")
+		}
+	}
+	b := testFNT(0x0200, 8, 400, false)
+	b[66] |= 1
+	if _, err := ParseFNT(b); err != errVectorFont {
+		t.Errorf("expected vector font error, got: %v", err)
+	}
+	if _, err := ParseFNT(testFNT(0x0200, 8, 400, false)[:200]); err == nil {
+		t.Errorf("expected error for truncated font")
+	}
+}
+
+func TestParseFON(t *testing.T) {
+	bf, err := ParseFON(testFON())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if bf.Family != "Test" || bf.Style != "Italic" {
+		t.Errorf("expected Test Italic, got: %q %q", bf.Family, bf.Style)
+	}
+	if len(bf.Strikes) != 2 || bf.Strikes[0].Height != 8 || bf.Strikes[1].Height != 16 {
+		t.Fatalf("expected strikes 8 and 16, got: %v", bf.Strikes)
+	}
+	tests := []struct {
+		height, exp, scale int
+	}{
+		{4, 8, 1},
+		{8, 8, 1},
+		{15, 16, 1},
+		{24, 8, 3},
+		{32, 16, 2},
+	}
+	for _, test := range tests {
+		s, scale := bf.strike(test.height)
+		if s.Height != test.exp || scale != test.scale {
+			t.Errorf("height %d expected %d×%d, got: %d×%d", test.height, test.exp, test.scale, s.Height, scale)
+		}
+	}
+	if _, err := ParseFON([]byte("MZ")); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+// testFNT builds a FNT font with glyphs for the printable ASCII runes, with
+// each glyph row set to the rune and the rightmost pixel set.
+func testFNT(version uint16, height, weight int, italic bool) []byte {
+	const first, last, width = 0x20, 0x7f, 10
+	hdr, entry := 118, 4
+	if version == 0x0300 {
+		hdr, entry = 148, 6
+	}
+	bits := hdr + entry*(last-first+2)
+	b := make([]byte, bits+2*height*(last-first+1))
+	binary.LittleEndian.PutUint16(b, version)
+	binary.LittleEndian.PutUint16(b[74:], uint16(height-1)) // ascent
+	if italic {
+		b[80] = 1
+	}
+	binary.LittleEndian.PutUint16(b[83:], uint16(weight))
+	binary.LittleEndian.PutUint16(b[88:], uint16(height))
+	b[95], b[96], b[97] = first, last, '?'-first
+	for c := first; c <= last; c++ {
+		i, off := hdr+entry*(c-first), bits+2*height*(c-first)
+		binary.LittleEndian.PutUint16(b[i:], width)
+		if version == 0x0200 {
+			binary.LittleEndian.PutUint16(b[i+2:], uint16(off))
+		} else {
+			binary.LittleEndian.PutUint32(b[i+2:], uint32(off))
+		}
+		for y := range height {
+			b[off+y], b[off+height+y] = byte(c), 0x40
+		}
+	}
+	binary.LittleEndian.PutUint32(b[105:], uint32(len(b)))
+	b = append(b, "Test\x00"...)
+	binary.LittleEndian.PutUint32(b[2:], uint32(len(b)))
+	return b
+}
+
+// testFON builds a FON font with italic 8 and 16 pixel FNT strikes.
+func testFON() []byte {
+	const shift = 4
+	b := make([]byte, 160)
+	copy(b, "MZ")
+	binary.LittleEndian.PutUint32(b[0x3c:], 64)
+	copy(b[64:], "NE")
+	binary.LittleEndian.PutUint16(b[64+0x24:], 64)
+	// resource table
+	binary.LittleEndian.PutUint16(b[128:], shift)
+	binary.LittleEndian.PutUint16(b[130:], fonFontResource)
+	binary.LittleEndian.PutUint16(b[132:], 2)
+	for i, height := range []int{16, 8} {
+		fnt := testFNT(0x0200, height, 400, true)
+		for len(b)%(1<<shift) != 0 {
+			b = append(b, 0)
+		}
+		rec := 138 + 12*i
+		binary.LittleEndian.PutUint16(b[rec:], uint16(len(b)>>shift))
+		binary.LittleEndian.PutUint16(b[rec+2:], uint16((len(fnt)+1<<shift-1)>>shift))
+		b = append(b, fnt...)
+	}
+	return b
+}
@@ -3,15 +3,21 @@ package fontimg
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
+	"encoding/hex"
 	"fmt"
 	"image"
 	"image/color"
+	"image/draw"
 	"io"
+	"io/fs"
+	"iter"
+	"math"
 	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,43 +31,229 @@ import (
 
 // Open opens fonts as either a path on disk or from the system fonts. When
 // sysfonts is nil, the default system fonts will be loaded.
+//
+// When name is "-", the font is read from standard input. When name is a
+// http or https URL, the font is retrieved from the URL. When name is a zip
+// archive, the fonts in the archive are opened.
+//
+// When name is a directory, files that cannot be read or are not recognized
+// as fonts do not prevent the remaining fonts from being opened: the
+// successfully opened fonts are returned along with an [Errors] for the
+// failed files. Files with unrecognized extensions are opened only when in a
+// registered decoder's format (see [RegisterDecoder]). The fonts are sorted
+// using [SortFonts].
+//
+// See [NewSource] and [OpenSource] for the sources used, [Scan] for
+// processing large directories without opening all fonts up front, and
+// [OpenFS] for opening fonts from a [fs.FS].
 func Open(name string, style canvas.FontStyle, sysfonts *fontpkg.SystemFonts) ([]*Font, error) {
-	if sysfonts == nil {
-		var err error
-		once.Do(func() {
-			sfonts, err = fontpkg.FindSystemFonts(fontpkg.DefaultFontDirs())
-		})
-		if err != nil {
-			return nil, err
+	return OpenContext(context.Background(), name, style, sysfonts)
+}
+
+// OpenContext opens fonts as with [Open], returning the context's error when
+// it is done while scanning a directory or opening and loading the fonts.
+func OpenContext(ctx context.Context, name string, style canvas.FontStyle, sysfonts *fontpkg.SystemFonts) ([]*Font, error) {
+	fonts, err := OpenSource(ctx, NewSource(name, style, sysfonts))
+	if len(fonts) == 0 && err == nil {
+		return nil, fmt.Errorf("unable to locate font %q", name)
+	}
+	return fonts, err
+}
+
+// OpenFS opens fonts from the file system (ie, an embed.FS, a zip archive, or
+// test fixtures) instead of the disk, where name is the slash-separated path
+// of a font file or a directory of font files in the file system (see
+// [FSSource]). Directories are opened as with [Open]: files that cannot be
+// read or are not recognized as fonts are returned as an [Errors], and the
+// fonts are sorted using [SortFonts].
+func OpenFS(fsys fs.FS, name string) ([]*Font, error) {
+	if !fs.ValidPath(name) {
+		return nil, fmt.Errorf("invalid path %q", name)
+	}
+	fonts, err := OpenSource(context.Background(), FSSource{FS: fsys, Path: name})
+	if len(fonts) == 0 && err == nil {
+		return nil, fmt.Errorf("unable to locate font %q", name)
+	}
+	return fonts, err
+}
+
+// Scan returns an iterator over the fonts opened as either a path on disk or
+// from the system fonts, as with [Open], except that the fonts are opened
+// one at a time, in the source's order, allowing large directories to be
+// processed without holding all fonts in memory, and stopped early. Fonts
+// that cannot be opened are yielded as a [*FontError], and iteration
+// continues. Any other error ends the iteration. When sysfonts is nil, the
+// default system fonts will be loaded when needed.
+func Scan(name string, style canvas.FontStyle, sysfonts *fontpkg.SystemFonts) iter.Seq2[*Font, error] {
+	return func(yield func(*Font, error) bool) {
+		var found bool
+		for font, err := range ScanSource(context.Background(), NewSource(name, style, sysfonts)) {
+			found = true
+			if !yield(font, err) {
+				return
+			}
+		}
+		if !found {
+			yield(nil, fmt.Errorf("unable to locate font %q", name))
 		}
-		sysfonts = sfonts
 	}
-	var v []*Font
-	switch fi, err := os.Stat(name); {
-	case err == nil && fi.IsDir():
-		entries, err := os.ReadDir(name)
+}
+
+// ScanSource returns an iterator over the fonts of the source, opening the
+// fonts one at a time. Fonts that cannot be opened are yielded as a
+// [*FontError], and iteration continues. Any other error, including the
+// context being done, ends the iteration.
+func ScanSource(ctx context.Context, src Source) iter.Seq2[*Font, error] {
+	return func(yield func(*Font, error) bool) {
+		refs, err := src.List(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("unable to open directory %q: %v", name, err)
+			yield(nil, err)
+			return
 		}
-		for _, entry := range entries {
-			if s := entry.Name(); !entry.IsDir() && extRE.MatchString(s) {
-				v = append(v, New(nil, filepath.Join(name, s)))
+		for _, ref := range refs {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			font, err := src.Open(ctx, ref)
+			if err != nil {
+				if !yield(nil, fontError(ref, err)) {
+					return
+				}
+				continue
+			}
+			if !yield(font, nil) {
+				return
 			}
 		}
-		sort.Slice(v, func(i, j int) bool {
-			return strings.ToLower(v[i].Family) < strings.ToLower(v[j].Family)
-		})
-	case err == nil:
-		v = append(v, New(nil, name))
+	}
+}
+
+// newReader reads a font from r, checking that it has a recognized font
+// header, and setting the family from the font's name table.
+func newReader(ctx context.Context, r io.Reader) (*Font, error) {
+	buf, err := readAll(r, newBudget())
+	if err != nil {
+		return nil, err
+	}
+	font := &Font{Buf: buf}
+	switch _, err := fontpkg.MediaType(buf); {
+	case decoder(buf) != nil:
+		if _, err := font.Bitmap(); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
 	default:
-		if font := Match(name, style, sysfonts); font != nil {
-			v = append(v, font)
+		if _, err := font.LoadContext(ctx, canvas.FontRegular); err != nil {
+			return nil, err
 		}
 	}
-	if len(v) == 0 {
-		return nil, fmt.Errorf("unable to locate font %q", name)
+	font.Family = font.Name
+	return font, nil
+}
+
+// sniff checks that the named file has a recognized font header.
+func sniff(name string) error {
+	buf, err := readHeader(name)
+	if err != nil {
+		return err
+	}
+	_, err = Sniff(buf)
+	return err
+}
+
+// readHeader reads the first bytes of the named file.
+func readHeader(name string) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return header(f)
+}
+
+// readHeaderFS reads the first bytes of the named file in the file system.
+func readHeaderFS(fsys fs.FS, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return header(f)
+}
+
+// header reads the first bytes of r.
+func header(r io.Reader) ([]byte, error) {
+	buf := make([]byte, 64)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// SystemFonts returns the default system fonts, loading them on first use.
+func SystemFonts() (*fontpkg.SystemFonts, error) {
+	once.Do(func() {
+		sfonts, sfontsErr = fontpkg.FindSystemFonts(fontpkg.DefaultFontDirs())
+	})
+	return sfonts, sfontsErr
+}
+
+// ParseStyle parses a font style name (ie, "Bold", "SemiBold Italic").
+func ParseStyle(name string) (canvas.FontStyle, error) {
+	s := fontpkg.ParseStyle(name)
+	if s == fontpkg.UnknownStyle {
+		s = fontpkg.ParseStyle(upperWords(name))
+	}
+	if s == fontpkg.UnknownStyle {
+		return 0, fmt.Errorf("invalid font style %q", name)
+	}
+	var style canvas.FontStyle
+	switch s.Weight() {
+	case fontpkg.Thin:
+		style = canvas.FontThin
+	case fontpkg.ExtraLight:
+		style = canvas.FontExtraLight
+	case fontpkg.Light:
+		style = canvas.FontLight
+	case fontpkg.Medium:
+		style = canvas.FontMedium
+	case fontpkg.SemiBold:
+		style = canvas.FontSemiBold
+	case fontpkg.Bold:
+		style = canvas.FontBold
+	case fontpkg.ExtraBold:
+		style = canvas.FontExtraBold
+	case fontpkg.Black:
+		style = canvas.FontBlack
+	}
+	if s.Italic() {
+		style |= canvas.FontItalic
+	}
+	return style, nil
+}
+
+// ParseColor parses a hex color (ie, 000, fff8, ffffff, #ff000080).
+func ParseColor(name string) (color.Color, error) {
+	s := strings.TrimPrefix(name, "#")
+	if len(s) == 3 || len(s) == 4 {
+		var b strings.Builder
+		for _, c := range s {
+			b.WriteRune(c)
+			b.WriteRune(c)
+		}
+		s = b.String()
+	}
+	if len(s) == 6 {
+		s += "ff"
 	}
-	return v, nil
+	buf, err := hex.DecodeString(s)
+	if err != nil || len(buf) != 4 {
+		return nil, fmt.Errorf("invalid color %q", name)
+	}
+	return color.NRGBA{R: buf[0], G: buf[1], B: buf[2], A: buf[3]}, nil
 }
 
 // Match creates a font image for a matching font name from the system fonts.
@@ -74,7 +266,18 @@ func Match(name string, style canvas.FontStyle, sysfonts *fontpkg.SystemFonts) *
 }
 
 // Font is a font image.
+//
+// A font is safe for concurrent use once its fields are set: each render
+// loads its own font family, and the metadata fields (Name, Style,
+// SampleText and Version) are only set by the first load, which is
+// synchronized. The fields must not be modified, and the metadata fields
+// must not be read, concurrently with rendering before the first call to
+// [Font.Load] returns. Use [Font.Clone] to obtain a copy that can be
+// modified.
 type Font struct {
+	// Buf is the font data. Buf is used without copying (fonts loaded from
+	// it reference it directly), and must not be modified while the font, or
+	// anything loaded from it, is in use. See [Mmap].
 	Buf        []byte
 	Path       string
 	Family     string
@@ -82,7 +285,16 @@ type Font struct {
 	Style      string
 	SampleText string
 	Version    string
-	once       sync.Once
+	// Lenient enables working around common defects when loading the font
+	// (see [Font.Repairs]).
+	Lenient bool
+	once    sync.Once
+	repair  repair
+	unmap   func() error
+	ref     Ref
+	// hash is the memoized hash of the font's buffer.
+	hash     string
+	hashOnce sync.Once
 }
 
 // NewFont creates a new font image.
@@ -107,6 +319,47 @@ func New(buf []byte, path string) *Font {
 	}
 }
 
+// NewReader creates a font image from the font data read from r, checking
+// that the data has a recognized font header (see [Sniff]). The font's family
+// is set from the font's name table, instead of from a file name as with
+// [New]. The font data is subject to the [ParseBudget].
+func NewReader(r io.Reader) (*Font, error) {
+	return newReader(context.Background(), r)
+}
+
+// Clone returns a copy of the font, sharing the font's data, with its own
+// load state. Clones of a memory mapped font (see [Mmap]) share the mapping,
+// and must not be used after the font is closed.
+func (font *Font) Clone() *Font {
+	return &Font{
+		Buf:        font.Buf,
+		Path:       font.Path,
+		Family:     font.Family,
+		Name:       font.Name,
+		Style:      font.Style,
+		SampleText: font.SampleText,
+		Version:    font.Version,
+		Lenient:    font.Lenient,
+		ref:        font.ref,
+	}
+}
+
+// Close releases the resources held by the font: the memory mapping of a
+// font created with [Mmap], and the repaired data of a lenient font. The
+// font remains usable after being closed, with a memory mapped font reading
+// its data from its path, however font families previously loaded from a
+// memory mapped font must no longer be used. Close must not be called
+// concurrently with other uses of the font.
+func (font *Font) Close() error {
+	font.repair = repair{}
+	if font.unmap == nil {
+		return nil
+	}
+	unmap := font.unmap
+	font.Buf, font.unmap = nil, nil
+	return unmap()
+}
+
 // BestName returns the best name for a font.
 func (font *Font) BestName() string {
 	if font.Name != "" {
@@ -130,22 +383,76 @@ func (font *Font) WriteYAML(w io.Writer) {
 	fmt.Fprintf(w, "path: %s\n", font.Path)
 	fmt.Fprintf(w, "family: %q\n", font.BestName())
 	fmt.Fprintf(w, "style: %q\n", font.Style)
+	if vm, err := font.VerticalMetrics(); err == nil && len(vm.Issues) != 0 {
+		fmt.Fprintln(w, "vertical_metrics_issues:")
+		for _, issue := range vm.Issues {
+			fmt.Fprintf(w, "  - %q\n", issue)
+		}
+	}
+	if sf, err := font.StyleFlags(); err == nil && len(sf.Issues) != 0 {
+		fmt.Fprintln(w, "style_flags_issues:")
+		for _, issue := range sf.Issues {
+			fmt.Fprintf(w, "  - %q\n", issue)
+		}
+	}
+	if formats, err := font.ColorFormats(); err == nil && len(formats) != 0 {
+		fmt.Fprintln(w, "color_formats:")
+		for _, format := range formats {
+			fmt.Fprintf(w, "  - %s\n", format)
+		}
+	}
+	if h, err := font.EOT(); err == nil {
+		fmt.Fprintln(w, "eot:")
+		fmt.Fprintf(w, "  family: %q\n", h.FamilyName)
+		fmt.Fprintf(w, "  style: %q\n", h.StyleName)
+		fmt.Fprintf(w, "  version: %q\n", h.VersionName)
+		fmt.Fprintf(w, "  full_name: %q\n", h.FullName)
+		fmt.Fprintf(w, "  compressed: %t\n", h.Compressed)
+	}
+	if repairs := font.Repairs(); len(repairs) != 0 {
+		fmt.Fprintln(w, "repairs:")
+		for _, s := range repairs {
+			fmt.Fprintf(w, "  - %q\n", s)
+		}
+	}
+	if h, err := font.Hinting(); err == nil {
+		fmt.Fprintln(w, "hinting:")
+		fmt.Fprintf(w, "  hinted: %t\n", h.Hinted())
+		fmt.Fprintf(w, "  fpgm: %d\n", h.Fpgm)
+		fmt.Fprintf(w, "  prep: %d\n", h.Prep)
+		fmt.Fprintf(w, "  cvt: %d\n", h.Cvt)
+		fmt.Fprintf(w, "  glyphs: %d\n", h.Glyphs)
+		fmt.Fprintf(w, "  instructions: %d\n", h.Instructions)
+		if h.Autohinted() {
+			fmt.Fprintf(w, "  autohinter: %q\n", h.Autohinter)
+		}
+	}
 }
 
-// Load loads the font style.
+// Load loads the font style. Panics encountered while parsing a malformed
+// font are returned as errors.
 func (font *Font) Load(style canvas.FontStyle) (*canvas.FontFamily, error) {
-	ff := canvas.NewFontFamily(font.Family)
-	switch {
-	case font.Buf != nil:
-		if err := ff.LoadFont(font.Buf, 0, style); err != nil {
-			return nil, err
-		}
-	case font.Path != "":
-		if err := ff.LoadFontFile(font.Path, style); err != nil {
-			return nil, err
-		}
-	default:
-		return nil, fmt.Errorf("font.Buf and font.Path not set")
+	return font.LoadContext(context.Background(), style)
+}
+
+// LoadContext loads the font style as with [Font.Load], returning the
+// context's error when it is done between the steps of loading the font (ie,
+// after decompressing a WOFF2 font, and before parsing it). When the
+// [DefaultFamilyCache] is set, a font family is taken from the cache when
+// available, and is not returned to the cache.
+func (font *Font) LoadContext(ctx context.Context, style canvas.FontStyle) (*canvas.FontFamily, error) {
+	ff, _, err := font.loadContext(ctx, style)
+	return ff, err
+}
+
+// loadContext loads the font style as with [Font.LoadContext], returning a
+// func that returns the font family to the [DefaultFamilyCache] once it is
+// no longer used.
+func (font *Font) loadContext(ctx context.Context, style canvas.FontStyle) (_ *canvas.FontFamily, _ func(), err error) {
+	defer recoverError(&err)
+	ff, release, err := font.family(ctx, style)
+	if err != nil {
+		return nil, nil, err
 	}
 	font.once.Do(func() {
 		face := ff.Face(16)
@@ -162,61 +469,481 @@ func (font *Font) Load(style canvas.FontStyle) (*canvas.FontFamily, error) {
 			font.Version = strings.TrimPrefix(v[0].String(), "Version ")
 		}
 	})
-	return ff, nil
+	return ff, release, nil
+}
+
+// load loads the font's data into the font family, checking the context
+// between each step.
+func (font *Font) load(ctx context.Context, ff *canvas.FontFamily, style canvas.FontStyle) error {
+	buf, err := font.prepare(ctx)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := ff.LoadFont(buf, 0, style); err != nil {
+		return unsupported(buf, err)
+	}
+	return nil
+}
+
+// prepare returns the font's data prepared for loading (ie, decompressed,
+// repaired, and with the bitmap glyphs' outlines), checking the context
+// between each step.
+func (font *Font) prepare(ctx context.Context) ([]byte, error) {
+	var buf []byte
+	var err error
+	if font.Lenient {
+		if buf, err = font.repaired(); err != nil {
+			return nil, err
+		}
+	} else {
+		if buf, err = font.data(); err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if buf, err = toSFNT(buf); err != nil {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	out, err := bitmapOutlines(buf)
+	if err != nil {
+		return nil, unsupported(buf, err)
+	}
+	return out, nil
 }
 
-// Rasterize rasterizes the font image.
+// Rasterize rasterizes the font image. See [Font.RasterizeContext] for
+// setting only some of the options.
 func (font *Font) Rasterize(
 	tpl *template.Template,
 	fontSize int, style canvas.FontStyle, variant canvas.FontVariant,
 	fg, bg color.Color,
 	dpi, margin float64,
 ) (*image.RGBA, error) {
-	// default template
-	if tpl == nil {
-		tpl = tplDefault
+	return font.RasterizeContext(
+		context.Background(),
+		WithTemplate(tpl),
+		WithSize(fontSize),
+		WithStyle(style),
+		WithVariant(variant),
+		WithColors(fg, bg),
+		WithDPI(dpi),
+		WithMargin(margin),
+	)
+}
+
+// RasterizeContext rasterizes the font image using the default options
+// modified by the rasterization options (ie, [WithSize], [WithColors]),
+// returning the context's error when it is done while loading the font,
+// laying out the lines of text, or rasterizing.
+func (font *Font) RasterizeContext(ctx context.Context, opts ...RasterizeOption) (*image.RGBA, error) {
+	o := DefaultOptions()
+	for _, opt := range opts {
+		opt(o)
 	}
-	// load font family
-	ff, err := font.Load(style)
+	return font.rasterize(ctx, o)
+}
+
+// RasterizeOptions rasterizes the font image using the options. When opts is
+// nil, the default options will be used.
+func (font *Font) RasterizeOptions(opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	return font.RasterizeInto(nil, opts)
+}
+
+// RasterizeInto rasterizes the font image using the options into dst,
+// reusing dst's pixel buffer when it has enough capacity for the font image,
+// otherwise a new image is allocated. Returns dst resized to the font image,
+// or the new image. Trimmed images (see [Options.Trim]) are always returned
+// as a new image. When opts is nil, the default options will be used.
+func (font *Font) RasterizeInto(dst *image.RGBA, opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, release, err := font.layout(context.Background(), trimOptions(opts), nil)
 	if err != nil {
 		return nil, err
 	}
-	// generate text
-	buf := new(bytes.Buffer)
-	if err := tpl.Execute(buf, TemplateData{
-		Size:       fontSize,
-		Name:       font.BestName(),
-		Style:      font.Style,
-		SampleText: font.SampleText,
-		Version:    font.Version,
-	}); err != nil {
+	defer release()
+	return rasterizeInto(dst, c, opts), nil
+}
+
+// RasterizeMulti rasterizes the font image using the options at each of the
+// DPIs (ie, 1x, 2x, and 3x variants), laying out the text only once. The
+// layout uses the options' DPI, including the selected bitmap font strike and
+// the max dimension (see [Options.MaxDimension]), so that the images are
+// scaled variants of each other. When opts is nil, the default options will
+// be used.
+func (font *Font) RasterizeMulti(opts *Options, dpis []float64) ([]*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	for _, dpi := range dpis {
+		if !(0 < dpi) || math.IsInf(dpi, 0) {
+			return nil, fmt.Errorf("invalid dpi %g: must be greater than 0", dpi)
+		}
+	}
+	c, release, err := font.layout(context.Background(), trimOptions(opts), nil)
+	if err != nil {
 		return nil, err
 	}
+	defer release()
+	imgs := make([]*image.RGBA, len(dpis))
+	for i, dpi := range dpis {
+		o := *opts
+		o.DPI = dpi
+		imgs[i] = rasterizeInto(nil, c, &o)
+	}
+	return imgs, nil
+}
+
+// rasterizeInto rasterizes the canvas into dst, reusing dst's pixel buffer
+// when it has enough capacity, otherwise a new image is allocated. The image
+// is trimmed when set in the options.
+func rasterizeInto(dst *image.RGBA, c *canvas.Canvas, opts *Options) *image.RGBA {
+	img, _ := rasterizeContext(context.Background(), dst, c, opts)
+	return img
+}
+
+// rasterizeContext rasterizes the canvas as with [rasterizeInto], returning
+// the context's error when it is done before all of the canvas' paths, text
+// and images are drawn.
+func rasterizeContext(ctx context.Context, dst *image.RGBA, c *canvas.Canvas, opts *Options) (*image.RGBA, error) {
+	res := canvas.DPI(opts.DPI)
+	w, h := int(c.W*res.DPMM()+0.5), int(c.H*res.DPMM()+0.5)
+	if dst == nil || cap(dst.Pix) < 4*w*h {
+		dst = image.NewRGBA(image.Rect(0, 0, w, h))
+	} else {
+		dst.Pix, dst.Stride, dst.Rect = dst.Pix[:4*w*h], 4*w, image.Rect(0, 0, w, h)
+		clear(dst.Pix)
+	}
+	ras := rasterizer.FromImage(dst, res, canvas.DefaultColorSpace)
+	c.RenderTo(contextRenderer{Renderer: ras, ctx: ctx})
+	ras.Close()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.Trim {
+		return trim(dst, opts), nil
+	}
+	return dst, nil
+}
+
+// contextRenderer is a renderer that stops drawing once its context is done.
+type contextRenderer struct {
+	canvas.Renderer
+	ctx context.Context
+}
+
+// RenderPath satisfies the [canvas.Renderer] interface.
+func (r contextRenderer) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
+	if r.ctx.Err() == nil {
+		r.Renderer.RenderPath(path, style, m)
+	}
+}
+
+// RenderText satisfies the [canvas.Renderer] interface.
+func (r contextRenderer) RenderText(text *canvas.Text, m canvas.Matrix) {
+	if r.ctx.Err() == nil {
+		r.Renderer.RenderText(text, m)
+	}
+}
+
+// RenderImage satisfies the [canvas.Renderer] interface.
+func (r contextRenderer) RenderImage(img image.Image, m canvas.Matrix) {
+	if r.ctx.Err() == nil {
+		r.Renderer.RenderImage(img, m)
+	}
+}
+
+// DrawTo draws the font image's text layer over dst with the image's top
+// left corner at pt, without a background, returning the bounds of the font
+// image in dst. When dst is an [*image.RGBA] and the font image is not
+// clipped by dst's left edge, the text is rasterized directly into dst,
+// without allocating an intermediate image, allowing multiple previews to be
+// composited into a single image. When opts is nil, the default options will
+// be used.
+func (font *Font) DrawTo(dst draw.Image, pt image.Point, opts *Options) (image.Rectangle, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	o := *opts
+	o.NoBackground = true
+	c, release, err := font.layout(context.Background(), &o, nil)
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	defer release()
+	res := canvas.DPI(o.DPI)
+	w, h := c.Size()
+	r := image.Rect(0, 0, int(w*res.DPMM()+0.5), int(h*res.DPMM()+0.5)).Add(pt)
+	clip := r.Intersect(dst.Bounds())
+	if clip.Empty() {
+		return r, nil
+	}
+	// rasterize directly into dst when possible, as the rasterizer only
+	// supports RGBA images, and does not correctly clip paths crossing the
+	// image's left edge
+	img, ok := dst.(*image.RGBA)
+	if !ok || clip.Min.X != r.Min.X {
+		img, clip = image.NewRGBA(r), r
+	}
+	// shift the view by the clipped bottom edge, as the rasterizer draws
+	// relative to the image's bottom left corner
+	view := canvas.Identity.Translate(0, float64(clip.Max.Y-r.Max.Y)/res.DPMM())
+	ras := rasterizer.FromImage(img.SubImage(clip).(*image.RGBA), res, canvas.DefaultColorSpace)
+	c.RenderViewTo(ras, view)
+	ras.Close()
+	if img != dst {
+		draw.Draw(dst, r, img, r.Min, draw.Over)
+	}
+	return r, nil
+}
+
+// Canvas lays out the font image on a canvas using the options, without
+// rasterizing it. When opts is nil, the default options will be used.
+func (font *Font) Canvas(opts *Options) (*canvas.Canvas, error) {
+	return font.CanvasContext(context.Background(), opts)
+}
+
+// CanvasContext lays out the font image on a canvas as with [Font.Canvas],
+// returning the context's error when it is done while loading the font or
+// laying out the lines of text.
+func (font *Font) CanvasContext(ctx context.Context, opts *Options) (*canvas.Canvas, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, _, err := font.layout(ctx, opts, nil)
+	return c, err
+}
+
+// layout lays out the font image on a canvas using the options, counting the
+// drawn lines in st when not nil. The render context is checked before each
+// line is drawn. Returns a func returning the font family to the
+// [DefaultFamilyCache], to be called once the canvas is rendered.
+func (font *Font) layout(rctx context.Context, opts *Options, st *Stats) (_ *canvas.Canvas, _ func(), err error) {
+	defer recoverError(&err)
+	if err := rctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	// bitmap fonts
+	if b, err := font.header(); err == nil && decoder(b) != nil {
+		bf, err := font.Bitmap()
+		if err != nil {
+			return nil, nil, err
+		}
+		c, err := font.bitmapCanvas(bf, opts, st)
+		return c, func() {}, err
+	}
+	// variable font instances
+	if opts.Instance != "" || len(opts.Variations) != 0 {
+		inst, err := font.instance(opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		o := *opts
+		o.Instance, o.Variations = "", nil
+		return inst.layout(rctx, &o, st)
+	}
+	// load font family
+	ff, release, err := font.loadContext(rctx, opts.Style)
+	if err != nil {
+		return nil, nil, err
+	}
+	// generate text
+	buf, err := font.text(opts, ff.Face(16).Font.SFNT)
+	if err != nil {
+		return nil, nil, err
+	}
 	// create canvas and context
 	c := canvas.New(100, 100)
 	ctx := canvas.NewContext(c)
 	ctx.SetZIndex(1)
-	ctx.SetFillColor(fg)
+	ctx.SetFillColor(opts.FG)
+	// paragraph hyphenation
+	var hyph *Hyphenator
+	if p := opts.Paragraph; p != nil && p.Language != "" {
+		if hyph, err = hyphenator(p.Language); err != nil {
+			return nil, nil, err
+		}
+	}
 	// draw text
-	lines, sizes := breakLines(buf.Bytes(), fontSize)
+	lines, sizes, features := breakLines(buf, opts.Size)
+	size := 0
+	for _, sz := range sizes {
+		size = max(size, sz)
+	}
+	cf, err := font.colorFace(int(math.Ceil(float64(size) * opts.DPI / 72)))
+	if err != nil {
+		return nil, nil, err
+	}
+	var ef *emojiFallback
+	if opts.EmojiFallback {
+		if ef, err = newEmojiFallback(int(math.Ceil(float64(size) * opts.DPI / 72))); err != nil {
+			return nil, nil, err
+		}
+	}
+	if opts.Strict {
+		runes := notdefRunes(ff.Face(float64(opts.Size)).Font.SFNT, lines)
+		if ef != nil {
+			// emoji drawn with the emoji font are not missing
+			runes = slices.DeleteFunc(runes, func(r rune) bool {
+				return ef.sfnt.GlyphIndex(r) != 0
+			})
+		}
+		if err := missingRunes(runes); err != nil {
+			return nil, nil, err
+		}
+	}
 	for i, y := 0, float64(0); i < len(lines); i++ {
-		face := ff.Face(float64(sizes[i]), fg, style, variant)
-		txt := canvas.NewTextBox(face, strings.TrimSpace(lines[i]), 0, 0, canvas.Left, canvas.Top, nil)
-		b := txt.Bounds()
-		ctx.DrawText(0, y, txt)
+		if err := rctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		ff.SetFeatures(features[i])
+		face := ff.Face(float64(sizes[i]), opts.FG, opts.Style, opts.Variant)
+		line := strings.TrimSpace(lines[i])
+		if p := opts.Paragraph; p != nil && 0 < p.Width {
+			if opts.Notdef == NotdefSkip {
+				line = strings.Map(func(r rune) rune {
+					if notdef(face.Font.SFNT, r) {
+						return -1
+					}
+					return r
+				}, line)
+			}
+			h := p.draw(ctx, face, line, y, hyph)
+			if st != nil {
+				st.Lines += int(math.Round(h / face.Metrics().LineHeight))
+			}
+			y -= h
+			continue
+		}
+		if st != nil && line != "" {
+			st.Lines++
+		}
+		if (opts.Notdef != "" && opts.Notdef != NotdefFont || ef != nil) && strings.IndexFunc(line, func(r rune) bool {
+			return notdef(face.Font.SFNT, r)
+		}) != -1 {
+			h, err := drawNotdefs(ctx, face, line, y, opts, ef)
+			if err != nil {
+				return nil, nil, err
+			}
+			y -= h
+			continue
+		}
+		if cf != nil {
+			// color glyphs are drawn over the transparent text
+			tf := *face
+			tf.Fill = canvas.Paint{Color: canvas.Transparent}
+			face = &tf
+		}
+		txt := canvas.NewTextBox(face, line, 0, 0, canvas.Left, canvas.Top, nil)
+		b, x := txt.Bounds(), 0.0
+		if opts.OpticalMargin && !txt.Empty() {
+			x = -txt.OutlineBounds().X0
+		}
+		if 0 < opts.BaselineGrid && !txt.Empty() {
+			// snap the baseline down to the grid, tolerating rounding error
+			baseline := b.Y1 - face.Metrics().Ascent
+			y = math.Floor((y+baseline)/opts.BaselineGrid+1e-9)*opts.BaselineGrid - baseline
+		}
+		ctx.DrawText(x, y, txt)
+		if cf != nil {
+			cf.drawText(ctx, x, y, txt, opts.FG)
+			ctx.SetFillColor(opts.FG)
+		}
+		if opts.ShowSpacing {
+			drawSpacing(ctx, txt, x, y)
+			ctx.SetFillColor(opts.FG)
+		}
 		y += b.Y0 - b.Y1
 	}
+	if st != nil && ef != nil {
+		st.EmojiRuns = ef.runs
+	}
+	// draw footer
+	if err := drawFooter(c, ctx, ff.Face(16).Font.SFNT, opts); err != nil {
+		return nil, nil, err
+	}
 	// fit canvas to context
-	c.Fit(margin)
+	fitCanvas(c, opts, st)
 	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, release, nil
+}
+
+// drawBackground draws the options' background, unless the options skip
+// drawing the background.
+func drawBackground(ctx *canvas.Context, opts *Options) {
+	if opts.NoBackground {
+		return
+	}
 	ctx.SetZIndex(-1)
-	ctx.SetFillColor(bg)
+	ctx.SetFillColor(opts.BG)
 	width, height := ctx.Size()
 	ctx.DrawPath(0, 0, canvas.Rectangle(width, height))
-	// close drawing context
-	ctx.Close()
-	// rasterize
-	return rasterizer.Draw(c, canvas.DPI(dpi), canvas.DefaultColorSpace), nil
+}
+
+// fitCanvas fits the canvas to its content plus the options' margin. When
+// the rasterized image would exceed the options' max dimension, the content
+// is scaled down to fit, and the scale is recorded in st when not nil.
+func fitCanvas(c *canvas.Canvas, opts *Options, st *Stats) {
+	c.Fit(opts.Margin)
+	if opts.MaxDimension <= 0 {
+		return
+	}
+	n := float64(opts.MaxDimension)/canvas.DPI(opts.DPI).DPMM() - 2*opts.Margin
+	w, h := c.W-2*opts.Margin, c.H-2*opts.Margin
+	scale := math.Min(n/w, n/h)
+	if n <= 0 || 1 <= scale {
+		return
+	}
+	c.Transform(canvas.Identity.Scale(scale, scale))
+	c.Fit(opts.Margin)
+	if st != nil {
+		st.Scale = scale
+	}
+}
+
+// text executes the options' template, returning the generated text. When
+// sfnt is not nil and is a symbol font, the symbol font template is used.
+func (font *Font) text(opts *Options, sfnt *fontpkg.SFNT) ([]byte, error) {
+	sampleText, tpl := font.SampleText, opts.template()
+	if opts.Text != "" {
+		sampleText = opts.Text
+	}
+	// symbol fonts show their first glyphs
+	if sfnt != nil && sampleText == "" && opts.Template == nil && isSymbol(sfnt) {
+		n := opts.SymbolGlyphs
+		if n <= 0 {
+			n = 16
+		}
+		if s := symbolText(sfnt, n); s != "" {
+			sampleText, tpl = s, tplSymbol
+		}
+	}
+	buf := new(bytes.Buffer)
+	if err := executeTemplate(buf, tpl, TemplateData{
+		Size:       opts.Size,
+		Name:       font.BestName(),
+		Style:      font.Style,
+		SampleText: sampleText,
+		Version:    font.Version,
+	}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 // TemplateData is the data passed to the text template.
@@ -228,22 +955,32 @@ type TemplateData struct {
 	Version    string
 }
 
-// breakLines breaks the text up by lines, returning the lines and the font
-// size for each line.
-func breakLines(buf []byte, size int) ([]string, []int) {
-	var lines []string
+// breakLines breaks the text up by lines, returning the lines, and the font
+// size and font features for each line.
+func breakLines(buf []byte, size int) ([]string, []int, []string) {
+	var lines, features []string
 	var sizes []int
 	for line := range bytes.SplitSeq(buf, []byte{'\n'}) {
-		sz := size
-		if m := sizeRE.FindSubmatch(line); m != nil {
-			if s, err := strconv.Atoi(string(m[1])); err == nil {
-				sz = s
+		sz, feat := size, ""
+	loop:
+		for {
+			switch {
+			case sizeRE.Match(line):
+				m := sizeRE.FindSubmatch(line)
+				if s, err := strconv.Atoi(string(m[1])); err == nil {
+					sz = s
+				}
+				line = m[2]
+			case featuresRE.Match(line):
+				m := featuresRE.FindSubmatch(line)
+				feat, line = string(m[1]), m[2]
+			default:
+				break loop
 			}
-			line = m[2]
 		}
-		lines, sizes = append(lines, string(line)), append(sizes, sz)
+		lines, sizes, features = append(lines, string(line)), append(sizes, sz), append(features, feat)
 	}
-	return lines, sizes
+	return lines, sizes, features
 }
 
 // titleCase returns the title case for a name.
@@ -267,6 +1004,17 @@ func titleCase(name string) string {
 	return spaceRE.ReplaceAllString(strings.TrimSpace(string(s)), " ")
 }
 
+// upperWords upper cases the first letter of each word in s.
+func upperWords(s string) string {
+	v := strings.Fields(s)
+	for i, word := range v {
+		r := []rune(word)
+		r[0] = unicode.ToUpper(r[0])
+		v[i] = string(r)
+	}
+	return strings.Join(v, " ")
+}
+
 // peek peeks a rune.
 func peek(r []rune, i int) rune {
 	if i < len(r) {
@@ -276,13 +1024,18 @@ func peek(r []rune, i int) rune {
 }
 
 var (
-	extRE   = regexp.MustCompile(`(?i)\.(ttf|ttc|otf|woff|woff2|sfnt)$`)
-	sizeRE  = regexp.MustCompile(`^\x00([0-9]+)\x00(.*)$`)
-	spaceRE = regexp.MustCompile(`\s+`)
+	extRE      = regexp.MustCompile(`(?i)\.(ttf|ttc|otf|woff|woff2|sfnt|eot|fon|fnt|psf|psfu)$`)
+	sizeRE     = regexp.MustCompile(`^\x00([0-9]+)\x00(.*)$`)
+	featuresRE = regexp.MustCompile(`^\x01([^\x01]*)\x01(.*)$`)
+	spaceRE    = regexp.MustCompile(`\s+`)
 )
 
+// stdin is the reader used for fonts read from standard input.
+var stdin io.Reader = os.Stdin
+
 var (
 	sfonts     *fontpkg.SystemFonts
+	sfontsErr  error
 	once       sync.Once
 	tplDefault *template.Template
 )
@@ -295,6 +1048,17 @@ func init() {
 }
 
 // NewTemplate creates a text template.
+//
+// Templates may set the font size of a line with {{ size N }} and the font
+// features of a line with {{ features "-calt,liga" }}, both of which must
+// occur at the start of the line. Additional funcs inc, scale and pseudo are
+// available for adjusting sizes and pseudo-localizing text, and number,
+// currency and date for formatting localized values (ie, {{ currency "de-DE"
+// 1234.5 }}). The waterfall func repeats text on a line per size (ie, {{
+// waterfall .SampleText 8 12 24 }}). The emoji func returns the default emoji
+// sequences of a kind (ie, {{ emoji "zwj" }}, see [DefaultEmojiSequences]),
+// and decompose separates each sequence of text into its components. Use
+// [NewSandboxTemplate] for template text from untrusted users.
 func NewTemplate(text string) (*template.Template, error) {
 	return template.New("").Funcs(map[string]any{
 		"size": func(size int) string {
@@ -303,6 +1067,19 @@ func NewTemplate(text string) (*template.Template, error) {
 		"inc": func(a, b int) int {
 			return a + b
 		},
+		"scale": func(size int, f float64) int {
+			return max(1, int(math.Round(float64(size)*f)))
+		},
+		"pseudo": PseudoLocalize,
+		"features": func(features string) string {
+			return "\x01" + features + "\x01"
+		},
+		"number":    formatNumber,
+		"currency":  formatCurrency,
+		"date":      formatDate,
+		"waterfall": waterfall,
+		"emoji":     emoji,
+		"decompose": decompose,
 	}).Parse(text)
 }
 
@@ -2,14 +2,22 @@ package fontimg
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"image"
 	"image/color"
-	"image/png"
+	"image/draw"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/kenshaw/fontimg/fontimgtest"
+	"github.com/kenshaw/fontimg/testfonts"
 	"github.com/tdewolff/canvas"
 )
 
@@ -21,6 +29,212 @@ func TestOpen(t *testing.T) {
 	t.Logf("font: %+v", font)
 }
 
+func TestOpenStdin(t *testing.T) {
+	defer func(r io.Reader) { stdin = r }(stdin)
+	for _, test := range testFonts(t) {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := os.Open(test.path)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			defer f.Close()
+			stdin = f
+			fonts, err := Open("-", canvas.FontRegular, nil)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if len(fonts) != 1 || fonts[0].Buf == nil || fonts[0].Family == "" {
+				t.Fatalf("expected font with buf and family, got: %+v", fonts)
+			}
+			if _, err := fonts[0].RasterizeOptions(nil); err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		})
+	}
+	stdin = strings.NewReader("not a font")
+	if _, err := Open("-", canvas.FontRegular, nil); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	tests := []struct {
+		name string
+		exp  string
+	}{
+		{"Ubuntu-R.ttf", "Ubuntu"},
+		{"NotoMono-Regular.ttf", "Noto Mono"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", test.name))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			defer f.Close()
+			font, err := NewReader(f)
+			switch {
+			case err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case font.Buf == nil || font.Path != "":
+				t.Errorf("expected font with buf and no path, got: %+v", font)
+			case font.Family != test.exp:
+				t.Errorf("expected family %q, got: %q", test.exp, font.Family)
+			}
+			if _, err := font.RasterizeOptions(nil); err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		})
+	}
+	if _, err := NewReader(strings.NewReader("not a font")); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestScan(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"a.ttf", "b.ttf", "bad.ttf", "c.ttf", "notes.txt"} {
+		b := buf
+		if name == "bad.ttf" || name == "notes.txt" {
+			b = []byte("not a font")
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0o644); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	var paths, bad []string
+	for font, err := range Scan(dir, canvas.FontRegular, nil) {
+		var ferr *FontError
+		switch {
+		case errors.As(err, &ferr):
+			bad = append(bad, filepath.Base(ferr.Path))
+		case err != nil:
+			t.Fatalf("expected no error, got: %v", err)
+		default:
+			paths = append(paths, filepath.Base(font.Path))
+		}
+	}
+	slices.Sort(paths)
+	if exp := []string{"a.ttf", "b.ttf", "c.ttf"}; !slices.Equal(paths, exp) {
+		t.Errorf("expected %v, got: %v", exp, paths)
+	}
+	if exp := []string{"bad.ttf"}; !slices.Equal(bad, exp) {
+		t.Errorf("expected %v, got: %v", exp, bad)
+	}
+	// early termination
+	var n int
+	for range Scan(dir, canvas.FontRegular, nil) {
+		if n++; n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("expected 2, got: %d", n)
+	}
+	// single file
+	n = 0
+	for font, err := range Scan(filepath.Join(dir, "a.ttf"), canvas.FontRegular, nil) {
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if n++; filepath.Base(font.Path) != "a.ttf" {
+			t.Errorf("expected a.ttf, got: %s", font.Path)
+		}
+	}
+	if n != 1 {
+		t.Errorf("expected 1 font, got: %d", n)
+	}
+	// empty directory
+	for _, err := range Scan(t.TempDir(), canvas.FontRegular, nil) {
+		if err == nil {
+			t.Errorf("expected error for empty directory")
+		}
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp canvas.FontStyle
+		err bool
+	}{
+		{"", canvas.FontRegular, false},
+		{"Regular", canvas.FontRegular, false},
+		{"bold", canvas.FontBold, false},
+		{"Bold Italic", canvas.FontBold | canvas.FontItalic, false},
+		{"semibold italic", canvas.FontSemiBold | canvas.FontItalic, false},
+		{"Thin", canvas.FontThin, false},
+		{"Italic", canvas.FontRegular | canvas.FontItalic, false},
+		{"blah", 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			style, err := ParseStyle(test.s)
+			switch {
+			case test.err && err == nil:
+				t.Fatalf("expected error")
+			case !test.err && err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if style != test.exp {
+				t.Errorf("expected %v, got: %v", test.exp, style)
+			}
+		})
+	}
+}
+
+func TestBreakLines(t *testing.T) {
+	tpl, err := NewTemplate(`{{ size 12 }}a
+{{ features "-liga" }}b
+{{ size 8 }}{{ features "-calt" }}c
+{{ features "+calt" }}{{ size 9 }}d
+e`)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	buf := new(bytes.Buffer)
+	if err := tpl.Execute(buf, nil); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	lines, sizes, features := breakLines(buf.Bytes(), 16)
+	expLines := []string{"a", "b", "c", "d", "e"}
+	expSizes := []int{12, 16, 8, 9, 16}
+	expFeatures := []string{"", "-liga", "-calt", "+calt", ""}
+	if !slices.Equal(lines, expLines) {
+		t.Errorf("expected %q, got: %q", expLines, lines)
+	}
+	if !slices.Equal(sizes, expSizes) {
+		t.Errorf("expected %v, got: %v", expSizes, sizes)
+	}
+	if !slices.Equal(features, expFeatures) {
+		t.Errorf("expected %q, got: %q", expFeatures, features)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	var v [][]byte
+	for _, s := range []string{"", `{{ features "-liga" }}`} {
+		tpl, err := NewTemplate(s + "{{ .SampleText }}")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		opts := DefaultOptions()
+		opts.Template, opts.Text = tpl, "fi fl ffi"
+		img, err := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf")).RasterizeOptions(opts)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		v = append(v, img.Pix)
+	}
+	if bytes.Equal(v[0], v[1]) {
+		t.Errorf("expected disabling ligatures to change output")
+	}
+}
+
 func TestRasterize(t *testing.T) {
 	var (
 		size    = 48
@@ -47,28 +261,299 @@ func TestRasterize(t *testing.T) {
 			if err != nil {
 				t.Fatalf("expected no error, got: %v", err)
 			}
-			var b bytes.Buffer
-			if err := png.Encode(&b, img); err != nil {
+			fontimgtest.Golden(t, test.golden, img, fontimgtest.Exact)
+		})
+	}
+}
+
+func TestRasterizeContext(t *testing.T) {
+	f := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	opts := DefaultOptions()
+	opts.Size, opts.FG, opts.BG, opts.Trim = 24, color.White, color.Black, true
+	exp, err := f.RasterizeOptions(opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	img, err := f.RasterizeContext(
+		context.Background(),
+		WithSize(24),
+		WithColors(color.White, color.Black),
+		WithTrim(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if img.Rect != exp.Rect || !bytes.Equal(img.Pix, exp.Pix) {
+		t.Errorf("expected the same image as the options")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.RasterizeContext(ctx, WithSize(24)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got: %v", context.Canceled, err)
+	}
+}
+
+func TestContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if _, err := f.LoadContext(ctx, canvas.FontRegular); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got: %v", context.Canceled, err)
+	}
+	if _, err := f.CanvasContext(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got: %v", context.Canceled, err)
+	}
+	if _, err := OpenContext(ctx, "testdata", canvas.FontRegular, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got: %v", context.Canceled, err)
+	}
+	// cancel after each number of checks, until rasterizing completes
+	tpl, err := NewTemplate("{{ .Name }}\n{{ .SampleText }}\n{{ .SampleText }}\n{{ .SampleText }}")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp, err := f.RasterizeContext(context.Background(), WithTemplate(tpl))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var n int
+	for ; ; n++ {
+		img, err := f.RasterizeContext(&countContext{Context: context.Background(), n: n}, WithTemplate(tpl))
+		if err == nil {
+			if !bytes.Equal(img.Pix, exp.Pix) {
+				t.Errorf("expected the same image after %d checks", n)
+			}
+			break
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected %v after %d checks, got: %v", context.Canceled, n, err)
+		}
+	}
+	if n < 8 {
+		t.Errorf("expected at least 8 checks, got: %d", n)
+	}
+}
+
+// countContext is a context that is canceled after n checks of its error.
+type countContext struct {
+	context.Context
+	n int
+}
+
+func (ctx *countContext) Err() error {
+	if ctx.n <= 0 {
+		return context.Canceled
+	}
+	ctx.n--
+	return nil
+}
+
+func TestConcurrent(t *testing.T) {
+	for _, test := range testFonts(t) {
+		t.Run(test.name, func(t *testing.T) {
+			f := New(nil, test.path)
+			if _, err := f.Load(canvas.FontRegular); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			exp, err := f.RasterizeOptions(nil)
+			if err != nil {
 				t.Fatalf("expected no error, got: %v", err)
 			}
-			buf := b.Bytes()
-			t.Logf("buf: %d", len(buf))
-			base := strings.TrimSuffix(test.path, filepath.Ext(test.path))
-			if err := os.WriteFile(base+".png", buf, 0o644); err != nil {
+			var wg sync.WaitGroup
+			for range 8 {
+				wg.Go(func() {
+					img, err := f.RasterizeOptions(nil)
+					switch {
+					case err != nil:
+						t.Errorf("expected no error, got: %v", err)
+					case !bytes.Equal(img.Pix, exp.Pix):
+						t.Errorf("expected concurrent render to match")
+					}
+					_ = f.String()
+				})
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	f := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if _, err := f.Load(canvas.FontRegular); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	c := f.Clone()
+	if c.String() != f.String() || c.Version != f.Version {
+		t.Errorf("expected %s, got: %s", f, c)
+	}
+	c.Lenient, c.Name = true, "Other"
+	if f.Lenient || f.Name != "Ubuntu" {
+		t.Errorf("expected font to be unchanged, got: %s", f)
+	}
+	if _, err := c.Load(canvas.FontRegular); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	f := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	f.Lenient = true
+	defer f.Close()
+	if _, err := f.Load(canvas.FontRegular); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if f.repair.buf == nil {
+		t.Fatalf("expected repaired data")
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if f.repair.buf != nil {
+		t.Errorf("expected repaired data to be released")
+	}
+	if _, err := f.Load(canvas.FontRegular); err != nil {
+		t.Fatalf("expected no error after close, got: %v", err)
+	}
+}
+
+func TestRasterizeInto(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	ref, err := font.RasterizeOptions(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	size := ref.Bounds().Size()
+	large := image.NewRGBA(image.Rect(0, 0, size.X+10, size.Y+10))
+	for i := range large.Pix {
+		large.Pix[i] = 0x7f
+	}
+	tests := []struct {
+		name  string
+		dst   *image.RGBA
+		reuse bool
+	}{
+		{"nil", nil, false},
+		{"small", image.NewRGBA(image.Rect(0, 0, size.X, size.Y-1)), false},
+		{"transposed", image.NewRGBA(image.Rect(0, 0, size.Y, size.X)), true},
+		{"large", large, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var pix []byte
+			if test.dst != nil {
+				pix = test.dst.Pix
+			}
+			img, err := font.RasterizeInto(test.dst, nil)
+			if err != nil {
 				t.Fatalf("expected no error, got: %v", err)
 			}
-			if !bytes.Equal(test.exp, buf) {
-				t.Errorf("expected %s to match rasterized image", test.golden)
+			if reused := 0 < len(pix) && &img.Pix[0] == &pix[0]; reused != test.reuse {
+				t.Errorf("expected reuse %t, got: %t", test.reuse, reused)
+			}
+			if img.Bounds() != ref.Bounds() {
+				t.Fatalf("expected bounds %v, got: %v", ref.Bounds(), img.Bounds())
+			}
+			if !bytes.Equal(img.Pix, ref.Pix) {
+				t.Errorf("expected image to match rasterized image")
 			}
 		})
 	}
 }
 
+func TestRasterizeMulti(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	dpis := []float64{100, 200, 300}
+	imgs, err := font.RasterizeMulti(nil, dpis)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(imgs) != len(dpis) {
+		t.Fatalf("expected %d images, got: %d", len(dpis), len(imgs))
+	}
+	for i, dpi := range dpis {
+		opts := DefaultOptions()
+		opts.DPI = dpi
+		exp, err := font.RasterizeOptions(opts)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if imgs[i].Bounds() != exp.Bounds() {
+			t.Errorf("dpi %g expected bounds %v, got: %v", dpi, exp.Bounds(), imgs[i].Bounds())
+		} else if !bytes.Equal(imgs[i].Pix, exp.Pix) {
+			t.Errorf("dpi %g expected image to match rasterized image", dpi)
+		}
+	}
+	if _, err := font.RasterizeMulti(nil, []float64{100, 0}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestDrawTo(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	opts := DefaultOptions()
+	opts.NoBackground = true
+	ref, err := font.RasterizeOptions(opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	size := ref.Bounds().Size()
+	tests := []struct {
+		name string
+		dst  draw.Image
+		pt   image.Point
+	}{
+		{"rgba", image.NewRGBA(image.Rect(0, 0, 2*size.X+20, size.Y+20)), image.Pt(10, 10)},
+		{"offset", image.NewRGBA(image.Rect(-50, -50, 2*size.X, size.Y)), image.Pt(-40, -40)},
+		{"clipped", image.NewRGBA(image.Rect(0, 0, size.X, size.Y)), image.Pt(-30, 20)},
+		{"clipped top", image.NewRGBA(image.Rect(0, 0, size.X, size.Y)), image.Pt(30, -20)},
+		{"nrgba", image.NewNRGBA(image.Rect(0, 0, 2*size.X+20, size.Y+20)), image.Pt(10, 10)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// draw two previews side by side
+			var rects []image.Rectangle
+			for _, pt := range []image.Point{test.pt, test.pt.Add(image.Pt(size.X, 0))} {
+				r, err := font.DrawTo(test.dst, pt, nil)
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				if exp := ref.Bounds().Add(pt); r != exp {
+					t.Fatalf("expected %v, got: %v", exp, r)
+				}
+				rects = append(rects, r)
+			}
+			b := test.dst.Bounds()
+			for y := b.Min.Y; y < b.Max.Y; y++ {
+				for x := b.Min.X; x < b.Max.X; x++ {
+					exp := color.RGBAModel.Convert(color.Transparent)
+					for _, r := range rects {
+						if p := image.Pt(x, y); p.In(r) {
+							exp = ref.At(p.X-r.Min.X, p.Y-r.Min.Y)
+						}
+					}
+					if c := color.RGBAModel.Convert(test.dst.At(x, y)); !colorNear(c, exp) {
+						t.Fatalf("expected %v at (%d, %d), got: %v", exp, x, y, c)
+					}
+				}
+			}
+		})
+	}
+}
+
+// colorNear reports whether the colors are equal, within the rounding of
+// converting between color models.
+func colorNear(a, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	near := func(x, y uint32) bool {
+		return max(x, y)-min(x, y) <= 0x0101
+	}
+	return near(r1, r2) && near(g1, g2) && near(b1, b2) && near(a1, a2)
+}
+
 type testFont struct {
 	path   string
 	golden string
 	name   string
-	exp    []byte
 }
 
 func testFonts(t *testing.T) []testFont {
@@ -81,21 +566,24 @@ func testFonts(t *testing.T) []testFont {
 			return nil
 		}
 		pathstr := filepath.Join("testdata", name)
-		golden := strings.TrimSuffix(pathstr, filepath.Ext(pathstr)) + ".png.golden"
-		exp, err := os.ReadFile(golden)
-		if err != nil {
-			t.Fatalf("expected no error, got: %v", err)
-		}
 		tests = append(tests, testFont{
 			path:   pathstr,
-			golden: golden,
+			golden: strings.TrimSuffix(pathstr, filepath.Ext(pathstr)) + ".png.golden",
 			name:   name,
-			exp:    exp,
 		})
 		return nil
 	})
 	if err != nil {
 		t.Fatalf("expected no error, got: %v", err)
 	}
+	// the variable font is shared with the testfonts package
+	tests = append(tests, testFont{
+		path:   filepath.Join("testfonts", testfonts.Variable),
+		golden: filepath.Join("testdata", "GoVF-Regular.png.golden"),
+		name:   testfonts.Variable,
+	})
+	slices.SortFunc(tests, func(a, b testFont) int {
+		return strings.Compare(a.name, b.name)
+	})
 	return tests
 }
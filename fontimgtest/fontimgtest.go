@@ -0,0 +1,143 @@
+// Package fontimgtest provides golden image test helpers for font images,
+// tolerating minor rasterizer drift between versions of the underlying
+// canvas package.
+package fontimgtest
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Update regenerates golden images instead of comparing against them. It is
+// set when the UPDATE_GOLDEN environment variable is not empty.
+var Update = os.Getenv("UPDATE_GOLDEN") != ""
+
+// Tolerance is the tolerance used when comparing images.
+type Tolerance struct {
+	// Delta is the maximum difference of a pixel's color channels (0-255)
+	// for the pixel to match.
+	Delta uint8
+	// Fraction is the maximum fraction (0-1) of pixels that may not match.
+	Fraction float64
+}
+
+// Exact is the tolerance requiring images to be identical.
+var Exact = Tolerance{}
+
+// DefaultTolerance is the default tolerance, allowing for anti-aliasing
+// differences between rasterizer versions.
+var DefaultTolerance = Tolerance{Delta: 32, Fraction: 0.01}
+
+// Diff is the difference between two images.
+type Diff struct {
+	// Pixels is the number of pixels not matching.
+	Pixels int
+	// Total is the total number of pixels.
+	Total int
+	// Delta is the largest difference of a pixel's color channels.
+	Delta uint8
+}
+
+// String satisfies the [fmt.Stringer] interface.
+func (d Diff) String() string {
+	return fmt.Sprintf("%d of %d pixels (%.2f%%) differ, max delta %d", d.Pixels, d.Total, 100*d.fraction(), d.Delta)
+}
+
+// fraction returns the fraction of pixels not matching.
+func (d Diff) fraction() float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	return float64(d.Pixels) / float64(d.Total)
+}
+
+// Compare compares the images, returning an error when they differ in size,
+// or when more than the tolerance's fraction of pixels differ by more than
+// the tolerance's delta.
+func Compare(exp, img image.Image, tol Tolerance) (Diff, error) {
+	eb, ib := exp.Bounds(), img.Bounds()
+	if eb.Size() != ib.Size() {
+		return Diff{}, fmt.Errorf("expected size %v, got: %v", eb.Size(), ib.Size())
+	}
+	d := Diff{Total: eb.Dx() * eb.Dy()}
+	for y := range eb.Dy() {
+		for x := range eb.Dx() {
+			delta := pixelDelta(exp, img, eb.Min.X+x, eb.Min.Y+y, ib.Min.X+x, ib.Min.Y+y)
+			d.Delta = max(d.Delta, delta)
+			if tol.Delta < delta {
+				d.Pixels++
+			}
+		}
+	}
+	if tol.Fraction < d.fraction() || (tol.Fraction == 0 && d.Pixels != 0) {
+		return d, fmt.Errorf("images differ: %v", d)
+	}
+	return d, nil
+}
+
+// pixelDelta returns the largest difference of the color channels of the
+// pixels, as 8-bit values.
+func pixelDelta(a, b image.Image, ax, ay, bx, by int) uint8 {
+	ar, ag, ab, aa := a.At(ax, ay).RGBA()
+	br, bg, bb, ba := b.At(bx, by).RGBA()
+	var delta uint32
+	for _, v := range [][2]uint32{{ar, br}, {ag, bg}, {ab, bb}, {aa, ba}} {
+		if v[0] < v[1] {
+			v[0], v[1] = v[1], v[0]
+		}
+		delta = max(delta, (v[0]-v[1])>>8)
+	}
+	return uint8(delta)
+}
+
+// Golden compares the image to the named golden PNG image, failing the test
+// when they differ (see [Compare]). When [Update] is set, the golden image is
+// written instead.
+func Golden(tb testing.TB, name string, img image.Image, tol Tolerance) {
+	tb.Helper()
+	if Update {
+		if err := WriteGolden(name, img); err != nil {
+			tb.Fatalf("expected no error, got: %v", err)
+		}
+		tb.Logf("updated %s", name)
+		return
+	}
+	exp, err := ReadGolden(name)
+	if err != nil {
+		tb.Fatalf("expected no error, got: %v (set UPDATE_GOLDEN=1 to create)", err)
+	}
+	if _, err := Compare(exp, img, tol); err != nil {
+		tb.Errorf("expected %s to match image: %v (set UPDATE_GOLDEN=1 to update)", name, err)
+	}
+}
+
+// ReadGolden reads the named golden PNG image.
+func ReadGolden(name string) (image.Image, error) {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	img, err := png.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return img, nil
+}
+
+// WriteGolden writes the image as the named golden PNG image, creating its
+// parent directory as needed.
+func WriteGolden(name string, img image.Image) error {
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, b.Bytes(), 0o644)
+}
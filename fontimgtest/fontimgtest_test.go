@@ -0,0 +1,79 @@
+package fontimgtest
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name   string
+		f      func(*image.RGBA)
+		tol    Tolerance
+		pixels int
+		err    bool
+	}{
+		{"same", func(*image.RGBA) {}, Exact, 0, false},
+		{"exact", func(img *image.RGBA) { img.Set(0, 0, color.RGBA{1, 0, 0, 255}) }, Exact, 0, true},
+		{"delta", func(img *image.RGBA) { img.Set(0, 0, color.RGBA{16, 0, 0, 255}) }, Tolerance{Delta: 16}, 0, false},
+		{"over delta", func(img *image.RGBA) { img.Set(0, 0, color.RGBA{17, 0, 0, 255}) }, Tolerance{Delta: 16}, 1, true},
+		{"fraction", func(img *image.RGBA) { img.Set(0, 0, color.White) }, Tolerance{Fraction: 0.01}, 1, false},
+		{"over fraction", func(img *image.RGBA) {
+			img.Set(0, 0, color.White)
+			img.Set(1, 0, color.White)
+		}, Tolerance{Fraction: 0.01}, 2, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exp, img := testImage(), testImage()
+			test.f(img)
+			d, err := Compare(exp, img, test.tol)
+			switch {
+			case test.err && err == nil:
+				t.Errorf("expected error")
+			case !test.err && err != nil:
+				t.Errorf("expected no error, got: %v", err)
+			}
+			if test.pixels != 0 && d.Pixels != test.pixels {
+				t.Errorf("expected %d pixels, got: %d", test.pixels, d.Pixels)
+			}
+		})
+	}
+	// offset bounds
+	img := testImage()
+	sub := image.NewRGBA(image.Rect(10, 10, 20, 20))
+	copy(sub.Pix, img.Pix)
+	if _, err := Compare(img, sub, Exact); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if _, err := Compare(img, image.NewRGBA(image.Rect(0, 0, 5, 5)), DefaultTolerance); err == nil {
+		t.Errorf("expected error for size mismatch")
+	}
+}
+
+func TestGolden(t *testing.T) {
+	defer func(update bool) { Update = update }(Update)
+	name := filepath.Join(t.TempDir(), "testdata", "test.png.golden")
+	Update = true
+	Golden(t, name, testImage(), Exact)
+	Update = false
+	Golden(t, name, testImage(), Exact)
+	exp, err := ReadGolden(name)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if b := exp.Bounds(); b.Dx() != 10 || b.Dy() != 10 {
+		t.Errorf("expected 10x10, got: %v", b)
+	}
+}
+
+// testImage returns a 10x10 black image.
+func testImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xff
+	}
+	return img
+}
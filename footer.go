@@ -0,0 +1,85 @@
+package fontimg
+
+import (
+	"strings"
+
+	"github.com/tdewolff/canvas"
+	fontpkg "github.com/tdewolff/font"
+	"rsc.io/qr"
+)
+
+// Footer is an attribution footer block, drawn below the font image's text,
+// for preview sheets that must carry attribution.
+type Footer struct {
+	// Text is the footer text (ie, a copyright and license notice). When
+	// empty, the font's copyright notice and license description are used.
+	Text string
+	// URL is the font's source URL, shown as a QR code to the right of the
+	// text. When empty, the font's vendor URL is used.
+	URL string
+}
+
+// drawFooter draws the options' footer, when set, a line below the canvas'
+// content, using the embedded label font. The text wraps to the content's
+// width, less the QR code. The footer's defaults are read from sfnt when not
+// nil.
+func drawFooter(c *canvas.Canvas, ctx *canvas.Context, sfnt *fontpkg.SFNT, opts *Options) error {
+	if opts.Footer == nil {
+		return nil
+	}
+	text, url := opts.Footer.Text, opts.Footer.URL
+	if sfnt != nil {
+		name := func(id fontpkg.NameID) string {
+			if v := sfnt.Name.Get(id); 0 < len(v) {
+				return strings.TrimSpace(v[0].String())
+			}
+			return ""
+		}
+		if text == "" {
+			text = strings.TrimSpace(name(fontpkg.NameCopyrightNotice) + "\n" + name(fontpkg.NameLicense))
+		}
+		if url == "" {
+			url = name(fontpkg.NameVendorURL)
+		}
+	}
+	if text == "" && url == "" {
+		return nil
+	}
+	lff, err := LabelFont().Load(canvas.FontRegular)
+	if err != nil {
+		return err
+	}
+	label := lff.Face(0.3*float64(opts.Size), opts.FG)
+	lh := label.Metrics().LineHeight
+	// fit to the content, to place the footer below it
+	c.Fit(0)
+	width, y := max(c.W, 20*lh), -lh
+	if url != "" {
+		code, err := qr.Encode(url, qr.M)
+		if err != nil {
+			return err
+		}
+		side := 6 * lh
+		px := side / float64(code.Size)
+		p := new(canvas.Path)
+		for j := range code.Size {
+			for i := range code.Size {
+				if !code.Black(i, j) {
+					continue
+				}
+				x0, y0 := float64(i)*px, side-float64(j+1)*px
+				p.MoveTo(x0, y0)
+				p.LineTo(x0+px, y0)
+				p.LineTo(x0+px, y0+px)
+				p.LineTo(x0, y0+px)
+				p.Close()
+			}
+		}
+		ctx.DrawPath(width-side, y-side, p)
+		width -= side + lh
+	}
+	if text != "" {
+		ctx.DrawText(0, y, canvas.NewTextBox(label, text, width, 0, canvas.Left, canvas.Top, nil))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package fontimg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFooter(t *testing.T) {
+	path := filepath.Join("testdata", "Ubuntu-R.ttf")
+	tests := []struct {
+		name   string
+		font   *Font
+		footer *Footer
+		taller bool
+	}{
+		{"defaults", New(nil, path), &Footer{}, true},
+		{"text", New(nil, path), &Footer{Text: "Licensed under the SIL Open Font License, Version 1.1."}, true},
+		{"url", New(nil, path), &Footer{Text: "OFL", URL: "https://example.com/fonts/ubuntu"}, true},
+		{"bitmap", &Font{Buf: testPSF2(true)}, &Footer{URL: "https://example.com"}, true},
+		{"bitmap defaults", &Font{Buf: testPSF2(true)}, &Footer{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			exp, err := test.font.RasterizeOptions(opts)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			opts.Footer = test.footer
+			img, err := test.font.RasterizeOptions(opts)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if taller := exp.Rect.Dy() < img.Rect.Dy(); taller != test.taller {
+				t.Errorf("expected taller %t, got: %v (without footer %v)", test.taller, img.Rect, exp.Rect)
+			}
+		})
+	}
+	opts := DefaultOptions()
+	opts.Footer = &Footer{URL: "https://example.com"}
+	font := New(nil, path)
+	if CacheKey(font, opts) == CacheKey(font, nil) {
+		t.Errorf("expected footer to produce a different key")
+	}
+}
@@ -0,0 +1,266 @@
+package fontimg
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+	fontpkg "github.com/tdewolff/font"
+	"golang.org/x/text/unicode/runenames"
+)
+
+// GlyphMatch is a glyph matching a glyph search.
+type GlyphMatch struct {
+	// GlyphID is the glyph ID.
+	GlyphID uint16
+	// Name is the glyph name, from the font's post or CFF table.
+	Name string
+	// Runes are the runes mapped to the glyph.
+	Runes []rune
+	// Match is the name the search matched, either the glyph name, or the
+	// Unicode name of a mapped rune (ie, MULTIPLICATION SIGN).
+	Match string
+	// Score is the match score, where lower is better.
+	Score int
+}
+
+// FindGlyph searches the font's glyphs by name, returning the matching glyphs
+// ordered by best match. Names are matched case insensitively, ignoring
+// punctuation, against the glyph names and the Unicode names of the runes
+// mapped to each glyph. Glyphs match when the name is equal to, a prefix of,
+// or (when at least 3 characters) contained by a name, or when each of the name's words is a prefix of a
+// word of the name (ie, "arrow right" matches RIGHTWARDS ARROW).
+func (font *Font) FindGlyph(name string) (_ []GlyphMatch, err error) {
+	defer recoverError(&err)
+	words := glyphWords(name)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("invalid glyph name %q", name)
+	}
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	cmap, err := font.Cmap()
+	if err != nil {
+		return nil, err
+	}
+	runes := make(map[uint16][]rune)
+	for _, e := range cmap {
+		runes[e.GlyphID] = append(runes[e.GlyphID], e.Rune)
+	}
+	var v []GlyphMatch
+	for id := range sfnt.NumGlyphs() {
+		g := GlyphMatch{
+			GlyphID: id,
+			Name:    sfnt.GlyphName(id),
+			Runes:   runes[id],
+			Score:   -1,
+		}
+		candidates := []string{g.Name}
+		for _, r := range g.Runes {
+			candidates = append(candidates, runenames.Name(r))
+		}
+		for _, s := range candidates {
+			if score := glyphScore(words, s); score != -1 && (g.Score == -1 || score < g.Score) {
+				g.Match, g.Score = s, score
+			}
+		}
+		if g.Score != -1 {
+			v = append(v, g)
+		}
+	}
+	slices.SortStableFunc(v, func(a, b GlyphMatch) int {
+		return cmp.Compare(a.Score, b.Score)
+	})
+	return v, nil
+}
+
+// glyphScore returns the match score of the search words against the name,
+// or -1 when not matched.
+func glyphScore(words []string, name string) int {
+	if name == "" {
+		return -1
+	}
+	query, v := strings.Join(words, ""), glyphWords(name)
+	s := strings.Join(v, "")
+	switch {
+	case s == query:
+		return 0
+	case strings.HasPrefix(s, query):
+		return 1
+	case 3 <= len(query) && strings.Contains(s, query):
+		return 2
+	}
+	for _, word := range words {
+		if !slices.ContainsFunc(v, func(w string) bool {
+			return strings.HasPrefix(w, word)
+		}) {
+			return -1
+		}
+	}
+	return 3
+}
+
+// glyphWords splits the name into lower case words, separated by any
+// non-letter or digit.
+func glyphWords(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Glyphs returns the font's glyphs, ordered by glyph ID, for use with
+// [Font.GlyphSheet]. When ranges are provided, only the glyphs mapped from
+// runes in the ranges (ie, [unicode.Greek]) are returned, ordered by rune.
+func (font *Font) Glyphs(ranges ...*unicode.RangeTable) (_ []GlyphMatch, err error) {
+	defer recoverError(&err)
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	cmap, err := font.Cmap()
+	if err != nil {
+		return nil, err
+	}
+	runes := make(map[uint16][]rune)
+	var ids []uint16
+	for _, e := range cmap {
+		if len(ranges) != 0 && !unicode.In(e.Rune, ranges...) {
+			continue
+		}
+		if _, ok := runes[e.GlyphID]; !ok {
+			ids = append(ids, e.GlyphID)
+		}
+		runes[e.GlyphID] = append(runes[e.GlyphID], e.Rune)
+	}
+	if len(ranges) == 0 {
+		ids = ids[:0]
+		for id := range sfnt.NumGlyphs() {
+			ids = append(ids, id)
+		}
+	}
+	v := make([]GlyphMatch, len(ids))
+	for i, id := range ids {
+		v[i] = GlyphMatch{
+			GlyphID: id,
+			Name:    sfnt.GlyphName(id),
+			Runes:   runes[id],
+		}
+	}
+	return v, nil
+}
+
+// GlyphGrid rasterizes a glyph sheet of the font's glyphs using the options,
+// each labeled with its glyph ID, name, and codepoints, showing the font's
+// full coverage (ie, of symbol and icon fonts). When ranges are provided,
+// only the glyphs mapped from runes in the ranges are shown. See
+// [Font.Glyphs] and [Font.GlyphSheet].
+func (font *Font) GlyphGrid(opts *Options, ranges ...*unicode.RangeTable) (*image.RGBA, error) {
+	v, err := font.Glyphs(ranges...)
+	if err != nil {
+		return nil, err
+	}
+	return font.RasterizeGlyphSheet(v, opts)
+}
+
+// RasterizeGlyphSheet rasterizes a glyph sheet of the matched glyphs using the
+// options. See [Font.GlyphSheet].
+func (font *Font) RasterizeGlyphSheet(matches []GlyphMatch, opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := font.GlyphSheet(matches, opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// GlyphSheet lays out the matched glyphs (see [Font.FindGlyph]) on a canvas,
+// 8 per row, each labeled with its glyph ID, name, and mapped runes using the
+// embedded label font. Glyphs are drawn directly from their outlines, so
+// unmapped glyphs (ie, alternates) are shown. When opts is nil, the default
+// options will be used.
+func (font *Font) GlyphSheet(matches []GlyphMatch, opts *Options) (*canvas.Canvas, error) {
+	return font.glyphSheet(matches, opts, nil)
+}
+
+// glyphSheet lays out the glyph sheet, calling decorate (when not nil) after
+// drawing each glyph with the glyph's origin and the scale from font units.
+func (font *Font) glyphSheet(matches []GlyphMatch, opts *Options, decorate func(ctx *canvas.Context, id uint16, x, y, scale float64) error) (_ *canvas.Canvas, err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no glyphs")
+	}
+	ff, err := font.Load(opts.Style)
+	if err != nil {
+		return nil, err
+	}
+	lff, err := LabelFont().Load(canvas.FontRegular)
+	if err != nil {
+		return nil, err
+	}
+	face := ff.Face(float64(opts.Size), opts.FG, opts.Style, opts.Variant)
+	label := lff.Face(0.25*float64(opts.Size), opts.FG)
+	sfnt := face.Font.SFNT
+	// create canvas and context
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	ctx.SetFillColor(opts.FG)
+	// cells are 1.5 em square, plus the labels
+	em := float64(opts.Size) * 25.4 / 72
+	lh := label.Metrics().LineHeight
+	cell := 1.5 * em
+	for _, m := range matches {
+		cell = max(cell, label.TextWidth(glyphLabel(m))+lh)
+	}
+	for i, m := range matches {
+		if sfnt.NumGlyphs() <= m.GlyphID {
+			return nil, fmt.Errorf("invalid glyph %d", m.GlyphID)
+		}
+		x, y := float64(i%8)*cell, -float64(i/8)*(cell+3*lh)
+		// draw glyph centered in cell, on a baseline at 0.5 em from the bottom
+		p := new(canvas.Path)
+		adv := float64(sfnt.GlyphAdvance(m.GlyphID)) * face.MmPerEm
+		x0, y0 := x+(cell-adv)/2, y-cell+em/2
+		if err := sfnt.GlyphPath(p, m.GlyphID, 0, x0, y0, face.MmPerEm, fontpkg.NoHinting); err != nil {
+			return nil, err
+		}
+		ctx.DrawPath(0, 0, p)
+		if decorate != nil {
+			if err := decorate(ctx, m.GlyphID, x0, y0, face.MmPerEm); err != nil {
+				return nil, err
+			}
+			ctx.SetFillColor(opts.FG)
+		}
+		// draw labels
+		runes := make([]string, len(m.Runes))
+		for j, r := range m.Runes {
+			runes[j] = fmt.Sprintf("U+%04X", r)
+		}
+		for j, s := range []string{glyphLabel(m), strings.Join(runes, " ")} {
+			ctx.DrawText(x+cell/2, y-cell-float64(j+1)*lh, canvas.NewTextLine(label, s, canvas.Center))
+		}
+	}
+	// fit canvas to context
+	c.Fit(opts.Margin)
+	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
+
+// glyphLabel returns the glyph sheet label for the match.
+func glyphLabel(m GlyphMatch) string {
+	return fmt.Sprintf("#%d %s", m.GlyphID, m.Name)
+}
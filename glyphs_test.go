@@ -0,0 +1,105 @@
+package fontimg
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"unicode"
+)
+
+func TestFindGlyph(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	tests := []struct {
+		name  string
+		exp   string
+		runes []rune
+	}{
+		{"multiply", "multiply", []rune{'×'}},
+		{"Multiplication Sign", "multiply", []rune{'×'}},
+		{"multiplication", "multiply", []rune{'×'}},
+		{"latin small ligature fi", "f_i", []rune{'ﬁ'}},
+		{"sign multiplication", "multiply", []rune{'×'}},
+		{"LATIN SMALL LETTER X", "x", []rune{'x'}},
+	}
+	for _, test := range tests {
+		v, err := font.FindGlyph(test.name)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(v) == 0 {
+			t.Fatalf("%q expected matches", test.name)
+		}
+		if v[0].Name != test.exp || !slices.Equal(v[0].Runes, test.runes) {
+			t.Errorf("%q expected %s %q, got: %+v", test.name, test.exp, test.runes, v[0])
+		}
+		for i := 1; i < len(v); i++ {
+			if v[i].Score < v[i-1].Score {
+				t.Errorf("%q expected matches ordered by score", test.name)
+			}
+		}
+	}
+	v, err := font.FindGlyph("blahblahblah")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(v) != 0 {
+		t.Errorf("expected no matches, got: %v", v)
+	}
+	if _, err := font.FindGlyph("  "); err == nil {
+		t.Errorf("expected error for empty name")
+	}
+	v, err = font.FindGlyph("multiply")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	img, err := font.RasterizeGlyphSheet(v, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+		t.Errorf("expected non-empty image, got: %v", b)
+	}
+	if _, err := font.RasterizeGlyphSheet(nil, nil); err == nil {
+		t.Errorf("expected error for no glyphs")
+	}
+}
+
+func TestGlyphs(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	all, err := font.Glyphs()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(all) != 1264 {
+		t.Fatalf("expected 1264 glyphs, got: %d", len(all))
+	}
+	for i, g := range all {
+		if int(g.GlyphID) != i {
+			t.Fatalf("expected glyph %d, got: %d", i, g.GlyphID)
+		}
+	}
+	digits, err := font.Glyphs(unicode.Digit)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(digits) < 10 || !slices.Equal(digits[0].Runes, []rune{'0'}) {
+		t.Fatalf("expected digits starting at 0, got: %+v", digits)
+	}
+	for _, g := range digits {
+		for _, r := range g.Runes {
+			if !unicode.IsDigit(r) {
+				t.Errorf("expected digit, got: %q", r)
+			}
+		}
+	}
+	img, err := font.GlyphGrid(nil, &unicode.RangeTable{R16: []unicode.Range16{{Lo: '0', Hi: '9', Stride: 1}}})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() <= b.Dy() {
+		t.Errorf("expected 2 rows of glyphs, got: %v", b)
+	}
+	if _, err := font.GlyphGrid(nil, unicode.Hangul); err == nil {
+		t.Errorf("expected error for no glyphs")
+	}
+}
@@ -0,0 +1,126 @@
+package fontimg
+
+import (
+	"encoding/binary"
+	"regexp"
+
+	fontpkg "github.com/tdewolff/font"
+)
+
+// Hinting is a report of a font's TrueType hinting.
+type Hinting struct {
+	// Fpgm, Prep and Cvt are the sizes in bytes of the font program, control
+	// value program and control value tables.
+	Fpgm, Prep, Cvt int
+	// Glyphs is the number of glyphs with instructions.
+	Glyphs int
+	// Instructions is the total size in bytes of the glyph instructions.
+	Instructions int
+	// Autohinter is the autohinter signature (for example, "ttfautohint
+	// (v1.8.3)"), when the font was autohinted.
+	Autohinter string
+}
+
+// Hinted returns true when the font has hinting instructions.
+func (h *Hinting) Hinted() bool {
+	return h.Fpgm != 0 || h.Prep != 0 || h.Glyphs != 0
+}
+
+// Autohinted returns true when the font was autohinted.
+func (h *Hinting) Autohinted() bool {
+	return h.Autohinter != ""
+}
+
+// ttfautohintRE matches the ttfautohint signature appended to the version
+// string.
+var ttfautohintRE = regexp.MustCompile(`ttfautohint \(v[^)]*\)`)
+
+// Hinting reports the presence and size of the font's hinting tables and
+// glyph instructions. CFF fonts report no TrueType hinting.
+func (font *Font) Hinting() (_ *Hinting, err error) {
+	defer recoverError(&err)
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	h := &Hinting{
+		Fpgm: len(sfnt.Tables["fpgm"]),
+		Prep: len(sfnt.Tables["prep"]),
+		Cvt:  len(sfnt.Tables["cvt "]),
+	}
+	if sfnt.Glyf != nil {
+		for id := range sfnt.NumGlyphs() {
+			if n := glyphInstructions(sfnt.Glyf.Get(id)); n != 0 {
+				h.Glyphs++
+				h.Instructions += n
+			}
+		}
+	}
+	for _, v := range sfnt.Name.Get(fontpkg.NameVersion) {
+		if s := ttfautohintRE.FindString(v.String()); s != "" {
+			h.Autohinter = s
+			break
+		}
+	}
+	if _, ok := sfnt.Tables["TTFA"]; ok && h.Autohinter == "" {
+		h.Autohinter = "ttfautohint"
+	}
+	return h, nil
+}
+
+// Composite glyph flags.
+const (
+	glyfArgWords       = 0x0001
+	glyfScale          = 0x0008
+	glyfMoreComponents = 0x0020
+	glyfXYScale        = 0x0040
+	glyfTwoByTwo       = 0x0080
+	glyfInstructions   = 0x0100
+)
+
+// glyphInstructions returns the length of the instructions of the raw glyf
+// glyph data. Returns 0 for empty or truncated glyphs.
+func glyphInstructions(b []byte) int {
+	if len(b) < 10 {
+		return 0
+	}
+	contours := int16(binary.BigEndian.Uint16(b))
+	if 0 <= contours {
+		n, err := u16(b, 10+2*int(contours))
+		if err != nil {
+			return 0
+		}
+		return int(n)
+	}
+	// composite glyph: skip components
+	for i := 10; ; {
+		flags, err := u16(b, i)
+		if err != nil {
+			return 0
+		}
+		i += 4
+		if flags&glyfArgWords != 0 {
+			i += 4
+		} else {
+			i += 2
+		}
+		switch {
+		case flags&glyfScale != 0:
+			i += 2
+		case flags&glyfXYScale != 0:
+			i += 4
+		case flags&glyfTwoByTwo != 0:
+			i += 8
+		}
+		if flags&glyfMoreComponents == 0 {
+			if flags&glyfInstructions == 0 {
+				return 0
+			}
+			n, err := u16(b, i)
+			if err != nil {
+				return 0
+			}
+			return int(n)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package fontimg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHinting(t *testing.T) {
+	for _, test := range testFonts(t) {
+		t.Run(test.name, func(t *testing.T) {
+			font := New(nil, test.path)
+			h, err := font.Hinting()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			t.Logf("hinting: %+v", h)
+			if h.Hinted() && h.Glyphs == 0 && h.Fpgm == 0 {
+				t.Errorf("expected fpgm or glyph instructions")
+			}
+			if h.Glyphs != 0 && h.Instructions == 0 {
+				t.Errorf("expected instructions for %d glyphs", h.Glyphs)
+			}
+			var buf bytes.Buffer
+			font.WriteYAML(&buf)
+			if !strings.Contains(buf.String(), "hinting:\n") {
+				t.Errorf("expected hinting, got:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+func TestGlyphInstructions(t *testing.T) {
+	tests := []struct {
+		b   []byte
+		exp int
+	}{
+		{nil, 0},
+		// simple glyph, 1 contour, 3 bytes of instructions
+		{[]byte{0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 0, 3}, 3},
+		// simple glyph, truncated
+		{[]byte{0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2}, 0},
+		// composite glyph, byte args, scale, with instructions
+		{[]byte{0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0, 0x01, 0x08, 0, 5, 1, 2, 0x40, 0, 0, 7}, 7},
+		// composite glyph, two components, word args, no instructions
+		{[]byte{0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x21, 0, 5, 0, 1, 0, 2, 0, 0x01, 0, 6, 0, 1, 0, 2}, 0},
+	}
+	for i, test := range tests {
+		if n := glyphInstructions(test.b); n != test.exp {
+			t.Errorf("test %d expected %d, got: %d", i, test.exp, n)
+		}
+	}
+}
+
+func TestAutohinter(t *testing.T) {
+	tests := []struct {
+		s, exp string
+	}{
+		{"Version 2.000", ""},
+		{"Version 2.000; ttfautohint (v1.8.3)", "ttfautohint (v1.8.3)"},
+		{"Version 1.1; ttfautohint (v1.5) -l 8 -r 50 -G 200 -x 14", "ttfautohint (v1.5)"},
+	}
+	for _, test := range tests {
+		if s := ttfautohintRE.FindString(test.s); s != test.exp {
+			t.Errorf("%q expected %q, got: %q", test.s, test.exp, s)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package fontimg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/language"
+)
+
+// Hyphenator hyphenates words using Liang's algorithm with TeX hyphenation
+// patterns.
+type Hyphenator struct {
+	// LeftMin is the minimum number of runes before a hyphen.
+	LeftMin int
+	// RightMin is the minimum number of runes after a hyphen.
+	RightMin int
+
+	patterns   map[string][]uint8
+	exceptions map[string][]int
+	maxLen     int
+}
+
+// NewHyphenator creates a hyphenator from TeX hyphenation patterns (ie, the
+// contents of a hyph-en-us.pat.txt file from the hyph-utf8 project). Patterns
+// are read as whitespace separated tokens, with % starting a comment. Tokens
+// containing a hyphen (ie, "as-so-ciate") are treated as hyphenation
+// exceptions. TeX \patterns{} and \hyphenation{} blocks are also accepted.
+func NewHyphenator(r io.Reader) (*Hyphenator, error) {
+	h := &Hyphenator{
+		LeftMin:    2,
+		RightMin:   3,
+		patterns:   make(map[string][]uint8),
+		exceptions: make(map[string][]int),
+	}
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line, _, _ := strings.Cut(s.Text(), "%")
+		for tok := range strings.FieldsSeq(line) {
+			tok = strings.TrimPrefix(strings.TrimPrefix(tok, `\patterns{`), `\hyphenation{`)
+			if tok = strings.TrimSuffix(tok, "}"); tok != "" {
+				h.add(tok)
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// add adds a pattern or exception.
+func (h *Hyphenator) add(tok string) {
+	tok = strings.ToLower(tok)
+	if strings.Contains(tok, "-") {
+		var word []rune
+		var points []int
+		for _, r := range tok {
+			if r == '-' {
+				points = append(points, len(word))
+				continue
+			}
+			word = append(word, r)
+		}
+		h.exceptions[string(word)] = points
+		return
+	}
+	var letters []rune
+	values := []uint8{0}
+	for _, r := range tok {
+		if '0' <= r && r <= '9' {
+			values[len(values)-1] = uint8(r - '0')
+			continue
+		}
+		letters, values = append(letters, r), append(values, 0)
+	}
+	h.patterns[string(letters)] = values
+	h.maxLen = max(h.maxLen, len(letters))
+}
+
+// Hyphenate returns the positions (in runes) where word may be hyphenated.
+func (h *Hyphenator) Hyphenate(word string) []int {
+	word = strings.ToLower(word)
+	if points, ok := h.exceptions[word]; ok {
+		return slices.Clone(points)
+	}
+	w := []rune("." + word + ".")
+	n := len(w) - 2
+	if n < h.LeftMin+h.RightMin {
+		return nil
+	}
+	values := make([]uint8, len(w)+1)
+	for i := range w {
+		for j := i + 1; j <= min(len(w), i+h.maxLen); j++ {
+			if p, ok := h.patterns[string(w[i:j])]; ok {
+				for k, v := range p {
+					values[i+k] = max(values[i+k], v)
+				}
+			}
+		}
+	}
+	// values[i+1] is the value between word[i-1] and word[i]
+	var points []int
+	for i := max(1, h.LeftMin); i <= n-h.RightMin; i++ {
+		if values[i+1]%2 == 1 {
+			points = append(points, i)
+		}
+	}
+	return points
+}
+
+// points returns the hyphenation points of word, ignoring leading and
+// trailing punctuation (ie, quotes).
+func (h *Hyphenator) points(word []rune) []int {
+	i, j := 0, len(word)
+	for i < j && !unicode.IsLetter(word[i]) {
+		i++
+	}
+	for i < j && !unicode.IsLetter(word[j-1]) {
+		j--
+	}
+	for _, r := range word[i:j] {
+		if !unicode.IsLetter(r) {
+			return nil
+		}
+	}
+	points := h.Hyphenate(string(word[i:j]))
+	for k := range points {
+		points[k] += i
+	}
+	return points
+}
+
+// RegisterHyphenation registers the hyphenator for the language (ie, "en-US"
+// or "de"), for use with [Paragraph.Language].
+func RegisterHyphenation(lang string, h *Hyphenator) error {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return err
+	}
+	hyphenators.Lock()
+	defer hyphenators.Unlock()
+	hyphenators.m[tag.String()] = h
+	return nil
+}
+
+// hyphenator returns the registered hyphenator for the language, falling
+// back to the language's base (ie, "en" for "en-GB").
+func hyphenator(lang string) (*Hyphenator, error) {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return nil, err
+	}
+	hyphenators.RLock()
+	defer hyphenators.RUnlock()
+	if h, ok := hyphenators.m[tag.String()]; ok {
+		return h, nil
+	}
+	if base, _ := tag.Base(); base.String() != tag.String() {
+		if h, ok := hyphenators.m[base.String()]; ok {
+			return h, nil
+		}
+	}
+	return nil, fmt.Errorf("no hyphenation patterns registered for %q", lang)
+}
+
+// hyphenators are the registered hyphenators.
+var hyphenators = struct {
+	sync.RWMutex
+	m map[string]*Hyphenator
+}{
+	m: make(map[string]*Hyphenator),
+}
@@ -0,0 +1,50 @@
+package fontimg
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testPatterns are the example patterns from Liang's thesis.
+const testPatterns = `% example patterns
+\patterns{
+hy3ph he2n hena4 hen5at 1na n2at 1tio 2io o2n
+}
+\hyphenation{ta-ble}`
+
+func TestHyphenate(t *testing.T) {
+	h, err := NewHyphenator(strings.NewReader(testPatterns))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		word string
+		exp  []int
+	}{
+		{"hyphenation", []int{2, 6}},
+		{"Hyphenation", []int{2, 6}},
+		{"table", []int{2}},
+		{"hy", nil},
+		{"abc", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.word, func(t *testing.T) {
+			if points := h.Hyphenate(test.word); !slices.Equal(points, test.exp) {
+				t.Errorf("expected %v, got: %v", test.exp, points)
+			}
+		})
+	}
+	if points, exp := h.points([]rune(`"hyphenation,"`)), []int{3, 7}; !slices.Equal(points, exp) {
+		t.Errorf("expected %v, got: %v", exp, points)
+	}
+	// exceptions are not modified
+	for range 2 {
+		if points, exp := h.points([]rune(`"table"`)), []int{3}; !slices.Equal(points, exp) {
+			t.Errorf("expected %v, got: %v", exp, points)
+		}
+	}
+	if points, exp := h.Hyphenate("table"), []int{2}; !slices.Equal(points, exp) {
+		t.Errorf("expected %v, got: %v", exp, points)
+	}
+}
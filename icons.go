@@ -0,0 +1,110 @@
+package fontimg
+
+import (
+	"fmt"
+	"image"
+	"regexp"
+	"unicode"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// Icon is an icon of an icon font.
+type Icon struct {
+	// Rune is the rune mapped to the icon.
+	Rune rune
+	// GlyphID is the icon's glyph ID.
+	GlyphID uint16
+	// Name is the icon's glyph name, from the font's post or CFF table, or
+	// the rune's uniXXXX name when the glyph is not named.
+	Name string
+}
+
+// IsIcon returns true when the font is an icon font, ie, a font mapping most
+// of its runes to the Private Use Area (as FontAwesome does), or with most
+// of its glyphs named as icons (ie, icon-home, fa-user).
+func (font *Font) IsIcon() (bool, error) {
+	cmap, err := font.Cmap()
+	if err != nil {
+		return false, err
+	}
+	var n, pua, named int
+	for _, e := range cmap {
+		if unicode.IsSpace(e.Rune) || unicode.IsControl(e.Rune) {
+			continue
+		}
+		n++
+		if unicode.Is(unicode.Co, e.Rune) {
+			pua++
+		}
+		if iconRE.MatchString(e.GlyphName) {
+			named++
+		}
+	}
+	return n != 0 && (n <= 2*pua || n <= 2*named), nil
+}
+
+// Icons returns the font's icons, ie, the runes mapped in the Private Use
+// Area, or mapped to glyphs named as icons, sorted by rune.
+func (font *Font) Icons() ([]Icon, error) {
+	cmap, err := font.Cmap()
+	if err != nil {
+		return nil, err
+	}
+	var v []Icon
+	for _, e := range cmap {
+		if !unicode.Is(unicode.Co, e.Rune) && !iconRE.MatchString(e.GlyphName) {
+			continue
+		}
+		name := e.GlyphName
+		if name == "" {
+			name = fmt.Sprintf("uni%04X", e.Rune)
+		}
+		v = append(v, Icon{
+			Rune:    e.Rune,
+			GlyphID: e.GlyphID,
+			Name:    name,
+		})
+	}
+	return v, nil
+}
+
+// RasterizeIconSheet rasterizes an icon sheet of the font's icons using the
+// options. See [Font.IconSheet].
+func (font *Font) RasterizeIconSheet(opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := font.IconSheet(opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// IconSheet lays out the font's icons (see [Font.Icons]) on a canvas, 8 per
+// row, each labeled with its glyph ID, name, and code point using the
+// embedded label font, for auditing an icon font's icon set. When opts is
+// nil, the default options will be used.
+func (font *Font) IconSheet(opts *Options) (*canvas.Canvas, error) {
+	icons, err := font.Icons()
+	if err != nil {
+		return nil, err
+	}
+	if len(icons) == 0 {
+		return nil, fmt.Errorf("no icons")
+	}
+	matches := make([]GlyphMatch, len(icons))
+	for i, icon := range icons {
+		matches[i] = GlyphMatch{
+			GlyphID: icon.GlyphID,
+			Name:    icon.Name,
+			Runes:   []rune{icon.Rune},
+		}
+	}
+	return font.glyphSheet(matches, opts, nil)
+}
+
+// iconRE matches glyph names of icons, as used by common icon fonts.
+var iconRE = regexp.MustCompile(`^(icon|fa[bsr]?|glyphicons?|mdi|bi|ion|octicon|material)[-_]`)
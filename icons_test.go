@@ -0,0 +1,86 @@
+package fontimg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsIcon(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		name string
+		font *Font
+		exp  bool
+	}{
+		{"ttf", New(buf, ""), false},
+		{"mono", New(nil, filepath.Join("testdata", "NotoMono-Regular.ttf")), false},
+		{"pua", New(testSymbol(t, buf, 40), ""), true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			icon, err := test.font.IsIcon()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if icon != test.exp {
+				t.Errorf("expected %t, got: %t", test.exp, icon)
+			}
+		})
+	}
+}
+
+func TestIcons(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	font := New(testSymbol(t, buf, 40), "")
+	icons, err := font.Icons()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(icons) != 40 {
+		t.Fatalf("expected 40 icons, got: %d", len(icons))
+	}
+	for i, icon := range icons {
+		if exp := 0xe000 + rune(i); icon.Rune != exp || icon.GlyphID != uint16(i+1) || icon.Name == "" {
+			t.Errorf("expected U+%04X glyph %d with name, got: %+v", exp, i+1, icon)
+		}
+	}
+	img, err := font.RasterizeIconSheet(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if img.Bounds().Empty() {
+		t.Errorf("expected non-empty image")
+	}
+	if _, err := New(nil, filepath.Join("testdata", "NotoMono-Regular.ttf")).IconSheet(nil); err == nil {
+		t.Errorf("expected error for font without icons")
+	}
+}
+
+func TestIconRE(t *testing.T) {
+	tests := []struct {
+		name string
+		exp  bool
+	}{
+		{"icon-home", true},
+		{"fa-user", true},
+		{"fas_star", true},
+		{"glyphicon-ok", true},
+		{"mdi-account", true},
+		{"uniE001", false},
+		{"iconic", false},
+		{"A", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if ok := iconRE.MatchString(test.name); ok != test.exp {
+			t.Errorf("%q: expected %t, got: %t", test.name, test.exp, ok)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package fontimg
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/go-text/typesetting/di"
+	gotext "github.com/go-text/typesetting/font"
+	"github.com/go-text/typesetting/shaping"
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// ScriptRun is a run of text of a single script and direction, as segmented
+// for shaping.
+type ScriptRun struct {
+	// Start and End are the byte offsets of the run in the text.
+	Start, End int
+	// Text is the run's text.
+	Text string
+	// Script is the run's ISO 15924 script code (ie, Latn, Arab).
+	Script string
+	// RTL is whether the run is right-to-left.
+	RTL bool
+	// Missing are the run's characters not mapped by the font, excluding
+	// whitespace.
+	Missing []rune
+}
+
+// Supported returns whether all the run's characters are mapped by the font.
+func (run ScriptRun) Supported() bool {
+	return len(run.Missing) == 0
+}
+
+// ScriptRuns itemizes the text into the script and direction runs it would
+// be segmented into for shaping, in logical order, with the characters of
+// each run not mapped by the font. Characters common to scripts (ie,
+// punctuation, digits, whitespace) are merged into the surrounding runs.
+func (font *Font) ScriptRuns(text string) (_ []ScriptRun, err error) {
+	defer recoverError(&err)
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return nil, nil
+	}
+	// byte offsets of each rune
+	offsets := make([]int, 0, len(runes)+1)
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(text))
+	var seg shaping.Segmenter
+	var runs []ScriptRun
+	for _, in := range seg.Split(shaping.Input{
+		Text:      runes,
+		RunEnd:    len(runes),
+		Direction: di.DirectionLTR,
+	}, noFaces{}) {
+		run := ScriptRun{
+			Start:  offsets[in.RunStart],
+			End:    offsets[in.RunEnd],
+			Script: in.Script.String(),
+			RTL:    in.Direction.Progression() == di.TowardTopLeft,
+		}
+		run.Text = text[run.Start:run.End]
+		for _, r := range run.Text {
+			if !unicode.IsSpace(r) && notdef(sfnt, r) && !slices.Contains(run.Missing, r) {
+				run.Missing = append(run.Missing, r)
+			}
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// noFaces is a font map resolving no faces, for itemizing text by script and
+// direction only.
+type noFaces struct{}
+
+// ResolveFace satisfies the [shaping.Fontmap] interface.
+func (noFaces) ResolveFace(rune) *gotext.Face {
+	return nil
+}
+
+// RasterizeScriptRuns rasterizes a script itemization report using the
+// options. See [Font.ScriptRunChart].
+func (font *Font) RasterizeScriptRuns(opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := font.ScriptRunChart(opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// ScriptRunChart lays out a script itemization report on a canvas, rendering
+// sample multilingual text with each script and direction run (see
+// [Font.ScriptRuns]) underlined and colored by script, above a legend of the
+// runs labeled using the embedded label font. The legend lists each run's
+// script, direction and byte offsets in its line, and whether the font maps all of the
+// run's characters or the code points it is missing. Runs are drawn in
+// visual order for a left-to-right paragraph. The options' text (when not
+// empty) is used as the sample text. When opts is nil, the default options
+// will be used.
+func (font *Font) ScriptRunChart(opts *Options) (_ *canvas.Canvas, err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	text := fallbackText
+	if strings.TrimSpace(opts.Text) != "" {
+		text = opts.Text
+	}
+	ff, err := font.Load(opts.Style)
+	if err != nil {
+		return nil, err
+	}
+	lff, err := LabelFont().Load(canvas.FontRegular)
+	if err != nil {
+		return nil, err
+	}
+	label, p := lff.Face(0.4*float64(opts.Size), opts.FG), labelPrinter(opts.Language)
+	face := ff.Face(float64(opts.Size), opts.FG, opts.Style, opts.Variant)
+	// create canvas and context
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	lh, m := label.Metrics().LineHeight, face.Metrics()
+	colors := make(map[string]color.Color)
+	var legend []ScriptRun
+	y := float64(0)
+	for line := range strings.SplitSeq(text, "\n") {
+		runs, err := font.ScriptRuns(line)
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range runs {
+			if _, ok := colors[run.Script]; !ok {
+				colors[run.Script] = scriptColors[len(colors)%len(scriptColors)]
+			}
+		}
+		x := float64(0)
+		for _, run := range visualRuns(runs) {
+			// draw trailing space separately, as right-to-left runs are
+			// reordered
+			text := strings.TrimRightFunc(run.Text, unicode.IsSpace)
+			txt := canvas.NewTextLine(face, text, canvas.Left)
+			w := txt.Bounds().X1
+			ctx.SetFillColor(opts.FG)
+			ctx.DrawText(x, y, txt)
+			// underline
+			ctx.SetFillColor(colors[run.Script])
+			ctx.DrawPath(x, y-m.Descent-lh/4, canvas.Rectangle(w, lh/8))
+			x += w + face.TextWidth(run.Text[len(text):])
+		}
+		legend = append(legend, runs...)
+		y -= m.LineHeight + lh/2
+	}
+	// draw legend
+	for _, run := range legend {
+		dir := p.Sprintf("ltr")
+		if run.RTL {
+			dir = p.Sprintf("rtl")
+		}
+		status := p.Sprintf("supported")
+		if !run.Supported() {
+			v := make([]string, len(run.Missing))
+			for i, r := range run.Missing {
+				v[i] = fmt.Sprintf("U+%04X", r)
+			}
+			status = p.Sprintf("%d missing: %s", len(run.Missing), strings.Join(v, " "))
+		}
+		ctx.SetFillColor(colors[run.Script])
+		ctx.DrawPath(0, y, canvas.Rectangle(lh/2, lh/2))
+		ctx.DrawText(lh, y, canvas.NewTextLine(label, fmt.Sprintf("%s %s %d-%d: %s", run.Script, dir, run.Start, run.End, status), canvas.Left))
+		y -= lh
+	}
+	// fit canvas to context
+	c.Fit(opts.Margin)
+	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
+
+// visualRuns returns the runs in visual order for a left-to-right paragraph,
+// reversing each sequence of right-to-left runs.
+func visualRuns(runs []ScriptRun) []ScriptRun {
+	v := slices.Clone(runs)
+	for i := 0; i < len(v); {
+		if !v[i].RTL {
+			i++
+			continue
+		}
+		j := i
+		for j < len(v) && v[j].RTL {
+			j++
+		}
+		slices.Reverse(v[i:j])
+		i = j
+	}
+	return v
+}
+
+// scriptColors are the script run colors.
+var scriptColors = []color.Color{
+	color.NRGBA{R: 0x30, G: 0x50, B: 0xd0, A: 0xff},
+	color.NRGBA{R: 0x30, G: 0x90, B: 0x30, A: 0xff},
+	color.NRGBA{R: 0xc0, G: 0x80, B: 0x00, A: 0xff},
+	color.NRGBA{R: 0x90, G: 0x30, B: 0xb0, A: 0xff},
+	color.NRGBA{R: 0x20, G: 0x90, B: 0xa0, A: 0xff},
+	color.NRGBA{R: 0xd0, G: 0x30, B: 0x80, A: 0xff},
+}
@@ -0,0 +1,85 @@
+package fontimg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestScriptRuns(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	text := "Hello, Привет שלום 123 你好"
+	runs, err := font.ScriptRuns(text)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := []struct {
+		script    string
+		rtl       bool
+		supported bool
+	}{
+		{"Latn", false, true},
+		{"Cyrl", false, true},
+		{"Hebr", true, false},
+		{"Hani", false, false},
+	}
+	if len(runs) != len(exp) {
+		t.Fatalf("expected %d runs, got: %d %+v", len(exp), len(runs), runs)
+	}
+	end := 0
+	for i, run := range runs {
+		switch {
+		case run.Start != end:
+			t.Errorf("run %d expected start %d, got: %d", i, end, run.Start)
+		case text[run.Start:run.End] != run.Text:
+			t.Errorf("run %d expected text %q, got: %q", i, text[run.Start:run.End], run.Text)
+		case run.Script != exp[i].script:
+			t.Errorf("run %d expected script %s, got: %s", i, exp[i].script, run.Script)
+		case run.RTL != exp[i].rtl:
+			t.Errorf("run %d expected rtl %t, got: %t", i, exp[i].rtl, run.RTL)
+		case run.Supported() != exp[i].supported:
+			t.Errorf("run %d expected supported %t, got: %q", i, exp[i].supported, run.Missing)
+		}
+		end = run.End
+	}
+	if end != len(text) {
+		t.Errorf("expected end %d, got: %d", len(text), end)
+	}
+	if runs, err := font.ScriptRuns(""); err != nil || len(runs) != 0 {
+		t.Errorf("expected no runs, got: %v %v", runs, err)
+	}
+}
+
+func TestScriptRunChart(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	c, err := font.ScriptRunChart(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := countGlyphs(c); n == 0 {
+		t.Errorf("expected glyphs")
+	}
+	img, err := font.RasterizeScriptRuns(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+		t.Errorf("expected non-empty image, got: %v", b)
+	}
+}
+
+func TestVisualRuns(t *testing.T) {
+	runs := []ScriptRun{
+		{Text: "a"},
+		{Text: "b", RTL: true},
+		{Text: "c", RTL: true},
+		{Text: "d"},
+		{Text: "e", RTL: true},
+	}
+	var s string
+	for _, run := range visualRuns(runs) {
+		s += run.Text
+	}
+	if exp := "acbde"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+}
@@ -0,0 +1,252 @@
+package fontimg
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// labelCatalog is the message catalog of the built-in labels, keyed by the
+// English label.
+var labelCatalog = func() *catalog.Builder {
+	b := catalog.NewBuilder(catalog.Fallback(language.English))
+	for lang, m := range labelTranslations {
+		for key, msg := range m {
+			if err := b.SetString(language.MustParse(lang), key, msg); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return b
+}()
+
+// RegisterLabels registers translations of the built-in labels for the
+// language (ie, "de", "pt-BR"), keyed by the English label (ie, "%d glyphs",
+// "not mapped"), adding to or replacing the language's existing
+// translations. Labels are drawn with the embedded label font (see
+// [LabelFont]), which only covers Latin, Greek and Cyrillic scripts.
+func RegisterLabels(lang string, labels map[string]string) error {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return fmt.Errorf("invalid language %q: %v", lang, err)
+	}
+	for key, msg := range labels {
+		if err := labelCatalog.SetString(tag, key, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labelPrinter returns the printer for the built-in labels in the language
+// (see [Options.Language]), formatting numbers for the language. When the
+// language is empty or invalid, English is used.
+func labelPrinter(lang string) *message.Printer {
+	tag := language.English
+	if lang != "" {
+		if t, err := language.Parse(lang); err == nil {
+			tag = t
+		}
+	}
+	return message.NewPrinter(tag, message.Catalog(labelCatalog))
+}
+
+// labelTranslations are the translations of the built-in labels, by
+// language. Weight names (ie, "Bold") are not translated, as they are the
+// names used by fonts.
+var labelTranslations = map[string]map[string]string{
+	"de": {
+		"%d glyphs":   "%d Glyphen",
+		"not mapped":  "nicht zugeordnet",
+		"Upright":     "Aufrecht",
+		"Italic":      "Kursiv",
+		"synthesized": "synthetisiert",
+		"x-height":    "x-Höhe",
+		"cap height":  "Versalhöhe",
+		"ascender":    "Oberlänge",
+		"descender":   "Unterlänge",
+		"avg":         "Mittel",
+		"space":       "Leerzeichen",
+		"avg %.2f em, a-z %.2f em, space %.2f em": "Mittel %.2f em, a-z %.2f em, Leerzeichen %.2f em",
+		"kerning on":  "Unterschneidung an",
+		"kerning off": "Unterschneidung aus",
+		"shaping on":  "Shaping an",
+		"shaping off": "Shaping aus",
+		"%d of %d glyphs changed, advance %+.2f em": "%d von %d Glyphen geändert, Breite %+.2f em",
+		"supported":                                             "unterstützt",
+		"%d missing: %s":                                        "%d fehlen: %s",
+		"missing OS/2 table":                                    "OS/2-Tabelle fehlt",
+		"hhea descender %d is positive":                         "hhea-Unterlänge %d ist positiv",
+		"typo descender %d is positive":                         "typo-Unterlänge %d ist positiv",
+		"USE_TYPO_METRICS is not set":                           "USE_TYPO_METRICS ist nicht gesetzt",
+		"hhea line height %d differs from %s line height %d":    "hhea-Zeilenhöhe %d weicht von %s-Zeilenhöhe %d ab",
+		"hhea ascender/descender %d/%d differs from typo %d/%d": "hhea-Ober-/Unterlänge %d/%d weicht von typo %d/%d ab",
+		"win ascent/descent %d/%d does not cover bounding box %d/%d, glyphs will be clipped on Windows": "win-Ober-/Unterlänge %d/%d deckt Begrenzungsrahmen %d/%d nicht ab, Glyphen werden unter Windows abgeschnitten",
+		"preview unavailable": "Vorschau nicht verfügbar",
+		"Unknown font":        "Unbekannte Schrift",
+		"ltr":                 "links-rechts",
+		"rtl":                 "rechts-links",
+	},
+	"es": {
+		"%d glyphs":   "%d glifos",
+		"not mapped":  "sin asignar",
+		"Upright":     "Redonda",
+		"Italic":      "Cursiva",
+		"synthesized": "sintetizada",
+		"x-height":    "altura x",
+		"cap height":  "altura de mayúsculas",
+		"ascender":    "ascendente",
+		"descender":   "descendente",
+		"avg":         "media",
+		"space":       "espacio",
+		"avg %.2f em, a-z %.2f em, space %.2f em": "media %.2f em, a-z %.2f em, espacio %.2f em",
+		"kerning on":  "kerning activado",
+		"kerning off": "kerning desactivado",
+		"shaping on":  "shaping activado",
+		"shaping off": "shaping desactivado",
+		"%d of %d glyphs changed, advance %+.2f em": "%d de %d glifos cambiados, avance %+.2f em",
+		"supported":                                             "compatible",
+		"%d missing: %s":                                        "%d faltan: %s",
+		"missing OS/2 table":                                    "falta la tabla OS/2",
+		"hhea descender %d is positive":                         "el descendente hhea %d es positivo",
+		"typo descender %d is positive":                         "el descendente typo %d es positivo",
+		"USE_TYPO_METRICS is not set":                           "USE_TYPO_METRICS no está activado",
+		"hhea line height %d differs from %s line height %d":    "la altura de línea hhea %d difiere de la altura de línea %s %d",
+		"hhea ascender/descender %d/%d differs from typo %d/%d": "ascendente/descendente hhea %d/%d difiere de typo %d/%d",
+		"win ascent/descent %d/%d does not cover bounding box %d/%d, glyphs will be clipped on Windows": "ascendente/descendente win %d/%d no cubre el cuadro delimitador %d/%d, los glifos se recortarán en Windows",
+		"preview unavailable": "vista previa no disponible",
+		"Unknown font":        "Fuente desconocida",
+		"ltr":                 "izq.-der.",
+		"rtl":                 "der.-izq.",
+	},
+	"fr": {
+		"%d glyphs":   "%d glyphes",
+		"not mapped":  "non associé",
+		"Upright":     "Romain",
+		"Italic":      "Italique",
+		"synthesized": "synthétisé",
+		"x-height":    "hauteur d'x",
+		"cap height":  "hauteur de capitale",
+		"ascender":    "ascendante",
+		"descender":   "descendante",
+		"avg":         "moyenne",
+		"space":       "espace",
+		"avg %.2f em, a-z %.2f em, space %.2f em": "moyenne %.2f em, a-z %.2f em, espace %.2f em",
+		"kerning on":  "crénage activé",
+		"kerning off": "crénage désactivé",
+		"shaping on":  "façonnage activé",
+		"shaping off": "façonnage désactivé",
+		"%d of %d glyphs changed, advance %+.2f em": "%d glyphes modifiés sur %d, chasse %+.2f em",
+		"supported":                                             "pris en charge",
+		"%d missing: %s":                                        "%d manquants : %s",
+		"missing OS/2 table":                                    "table OS/2 manquante",
+		"hhea descender %d is positive":                         "descendante hhea %d positive",
+		"typo descender %d is positive":                         "descendante typo %d positive",
+		"USE_TYPO_METRICS is not set":                           "USE_TYPO_METRICS n'est pas activé",
+		"hhea line height %d differs from %s line height %d":    "hauteur de ligne hhea %d différente de la hauteur de ligne %s %d",
+		"hhea ascender/descender %d/%d differs from typo %d/%d": "ascendante/descendante hhea %d/%d différente de typo %d/%d",
+		"win ascent/descent %d/%d does not cover bounding box %d/%d, glyphs will be clipped on Windows": "ascendante/descendante win %d/%d ne couvre pas la boîte englobante %d/%d, les glyphes seront rognés sous Windows",
+		"preview unavailable": "aperçu indisponible",
+		"Unknown font":        "Police inconnue",
+		"ltr":                 "g-d",
+		"rtl":                 "d-g",
+	},
+	"it": {
+		"%d glyphs":   "%d glifi",
+		"not mapped":  "non mappato",
+		"Upright":     "Tondo",
+		"Italic":      "Corsivo",
+		"synthesized": "sintetizzato",
+		"x-height":    "altezza x",
+		"cap height":  "altezza maiuscole",
+		"ascender":    "ascendente",
+		"descender":   "discendente",
+		"avg":         "media",
+		"space":       "spazio",
+		"avg %.2f em, a-z %.2f em, space %.2f em": "media %.2f em, a-z %.2f em, spazio %.2f em",
+		"kerning on":  "crenatura attiva",
+		"kerning off": "crenatura disattiva",
+		"shaping on":  "shaping attivo",
+		"shaping off": "shaping disattivo",
+		"%d of %d glyphs changed, advance %+.2f em": "%d di %d glifi modificati, avanzamento %+.2f em",
+		"supported":                                             "supportato",
+		"%d missing: %s":                                        "%d mancanti: %s",
+		"missing OS/2 table":                                    "tabella OS/2 mancante",
+		"hhea descender %d is positive":                         "discendente hhea %d positivo",
+		"typo descender %d is positive":                         "discendente typo %d positivo",
+		"USE_TYPO_METRICS is not set":                           "USE_TYPO_METRICS non impostato",
+		"hhea line height %d differs from %s line height %d":    "altezza di riga hhea %d diversa dall'altezza di riga %s %d",
+		"hhea ascender/descender %d/%d differs from typo %d/%d": "ascendente/discendente hhea %d/%d diverso da typo %d/%d",
+		"win ascent/descent %d/%d does not cover bounding box %d/%d, glyphs will be clipped on Windows": "ascendente/discendente win %d/%d non copre il riquadro di delimitazione %d/%d, i glifi saranno tagliati su Windows",
+		"preview unavailable": "anteprima non disponibile",
+		"Unknown font":        "Font sconosciuto",
+		"ltr":                 "sx-dx",
+		"rtl":                 "dx-sx",
+	},
+	"nl": {
+		"%d glyphs":   "%d glyphs",
+		"not mapped":  "niet toegewezen",
+		"Upright":     "Romein",
+		"Italic":      "Cursief",
+		"synthesized": "gesynthetiseerd",
+		"x-height":    "x-hoogte",
+		"cap height":  "kapitaalhoogte",
+		"ascender":    "stokhoogte",
+		"descender":   "staarthoogte",
+		"avg":         "gem.",
+		"space":       "spatie",
+		"avg %.2f em, a-z %.2f em, space %.2f em": "gem. %.2f em, a-z %.2f em, spatie %.2f em",
+		"kerning on":  "kerning aan",
+		"kerning off": "kerning uit",
+		"shaping on":  "shaping aan",
+		"shaping off": "shaping uit",
+		"%d of %d glyphs changed, advance %+.2f em": "%d van %d glyphs gewijzigd, breedte %+.2f em",
+		"supported":                                             "ondersteund",
+		"%d missing: %s":                                        "%d ontbreken: %s",
+		"missing OS/2 table":                                    "OS/2-tabel ontbreekt",
+		"hhea descender %d is positive":                         "hhea-staarthoogte %d is positief",
+		"typo descender %d is positive":                         "typo-staarthoogte %d is positief",
+		"USE_TYPO_METRICS is not set":                           "USE_TYPO_METRICS is niet ingesteld",
+		"hhea line height %d differs from %s line height %d":    "hhea-regelhoogte %d wijkt af van %s-regelhoogte %d",
+		"hhea ascender/descender %d/%d differs from typo %d/%d": "hhea-stok-/staarthoogte %d/%d wijkt af van typo %d/%d",
+		"win ascent/descent %d/%d does not cover bounding box %d/%d, glyphs will be clipped on Windows": "win-stok-/staarthoogte %d/%d omvat kader %d/%d niet, glyphs worden afgekapt op Windows",
+		"preview unavailable": "voorbeeld niet beschikbaar",
+		"Unknown font":        "Onbekend lettertype",
+		"ltr":                 "links-rechts",
+		"rtl":                 "rechts-links",
+	},
+	"pt": {
+		"%d glyphs":   "%d glifos",
+		"not mapped":  "não mapeado",
+		"Upright":     "Redondo",
+		"Italic":      "Itálico",
+		"synthesized": "sintetizado",
+		"x-height":    "altura x",
+		"cap height":  "altura das maiúsculas",
+		"ascender":    "ascendente",
+		"descender":   "descendente",
+		"avg":         "média",
+		"space":       "espaço",
+		"avg %.2f em, a-z %.2f em, space %.2f em": "média %.2f em, a-z %.2f em, espaço %.2f em",
+		"kerning on":  "kerning ativado",
+		"kerning off": "kerning desativado",
+		"shaping on":  "shaping ativado",
+		"shaping off": "shaping desativado",
+		"%d of %d glyphs changed, advance %+.2f em": "%d de %d glifos alterados, avanço %+.2f em",
+		"supported":                                             "suportado",
+		"%d missing: %s":                                        "%d em falta: %s",
+		"missing OS/2 table":                                    "tabela OS/2 em falta",
+		"hhea descender %d is positive":                         "descendente hhea %d é positivo",
+		"typo descender %d is positive":                         "descendente typo %d é positivo",
+		"USE_TYPO_METRICS is not set":                           "USE_TYPO_METRICS não está definido",
+		"hhea line height %d differs from %s line height %d":    "altura de linha hhea %d difere da altura de linha %s %d",
+		"hhea ascender/descender %d/%d differs from typo %d/%d": "ascendente/descendente hhea %d/%d difere de typo %d/%d",
+		"win ascent/descent %d/%d does not cover bounding box %d/%d, glyphs will be clipped on Windows": "ascendente/descendente win %d/%d não cobre a caixa delimitadora %d/%d, os glifos serão cortados no Windows",
+		"preview unavailable": "pré-visualização indisponível",
+		"Unknown font":        "Fonte desconhecida",
+		"ltr":                 "esq.-dir.",
+		"rtl":                 "dir.-esq.",
+	},
+}
@@ -0,0 +1,79 @@
+package fontimg
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestLabelPrinter(t *testing.T) {
+	tests := []struct {
+		lang string
+		key  string
+		v    []any
+		exp  string
+	}{
+		{"", "%d glyphs", []any{12345}, "12,345 glyphs"},
+		{"en-GB", "%d glyphs", []any{12345}, "12,345 glyphs"},
+		{"de", "%d glyphs", []any{12345}, "12.345 Glyphen"},
+		{"de-CH", "not mapped", nil, "nicht zugeordnet"},
+		{"fr", "x-height", nil, "hauteur d'x"},
+		{"es", "avg %.2f em, a-z %.2f em, space %.2f em", []any{0.5, 0.25, 0.75}, "media 0,50 em, a-z 0,25 em, espacio 0,75 em"},
+		{"de", "preview unavailable", nil, "Vorschau nicht verfügbar"},
+		{"fr", "hhea descender %d is positive", []any{200}, "descendante hhea 200 positive"},
+		{"it", "rtl", nil, "dx-sx"},
+		{"ja", "synthesized", nil, "synthesized"},
+		{"!!", "Italic", nil, "Italic"},
+	}
+	for _, test := range tests {
+		if s := labelPrinter(test.lang).Sprintf(test.key, test.v...); s != test.exp {
+			t.Errorf("%q %q: expected %q, got: %q", test.lang, test.key, test.exp, s)
+		}
+	}
+	// all languages translate the same labels
+	exp := slices.Sorted(maps.Keys(labelTranslations["de"]))
+	for lang, m := range labelTranslations {
+		if keys := slices.Sorted(maps.Keys(m)); !slices.Equal(keys, exp) {
+			t.Errorf("%s: expected labels %q, got: %q", lang, exp, keys)
+		}
+	}
+}
+
+func TestRegisterLabels(t *testing.T) {
+	if err := RegisterLabels("sv", map[string]string{"not mapped": "ej mappad"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s, exp := labelPrinter("sv-SE").Sprintf("not mapped"), "ej mappad"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+	if err := RegisterLabels("!!", nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	opts := DefaultOptions()
+	opts.Language = "!!"
+	if err := opts.Validate(); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	font := New(nil, "testdata/Ubuntu-R.ttf")
+	opts := DefaultOptions()
+	en, err := Badge(font, nil, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	opts.Language = "de"
+	de, err := Badge(font, nil, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if w0, _ := en.Size(); w0 == 0 {
+		t.Errorf("expected non-empty badge")
+	} else if w1, _ := de.Size(); w0 == w1 {
+		t.Errorf("expected localized badge to differ in width, got: %f", w1)
+	}
+	if CacheKey(font, opts) == CacheKey(font, DefaultOptions()) {
+		t.Errorf("expected language to change the cache key")
+	}
+}
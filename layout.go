@@ -0,0 +1,253 @@
+package fontimg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"unicode"
+)
+
+// layoutFeatures parses the raw GPOS or GSUB table, returning the lookup
+// types used by each feature tag. Extension lookups are resolved to their
+// extension lookup type.
+func layoutFeatures(b []byte, extType uint16) (map[string][]uint16, error) {
+	if len(b) < 10 {
+		return nil, fmt.Errorf("bad layout table")
+	}
+	featureList, lookupList := int(binary.BigEndian.Uint16(b[6:])), int(binary.BigEndian.Uint16(b[8:]))
+	// read lookup types
+	n, err := u16(b, lookupList)
+	if err != nil {
+		return nil, err
+	}
+	types := make([]uint16, n)
+	for i := range types {
+		off, err := u16(b, lookupList+2+2*i)
+		if err != nil {
+			return nil, err
+		}
+		lookup := lookupList + int(off)
+		if types[i], err = u16(b, lookup); err != nil {
+			return nil, err
+		}
+		if types[i] != extType {
+			continue
+		}
+		// extension lookup: type is in the first subtable
+		if count, err := u16(b, lookup+4); err != nil || count == 0 {
+			continue
+		}
+		sub, err := u16(b, lookup+6)
+		if err != nil {
+			return nil, err
+		}
+		if types[i], err = u16(b, lookup+int(sub)+2); err != nil {
+			return nil, err
+		}
+	}
+	// read features
+	if n, err = u16(b, featureList); err != nil {
+		return nil, err
+	}
+	m := make(map[string][]uint16)
+	for i := range int(n) {
+		rec := featureList + 2 + 6*i
+		if len(b) < rec+6 {
+			return nil, fmt.Errorf("bad feature record")
+		}
+		tag, feature := string(b[rec:rec+4]), featureList+int(binary.BigEndian.Uint16(b[rec+4:]))
+		count, err := u16(b, feature+2)
+		if err != nil {
+			return nil, err
+		}
+		for j := range int(count) {
+			idx, err := u16(b, feature+4+2*j)
+			if err != nil {
+				return nil, err
+			}
+			if int(idx) < len(types) && !slices.Contains(m[tag], types[idx]) {
+				m[tag] = append(m[tag], types[idx])
+			}
+		}
+		if _, ok := m[tag]; !ok {
+			m[tag] = nil
+		}
+	}
+	return m, nil
+}
+
+// layoutSubtables calls f with the lookup type and offset of each subtable of
+// each lookup in the raw GPOS or GSUB table. Extension subtables are resolved
+// to their extension lookup type and subtable.
+func layoutSubtables(b []byte, extType uint16, f func(typ uint16, sub int) error) error {
+	lookupList, err := u16(b, 8)
+	if err != nil {
+		return err
+	}
+	n, err := u16(b, int(lookupList))
+	if err != nil {
+		return err
+	}
+	for i := range int(n) {
+		off, err := u16(b, int(lookupList)+2+2*i)
+		if err != nil {
+			return err
+		}
+		lookup := int(lookupList) + int(off)
+		typ, err := u16(b, lookup)
+		if err != nil {
+			return err
+		}
+		count, err := u16(b, lookup+4)
+		if err != nil {
+			return err
+		}
+		for j := range int(count) {
+			off, err := u16(b, lookup+6+2*j)
+			if err != nil {
+				return err
+			}
+			sub, subType := lookup+int(off), typ
+			if typ == extType {
+				if subType, err = u16(b, sub+2); err != nil {
+					return err
+				}
+				ext, err := u32(b, sub+4)
+				if err != nil {
+					return err
+				}
+				sub += int(ext)
+			}
+			if err := f(subType, sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// coverage parses the coverage table at i, returning the covered glyphs in
+// coverage index order.
+func coverage(b []byte, i int) ([]uint16, error) {
+	format, err := u16(b, i)
+	if err != nil {
+		return nil, err
+	}
+	n, err := u16(b, i+2)
+	if err != nil {
+		return nil, err
+	}
+	var v []uint16
+	switch format {
+	case 1:
+		for j := range int(n) {
+			id, err := u16(b, i+4+2*j)
+			if err != nil {
+				return nil, err
+			}
+			v = append(v, id)
+		}
+	case 2:
+		for j := range int(n) {
+			start, err := u16(b, i+4+6*j)
+			if err != nil {
+				return nil, err
+			}
+			end, err := u16(b, i+6+6*j)
+			if err != nil {
+				return nil, err
+			}
+			for id := int(start); id <= int(end); id++ {
+				v = append(v, uint16(id))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("bad coverage format %d", format)
+	}
+	return v, nil
+}
+
+// u32 reads a big endian uint32 at i.
+func u32(b []byte, i int) (uint32, error) {
+	if i < 0 || len(b) < i+4 {
+		return 0, fmt.Errorf("offset %d out of bounds", i)
+	}
+	return binary.BigEndian.Uint32(b[i:]), nil
+}
+
+// u16 reads a big endian uint16 at i.
+func u16(b []byte, i int) (uint16, error) {
+	if i < 0 || len(b) < i+2 {
+		return 0, fmt.Errorf("offset %d out of bounds", i)
+	}
+	return binary.BigEndian.Uint16(b[i:]), nil
+}
+
+// GPOS lookup types.
+const (
+	gposMarkToBase     = 4
+	gposMarkToLigature = 5
+	gposMarkToMark     = 6
+	gposExtension      = 9
+)
+
+// MarkPositioning is a report of a font's combining mark positioning
+// support.
+type MarkPositioning struct {
+	// Marks is the number of combining marks (Unicode category Mn) mapped by
+	// the font.
+	Marks int
+	// MarkToBase is true when the font has GPOS mark-to-base attachment.
+	MarkToBase bool
+	// MarkToLigature is true when the font has GPOS mark-to-ligature
+	// attachment.
+	MarkToLigature bool
+	// MarkToMark is true when the font has GPOS mark-to-mark attachment,
+	// used for stacking marks.
+	MarkToMark bool
+}
+
+// Unpositioned returns true when the font maps combining marks without
+// positioning them with GPOS mark attachment.
+func (mp *MarkPositioning) Unpositioned() bool {
+	return 0 < mp.Marks && !mp.MarkToBase
+}
+
+// MarkPositioning reports the font's combining mark positioning support.
+func (font *Font) MarkPositioning() (*MarkPositioning, error) {
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	runes, err := font.Runes()
+	if err != nil {
+		return nil, err
+	}
+	mp := new(MarkPositioning)
+	for _, r := range runes {
+		if unicode.Is(unicode.Mn, r) {
+			mp.Marks++
+		}
+	}
+	b, ok := sfnt.Tables["GPOS"]
+	if !ok {
+		return mp, nil
+	}
+	features, err := layoutFeatures(b, gposExtension)
+	if err != nil {
+		return nil, fmt.Errorf("GPOS: %v", err)
+	}
+	for _, tag := range []string{"mark", "mkmk"} {
+		for _, typ := range features[tag] {
+			switch typ {
+			case gposMarkToBase:
+				mp.MarkToBase = true
+			case gposMarkToLigature:
+				mp.MarkToLigature = true
+			case gposMarkToMark:
+				mp.MarkToMark = true
+			}
+		}
+	}
+	return mp, nil
+}
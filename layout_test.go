@@ -0,0 +1,52 @@
+package fontimg
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMarkPositioning(t *testing.T) {
+	tests := []struct {
+		name         string
+		unpositioned bool
+	}{
+		{"NotoMono-Regular.ttf", true},
+		{"Ubuntu-R.ttf", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mp, err := New(nil, filepath.Join("testdata", test.name)).MarkPositioning()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if mp.Marks == 0 {
+				t.Errorf("expected marks")
+			}
+			if b := mp.Unpositioned(); b != test.unpositioned {
+				t.Errorf("expected %t, got: %t", test.unpositioned, b)
+			}
+		})
+	}
+}
+
+func TestLayoutFeatures(t *testing.T) {
+	sfnt, err := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf")).sfnt()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	gpos, err := layoutFeatures(sfnt.Tables["GPOS"], gposExtension)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !slices.Contains(gpos["kern"], 2) {
+		t.Errorf("expected kern pair adjustment lookup, got: %v", gpos)
+	}
+	// truncated tables must error, not panic
+	b := slices.Clone(sfnt.Tables["GPOS"])
+	for _, n := range []int{0, 9, 12, 64} {
+		if _, err := layoutFeatures(b[:n], gposExtension); err == nil && n < 10 {
+			t.Errorf("expected error for %d bytes", n)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package fontimg
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+
+	"github.com/andybalholm/brotli"
+)
+
+// ParseBudget is the maximum number of bytes of font data, and of data
+// decompressed or decoded from it, allowed when parsing a single font.
+// Parsing a font exceeding the budget (ie, a font with tables claiming
+// absurd sizes, or a WOFF2 expansion bomb) fails with [ErrResourceLimit]
+// instead of exhausting memory. Zero disables the budget.
+var ParseBudget int64 = 256 << 20
+
+// ErrResourceLimit is the error returned when parsing a font exceeds the
+// [ParseBudget].
+var ErrResourceLimit = errors.New("resource limit exceeded")
+
+// budget is a parse byte budget.
+type budget struct {
+	n int64
+}
+
+// newBudget creates a budget of [ParseBudget] bytes.
+func newBudget() *budget {
+	if ParseBudget <= 0 {
+		return &budget{n: math.MaxInt64}
+	}
+	return &budget{n: ParseBudget}
+}
+
+// alloc charges n bytes to the budget, returning [ErrResourceLimit] when the
+// budget is exhausted.
+func (b *budget) alloc(n int64) error {
+	if n < 0 || b.n < n {
+		b.n = 0
+		return ErrResourceLimit
+	}
+	b.n -= n
+	return nil
+}
+
+// readFile reads the named file, returning [ErrResourceLimit] when the file
+// is larger than the budget.
+func readFile(name string, bud *budget) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readAll(f, bud)
+}
+
+// readAll reads all of r, returning [ErrResourceLimit] when there is more
+// data than the budget allows.
+func readAll(r io.Reader, bud *budget) ([]byte, error) {
+	buf, err := io.ReadAll(io.LimitReader(r, min(bud.n, math.MaxInt64-1)+1))
+	switch {
+	case err != nil:
+		return nil, err
+	case bud.alloc(int64(len(buf))) != nil:
+		return nil, ErrResourceLimit
+	}
+	return buf, nil
+}
+
+// checkWOFF charges the decompressed size of the WOFF font data to the
+// budget, verifying that the compressed tables do not decompress past their
+// original lengths. Malformed data is left for the font parser to report.
+func checkWOFF(b []byte, bud *budget) error {
+	if len(b) < 44 {
+		return nil
+	}
+	if err := bud.alloc(int64(binary.BigEndian.Uint32(b[16:]))); err != nil {
+		return err
+	}
+	for i := range int(binary.BigEndian.Uint16(b[12:])) {
+		rec := 44 + 20*i
+		if len(b) < rec+20 {
+			return nil
+		}
+		offset, length := int64(binary.BigEndian.Uint32(b[rec+4:])), int64(binary.BigEndian.Uint32(b[rec+8:]))
+		origLength := int64(binary.BigEndian.Uint32(b[rec+12:]))
+		if length == origLength || int64(len(b)) < offset+length {
+			continue
+		}
+		if err := bud.alloc(origLength); err != nil {
+			return err
+		}
+		r, err := zlib.NewReader(bytes.NewReader(b[offset : offset+length]))
+		if err != nil {
+			continue
+		}
+		if n, _ := io.Copy(io.Discard, io.LimitReader(r, origLength+1)); origLength < n {
+			return ErrResourceLimit
+		}
+	}
+	return nil
+}
+
+// checkWOFF2 charges the decompressed size of the WOFF2 font data to the
+// budget, verifying that the compressed data does not decompress past the
+// sum of the table lengths. Malformed data is left for the font parser to
+// report.
+func checkWOFF2(b []byte, bud *budget) error {
+	if len(b) < 48 || string(b[4:8]) == "ttcf" {
+		return nil
+	}
+	if err := bud.alloc(int64(binary.BigEndian.Uint32(b[16:]))); err != nil {
+		return err
+	}
+	tables, offset, ok := readWOFF2Directory(b)
+	var size int64
+	for _, t := range tables {
+		size += t.n()
+	}
+	length := int64(binary.BigEndian.Uint32(b[20:]))
+	if !ok || int64(len(b)-offset) < length {
+		return nil
+	}
+	if err := bud.alloc(size); err != nil {
+		return err
+	}
+	br := brotli.NewReader(bytes.NewReader(b[offset : int64(offset)+length]))
+	if n, _ := io.Copy(io.Discard, io.LimitReader(br, size+1)); size < n {
+		return ErrResourceLimit
+	}
+	return nil
+}
+
+// woff2Table is a WOFF2 table directory entry.
+type woff2Table struct {
+	flags byte
+	tag   string
+	// orig is the table's original length, and trans is the length of the
+	// transformed table, when transformed.
+	orig, trans int64
+	transformed bool
+}
+
+// n returns the length of the table's data in the compressed data.
+func (t woff2Table) n() int64 {
+	if t.transformed {
+		return t.trans
+	}
+	return t.orig
+}
+
+// readWOFF2Directory reads the table directory of the WOFF2 font data,
+// returning the tables and the offset of the compressed data following the
+// directory. Returns false when the directory is out of bounds or invalid.
+func readWOFF2Directory(b []byte) ([]woff2Table, int, bool) {
+	r := &base128Reader{b: b, i: 48}
+	var tables []woff2Table
+	for range int(binary.BigEndian.Uint16(b[12:])) {
+		t := woff2Table{flags: r.byte()}
+		t.tag = woff2Tag(t.flags & 0x3f)
+		if t.flags&0x3f == 63 {
+			t.tag = string(r.bytes(4))
+		}
+		version := t.flags >> 6
+		t.orig = r.uint()
+		if (t.tag == "glyf" || t.tag == "loca") && version == 0 || t.tag == "hmtx" && version == 1 {
+			t.trans, t.transformed = r.uint(), true
+		}
+		tables = append(tables, t)
+	}
+	return tables, r.i, !r.bad
+}
+
+// woff2Tag returns the WOFF2 known table tag for the index, for the tags
+// with transforms and the hhea table.
+func woff2Tag(i byte) string {
+	switch i {
+	case 2:
+		return "hhea"
+	case 3:
+		return "hmtx"
+	case 10:
+		return "glyf"
+	case 11:
+		return "loca"
+	}
+	return ""
+}
+
+// base128Reader reads WOFF2 table directory fields, recording any out of
+// bounds or invalid read.
+type base128Reader struct {
+	b   []byte
+	i   int
+	bad bool
+}
+
+// bytes reads n bytes.
+func (r *base128Reader) bytes(n int) []byte {
+	if len(r.b) < r.i+n {
+		r.bad, r.i = true, len(r.b)
+		return make([]byte, n)
+	}
+	b := r.b[r.i : r.i+n]
+	r.i += n
+	return b
+}
+
+// byte reads a byte.
+func (r *base128Reader) byte() byte {
+	return r.bytes(1)[0]
+}
+
+// uint reads a UIntBase128 value.
+func (r *base128Reader) uint() int64 {
+	var v int64
+	for i := range 5 {
+		c := r.byte()
+		if i == 0 && c == 0x80 {
+			r.bad = true
+		}
+		v = v<<7 | int64(c&0x7f)
+		if c&0x80 == 0 {
+			return v
+		}
+	}
+	r.bad = true
+	return v
+}
@@ -0,0 +1,141 @@
+package fontimg
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/tdewolff/canvas"
+)
+
+func TestBudget(t *testing.T) {
+	bud := &budget{n: 10}
+	if err := bud.alloc(6); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := bud.alloc(4); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := bud.alloc(1); err != ErrResourceLimit {
+		t.Errorf("expected ErrResourceLimit, got: %v", err)
+	}
+	if err := (&budget{n: 10}).alloc(-1); err != ErrResourceLimit {
+		t.Errorf("expected ErrResourceLimit, got: %v", err)
+	}
+}
+
+func TestParseBudget(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fnt, psf := testFNT(0x0300, 16, 400, false), testPSF2(true)
+	tests := []struct {
+		name   string
+		budget int64
+		f      func() error
+	}{
+		{"buf", 1024, func() error {
+			_, err := (&Font{Buf: buf}).Load(canvas.FontRegular)
+			return err
+		}},
+		{"path", 1024, func() error {
+			_, err := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf")).Load(canvas.FontRegular)
+			return err
+		}},
+		{"reader", 1024, func() error {
+			_, err := newReader(context.Background(), bytes.NewReader(buf))
+			return err
+		}},
+		{"metadata", 1024, func() error {
+			_, err := ParseMetadata(buf)
+			return err
+		}},
+		{"fnt", int64(len(fnt)) + 1024, func() error {
+			_, err := ParseFNT(fnt)
+			return err
+		}},
+		{"fon", int64(len(testFON())) + 1024, func() error {
+			_, err := ParseFON(testFON())
+			return err
+		}},
+		{"psf", int64(len(psf)) + 1024, func() error {
+			_, err := ParsePSF(psf)
+			return err
+		}},
+		{"woff", 0, func() error {
+			_, err := (&Font{Buf: testWOFFBomb()}).Load(canvas.FontRegular)
+			return err
+		}},
+		{"woff2", 0, func() error {
+			_, err := DecompressWOFF2(testWOFF2Bomb())
+			return err
+		}},
+		{"woff2 metadata", 0, func() error {
+			_, err := ParseMetadata(testWOFF2Bomb())
+			return err
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.budget != 0 {
+				defer func(n int64) { ParseBudget = n }(ParseBudget)
+				ParseBudget = test.budget
+			}
+			if err := test.f(); !errors.Is(err, ErrResourceLimit) {
+				t.Errorf("expected ErrResourceLimit, got: %v", err)
+			}
+		})
+	}
+	if _, err := ParseFNT(fnt); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+// testBomb is the size of the decompression bomb test data.
+const testBomb = 16 << 20
+
+// testWOFFBomb builds a WOFF font with a name table claiming 4 KiB, but
+// decompressing to 16 MiB.
+func testWOFFBomb() []byte {
+	var z bytes.Buffer
+	w := zlib.NewWriter(&z)
+	w.Write(make([]byte, testBomb))
+	w.Close()
+	b := make([]byte, 64, 64+z.Len())
+	copy(b, "wOFF\x00\x01\x00\x00")
+	binary.BigEndian.PutUint16(b[12:], 1)               // num tables
+	binary.BigEndian.PutUint32(b[16:], 12+16+4096)      // total sfnt size
+	copy(b[44:], "name")                                // tag
+	binary.BigEndian.PutUint32(b[48:], 64)              // offset
+	binary.BigEndian.PutUint32(b[52:], uint32(z.Len())) // length
+	binary.BigEndian.PutUint32(b[56:], 4096)            // original length
+	b = append(b, z.Bytes()...)
+	binary.BigEndian.PutUint32(b[8:], uint32(len(b)))
+	return b
+}
+
+// testWOFF2Bomb builds a WOFF2 font with a name table claiming 16 KiB, but
+// decompressing to 16 MiB.
+func testWOFF2Bomb() []byte {
+	var z bytes.Buffer
+	w := brotli.NewWriter(&z)
+	w.Write(make([]byte, testBomb))
+	w.Close()
+	b := make([]byte, 48, 51+z.Len())
+	copy(b, "wOF2\x00\x01\x00\x00")
+	binary.BigEndian.PutUint16(b[12:], 1)               // num tables
+	binary.BigEndian.PutUint32(b[16:], 12+16+16384)     // total sfnt size
+	binary.BigEndian.PutUint32(b[20:], uint32(z.Len())) // total compressed size
+	// name table, original length 16383
+	b = append(b, 5, 0xff, 0x7f)
+	b = append(b, z.Bytes()...)
+	binary.BigEndian.PutUint32(b[8:], uint32(len(b)))
+	return b
+}
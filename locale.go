@@ -0,0 +1,100 @@
+package fontimg
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// formatNumber formats v as a decimal number for the locale (ie, "de-DE").
+func formatNumber(locale string, v float64) (string, error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", err
+	}
+	return message.NewPrinter(tag).Sprint(number.Decimal(v, number.MaxFractionDigits(2))), nil
+}
+
+// formatCurrency formats v as a currency amount for the locale, using the
+// locale's currency or the optional ISO 4217 currency code (ie, "EUR").
+func formatCurrency(locale string, v float64, code ...string) (string, error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", err
+	}
+	var unit currency.Unit
+	switch len(code) {
+	case 0:
+		var conf language.Confidence
+		if unit, conf = currency.FromTag(tag); conf == language.No {
+			return "", fmt.Errorf("no currency for locale %q", locale)
+		}
+	case 1:
+		if unit, err = currency.ParseISO(code[0]); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("too many currency codes")
+	}
+	return message.NewPrinter(tag).Sprint(currency.Symbol(unit.Amount(v))), nil
+}
+
+// formatDate formats a date for the locale. The date is parsed from the
+// optional value (as 2006-01-02), or is the sample date when not provided.
+func formatDate(locale string, value ...string) (string, error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", err
+	}
+	t := sampleDate
+	switch len(value) {
+	case 0:
+	case 1:
+		if t, err = time.Parse(time.DateOnly, value[0]); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("too many dates")
+	}
+	base, _ := tag.Base()
+	region, _ := tag.Region()
+	layout, ok := dateLayouts[base.String()+"-"+region.String()]
+	if !ok {
+		if layout, ok = dateLayouts[base.String()]; !ok {
+			layout = time.DateOnly
+		}
+	}
+	return t.Format(layout), nil
+}
+
+// sampleDate is the default date used for date samples.
+var sampleDate = time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// dateLayouts are the numeric date layouts for languages and
+// language-regions.
+var dateLayouts = map[string]string{
+	"en-US": "01/02/2006",
+	"en":    "02/01/2006",
+	"de":    "02.01.2006",
+	"ru":    "02.01.2006",
+	"pl":    "02.01.2006",
+	"cs":    "2. 1. 2006",
+	"fi":    "2.1.2006",
+	"tr":    "02.01.2006",
+	"fr":    "02/01/2006",
+	"es":    "02/01/2006",
+	"it":    "02/01/2006",
+	"pt":    "02/01/2006",
+	"el":    "2/1/2006",
+	"nl":    "02-01-2006",
+	"hi":    "2/1/2006",
+	"ar":    "2/1/2006",
+	"he":    "2.1.2006",
+	"ja":    "2006年1月2日",
+	"zh":    "2006年1月2日",
+	"ko":    "2006년 1월 2일",
+}
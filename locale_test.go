@@ -0,0 +1,44 @@
+package fontimg
+
+import (
+	"testing"
+)
+
+func TestLocale(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func() (string, error)
+		exp  string
+	}{
+		{"number en-US", func() (string, error) { return formatNumber("en-US", 1234567.891) }, "1,234,567.89"},
+		{"number de-DE", func() (string, error) { return formatNumber("de-DE", 1234567.891) }, "1.234.567,89"},
+		{"currency en-US", func() (string, error) { return formatCurrency("en-US", 1234.5) }, "$ 1,234.50"},
+		{"currency de-DE", func() (string, error) { return formatCurrency("de-DE", 1234.5) }, "€ 1.234,50"},
+		{"currency de-DE USD", func() (string, error) { return formatCurrency("de-DE", 1234.5, "USD") }, "$ 1.234,50"},
+		{"date en-US", func() (string, error) { return formatDate("en-US") }, "12/31/2024"},
+		{"date en-GB", func() (string, error) { return formatDate("en-GB") }, "31/12/2024"},
+		{"date de-DE", func() (string, error) { return formatDate("de-DE", "2025-03-09") }, "09.03.2025"},
+		{"date ja-JP", func() (string, error) { return formatDate("ja-JP") }, "2024年12月31日"},
+		{"date sv-SE", func() (string, error) { return formatDate("sv-SE") }, "2024-12-31"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.f()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if s != test.exp {
+				t.Errorf("expected %q, got: %q", test.exp, s)
+			}
+		})
+	}
+	for _, f := range []func() (string, error){
+		func() (string, error) { return formatNumber("!!", 1) },
+		func() (string, error) { return formatCurrency("en-US", 1, "XYZW") },
+		func() (string, error) { return formatDate("en-US", "blah") },
+	} {
+		if _, err := f(); err == nil {
+			t.Errorf("expected error")
+		}
+	}
+}
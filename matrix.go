@@ -0,0 +1,150 @@
+package fontimg
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// RasterizeStyleMatrix rasterizes a style matrix of the family's fonts using
+// the options. See [StyleMatrix].
+func RasterizeStyleMatrix(fonts []*Font, opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := StyleMatrix(fonts, opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// StyleMatrix lays out a weight by italic matrix of the fonts of a family
+// (ie, as returned by [Open]) on a canvas, with each cell drawing the family
+// name in the cell's style, with rows and columns labeled using the embedded
+// label font. Styles without a font are synthesized from the closest font, as
+// a browser would, and drawn in gray. The options' text (when not empty) is
+// used in place of the family name. When opts is nil, the default options
+// will be used.
+func StyleMatrix(fonts []*Font, opts *Options) (_ *canvas.Canvas, err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	ff, styles, err := matrixFamily(fonts)
+	if err != nil {
+		return nil, err
+	}
+	text := fonts[0].BestName()
+	if opts.Text != "" {
+		text = opts.Text
+	}
+	lff, err := LabelFont().Load(canvas.FontRegular)
+	if err != nil {
+		return nil, err
+	}
+	label, p := lff.Face(0.4*float64(opts.Size), opts.FG), labelPrinter(opts.Language)
+	// create canvas and context
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	// determine label and column widths
+	lh := label.Metrics().LineHeight
+	var labelWidth, colWidth, rowHeight float64
+	for i, w := range matrixWeights {
+		labelWidth = max(labelWidth, label.TextWidth(matrixLabel(i)))
+		for _, italic := range []canvas.FontStyle{0, canvas.FontItalic} {
+			face := ff.Face(float64(opts.Size), w|italic, opts.Variant)
+			colWidth = max(colWidth, face.TextWidth(text))
+			rowHeight = max(rowHeight, face.Metrics().LineHeight)
+		}
+	}
+	labelWidth, colWidth = labelWidth+lh, colWidth+lh
+	// draw column labels
+	for i, s := range []string{"Upright", "Italic"} {
+		ctx.DrawText(labelWidth+float64(i)*colWidth, 0, canvas.NewTextLine(label, p.Sprintf(s), canvas.Left))
+	}
+	// draw rows
+	y := -lh
+	for i, w := range matrixWeights {
+		y -= rowHeight
+		ctx.DrawText(0, y, canvas.NewTextLine(label, matrixLabel(i), canvas.Left))
+		for j, italic := range []canvas.FontStyle{0, canvas.FontItalic} {
+			fg := opts.FG
+			if !styles[w|italic] {
+				fg = matrixSynthesized
+			}
+			face := ff.Face(float64(opts.Size), fg, w|italic, opts.Variant)
+			ctx.DrawText(labelWidth+float64(j)*colWidth, y, canvas.NewTextLine(face, text, canvas.Left))
+		}
+	}
+	// draw legend
+	y -= 2 * lh
+	ctx.SetFillColor(matrixSynthesized)
+	ctx.DrawPath(0, y, canvas.Rectangle(lh/2, lh/2))
+	ctx.DrawText(lh, y, canvas.NewTextLine(label, p.Sprintf("synthesized"), canvas.Left))
+	// fit canvas to context
+	c.Fit(opts.Margin)
+	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
+
+// matrixFamily loads the fonts into a font family, returning the family and
+// the styles of the loaded fonts. The style of each font is parsed from the
+// font's style, or its name table when not set. When multiple fonts have the
+// same style, the first is used.
+func matrixFamily(fonts []*Font) (*canvas.FontFamily, map[canvas.FontStyle]bool, error) {
+	if len(fonts) == 0 {
+		return nil, nil, fmt.Errorf("no fonts")
+	}
+	ff := canvas.NewFontFamily(fonts[0].Family)
+	styles := make(map[canvas.FontStyle]bool)
+	for _, font := range fonts {
+		if font.Style == "" {
+			if _, err := font.Load(canvas.FontRegular); err != nil {
+				return nil, nil, fmt.Errorf("%s: %v", font.BestName(), err)
+			}
+		}
+		style, err := ParseStyle(font.Style)
+		if err != nil {
+			style = canvas.FontRegular
+		}
+		if styles[style] {
+			continue
+		}
+		if err := font.load(context.Background(), ff, style); err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", font.BestName(), err)
+		}
+		styles[style] = true
+	}
+	return ff, styles, nil
+}
+
+// matrixLabel returns the style matrix row label for the i'th weight (ie,
+// "400 Regular").
+func matrixLabel(i int) string {
+	return fmt.Sprintf("%d %s", 100*(i+1), matrixWeights[i])
+}
+
+// matrixWeights are the style matrix weights, from 100 to 900.
+var matrixWeights = []canvas.FontStyle{
+	canvas.FontThin,
+	canvas.FontExtraLight,
+	canvas.FontLight,
+	canvas.FontRegular,
+	canvas.FontMedium,
+	canvas.FontSemiBold,
+	canvas.FontBold,
+	canvas.FontExtraBold,
+	canvas.FontBlack,
+}
+
+// matrixSynthesized is the color of synthesized styles.
+var matrixSynthesized color.Color = color.NRGBA{R: 0xa0, G: 0xa0, B: 0xa0, A: 0xff}
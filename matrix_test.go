@@ -0,0 +1,57 @@
+package fontimg
+
+import (
+	"maps"
+	"path/filepath"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+)
+
+func TestMatrixFamily(t *testing.T) {
+	path := filepath.Join("testdata", "Ubuntu-R.ttf")
+	tests := []struct {
+		name  string
+		fonts []*Font
+		exp   []canvas.FontStyle
+	}{
+		{"regular", []*Font{New(nil, path)}, []canvas.FontStyle{canvas.FontRegular}},
+		{"styles", []*Font{
+			{Path: path, Family: "Ubuntu", Style: "Regular"},
+			{Path: path, Family: "Ubuntu", Style: "Bold Italic"},
+			{Path: path, Family: "Ubuntu", Style: "Light"},
+			{Path: path, Family: "Ubuntu", Style: "Light"},
+		}, []canvas.FontStyle{canvas.FontRegular, canvas.FontBold | canvas.FontItalic, canvas.FontLight}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, styles, err := matrixFamily(test.fonts)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			exp := make(map[canvas.FontStyle]bool)
+			for _, style := range test.exp {
+				exp[style] = true
+			}
+			if !maps.Equal(styles, exp) {
+				t.Errorf("expected %v, got: %v", exp, styles)
+			}
+		})
+	}
+	if _, _, err := matrixFamily(nil); err == nil {
+		t.Errorf("expected error for no fonts")
+	}
+}
+
+func TestRasterizeStyleMatrix(t *testing.T) {
+	img, err := RasterizeStyleMatrix([]*Font{New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+		t.Errorf("expected non-empty image, got: %v", b)
+	}
+	if s := matrixLabel(3); s != "400 Regular" {
+		t.Errorf("expected %q, got: %q", "400 Regular", s)
+	}
+}
@@ -0,0 +1,224 @@
+package fontimg
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// VerticalMetrics is a report of a font's vertical metrics, as defined by the
+// hhea table (used by macOS), the OS/2 typo metrics (used by Windows and most
+// browsers when USE_TYPO_METRICS is set), and the OS/2 win metrics (used by
+// Windows otherwise). Values are in font units.
+type VerticalMetrics struct {
+	UnitsPerEm int
+	// YMin and YMax are the font's bounding box, from the head table.
+	YMin, YMax int
+	// Hhea metrics.
+	HheaAscender, HheaDescender, HheaLineGap int
+	// OS/2 typo metrics.
+	TypoAscender, TypoDescender, TypoLineGap int
+	// OS/2 win metrics. WinDescent is positive below the baseline.
+	WinAscent, WinDescent int
+	// UseTypoMetrics is true when the OS/2 USE_TYPO_METRICS flag is set.
+	UseTypoMetrics bool
+	// Issues are the detected inconsistencies.
+	Issues []string
+	// missingOS2 is true when the font has no OS/2 table.
+	missingOS2 bool
+}
+
+// HheaLineHeight returns the line height using the hhea metrics.
+func (vm *VerticalMetrics) HheaLineHeight() int {
+	return vm.HheaAscender - vm.HheaDescender + vm.HheaLineGap
+}
+
+// TypoLineHeight returns the line height using the OS/2 typo metrics.
+func (vm *VerticalMetrics) TypoLineHeight() int {
+	return vm.TypoAscender - vm.TypoDescender + vm.TypoLineGap
+}
+
+// WinLineHeight returns the line height using the OS/2 win metrics.
+func (vm *VerticalMetrics) WinLineHeight() int {
+	return vm.WinAscent + vm.WinDescent
+}
+
+// VerticalMetrics reports the font's vertical metrics, checking them for the
+// inconsistencies that cause line heights to differ across platforms.
+func (font *Font) VerticalMetrics() (_ *VerticalMetrics, err error) {
+	defer recoverError(&err)
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	vm := &VerticalMetrics{
+		UnitsPerEm: int(sfnt.Head.UnitsPerEm),
+		YMin:       int(sfnt.Head.YMin),
+		YMax:       int(sfnt.Head.YMax),
+	}
+	if sfnt.Hhea != nil {
+		vm.HheaAscender = int(sfnt.Hhea.Ascender)
+		vm.HheaDescender = int(sfnt.Hhea.Descender)
+		vm.HheaLineGap = int(sfnt.Hhea.LineGap)
+	}
+	if sfnt.OS2 == nil {
+		vm.missingOS2 = true
+		vm.check()
+		return vm, nil
+	}
+	vm.TypoAscender = int(sfnt.OS2.STypoAscender)
+	vm.TypoDescender = int(sfnt.OS2.STypoDescender)
+	vm.TypoLineGap = int(sfnt.OS2.STypoLineGap)
+	vm.WinAscent = int(sfnt.OS2.UsWinAscent)
+	vm.WinDescent = int(sfnt.OS2.UsWinDescent)
+	vm.UseTypoMetrics = sfnt.OS2.FsSelection&(1<<7) != 0
+	vm.check()
+	return vm, nil
+}
+
+// check checks the metrics for inconsistencies.
+func (vm *VerticalMetrics) check() {
+	vm.Issues = vm.issues(fmt.Sprintf)
+}
+
+// issues returns the inconsistencies of the metrics, formatted with sprintf
+// (ie, a label printer's Sprintf, for localized issues).
+func (vm *VerticalMetrics) issues(sprintf func(string, ...any) string) []string {
+	var issues []string
+	add := func(format string, v ...any) {
+		issues = append(issues, sprintf(format, v...))
+	}
+	if vm.missingOS2 {
+		add("missing OS/2 table")
+		return issues
+	}
+	if 0 < vm.HheaDescender {
+		add("hhea descender %d is positive", vm.HheaDescender)
+	}
+	if 0 < vm.TypoDescender {
+		add("typo descender %d is positive", vm.TypoDescender)
+	}
+	// line height used by windows
+	win, winName := vm.WinLineHeight(), "win"
+	if vm.UseTypoMetrics {
+		win, winName = vm.TypoLineHeight(), "typo"
+	} else {
+		add("USE_TYPO_METRICS is not set")
+	}
+	if hhea := vm.HheaLineHeight(); hhea != win {
+		add("hhea line height %d differs from %s line height %d", hhea, winName, win)
+	}
+	if vm.HheaAscender != vm.TypoAscender || vm.HheaDescender != vm.TypoDescender {
+		add("hhea ascender/descender %d/%d differs from typo %d/%d", vm.HheaAscender, vm.HheaDescender, vm.TypoAscender, vm.TypoDescender)
+	}
+	if vm.WinAscent < vm.YMax || vm.WinDescent < -vm.YMin {
+		add("win ascent/descent %d/%d does not cover bounding box %d/%d, glyphs will be clipped on Windows", vm.WinAscent, vm.WinDescent, vm.YMax, -vm.YMin)
+	}
+	return issues
+}
+
+// RasterizeMetrics rasterizes the font's vertical metrics chart using the
+// options. See [Font.MetricsChart].
+func (font *Font) RasterizeMetrics(opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := font.MetricsChart(opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// MetricsChart lays out a chart of the font's vertical metrics on a canvas,
+// overlaying the hhea, typo, and win ascender and descender lines on a sample
+// line of text, annotated with the detected inconsistencies using the embedded
+// label font. The options' text (when not empty) is used as the sample text.
+// When opts is nil, the default options will be used.
+func (font *Font) MetricsChart(opts *Options) (_ *canvas.Canvas, err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	vm, err := font.VerticalMetrics()
+	if err != nil {
+		return nil, err
+	}
+	ff, err := font.Load(opts.Style)
+	if err != nil {
+		return nil, err
+	}
+	lff, err := LabelFont().Load(canvas.FontRegular)
+	if err != nil {
+		return nil, err
+	}
+	text := metricsText
+	if opts.Text != "" {
+		text = opts.Text
+	}
+	face := ff.Face(float64(opts.Size), opts.FG, opts.Style, opts.Variant)
+	label := lff.Face(0.4*float64(opts.Size), opts.FG)
+	// create canvas and context
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	ctx.SetFillColor(opts.FG)
+	// draw text on the baseline
+	ctx.DrawText(0, 0, canvas.NewTextLine(face, text, canvas.Left))
+	width := face.TextWidth(text)
+	// draw metric lines across the text, and a bar for each set of metrics
+	// at the right, labeled below
+	scale := float64(opts.Size) * 25.4 / 72 / float64(vm.UnitsPerEm)
+	line := 0.5 * 25.4 / 72
+	bottom := float64(min(vm.YMin, vm.HheaDescender, vm.TypoDescender, -vm.WinDescent)) * scale
+	lh := label.Metrics().LineHeight
+	ctx.DrawPath(0, -line/2, canvas.Rectangle(width, line))
+	x := width + lh
+	for i, m := range []struct {
+		name      string
+		asc, desc int
+	}{
+		{"hhea", vm.HheaAscender, vm.HheaDescender},
+		{"typo", vm.TypoAscender, vm.TypoDescender},
+		{"win", vm.WinAscent, -vm.WinDescent},
+	} {
+		asc, desc := float64(m.asc)*scale, float64(m.desc)*scale
+		ctx.SetFillColor(metricsColors[i])
+		ctx.DrawPath(0, asc-line/2, canvas.Rectangle(x+lh, line))
+		ctx.DrawPath(0, desc-line/2, canvas.Rectangle(x+lh, line))
+		ctx.DrawPath(x+lh/2-line, desc, canvas.Rectangle(2*line, asc-desc))
+		ctx.DrawText(x+lh/2, bottom-lh, canvas.NewTextLine(label, m.name, canvas.Center))
+		x += label.TextWidth(m.name) + lh
+	}
+	// annotate issues below
+	ctx.SetFillColor(opts.FG)
+	y := bottom - 2.5*lh
+	p := labelPrinter(opts.Language)
+	issues := vm.issues(func(format string, v ...any) string {
+		return p.Sprintf(format, v...)
+	})
+	for _, issue := range issues {
+		ctx.DrawText(0, y, canvas.NewTextLine(label, "! "+issue, canvas.Left))
+		y -= lh
+	}
+	// fit canvas to context
+	c.Fit(opts.Margin)
+	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
+
+// metricsText is the default metrics chart sample text.
+const metricsText = "ÅHxgjpÉ"
+
+// metricsColors are the hhea, typo, and win metric line colors.
+var metricsColors = []color.Color{
+	color.NRGBA{R: 0xd0, G: 0x30, B: 0x30, A: 0xff},
+	color.NRGBA{R: 0x30, G: 0x90, B: 0x30, A: 0xff},
+	color.NRGBA{R: 0x30, G: 0x50, B: 0xd0, A: 0xff},
+}
@@ -0,0 +1,101 @@
+package fontimg
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestVerticalMetrics(t *testing.T) {
+	for _, test := range testFonts(t) {
+		t.Run(test.name, func(t *testing.T) {
+			font := New(nil, test.path)
+			vm, err := font.VerticalMetrics()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if vm.UnitsPerEm == 0 || vm.HheaAscender <= 0 || vm.TypoAscender <= 0 || vm.WinAscent <= 0 {
+				t.Errorf("expected metrics, got: %+v", vm)
+			}
+			img, err := font.RasterizeMetrics(nil)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+				t.Errorf("expected non-empty image, got: %v", b)
+			}
+		})
+	}
+	// ubuntu's win ascent is less than its bounding box
+	var buf bytes.Buffer
+	New(nil, filepath.Join("testdata", "Ubuntu-R.ttf")).WriteYAML(&buf)
+	if !strings.Contains(buf.String(), "vertical_metrics_issues:\n") || !strings.Contains(buf.String(), "clipped") {
+		t.Errorf("expected vertical metrics issues, got:\n%s", buf.String())
+	}
+}
+
+func TestVerticalMetricsCheck(t *testing.T) {
+	tests := []struct {
+		vm  VerticalMetrics
+		exp []string
+	}{
+		{
+			VerticalMetrics{
+				YMin: -200, YMax: 800,
+				HheaAscender: 800, HheaDescender: -200,
+				TypoAscender: 800, TypoDescender: -200,
+				WinAscent: 800, WinDescent: 200,
+				UseTypoMetrics: true,
+			},
+			nil,
+		},
+		{
+			VerticalMetrics{
+				YMin: -200, YMax: 800,
+				HheaAscender: 800, HheaDescender: -200, HheaLineGap: 100,
+				TypoAscender: 800, TypoDescender: -200,
+				WinAscent: 800, WinDescent: 200,
+				UseTypoMetrics: true,
+			},
+			[]string{"hhea line height 1100 differs from typo line height 1000"},
+		},
+		{
+			VerticalMetrics{
+				YMin: -250, YMax: 800,
+				HheaAscender: 800, HheaDescender: 200,
+				TypoAscender: 750, TypoDescender: -200,
+				WinAscent: 800, WinDescent: 200,
+			},
+			[]string{
+				"hhea descender 200 is positive",
+				"USE_TYPO_METRICS is not set",
+				"hhea line height 600 differs from win line height 1000",
+				"hhea ascender/descender 800/200 differs from typo 750/-200",
+				"win ascent/descent 800/200 does not cover bounding box 800/250, glyphs will be clipped on Windows",
+			},
+		},
+	}
+	for i, test := range tests {
+		test.vm.check()
+		if !slices.Equal(test.vm.Issues, test.exp) {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, test.vm.Issues)
+		}
+	}
+	// localized issues
+	p := labelPrinter("de")
+	issues := tests[2].vm.issues(func(format string, v ...any) string {
+		return p.Sprintf(format, v...)
+	})
+	for i, issue := range issues {
+		if issue == tests[2].exp[i] {
+			t.Errorf("expected localized issue, got: %q", issue)
+		}
+	}
+	vm := VerticalMetrics{missingOS2: true}
+	vm.check()
+	if exp := []string{"missing OS/2 table"}; !slices.Equal(vm.Issues, exp) {
+		t.Errorf("expected %q, got: %q", exp, vm.Issues)
+	}
+}
@@ -0,0 +1,40 @@
+package fontimg
+
+import (
+	"fmt"
+	"os"
+)
+
+// Mmap creates a font image for the named file, memory mapping the file
+// read-only as the font's data instead of reading it. The mapped data is
+// shared with the operating system's page cache and is not copied when
+// loading the font, reducing the memory used when rendering many large fonts
+// (ie, CJK fonts) concurrently.
+//
+// The font's data aliases the mapping until the font is closed (see
+// [Font.Close]), after which any font family loaded from it must no longer be
+// used. The file must not be modified while mapped. On platforms without
+// memory mapping, the file is read instead.
+func Mmap(path string) (*Font, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	switch {
+	case err != nil:
+		return nil, err
+	case fi.Size() == 0:
+		return nil, fmt.Errorf("empty font file %q", path)
+	case 0 < ParseBudget && ParseBudget < fi.Size():
+		return nil, ErrResourceLimit
+	}
+	buf, unmap, err := mmap(f, int(fi.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("unable to map %q: %v", path, err)
+	}
+	font := New(buf, path)
+	font.unmap = unmap
+	return font, nil
+}
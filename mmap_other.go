@@ -0,0 +1,19 @@
+//go:build !unix
+
+package fontimg
+
+import (
+	"io"
+	"os"
+)
+
+// mmap reads size bytes of the file, as memory mapping is not supported.
+func mmap(f *os.File, size int) ([]byte, func() error, error) {
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		return nil, nil, err
+	}
+	return buf, func() error {
+		return nil
+	}, nil
+}
@@ -0,0 +1,120 @@
+package fontimg
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+)
+
+func TestMmap(t *testing.T) {
+	for _, test := range testFonts(t) {
+		t.Run(test.name, func(t *testing.T) {
+			font, err := Mmap(test.path)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			exp, err := os.ReadFile(test.path)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !bytes.Equal(font.Buf, exp) {
+				t.Errorf("expected mapped data to match file")
+			}
+			img, err := font.RasterizeOptions(nil)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			ref, err := New(nil, test.path).RasterizeOptions(nil)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !bytes.Equal(img.Pix, ref.Pix) {
+				t.Errorf("expected mapped font to match rasterized image")
+			}
+			if err := font.Close(); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if font.Buf != nil {
+				t.Errorf("expected mapped data to be released")
+			}
+			if err := font.Close(); err != nil {
+				t.Errorf("expected no error on second close, got: %v", err)
+			}
+			// closed fonts read from the path
+			if img, err = font.RasterizeOptions(nil); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !bytes.Equal(img.Pix, ref.Pix) {
+				t.Errorf("expected closed font to match rasterized image")
+			}
+		})
+	}
+	empty := filepath.Join(t.TempDir(), "empty.ttf")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := Mmap(empty); err == nil {
+		t.Errorf("expected error for empty file")
+	}
+}
+
+func TestMmapBudget(t *testing.T) {
+	defer func(n int64) { ParseBudget = n }(ParseBudget)
+	path := filepath.Join("testdata", "Ubuntu-R.ttf")
+	tests := []struct {
+		budget int64
+		exp    error
+	}{
+		{0, nil},
+		{-1, nil},
+		{1024, ErrResourceLimit},
+	}
+	for _, test := range tests {
+		ParseBudget = test.budget
+		font, err := Mmap(path)
+		switch {
+		case test.exp == nil && err != nil:
+			t.Errorf("budget %d expected no error, got: %v", test.budget, err)
+		case test.exp != nil && !errors.Is(err, test.exp):
+			t.Errorf("budget %d expected %v, got: %v", test.budget, test.exp, err)
+		case font != nil:
+			if err := font.Close(); err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		}
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	path := filepath.Join("testdata", "Ubuntu-R.ttf")
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		b.Fatalf("expected no error, got: %v", err)
+	}
+	font, err := Mmap(path)
+	if err != nil {
+		b.Fatalf("expected no error, got: %v", err)
+	}
+	defer font.Close()
+	for _, test := range []struct {
+		name string
+		font func() *Font
+	}{
+		{"path", func() *Font { return New(nil, path) }},
+		{"buf", func() *Font { return New(buf, path) }},
+		{"mmap", func() *Font { return New(font.Buf, path) }},
+	} {
+		b.Run(test.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := test.font().Load(canvas.FontRegular); err != nil {
+					b.Fatalf("expected no error, got: %v", err)
+				}
+			}
+		})
+	}
+}
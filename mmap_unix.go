@@ -0,0 +1,19 @@
+//go:build unix
+
+package fontimg
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmap maps size bytes of the file read-only.
+func mmap(f *os.File, size int) ([]byte, func() error, error) {
+	buf, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return buf, func() error {
+		return syscall.Munmap(buf)
+	}, nil
+}
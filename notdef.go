@@ -0,0 +1,147 @@
+package fontimg
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tdewolff/canvas"
+	fontpkg "github.com/tdewolff/font"
+)
+
+// Notdef is the rendering of characters missing from a font.
+type Notdef string
+
+// Notdef renderings.
+const (
+	// NotdefFont renders missing characters with the font's .notdef glyph.
+	NotdefFont Notdef = "font"
+	// NotdefHexBox renders missing characters as a box containing the
+	// character's hexadecimal code point, as terminals do.
+	NotdefHexBox Notdef = "hexbox"
+	// NotdefFallback renders missing characters as the replacement
+	// character (U+FFFD) using the embedded label font.
+	NotdefFallback Notdef = "fallback"
+	// NotdefSkip skips missing characters.
+	NotdefSkip Notdef = "skip"
+)
+
+// Notdefs returns the number of characters of the font image's text that are
+// missing from the font, and are rendered as .notdef glyphs (or as the
+// options' [Notdef] rendering). Bitmap fonts are not supported. When opts is
+// nil, the default options will be used.
+func (font *Font) Notdefs(opts *Options) (_ int, err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return 0, err
+	}
+	buf, err := font.text(opts, sfnt)
+	if err != nil {
+		return 0, err
+	}
+	lines, _, _ := breakLines(buf, opts.Size)
+	return len(notdefRunes(sfnt, lines)), nil
+}
+
+// notdefRunes returns the runes of the lines missing from the font.
+func notdefRunes(sfnt *fontpkg.SFNT, lines []string) []rune {
+	var runes []rune
+	for _, line := range lines {
+		for _, r := range strings.TrimSpace(line) {
+			if notdef(sfnt, r) {
+				runes = append(runes, r)
+			}
+		}
+	}
+	return runes
+}
+
+// notdef returns true when the rune is missing from the font. Control
+// characters are never rendered, and are not considered missing.
+func notdef(sfnt *fontpkg.SFNT, r rune) bool {
+	return !unicode.IsControl(r) && sfnt.GlyphIndex(r) == 0
+}
+
+// drawNotdefs draws the line with its top at y, rendering the characters
+// missing from the face using the options' [Notdef] rendering, returning the
+// height of the drawn line. When ef is not nil, runs of missing emoji mapped
+// by the emoji font are drawn with it.
+func drawNotdefs(ctx *canvas.Context, face *canvas.FontFace, line string, y float64, opts *Options, ef *emojiFallback) (float64, error) {
+	var label *canvas.FontFamily
+	switch opts.Notdef {
+	case "", NotdefFont, NotdefSkip:
+	case NotdefHexBox, NotdefFallback:
+		var err error
+		if label, err = LabelFont().Load(canvas.FontRegular); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("invalid notdef rendering %q", opts.Notdef)
+	}
+	metrics := face.Metrics()
+	x, baseline := float64(0), y-metrics.Ascent
+	var sb strings.Builder
+	flush := func() {
+		if sb.Len() != 0 {
+			ctx.DrawText(x, baseline, canvas.NewTextLine(face, sb.String(), canvas.Left))
+			x += face.TextWidth(sb.String())
+			sb.Reset()
+		}
+	}
+	for i := 0; i < len(line); {
+		if ef != nil {
+			if n := ef.span(face.Font.SFNT, line[i:]); n != 0 {
+				flush()
+				x += ef.draw(ctx, face, line[i:i+n], x, baseline)
+				i += n
+				continue
+			}
+		}
+		r, n := utf8.DecodeRuneInString(line[i:])
+		i += n
+		if !notdef(face.Font.SFNT, r) || opts.Notdef == "" || opts.Notdef == NotdefFont {
+			sb.WriteRune(r)
+			continue
+		}
+		flush()
+		switch opts.Notdef {
+		case NotdefHexBox:
+			x += drawHexBox(ctx, face, label, x, baseline, r)
+		case NotdefFallback:
+			fallback := label.Face(face.Size*72/25.4, face.Fill.Color)
+			ctx.DrawText(x, baseline, canvas.NewTextLine(fallback, string(unicode.ReplacementChar), canvas.Left))
+			x += fallback.TextWidth(string(unicode.ReplacementChar))
+		}
+	}
+	flush()
+	return metrics.LineHeight, nil
+}
+
+// drawHexBox draws a box the height of the face's cap height at the
+// baseline, containing the rune's hexadecimal code point in two rows drawn
+// using the label font, returning the box's advance.
+func drawHexBox(ctx *canvas.Context, face *canvas.FontFace, label *canvas.FontFamily, x, baseline float64, r rune) float64 {
+	h := face.Metrics().CapHeight
+	if h <= 0 {
+		h = 0.7 * face.Size
+	}
+	s := fmt.Sprintf("%04X", r)
+	rows := []string{s[:len(s)/2], s[len(s)/2:]}
+	digits := label.Face(0.4*h*72/25.4, face.Fill.Color)
+	line, pad := h/16, h/8
+	w := digits.TextWidth(rows[1]) + 2*pad
+	ctx.Push()
+	defer ctx.Pop()
+	ctx.SetFillColor(canvas.Transparent)
+	ctx.SetStrokeColor(face.Fill.Color)
+	ctx.SetStrokeWidth(line)
+	ctx.DrawPath(x+pad/2+line/2, baseline+line/2, canvas.Rectangle(w-line, h-line))
+	ctx.DrawText(x+pad/2+w/2, baseline+h/2+pad/2, canvas.NewTextLine(digits, rows[0], canvas.Center))
+	ctx.DrawText(x+pad/2+w/2, baseline+pad+line, canvas.NewTextLine(digits, rows[1], canvas.Center))
+	return w + pad
+}
@@ -0,0 +1,109 @@
+package fontimg
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kenshaw/fontimg/testfonts"
+)
+
+func TestNotdefs(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	tests := []struct {
+		text string
+		exp  int
+	}{
+		{"The quick brown fox", 0},
+		{"A日本B", 2},
+		{"A\u0378 B\n😀", 2},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Text = test.text
+			n, err := font.Notdefs(opts)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if n != test.exp {
+				t.Errorf("expected %d, got: %d", test.exp, n)
+			}
+		})
+	}
+}
+
+func TestNotdef(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	rasterize := func(text string, notdef Notdef) []byte {
+		t.Helper()
+		opts := DefaultOptions()
+		opts.Text, opts.Notdef = text, notdef
+		img, err := font.RasterizeOptions(opts)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		return img.Pix
+	}
+	def := rasterize("A日B x", "")
+	tests := []struct {
+		notdef Notdef
+		exp    []byte
+	}{
+		{NotdefFont, def},
+		{NotdefHexBox, nil},
+		{NotdefFallback, nil},
+		{NotdefSkip, rasterize("AB x", "")},
+	}
+	for _, test := range tests {
+		t.Run(string(test.notdef), func(t *testing.T) {
+			pix := rasterize("A日B x", test.notdef)
+			switch {
+			case test.exp != nil && !bytes.Equal(pix, test.exp):
+				t.Errorf("expected image to match")
+			case test.exp == nil && bytes.Equal(pix, def):
+				t.Errorf("expected image to differ from .notdef rendering")
+			}
+		})
+	}
+	opts := DefaultOptions()
+	opts.Text, opts.Notdef = "A日B", "bad"
+	if _, err := font.RasterizeOptions(opts); err == nil {
+		t.Errorf("expected error for invalid notdef rendering")
+	}
+}
+
+func TestStrict(t *testing.T) {
+	ttf, fnt := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf")), &Font{Buf: testFNT(0x0300, 12, 400, false)}
+	tests := []struct {
+		name string
+		font *Font
+		text string
+		exp  []rune
+	}{
+		{"ttf", ttf, "The quick brown fox", nil},
+		{"ttf missing", ttf, "A日本\n日B", []rune{'日', '本'}},
+		{"cjk", New(testfonts.Bytes(testfonts.CJK), testfonts.CJK), "A日本\n日B", nil},
+		{"fnt", fnt, "The quick brown fox", nil},
+		{"fnt missing", fnt, "Aé ü é", []rune{'é', 'ü'}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Text, opts.Strict = test.text, true
+			_, err := test.font.RasterizeOptions(opts)
+			var e *MissingRunesError
+			switch {
+			case test.exp == nil && err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case test.exp == nil:
+			case !errors.As(err, &e):
+				t.Fatalf("expected MissingRunesError, got: %v", err)
+			case !slices.Equal(e.Runes, test.exp):
+				t.Errorf("expected %q, got: %q", test.exp, e.Runes)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package fontimg
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"text/template"
+
+	"github.com/tdewolff/canvas"
+	"golang.org/x/text/language"
+)
+
+// Options are the options used when rasterizing a font image.
+type Options struct {
+	// Template is the text template. When nil, the default template is used.
+	Template *template.Template
+	// Text is the sample text. When not empty, it is used in place of the
+	// font's sample text.
+	Text string
+	// Size is the base font size.
+	Size int
+	// Style is the font style.
+	Style canvas.FontStyle
+	// Variant is the font variant.
+	Variant canvas.FontVariant
+	// Instance is the name of the variable font's named instance to render
+	// (see [Font.Instances]). When empty, the font's default instance is
+	// rendered.
+	Instance string
+	// Variations are the variable font's axis coordinates, by axis tag (ie,
+	// "wght"), in design units, applied on top of the instance's coordinates
+	// (see [Font.Instantiate]).
+	Variations map[string]float64
+	// FG is the foreground (text) color.
+	FG color.Color
+	// BG is the background color.
+	BG color.Color
+	// NoBackground skips drawing the background, leaving it transparent.
+	NoBackground bool
+	// DPI is the rasterization resolution.
+	DPI float64
+	// Margin is the margin around the text.
+	Margin float64
+	// Trim crops the rasterized image to the bounds of its content plus the
+	// margin, giving equal margins on all sides regardless of the glyphs'
+	// extents (see [Trim]).
+	Trim bool
+	// MaxDimension is the maximum width and height of the rasterized image,
+	// in pixels. Content that would exceed it (ie, from a long template or
+	// family name) is scaled down to fit, keeping the margin. When zero, the
+	// image is not scaled.
+	MaxDimension int
+	// Paragraph enables paragraph mode, wrapping each line of text to the
+	// paragraph's width. When nil, lines are not wrapped.
+	Paragraph *Paragraph
+	// OpticalMargin aligns lines on a common left optical margin, by shifting
+	// each line so that the ink of its first glyph starts on the margin,
+	// rather than its origin (ie, lines of different sizes, as in a
+	// [Font.Waterfall], are not indented by their scaled side bearings). Not
+	// applied in paragraph mode, or to lines with missing characters rendered
+	// with a notdef rendering other than [NotdefFont].
+	OpticalMargin bool
+	// BaselineGrid is the baseline grid increment, in millimeters. When not
+	// zero, the baseline of each line is snapped down to the next multiple of
+	// the increment from the top of the text, giving consistent baseline to
+	// baseline distances. The same lines as [Options.OpticalMargin] are
+	// snapped.
+	BaselineGrid float64
+	// ShowSpacing draws each glyph's advance box, side bearings, and origin
+	// under the text, for debugging spacing. Not applied in paragraph mode.
+	ShowSpacing bool
+	// Notdef is the rendering of characters missing from the font. When
+	// empty, the font's .notdef glyph is used. In paragraph mode, only
+	// [NotdefSkip] is applied.
+	Notdef Notdef
+	// EmojiFallback draws runs of emoji missing from the font (ie, in UI
+	// samples) with the platform emoji font (see [SystemEmojiFont]), flagging
+	// each substituted run with an underline, instead of with the options'
+	// [Notdef] rendering. Emoji drawn with the emoji font are not missing for
+	// [Options.Strict]. Not applied in paragraph mode, or when no emoji font
+	// is installed.
+	EmojiFallback bool
+	// Strict returns a [*MissingRunesError] listing the missing runes, instead
+	// of rendering the font image, when the text contains characters missing
+	// from the font.
+	Strict bool
+	// Footer is an attribution footer drawn below the text. When nil, no
+	// footer is drawn.
+	Footer *Footer
+	// SymbolGlyphs is the number of glyphs shown in a row, in place of the
+	// default template, for symbol fonts without sample text (see
+	// [Font.IsSymbol]). When zero, 16 glyphs are shown.
+	SymbolGlyphs int
+	// Language is the language of built-in labels (ie, "de"), such as chart
+	// legends, glyph counts and page numbers, including the formatting of
+	// their numbers. When empty, English is used. See [RegisterLabels].
+	Language string
+}
+
+// Paragraph are the paragraph mode options.
+type Paragraph struct {
+	// Width is the paragraph width, in millimeters.
+	Width float64
+	// Justify justifies wrapped lines.
+	Justify bool
+	// Language is the language used to hyphenate words (ie, "en-US"). When
+	// empty, words are not hyphenated. See [RegisterHyphenation].
+	Language string
+	// ShowStretch highlights the inter-word spaces of justified lines,
+	// colored by how much they are stretched: green when close to the
+	// natural space width, through yellow, to red when over-stretched.
+	ShowStretch bool
+	// MaxStretch is the ratio of stretched to natural space width above which
+	// a line is considered over-stretched. When zero, 2 is used.
+	MaxStretch float64
+	// HangPunctuation hangs punctuation (ie, quotes, periods, commas, and
+	// hyphens) at the start and end of lines into the margins, optically
+	// aligning the margins.
+	HangPunctuation bool
+}
+
+// DefaultOptions returns the default options.
+func DefaultOptions() *Options {
+	return &Options{
+		Size:    48,
+		Style:   canvas.FontRegular,
+		Variant: canvas.FontNormal,
+		FG:      color.Black,
+		BG:      color.White,
+		DPI:     100,
+		Margin:  5,
+	}
+}
+
+// MaxImageDimension is the maximum width or height, in pixels, of a font
+// image allowed by [Options.Validate].
+var MaxImageDimension = 16384
+
+// Validate checks the options for impossible or unusable combinations of
+// values (ie, a zero DPI, a negative margin, or the same foreground and
+// background colors), that would otherwise render broken or blank images.
+// All detected problems are returned, joined.
+func (opts *Options) Validate() error {
+	var errs []error
+	add := func(format string, v ...any) {
+		errs = append(errs, fmt.Errorf(format, v...))
+	}
+	if opts.Size <= 0 {
+		add("invalid size %d: must be greater than 0", opts.Size)
+	}
+	if !(0 < opts.DPI) || math.IsInf(opts.DPI, 0) {
+		add("invalid dpi %g: must be greater than 0 (ie, 100)", opts.DPI)
+	}
+	if !(0 <= opts.Margin) || math.IsInf(opts.Margin, 0) {
+		add("invalid margin %g: must be 0 or greater", opts.Margin)
+	}
+	switch fg := colorHex(opts.FG); {
+	case opts.FG == nil:
+		add("fg not set: set a foreground color (ie, color.Black)")
+	case !opts.NoBackground && opts.BG == nil:
+		add("bg not set: set a background color (ie, color.White), or set NoBackground")
+	case fg[6:] == "00":
+		add("fg %s is fully transparent: the text would not be visible", fg)
+	case !opts.NoBackground && fg == colorHex(opts.BG):
+		add("fg and bg are both %s: the text would not be visible", fg)
+	}
+	if p := opts.Paragraph; p != nil {
+		if !(0 <= p.Width) || math.IsInf(p.Width, 0) {
+			add("invalid paragraph width %g: must be 0 or greater", p.Width)
+		}
+		if !(0 <= p.MaxStretch) || math.IsInf(p.MaxStretch, 0) {
+			add("invalid paragraph max stretch %g: must be 0 or greater", p.MaxStretch)
+		}
+	}
+	if !(0 <= opts.BaselineGrid) || math.IsInf(opts.BaselineGrid, 0) {
+		add("invalid baseline grid %g: must be 0 or greater", opts.BaselineGrid)
+	}
+	if opts.MaxDimension < 0 {
+		add("invalid max dimension %d: must be 0 or greater", opts.MaxDimension)
+	}
+	if opts.SymbolGlyphs < 0 {
+		add("invalid symbol glyphs %d: must be 0 or greater", opts.SymbolGlyphs)
+	}
+	if opts.Language != "" {
+		if _, err := language.Parse(opts.Language); err != nil {
+			add("invalid language %q: %v", opts.Language, err)
+		}
+	}
+	switch opts.Notdef {
+	case "", NotdefFont, NotdefHexBox, NotdefFallback, NotdefSkip:
+	default:
+		add("invalid notdef rendering %q: must be one of font, hexbox, fallback or skip", opts.Notdef)
+	}
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+	// pixel dimensions, checked only for otherwise valid options
+	dpmm := opts.DPI / 25.4
+	if n := math.Ceil(float64(opts.Size) * opts.DPI / 72); MaxImageDimension < int(n) {
+		add("size %d at %g dpi is %g pixels, exceeding the maximum image dimension %d: reduce the size or dpi", opts.Size, opts.DPI, n, MaxImageDimension)
+	}
+	if n := math.Ceil(2 * opts.Margin * dpmm); MaxImageDimension < int(n) {
+		add("margin %g at %g dpi is %g pixels, exceeding the maximum image dimension %d: reduce the margin or dpi", opts.Margin, opts.DPI, n, MaxImageDimension)
+	} else if 0 < opts.MaxDimension && opts.MaxDimension <= int(n) {
+		add("margin %g at %g dpi is %g pixels, leaving no room for content within max dimension %d: reduce the margin or dpi", opts.Margin, opts.DPI, n, opts.MaxDimension)
+	}
+	if p := opts.Paragraph; p != nil {
+		if n := math.Ceil((p.Width + 2*opts.Margin) * dpmm); MaxImageDimension < int(n) {
+			add("paragraph width %g at %g dpi is %g pixels, exceeding the maximum image dimension %d: reduce the width or dpi", p.Width, opts.DPI, n, MaxImageDimension)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// template returns the options' template, or the default template.
+func (opts *Options) template() *template.Template {
+	if opts.Template != nil {
+		return opts.Template
+	}
+	return tplDefault
+}
+
+// RasterizeOption is a rasterization option, modifying the options used by
+// [Font.RasterizeContext].
+type RasterizeOption func(*Options)
+
+// WithOptions is a rasterization option to set all the options, replacing
+// those set by any prior rasterization option. When opts is nil, the default
+// options are used.
+func WithOptions(opts *Options) RasterizeOption {
+	return func(o *Options) {
+		if opts == nil {
+			opts = DefaultOptions()
+		}
+		*o = *opts
+	}
+}
+
+// WithTemplate is a rasterization option to set the text template. When nil,
+// the default template is used.
+func WithTemplate(tpl *template.Template) RasterizeOption {
+	return func(o *Options) {
+		o.Template = tpl
+	}
+}
+
+// WithText is a rasterization option to set the sample text.
+func WithText(text string) RasterizeOption {
+	return func(o *Options) {
+		o.Text = text
+	}
+}
+
+// WithSize is a rasterization option to set the base font size.
+func WithSize(size int) RasterizeOption {
+	return func(o *Options) {
+		o.Size = size
+	}
+}
+
+// WithStyle is a rasterization option to set the font style.
+func WithStyle(style canvas.FontStyle) RasterizeOption {
+	return func(o *Options) {
+		o.Style = style
+	}
+}
+
+// WithVariant is a rasterization option to set the font variant.
+func WithVariant(variant canvas.FontVariant) RasterizeOption {
+	return func(o *Options) {
+		o.Variant = variant
+	}
+}
+
+// WithInstance is a rasterization option to set the variable font's named
+// instance and axis coordinates (see [Options.Variations]).
+func WithInstance(instance string, variations map[string]float64) RasterizeOption {
+	return func(o *Options) {
+		o.Instance, o.Variations = instance, variations
+	}
+}
+
+// WithColors is a rasterization option to set the foreground (text) and
+// background colors.
+func WithColors(fg, bg color.Color) RasterizeOption {
+	return func(o *Options) {
+		o.FG, o.BG = fg, bg
+	}
+}
+
+// WithDPI is a rasterization option to set the rasterization resolution.
+func WithDPI(dpi float64) RasterizeOption {
+	return func(o *Options) {
+		o.DPI = dpi
+	}
+}
+
+// WithMargin is a rasterization option to set the margin around the text.
+func WithMargin(margin float64) RasterizeOption {
+	return func(o *Options) {
+		o.Margin = margin
+	}
+}
+
+// WithTrim is a rasterization option to crop the image to its content plus
+// the margin (see [Options.Trim]).
+func WithTrim() RasterizeOption {
+	return func(o *Options) {
+		o.Trim = true
+	}
+}
+
+// WithMaxDimension is a rasterization option to set the maximum width and
+// height of the image, in pixels (see [Options.MaxDimension]).
+func WithMaxDimension(dim int) RasterizeOption {
+	return func(o *Options) {
+		o.MaxDimension = dim
+	}
+}
+
+// WithLanguage is a rasterization option to set the language of built-in
+// labels (see [Options.Language]).
+func WithLanguage(lang string) RasterizeOption {
+	return func(o *Options) {
+		o.Language = lang
+	}
+}
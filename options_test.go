@@ -0,0 +1,106 @@
+package fontimg
+
+import (
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(*Options)
+		exp  []string
+	}{
+		{"default", func(*Options) {}, nil},
+		{"zero dpi", func(opts *Options) { opts.DPI = 0 }, []string{"invalid dpi 0"}},
+		{"zero size", func(opts *Options) { opts.Size = 0 }, []string{"invalid size 0"}},
+		{"negative margin", func(opts *Options) { opts.Margin = -1 }, []string{"invalid margin -1"}},
+		{"same colors", func(opts *Options) { opts.BG = color.RGBA{A: 0xff} }, []string{"fg and bg are both 000000ff"}},
+		{"same colors no background", func(opts *Options) {
+			opts.BG, opts.NoBackground = color.Black, true
+		}, nil},
+		{"transparent fg", func(opts *Options) { opts.FG = color.Transparent }, []string{"fg 00000000 is fully transparent"}},
+		{"nil fg", func(opts *Options) { opts.FG = nil }, []string{"fg not set"}},
+		{"nil bg", func(opts *Options) { opts.BG = nil }, []string{"bg not set"}},
+		{"nil bg no background", func(opts *Options) { opts.BG, opts.NoBackground = nil, true }, nil},
+		{"paragraph", func(opts *Options) {
+			opts.Paragraph = &Paragraph{Width: -10, MaxStretch: -1}
+		}, []string{"invalid paragraph width -10", "invalid paragraph max stretch -1"}},
+		{"notdef", func(opts *Options) { opts.Notdef = "box" }, []string{`invalid notdef rendering "box"`}},
+		{"multiple", func(opts *Options) {
+			opts.DPI, opts.Margin = 0, -1
+		}, []string{"invalid dpi 0", "invalid margin -1"}},
+		{"size too large", func(opts *Options) { opts.Size, opts.DPI = 4096, 600 }, []string{"size 4096 at 600 dpi is 34134 pixels"}},
+		{"margin too large", func(opts *Options) { opts.Margin = 5000 }, []string{"margin 5000 at 100 dpi"}},
+		{"negative max dimension", func(opts *Options) { opts.MaxDimension = -1 }, []string{"invalid max dimension -1"}},
+		{"max dimension too small", func(opts *Options) { opts.MaxDimension = 20 }, []string{"margin 5 at 100 dpi is 40 pixels, leaving no room"}},
+		{"paragraph too wide", func(opts *Options) {
+			opts.Paragraph = &Paragraph{Width: 10000}
+		}, []string{"paragraph width 10000 at 100 dpi"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			test.f(opts)
+			err := opts.Validate()
+			switch {
+			case test.exp == nil && err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case test.exp != nil && err == nil:
+				t.Fatalf("expected error")
+			case err == nil:
+				return
+			}
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(test.exp) {
+				t.Fatalf("expected %d errors, got: %q", len(test.exp), lines)
+			}
+			for i, s := range test.exp {
+				if !strings.HasPrefix(lines[i], s) {
+					t.Errorf("expected error %d to start with %q, got: %q", i, s, lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRasterizeOption(t *testing.T) {
+	tpl, err := NewTemplate("{{ .Name }}")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := DefaultOptions()
+	exp.Template, exp.Text, exp.Size = tpl, "abc", 24
+	exp.Style, exp.Variant = canvas.FontBold|canvas.FontItalic, canvas.FontSmallcaps
+	exp.Instance, exp.Variations = "Light", map[string]float64{"wght": 300}
+	exp.FG, exp.BG = color.White, color.Black
+	exp.DPI, exp.Margin, exp.Trim, exp.MaxDimension, exp.Language = 200, 2, true, 1024, "de"
+	opts := DefaultOptions()
+	for _, o := range []RasterizeOption{
+		WithTemplate(tpl),
+		WithText("abc"),
+		WithSize(24),
+		WithStyle(canvas.FontBold | canvas.FontItalic),
+		WithVariant(canvas.FontSmallcaps),
+		WithInstance("Light", map[string]float64{"wght": 300}),
+		WithColors(color.White, color.Black),
+		WithDPI(200),
+		WithMargin(2),
+		WithTrim(),
+		WithMaxDimension(1024),
+		WithLanguage("de"),
+	} {
+		o(opts)
+	}
+	if !reflect.DeepEqual(opts, exp) {
+		t.Errorf("expected %+v, got: %+v", exp, opts)
+	}
+	WithOptions(nil)(opts)
+	if !reflect.DeepEqual(opts, DefaultOptions()) {
+		t.Errorf("expected default options, got: %+v", opts)
+	}
+}
@@ -0,0 +1,164 @@
+package fontimg
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// BoundsOutlier is a glyph whose bounding box wildly exceeds the font's
+// typical extents, a common symptom of corrupt or misdesigned glyphs.
+type BoundsOutlier struct {
+	GlyphID uint16
+	// Name is the glyph name, from the font's post or CFF table.
+	Name string
+	// Runes are the runes mapped to the glyph.
+	Runes []rune
+	// XMin, YMin, XMax, and YMax are the glyph's bounding box, in font units.
+	XMin, YMin, XMax, YMax int
+	// Reasons are the extents exceeded (ie, "top 3200 exceeds 1250").
+	Reasons []string
+}
+
+// BoundsOutliers returns the font's glyphs whose bounding boxes exceed the
+// font's typical extents by more than half an em. Typical extents are the
+// 99th percentile of the glyphs' tops, widths, and left and right sides, and
+// the 1st percentile of their bottoms, extended to the font's ascender and
+// descender.
+func (font *Font) BoundsOutliers() (_ []BoundsOutlier, err error) {
+	defer recoverError(&err)
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	cmap, err := font.Cmap()
+	if err != nil {
+		return nil, err
+	}
+	runes := make(map[uint16][]rune)
+	for _, e := range cmap {
+		runes[e.GlyphID] = append(runes[e.GlyphID], e.Rune)
+	}
+	var v []BoundsOutlier
+	for id := range sfnt.NumGlyphs() {
+		xmin, ymin, xmax, ymax := sfnt.GlyphBounds(id)
+		if xmin == xmax && ymin == ymax {
+			continue
+		}
+		v = append(v, BoundsOutlier{
+			GlyphID: id,
+			Name:    sfnt.GlyphName(id),
+			Runes:   runes[id],
+			XMin:    int(xmin),
+			YMin:    int(ymin),
+			XMax:    int(xmax),
+			YMax:    int(ymax),
+		})
+	}
+	var asc, desc int
+	if sfnt.Hhea != nil {
+		asc, desc = int(sfnt.Hhea.Ascender), int(sfnt.Hhea.Descender)
+	}
+	return boundsOutliers(v, int(sfnt.Head.UnitsPerEm), asc, desc), nil
+}
+
+// boundsOutliers returns the glyphs exceeding the typical extents, with their
+// reasons set.
+func boundsOutliers(glyphs []BoundsOutlier, upem, asc, desc int) []BoundsOutlier {
+	if len(glyphs) == 0 {
+		return nil
+	}
+	percentile := func(p float64, f func(BoundsOutlier) int) int {
+		v := make([]int, len(glyphs))
+		for i, g := range glyphs {
+			v[i] = f(g)
+		}
+		slices.Sort(v)
+		return v[min(int(p*float64(len(v))), len(v)-1)]
+	}
+	margin := upem / 2
+	top := max(percentile(0.99, func(g BoundsOutlier) int { return g.YMax }), asc) + margin
+	bottom := min(percentile(0.01, func(g BoundsOutlier) int { return g.YMin }), desc) - margin
+	left := min(percentile(0.01, func(g BoundsOutlier) int { return g.XMin }), 0) - margin
+	right := percentile(0.99, func(g BoundsOutlier) int { return g.XMax }) + margin
+	width := percentile(0.99, func(g BoundsOutlier) int { return g.XMax - g.XMin }) + margin
+	var v []BoundsOutlier
+	for _, g := range glyphs {
+		add := func(name string, n, limit int) {
+			g.Reasons = append(g.Reasons, fmt.Sprintf("%s %d exceeds %d", name, n, limit))
+		}
+		if top < g.YMax {
+			add("top", g.YMax, top)
+		}
+		if g.YMin < bottom {
+			add("bottom", g.YMin, bottom)
+		}
+		if g.XMin < left {
+			add("left", g.XMin, left)
+		}
+		if right < g.XMax {
+			add("right", g.XMax, right)
+		}
+		if w := g.XMax - g.XMin; width < w {
+			add("width", w, width)
+		}
+		if len(g.Reasons) != 0 {
+			v = append(v, g)
+		}
+	}
+	return v
+}
+
+// RasterizeBoundsOutliers rasterizes a glyph sheet of the font's bounds
+// outliers using the options. See [Font.BoundsOutliersSheet].
+func (font *Font) RasterizeBoundsOutliers(opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := font.BoundsOutliersSheet(opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// BoundsOutliersSheet lays out a glyph sheet (see [Font.GlyphSheet]) of the
+// font's bounds outliers (see [Font.BoundsOutliers]), highlighting each
+// glyph's bounding box. Returns an error when the font has no outliers. When
+// opts is nil, the default options will be used.
+func (font *Font) BoundsOutliersSheet(opts *Options) (*canvas.Canvas, error) {
+	outliers, err := font.BoundsOutliers()
+	if err != nil {
+		return nil, err
+	}
+	if len(outliers) == 0 {
+		return nil, fmt.Errorf("no bounds outliers")
+	}
+	m := make(map[uint16]BoundsOutlier)
+	matches := make([]GlyphMatch, len(outliers))
+	for i, g := range outliers {
+		m[g.GlyphID] = g
+		matches[i] = GlyphMatch{
+			GlyphID: g.GlyphID,
+			Name:    g.Name,
+			Runes:   g.Runes,
+		}
+	}
+	return font.glyphSheet(matches, opts, func(ctx *canvas.Context, id uint16, x, y, scale float64) error {
+		g := m[id]
+		ctx.SetFillColor(outlierColor)
+		w, h := float64(g.XMax-g.XMin)*scale, float64(g.YMax-g.YMin)*scale
+		line := 0.5 * 25.4 / 72
+		ctx.DrawPath(x+float64(g.XMin)*scale, y+float64(g.YMin)*scale, canvas.Rectangle(w, h).Append(
+			canvas.Rectangle(w-2*line, h-2*line).Translate(line, line).Reverse(),
+		))
+		return nil
+	})
+}
+
+// outlierColor is the bounds outlier highlight color.
+var outlierColor color.Color = color.NRGBA{R: 0xe0, G: 0x20, B: 0x20, A: 0xff}
@@ -0,0 +1,40 @@
+package fontimg
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBoundsOutliers(t *testing.T) {
+	for _, test := range testFonts(t) {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := New(nil, test.path).BoundsOutliers()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			for _, g := range v {
+				t.Logf("%d %s: %v", g.GlyphID, g.Name, g.Reasons)
+			}
+		})
+	}
+	var glyphs []BoundsOutlier
+	for i := range 200 {
+		glyphs = append(glyphs, BoundsOutlier{GlyphID: uint16(i), XMin: 50, YMin: -200, XMax: 500 + i, YMax: 700})
+	}
+	glyphs[10].YMax = 5000
+	glyphs[20].XMin, glyphs[20].XMax = -3000, 3000
+	v := boundsOutliers(glyphs, 1000, 800, -200)
+	var ids []uint16
+	for _, g := range v {
+		ids = append(ids, g.GlyphID)
+	}
+	if !slices.Equal(ids, []uint16{10, 20}) {
+		t.Fatalf("expected outliers [10 20], got: %v", ids)
+	}
+	if exp := []string{"top 5000 exceeds 1300"}; !slices.Equal(v[0].Reasons, exp) {
+		t.Errorf("expected %q, got: %q", exp, v[0].Reasons)
+	}
+	if n := len(v[1].Reasons); n != 3 {
+		t.Errorf("expected 3 reasons, got: %q", v[1].Reasons)
+	}
+}
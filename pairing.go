@@ -0,0 +1,87 @@
+package fontimg
+
+import (
+	"image"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// RasterizePairing rasterizes a font pairing image using the options. See
+// [Pairing].
+func RasterizePairing(heading, body *Font, opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := Pairing(heading, body, opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// Pairing lays out a font pairing on a canvas, showing a headline set in the
+// heading font above a caption and paragraph set in the body font, for
+// evaluating how the two fonts work together. The options' size is used as
+// the body size, and the options' text (when not empty) is used as the
+// paragraph. When opts is nil, the default options will be used.
+func Pairing(heading, body *Font, opts *Options) (_ *canvas.Canvas, err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	// load font families
+	hff, err := heading.Load(opts.Style)
+	if err != nil {
+		return nil, err
+	}
+	bff, err := body.Load(opts.Style)
+	if err != nil {
+		return nil, err
+	}
+	paragraph := pairingParagraph
+	if opts.Text != "" {
+		paragraph = opts.Text
+	}
+	// create canvas and context
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	ctx.SetFillColor(opts.FG)
+	// draw text, wrapping at 35 em of the body size and separated by half an em
+	size := float64(opts.Size)
+	em := size * 25.4 / 72
+	texts := []*canvas.Text{
+		canvas.NewTextBox(
+			hff.Face(2*size, opts.FG, opts.Style, opts.Variant),
+			pairingHeadline, 35*em, 0, canvas.Left, canvas.Top, nil,
+		),
+		canvas.NewTextBox(
+			bff.Face(0.75*size, opts.FG, opts.Style, opts.Variant),
+			heading.BestName()+" / "+body.BestName(), 35*em, 0, canvas.Left, canvas.Top, nil,
+		),
+		canvas.NewTextBox(
+			bff.Face(size, opts.FG, opts.Style, opts.Variant),
+			paragraph, 35*em, 0, canvas.Justify, canvas.Top, nil,
+		),
+	}
+	y := float64(0)
+	for _, txt := range texts {
+		b := txt.Bounds()
+		ctx.DrawText(0, y, txt)
+		y += b.Y0 - b.Y1 - em/2
+	}
+	// fit canvas to context
+	c.Fit(opts.Margin)
+	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
+
+// Pairing text.
+const (
+	pairingHeadline  = "Jackdaws Love My Big Sphinx of Quartz"
+	pairingParagraph = "The quick brown fox jumps over the lazy dog. Pack my box with five dozen liquor jugs, and how vexingly quick daft zebras jump! Sphinx of black quartz, judge my vow: the five boxing wizards jump quickly, while a wizard's job is to vex chumps quickly in fog."
+)
@@ -0,0 +1,31 @@
+package fontimg
+
+import (
+	"testing"
+)
+
+func TestRasterizePairing(t *testing.T) {
+	tests := testFonts(t)
+	if len(tests) < 2 {
+		t.Fatalf("expected at least 2 test fonts, got: %d", len(tests))
+	}
+	heading, body := New(nil, tests[0].path), New(nil, tests[1].path)
+	opts := DefaultOptions()
+	opts.Size = 12
+	img, err := RasterizePairing(heading, body, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		t.Fatalf("expected non-empty image, got: %v", b)
+	}
+	opts.Text = "short"
+	short, err := RasterizePairing(heading, body, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := short.Bounds(); b.Dy() <= s.Dy() {
+		t.Errorf("expected shorter paragraph to produce shorter image, got: %v <= %v", b, s)
+	}
+}
@@ -0,0 +1,148 @@
+package fontimg
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/tdewolff/canvas"
+)
+
+// paragraphLine is a line of a wrapped paragraph.
+type paragraphLine struct {
+	words []string
+	// width is the natural width of the line, with single spaces.
+	width float64
+	// left and right are the widths of punctuation hanging into the margins.
+	left, right float64
+	last        bool
+}
+
+// wrap breaks s into lines no wider than the paragraph width, hyphenating
+// words with h when not nil.
+func (p *Paragraph) wrap(face *canvas.FontFace, s string, h *Hyphenator) []paragraphLine {
+	space := face.TextWidth(" ")
+	var lines []paragraphLine
+	var line paragraphLine
+	fits := func(word string, sep float64) bool {
+		left, right := p.hang(face, word)
+		if len(line.words) != 0 {
+			left = line.left
+		}
+		return line.width+sep+face.TextWidth(word)-left-right <= p.Width
+	}
+	add := func(word string, sep float64) {
+		left, right := p.hang(face, word)
+		if len(line.words) == 0 {
+			line.left = left
+		}
+		line.words, line.width, line.right = append(line.words, word), line.width+sep+face.TextWidth(word), right
+	}
+	words := strings.Fields(s)
+	for i := 0; i < len(words); i++ {
+		word, sep := words[i], 0.0
+		if len(line.words) != 0 {
+			sep = space
+		}
+		if fits(word, sep) {
+			add(word, sep)
+			continue
+		}
+		// hyphenate, using the longest prefix that fits
+		if h != nil {
+			r := []rune(word)
+			points := h.points(r)
+			for k := len(points) - 1; 0 <= k; k-- {
+				if prefix := string(r[:points[k]]) + "-"; fits(prefix, sep) {
+					add(prefix, sep)
+					words[i] = string(r[points[k]:])
+					break
+				}
+			}
+			if words[i] != word {
+				lines, line = append(lines, line), paragraphLine{}
+				i--
+				continue
+			}
+		}
+		// overflow when the word does not fit an empty line
+		if len(line.words) == 0 {
+			add(word, sep)
+			continue
+		}
+		lines, line = append(lines, line), paragraphLine{}
+		i--
+	}
+	if len(line.words) != 0 || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	lines[len(lines)-1].last = true
+	return lines
+}
+
+// hang returns the widths of the word's leading and trailing punctuation that
+// hangs into the margins, when hanging punctuation is enabled.
+func (p *Paragraph) hang(face *canvas.FontFace, word string) (float64, float64) {
+	if !p.HangPunctuation {
+		return 0, 0
+	}
+	trimmed := strings.TrimLeft(word, hangingPunctuation)
+	left := face.TextWidth(word[:len(word)-len(trimmed)])
+	var right float64
+	if trimmed != "" {
+		trimmed := strings.TrimRight(word, hangingPunctuation)
+		right = face.TextWidth(word[len(trimmed):])
+	}
+	return left, right
+}
+
+// hangingPunctuation is the punctuation hung into the margins.
+const hangingPunctuation = `.,-‐–—'"‘’“”‚„«»‹›`
+
+// draw wraps and draws s with its top at y, returning the height of the
+// drawn paragraph.
+func (p *Paragraph) draw(ctx *canvas.Context, face *canvas.FontFace, s string, y float64, h *Hyphenator) float64 {
+	space, lineHeight := face.TextWidth(" "), face.Metrics().LineHeight
+	lines := p.wrap(face, s, h)
+	for i, line := range lines {
+		gap := space
+		if p.Justify && !line.last && 1 < len(line.words) {
+			gap += (p.Width - line.width + line.left + line.right) / float64(len(line.words)-1)
+		}
+		top := y - float64(i)*lineHeight
+		x := -line.left
+		for j, word := range line.words {
+			ctx.DrawText(x, top, canvas.NewTextBox(face, word, 0, 0, canvas.Left, canvas.Top, nil))
+			x += face.TextWidth(word)
+			if p.ShowStretch && gap != space && j < len(line.words)-1 {
+				ctx.Push()
+				ctx.SetZIndex(0)
+				ctx.SetFillColor(p.stretchColor(gap / space))
+				ctx.DrawPath(x, top-lineHeight, canvas.Rectangle(gap, lineHeight))
+				ctx.Pop()
+			}
+			x += gap
+		}
+	}
+	return float64(len(lines)) * lineHeight
+}
+
+// stretchColor returns the highlight color for the stretch ratio, blending
+// from green (no stretch) to yellow (half the maximum stretch) to red (the
+// maximum stretch or more).
+func (p *Paragraph) stretchColor(ratio float64) color.Color {
+	maxStretch := p.MaxStretch
+	if maxStretch <= 1 {
+		maxStretch = 2
+	}
+	t := min(1, max(0, (ratio-1)/(maxStretch-1)))
+	c := color.NRGBA{A: 0x80}
+	switch {
+	case t < 0.5:
+		c.R, c.G = uint8(0xff*2*t), 0xc0
+	case t < 1:
+		c.R, c.G = 0xff, uint8(0xc0*2*(1-t))
+	default:
+		c.R = 0xff
+	}
+	return c
+}
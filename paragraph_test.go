@@ -0,0 +1,107 @@
+package fontimg
+
+import (
+	"bytes"
+	"image/color"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+)
+
+func TestParagraph(t *testing.T) {
+	h, err := NewHyphenator(strings.NewReader(testPatterns))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := RegisterHyphenation("x-test", h); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	tpl, err := NewTemplate("{{ .SampleText }}")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var v [][]byte
+	for _, p := range []*Paragraph{
+		nil,
+		{Width: 60},
+		{Width: 60, Justify: true},
+		{Width: 60, Justify: true, Language: "x-test"},
+		{Width: 60, Justify: true, Language: "x-test", ShowStretch: true},
+	} {
+		opts := DefaultOptions()
+		opts.Template, opts.Size, opts.Paragraph = tpl, 16, p
+		opts.Text = "the hyphenation of hyphenation tables in a hyphenation table"
+		img, err := font.RasterizeOptions(opts)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if p != nil && 60*opts.DPI/25.4+2*opts.Margin*opts.DPI/25.4+1 < float64(img.Bounds().Dx()) {
+			t.Errorf("expected width to be wrapped, got: %d", img.Bounds().Dx())
+		}
+		v = append(v, img.Pix)
+	}
+	for i := 1; i < len(v); i++ {
+		if bytes.Equal(v[i-1], v[i]) {
+			t.Errorf("expected paragraph %d to differ from %d", i, i-1)
+		}
+	}
+	opts := DefaultOptions()
+	opts.Paragraph = &Paragraph{Width: 60, Language: "xx-blah"}
+	if _, err := font.RasterizeOptions(opts); err == nil {
+		t.Errorf("expected error for unregistered language")
+	}
+}
+
+func TestStretchColor(t *testing.T) {
+	tests := []struct {
+		ratio, max float64
+		exp        color.NRGBA
+	}{
+		{1, 0, color.NRGBA{G: 0xc0, A: 0x80}},
+		{1.5, 0, color.NRGBA{R: 0xff, G: 0xc0, A: 0x80}},
+		{2, 0, color.NRGBA{R: 0xff, A: 0x80}},
+		{3, 0, color.NRGBA{R: 0xff, A: 0x80}},
+		{2, 3, color.NRGBA{R: 0xff, G: 0xc0, A: 0x80}},
+	}
+	for _, test := range tests {
+		p := &Paragraph{MaxStretch: test.max}
+		if c := p.stretchColor(test.ratio); c != test.exp {
+			t.Errorf("ratio %g max %g expected %v, got: %v", test.ratio, test.max, test.exp, c)
+		}
+	}
+}
+
+func TestParagraphHang(t *testing.T) {
+	ff, err := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf")).Load(canvas.FontRegular)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	face := ff.Face(16, color.Black)
+	tests := []struct {
+		word        string
+		left, right bool
+	}{
+		{"word", false, false},
+		{"“word", true, false},
+		{"word.", false, true},
+		{"“word,”", true, true},
+		{"—", true, false},
+	}
+	for _, test := range tests {
+		left, right := (&Paragraph{}).hang(face, test.word)
+		if left != 0 || right != 0 {
+			t.Errorf("%q expected no hanging when disabled, got: %g %g", test.word, left, right)
+		}
+		left, right = (&Paragraph{HangPunctuation: true}).hang(face, test.word)
+		if (left != 0) != test.left || (right != 0) != test.right {
+			t.Errorf("%q expected hanging %t %t, got: %g %g", test.word, test.left, test.right, left, right)
+		}
+	}
+	lines := (&Paragraph{Width: 60, HangPunctuation: true}).wrap(face, "“The quick brown fox jumps over the lazy dog.”", nil)
+	if len(lines) == 0 || lines[0].left == 0 || lines[len(lines)-1].right == 0 {
+		t.Errorf("expected hanging first and last lines, got: %+v", lines)
+	}
+}
@@ -0,0 +1,63 @@
+package fontimg
+
+import (
+	"context"
+	"image"
+	"runtime"
+	"sync"
+)
+
+// Result is the result of rasterizing a font with [RasterizeAll].
+type Result struct {
+	// Font is the rasterized font.
+	Font *Font
+	// Image is the font image, or nil when Err is set.
+	Image *image.RGBA
+	// Err is the error rasterizing the font.
+	Err error
+}
+
+// RasterizeAll rasterizes the font images of the fonts using the options,
+// concurrently with a pool of at most concurrency workers, returning a result
+// per font, in the order of the fonts. A font failing to rasterize does not
+// stop the other fonts from being rasterized. When concurrency is 0 or less,
+// [runtime.GOMAXPROCS] workers are used. Once the context is done, fonts not
+// yet rasterized have the context's error. When opts is nil, the default
+// options will be used.
+func RasterizeAll(ctx context.Context, fonts []*Font, opts *Options, concurrency int) []Result {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	res := make([]Result, len(fonts))
+	ch := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(fonts)) {
+		wg.Go(func() {
+			for i := range ch {
+				res[i] = Result{Font: fonts[i]}
+				res[i].Image, res[i].Err = fonts[i].rasterize(ctx, opts)
+			}
+		})
+	}
+	for i := range fonts {
+		ch <- i
+	}
+	close(ch)
+	wg.Wait()
+	return res
+}
+
+// rasterize rasterizes the font image using the options, returning the
+// context's error when it is done while loading the font, laying out the
+// lines of text, or rasterizing.
+func (font *Font) rasterize(ctx context.Context, opts *Options) (*image.RGBA, error) {
+	c, release, err := font.layout(ctx, trimOptions(opts), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return rasterizeContext(ctx, nil, c, opts)
+}
@@ -0,0 +1,52 @@
+package fontimg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestRasterizeAll(t *testing.T) {
+	var fonts []*Font
+	for _, test := range testFonts(t) {
+		fonts = append(fonts, New(nil, test.path))
+	}
+	fonts = append(fonts, New(nil, filepath.Join("testdata", "missing.ttf")))
+	for _, concurrency := range []int{0, 1, 3, 16} {
+		res := RasterizeAll(context.Background(), fonts, nil, concurrency)
+		if len(res) != len(fonts) {
+			t.Fatalf("expected %d results, got: %d", len(fonts), len(res))
+		}
+		for i, r := range res {
+			switch {
+			case r.Font != fonts[i]:
+				t.Errorf("concurrency %d result %d expected font %s, got: %v", concurrency, i, fonts[i], r.Font)
+			case i == len(fonts)-1 && r.Err == nil:
+				t.Errorf("concurrency %d expected error for missing font", concurrency)
+			case i == len(fonts)-1:
+			case r.Err != nil:
+				t.Errorf("concurrency %d %s expected no error, got: %v", concurrency, r.Font, r.Err)
+			default:
+				exp, err := fonts[i].RasterizeOptions(nil)
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				if !bytes.Equal(r.Image.Pix, exp.Pix) {
+					t.Errorf("concurrency %d %s expected image to match", concurrency, r.Font)
+				}
+			}
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, r := range RasterizeAll(ctx, fonts, nil, 2) {
+		if !errors.Is(r.Err, context.Canceled) || r.Image != nil {
+			t.Errorf("%s expected context.Canceled, got: %v", r.Font, r.Err)
+		}
+	}
+	if res := RasterizeAll(context.Background(), nil, nil, 4); len(res) != 0 {
+		t.Errorf("expected no results, got: %d", len(res))
+	}
+}
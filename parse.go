@@ -0,0 +1,159 @@
+package fontimg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	fontpkg "github.com/tdewolff/font"
+)
+
+// Sniff returns the format of the font data from its header: one of ttf,
+// otf, ttc, woff, woff2 or eot for the built-in formats, or the name of the
+// registered decoder (see [RegisterDecoder]) matching the header. Only the
+// first 64 bytes are examined.
+func Sniff(header []byte) (string, error) {
+	header = header[:min(64, len(header))]
+	if name, d := matchDecoder(header); d != nil {
+		return name, nil
+	}
+	typ, err := fontpkg.MediaType(header)
+	switch {
+	case err != nil:
+		return "", err
+	case typ == "application/vnd.ms-fontobject":
+		return "eot", nil
+	case bytes.HasPrefix(header, []byte("ttcf")):
+		return "ttc", nil
+	}
+	return strings.TrimPrefix(fontpkg.Extension(header), "."), nil
+}
+
+// Metadata is font metadata.
+type Metadata struct {
+	// Format is the font format (see [Sniff]).
+	Format     string
+	Family     string
+	Style      string
+	Version    string
+	SampleText string
+	NumGlyphs  int
+}
+
+// ParseMetadata parses the metadata of the font data, without loading the
+// font for rendering. The first font of a font collection is used. Font data
+// exceeding the [ParseBudget] is rejected, and panics encountered while
+// parsing a malformed font are returned as errors, making ParseMetadata safe
+// to use with untrusted font data.
+func ParseMetadata(b []byte) (_ *Metadata, err error) {
+	defer recoverError(&err)
+	format, err := Sniff(b)
+	if err != nil {
+		return nil, err
+	}
+	md := &Metadata{Format: format}
+	if _, d := matchDecoder(b[:min(64, len(b))]); d != nil {
+		bf, err := d.Decode(b)
+		if err != nil {
+			return nil, err
+		}
+		md.Family, md.Style = bf.Family, bf.Style
+		if len(bf.Strikes) != 0 {
+			md.NumGlyphs = len(bf.Strikes[0].Glyphs)
+		}
+		return md, nil
+	}
+	buf, err := toSFNT(b)
+	if err != nil {
+		return nil, err
+	}
+	sfnt, err := fontpkg.ParseSFNT(buf, 0)
+	if err != nil {
+		return nil, err
+	}
+	md.NumGlyphs = int(sfnt.NumGlyphs())
+	if sfnt.Name == nil {
+		return md, nil
+	}
+	if v := sfnt.Name.Get(fontpkg.NameFontFamily); 0 < len(v) {
+		md.Family = v[0].String()
+	}
+	if v := sfnt.Name.Get(fontpkg.NameFontSubfamily); 0 < len(v) {
+		md.Style = fontpkg.ParseStyle(v[0].String()).String()
+	}
+	if v := sfnt.Name.Get(fontpkg.NameVersion); 0 < len(v) {
+		md.Version = strings.TrimPrefix(v[0].String(), "Version ")
+	}
+	if v := sfnt.Name.Get(fontpkg.NameSampleText); 0 < len(v) {
+		md.SampleText = v[0].String()
+	}
+	return md, nil
+}
+
+// toSFNT returns the SFNT data of the font data b, extracting it from EOT,
+// WOFF and WOFF2 containers. Unlike [fontpkg.ToSFNT], b is not modified.
+// Returns [ErrResourceLimit] when the font data or the extracted data exceed
+// the [ParseBudget].
+func toSFNT(b []byte) ([]byte, error) {
+	bud := newBudget()
+	if err := bud.alloc(int64(len(b))); err != nil {
+		return nil, err
+	}
+	switch typ, _ := fontpkg.MediaType(b); typ {
+	case "application/vnd.ms-fontobject":
+		h, err := parseEOT(b)
+		if err != nil {
+			return nil, err
+		}
+		// obfuscated data is copied
+		if h.XOR {
+			if err := bud.alloc(int64(len(h.data))); err != nil {
+				return nil, err
+			}
+		}
+		return h.FontData()
+	case "font/woff":
+		if err := checkWOFF(b, bud); err != nil {
+			return nil, err
+		}
+	case "font/woff2":
+		return decompressWOFF2(b, bud)
+	}
+	return fontpkg.ToSFNT(b)
+}
+
+// DecompressWOFF2 decompresses WOFF2 font data, returning the SFNT data. Font
+// data that would decompress past the [ParseBudget] is rejected with
+// [ErrResourceLimit] before decompressing. Panics encountered while
+// decompressing malformed font data are returned as errors.
+func DecompressWOFF2(b []byte) ([]byte, error) {
+	bud := newBudget()
+	if err := bud.alloc(int64(len(b))); err != nil {
+		return nil, err
+	}
+	return decompressWOFF2(b, bud)
+}
+
+// decompressWOFF2 decompresses WOFF2 font data, charging the decompressed
+// data to the budget.
+func decompressWOFF2(b []byte, bud *budget) (_ []byte, err error) {
+	defer recoverError(&err)
+	if len(b) < 48 || string(b[:4]) != "wOF2" {
+		return nil, fmt.Errorf("WOFF2: bad header")
+	}
+	if err := checkWOFF2(b, bud); err != nil {
+		return nil, err
+	}
+	b, hhea, err := padWOFF2(b, bud)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := fontpkg.ParseWOFF2(b)
+	if err != nil {
+		return nil, fmt.Errorf("WOFF2: %v", err)
+	}
+	if hhea {
+		trimHhea(buf)
+	}
+	return buf, nil
+}
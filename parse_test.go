@@ -0,0 +1,179 @@
+package fontimg
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fontpkg "github.com/tdewolff/font"
+)
+
+func TestSniff(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		name string
+		buf  []byte
+		exp  string
+	}{
+		{"ttf", buf, "ttf"},
+		{"otf", testCFF2(t, buf), "otf"},
+		{"ttc", []byte("ttcf\x00\x02\x00\x00"), "ttc"},
+		{"woff2", testWOFF2(t, testCFF2(t, buf)), "woff2"},
+		{"eot", testEOT(buf, 0x00020001, 0), "eot"},
+		{"fon", testFON(), "fon"},
+		{"fnt", testFNT(0x0300, 12, 400, false), "fnt"},
+		{"psf", testPSF2(true), "psf"},
+		{"bad", []byte("not a font"), ""},
+		{"empty", nil, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			format, err := Sniff(test.buf)
+			switch {
+			case test.exp == "" && err == nil:
+				t.Errorf("expected error, got: %q", format)
+			case test.exp != "" && err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case format != test.exp:
+				t.Errorf("expected %q, got: %q", test.exp, format)
+			}
+		})
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		name string
+		buf  []byte
+		exp  Metadata
+	}{
+		{"ttf", buf, Metadata{Format: "ttf", Family: "Ubuntu", Style: "Regular", NumGlyphs: 1264}},
+		{"fnt", testFNT(0x0300, 12, 700, true), Metadata{Format: "fnt", Family: "Test", Style: "Bold Italic", NumGlyphs: 96}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			md, err := ParseMetadata(test.buf)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			md.Version, md.SampleText = "", ""
+			if *md != test.exp {
+				t.Errorf("expected %+v, got: %+v", test.exp, *md)
+			}
+		})
+	}
+	if _, err := ParseMetadata(buf[:256]); err == nil {
+		t.Errorf("expected error for truncated font")
+	}
+}
+
+func TestDecompressWOFF2(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	woff2 := testWOFF2(t, testCFF2(t, buf))
+	sfnt, err := DecompressWOFF2(woff2)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if format, _ := Sniff(sfnt); format != "otf" || !hasTable(sfnt, "CFF2") {
+		t.Errorf("expected otf with CFF2 table, got: %q", format)
+	}
+	// claim a decompressed size over the budget
+	binary.BigEndian.PutUint32(woff2[16:], math.MaxUint32)
+	if _, err := DecompressWOFF2(woff2); !errors.Is(err, ErrResourceLimit) {
+		t.Errorf("expected ErrResourceLimit, got: %v", err)
+	}
+	if _, err := DecompressWOFF2(buf); err == nil {
+		t.Errorf("expected error for ttf")
+	}
+}
+
+func FuzzSniff(f *testing.F) {
+	for _, b := range testSeeds(f) {
+		f.Add(b[:min(64, len(b))])
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		format, err := Sniff(b)
+		if err == nil && format == "" {
+			t.Errorf("expected format")
+		}
+	})
+}
+
+func FuzzParseMetadata(f *testing.F) {
+	for _, b := range testSeeds(f) {
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		md, err := ParseMetadata(b)
+		if err == nil && md.Format == "" {
+			t.Errorf("expected format")
+		}
+	})
+}
+
+func FuzzDecompressWOFF2(f *testing.F) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		f.Fatalf("expected no error, got: %v", err)
+	}
+	f.Add(testWOFF2(f, testCFF2(f, buf)))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		_, _ = DecompressWOFF2(b)
+	})
+}
+
+// testSeeds returns fuzzing seeds, one for each supported format.
+func testSeeds(tb testing.TB) [][]byte {
+	tb.Helper()
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		tb.Fatalf("expected no error, got: %v", err)
+	}
+	return [][]byte{
+		buf,
+		testWOFF2(tb, testCFF2(tb, buf)),
+		testEOT(buf, 0x00020001, eotXOR),
+		testFON(),
+		testFNT(0x0200, 12, 400, false),
+		testPSF1(true),
+		testPSF2(true),
+	}
+}
+
+// testWOFF2 converts the SFNT font to WOFF2. The underlying font parser is
+// unable to reverse the glyf table transform, so only fonts without glyf
+// tables are supported.
+func testWOFF2(tb testing.TB, buf []byte) []byte {
+	tb.Helper()
+	sfnt := &fontpkg.SFNT{
+		Version: string(buf[:4]),
+		Length:  uint32(len(buf)),
+		Tables:  make(map[string][]byte),
+	}
+	for i := range int(binary.BigEndian.Uint16(buf[4:])) {
+		rec := buf[12+16*i:]
+		offset, length := binary.BigEndian.Uint32(rec[8:]), binary.BigEndian.Uint32(rec[12:])
+		sfnt.Tables[string(rec[:4])] = buf[offset : offset+length]
+	}
+	if sfnt.Tables["glyf"] != nil {
+		tb.Fatalf("expected no glyf table")
+	}
+	b, err := sfnt.WriteWOFF2()
+	if err != nil {
+		tb.Fatalf("expected no error, got: %v", err)
+	}
+	return b
+}
@@ -0,0 +1,48 @@
+package fontimg
+
+import (
+	_ "embed"
+	"image"
+	"strings"
+	"text/template"
+
+	"github.com/tdewolff/canvas"
+)
+
+// RenderPlaceholder renders a standardized "preview unavailable" card for the
+// named font and reason using the embedded label font, for use when a font
+// cannot be parsed or rasterized. Only the options' size, colors, DPI, and
+// margin are used. When opts is nil, the default options will be used.
+func RenderPlaceholder(name, reason string, opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	p := labelPrinter(opts.Language)
+	if name = strings.TrimSpace(name); name == "" {
+		name = p.Sprintf("Unknown font")
+	}
+	text := name + "\n" + p.Sprintf("preview unavailable")
+	if reason = spaceRE.ReplaceAllString(strings.TrimSpace(reason), " "); reason != "" {
+		text += ": " + reason
+	}
+	o := *opts
+	o.Template, o.Text = tplPlaceholder, text
+	o.Style, o.Variant = canvas.FontRegular, canvas.FontNormal
+	return LabelFont().RasterizeOptions(&o)
+}
+
+// LabelFont returns the embedded label font.
+func LabelFont() *Font {
+	return &Font{
+		Buf:    labelTTF,
+		Family: "Noto Mono",
+	}
+}
+
+// tplPlaceholder is the placeholder template.
+var tplPlaceholder = template.Must(NewTemplate(`{{ size (inc .Size 6) }}{{ .SampleText }}`))
+
+// labelTTF is the embedded label font (Noto Mono).
+//
+//go:embed label.ttf
+var labelTTF []byte
@@ -0,0 +1,25 @@
+package fontimg
+
+import (
+	"testing"
+)
+
+func TestRenderPlaceholder(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Size = 16
+	img, err := RenderPlaceholder("Blah", "unrecognized font file format", opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		t.Fatalf("expected non-empty image, got: %v", b)
+	}
+	short, err := RenderPlaceholder("", "", opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := short.Bounds(); b.Dx() <= s.Dx() {
+		t.Errorf("expected reason to widen image, got: %v <= %v", b, s)
+	}
+}
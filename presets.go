@@ -0,0 +1,71 @@
+package fontimg
+
+import (
+	"embed"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Preset is a named template that typesets canned content in the previewed
+// font.
+type Preset struct {
+	// Name is the preset name.
+	Name string
+	// Description is a short description of the preset.
+	Description string
+	// Template is the preset's text template.
+	Template *template.Template
+}
+
+// Presets returns the available presets, sorted by name.
+func Presets() []*Preset {
+	v := make([]*Preset, 0, len(presets))
+	for _, p := range presets {
+		v = append(v, p)
+	}
+	slices.SortFunc(v, func(a, b *Preset) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return v
+}
+
+// LookupPreset returns the named preset.
+func LookupPreset(name string) (*Preset, error) {
+	if p, ok := presets[strings.ToLower(name)]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown preset %q", name)
+}
+
+// presets are the available presets.
+var presets = make(map[string]*Preset)
+
+// addPreset adds a preset, parsing its template from the embedded preset
+// files.
+func addPreset(name, desc string) {
+	buf, err := presetFS.ReadFile("presets/" + name + ".tpl")
+	if err != nil {
+		panic(err)
+	}
+	presets[name] = &Preset{
+		Name:        name,
+		Description: desc,
+		Template:    template.Must(NewTemplate(string(buf))),
+	}
+}
+
+func init() {
+	addPreset("article", "news article headline, byline, and body copy")
+	addPreset("calt", "contextual alternates with the feature on and off in adjacent rows")
+	addPreset("card", "mobile UI card with status bar, title, and buttons")
+	addPreset("emoji", "emoji modifier, ZWJ, and flag sequences, composed and decomposed")
+	addPreset("poster", "poster with large display headline")
+	addPreset("locale", "localized numbers, currency amounts, and dates")
+	addPreset("marks", "stacked combining marks exposing mark positioning")
+	addPreset("pseudo", "pseudo-localized text exposing missing diacritics")
+}
+
+//go:embed presets/*.tpl
+var presetFS embed.FS
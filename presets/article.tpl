@@ -0,0 +1,12 @@
+{{ size (scale .Size 0.3) }}WORLD · SCIENCE
+{{ size .Size }}Tiny Sphinx of Quartz Found
+{{ size .Size }}in Jackdaw's Riverside Nest
+{{ size (scale .Size 0.45) }}Birdwatchers puzzle over a glittering hoard of stones
+{{ size (scale .Size 0.3) }}By Alex Quill · June 14, 2024 · 4 min read
+{{ size (scale .Size 0.4) }}Residents near the old quarry were surprised this week when a
+{{ size (scale .Size 0.4) }}local birdwatcher discovered a small carved sphinx, no larger
+{{ size (scale .Size 0.4) }}than a thumb, tucked among twigs in a jackdaw's nest. "They do
+{{ size (scale .Size 0.4) }}love shiny things," said Dr. Maya Ortiz, an ornithologist at the
+{{ size (scale .Size 0.4) }}university, "but quartz carvings are a first for me." The figure,
+{{ size (scale .Size 0.4) }}estimated to be 120–150 years old, will be displayed at the
+{{ size (scale .Size 0.4) }}town museum from 9:30 a.m. on Saturday.
@@ -0,0 +1,11 @@
+{{ size (inc .Size 2) }}{{ .Name }}, {{ .Style }}
+{{ size .Size }}{{ features "+calt" }}+calt  minimum illumination, little mummy's tummy
+{{ features "-calt" }}-calt  minimum illumination, little mummy's tummy
+{{ features "+calt" }}+calt  Hello! Welcome home, we will all be swimming soon.
+{{ features "-calt" }}-calt  Hello! Welcome home, we will all be swimming soon.
+{{ features "+calt" }}+calt  aaa bbb eee lll nnn ooo rrr sss ttt ooo www
+{{ features "-calt" }}-calt  aaa bbb eee lll nnn ooo rrr sss ttt ooo www
+{{ features "+calt" }}+calt  -> => ->> >>= <= >= != === !== |> <| :: ::: ... <!-- --> /* */ www
+{{ features "-calt" }}-calt  -> => ->> >>= <= >= != === !== |> <| :: ::: ... <!-- --> /* */ www
+{{ features "+calt" }}+calt  0xFF 1920x1080 a+b==c x*y <=> <-> |-| ## ### #{ }# :=
+{{ features "-calt" }}-calt  0xFF 1920x1080 a+b==c x*y <=> <-> |-| ## ### #{ }# :=
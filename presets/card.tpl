@@ -0,0 +1,7 @@
+{{ size (scale .Size 0.35) }}9:41                                        5G  87%
+{{ size (scale .Size 0.45) }}Good morning, Sam
+{{ size .Size }}3 new messages
+{{ size (scale .Size 0.4) }}Your flight to Lisbon (TP 1357) boards at 9:40
+{{ size (scale .Size 0.4) }}from gate B12. Tap to view your boarding pass.
+{{ size (scale .Size 0.35) }}Settings  ·  Privacy  ·  Help & Feedback
+{{ size (scale .Size 0.5) }}VIEW PASS          DISMISS
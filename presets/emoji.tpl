@@ -0,0 +1,7 @@
+{{ size (inc .Size 2) }}{{ .Name }}, {{ .Style }}
+{{ size .Size }}{{ emoji "modifier" }}
+{{ decompose (emoji "modifier") }}
+{{ emoji "zwj" }}
+{{ decompose (emoji "zwj") }}
+{{ emoji "flag" }}
+{{ decompose (emoji "flag") }}
@@ -0,0 +1,13 @@
+{{ size (inc .Size 2) }}{{ .Name }}, {{ .Style }}
+{{ size .Size }}{{ features "+tnum" }}en-US  {{ number "en-US" 1234567.89 }}  {{ currency "en-US" 1234.5 }}  {{ date "en-US" }}
+{{ features "+tnum" }}en-GB  {{ number "en-GB" 1234567.89 }}  {{ currency "en-GB" 1234.5 }}  {{ date "en-GB" }}
+{{ features "+tnum" }}de-DE  {{ number "de-DE" 1234567.89 }}  {{ currency "de-DE" 1234.5 }}  {{ date "de-DE" }}
+{{ features "+tnum" }}fr-FR  {{ number "fr-FR" 1234567.89 }}  {{ currency "fr-FR" 1234.5 }}  {{ date "fr-FR" }}
+{{ features "+tnum" }}de-CH  {{ number "de-CH" 1234567.89 }}  {{ currency "de-CH" 1234.5 }}  {{ date "de-CH" }}
+{{ features "+tnum" }}hi-IN  {{ number "hi-IN" 1234567.89 }}  {{ currency "hi-IN" 1234.5 }}  {{ date "hi-IN" }}
+{{ features "+tnum" }}ja-JP  {{ number "ja-JP" 1234567.89 }}  {{ currency "ja-JP" 1234.5 }}  {{ date "ja-JP" }}
+{{ features "+tnum" }}ko-KR  {{ number "ko-KR" 1234567.89 }}  {{ currency "ko-KR" 1234.5 }}  {{ date "ko-KR" }}
+{{ features "+tnum" }}ru-RU  {{ number "ru-RU" 1234567.89 }}  {{ currency "ru-RU" 1234.5 }}  {{ date "ru-RU" }}
+{{ features "+tnum" }}tr-TR  {{ number "tr-TR" 1234567.89 }}  {{ currency "tr-TR" 1234.5 }}  {{ date "tr-TR" }}
+{{ features "+tnum" }}pt-BR  {{ number "pt-BR" 1234567.89 }}  {{ currency "pt-BR" 1234.5 }}  {{ date "pt-BR" }}
+{{ features "+tnum" }}₿ ₡ ₦ ₩ ₪ ₫ € ₭ ₮ ₱ ₲ ₴ ₵ ₸ ₹ ₺ ₼ ₽ ₾ ¢ £ ¥ $ ¤
@@ -0,0 +1,6 @@
+{{ size (inc .Size 2) }}{{ .Name }}, {{ .Style }}
+{{ size .Size }}à á â ã ä å ā ă ȧ ǎ
+à á â ã ä å ā ă ȧ ǎ
+ẹ̄ ǭ ữ ắ ỗ ḯ n̰̈ x̂̃
+Z̵̡̛ä̷̧l̸͇̈g̶̠̊o̵̤͊ H́̂̃à́̂̃M̖̗̘
+Á̈ É̈ Í̈ Ó̈ Ú̈ á̈ é̈ í̈ ó̈ ú̈
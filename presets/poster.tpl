@@ -0,0 +1,5 @@
+{{ size (scale .Size 0.5) }}THE HARBOR ARTS FESTIVAL PRESENTS
+{{ size (scale .Size 2.5) }}JAZZ
+{{ size (scale .Size 2.5) }}& WAVES
+{{ size (scale .Size 0.75) }}Friday August 23 — Pier 39
+{{ size (scale .Size 0.5) }}Doors 7PM · Tickets $25 · All Ages
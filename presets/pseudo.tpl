@@ -0,0 +1,6 @@
+{{ size (inc .Size 2) }}{{ pseudo .Name }}, {{ .Style }}
+{{ size .Size }}{{ if .SampleText }}{{ pseudo .SampleText }}{{ else }}{{ pseudo "The quick brown fox jumps over the lazy dog." }}
+{{ pseudo "PACK MY BOX WITH FIVE DOZEN LIQUOR JUGS." }}{{ end }}
+ȦȧḂḃĊċḊḋĖėḞḟĠġḢḣİıĿŀṀṁṄṅȮȯṖṗṘṙṠṡṪṫẆẇẊẋẎẏŻż ɱ ẞß
+ÀÁÂÃÄÅĀĂĄǍ àáâãäåāăąǎ ĈĊČĎĐĒĚĜĞĢĤĴĶĹĻĽŁŃŅŇŌŐŒŔŘŚŜŞŠŢŤŦŨŪŬŮŰŲŴŶŸŹŽ
+a̧ ẹ̄ ờ ṳ̂ n̈ g̃ q̣ ŗ̌ j̇ ç̆ ı̊ ȷ̂ ɛ̃ ǯ ŋ̄
@@ -0,0 +1,30 @@
+package fontimg
+
+import (
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	for _, test := range testFonts(t) {
+		for _, p := range Presets() {
+			t.Run(test.name+"/"+p.Name, func(t *testing.T) {
+				preset, err := LookupPreset(p.Name)
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				opts := DefaultOptions()
+				opts.Size, opts.Template = 12, preset.Template
+				img, err := New(nil, test.path).RasterizeOptions(opts)
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+					t.Errorf("expected non-empty image, got: %v", b)
+				}
+			})
+		}
+	}
+	if _, err := LookupPreset("blah"); err == nil {
+		t.Errorf("expected error")
+	}
+}
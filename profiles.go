@@ -0,0 +1,216 @@
+package fontimg
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// Profile is a named end-to-end rendering profile, combining a layout, a
+// color theme, and encoder settings, so that integrations can refer to
+// complete rendering settings by name (ie, in configuration files or query
+// parameters). See [RegisterProfile].
+type Profile struct {
+	// Name is the profile name.
+	Name string
+	// Description is a short description of the profile.
+	Description string
+	// Layout lays out the font image on a canvas using the options. When
+	// nil, [Font.Canvas] is used.
+	Layout func(font *Font, opts *Options) (*canvas.Canvas, error)
+	// Options sets the profile's options (ie, the size or DPI). When nil, the
+	// options are not changed.
+	Options func(opts *Options)
+	// Theme is the color theme. When nil, the options' colors are used.
+	Theme *Theme
+	// Format is the image format (see [Output.Format]). When empty, png is
+	// used.
+	Format string
+	// Width and Height are the maximum dimensions of the output image (see
+	// [Output.Width]).
+	Width, Height int
+	// Quality is the encoding quality for lossy formats (see
+	// [Output.Quality]).
+	Quality int
+}
+
+// Apply returns a copy of the options with the profile's options and theme
+// applied. When opts is nil, the default options will be used.
+func (p *Profile) Apply(opts *Options) *Options {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	o := *opts
+	if p.Options != nil {
+		p.Options(&o)
+	}
+	if p.Theme != nil {
+		o.FG, o.BG = p.Theme.FG, p.Theme.BG
+	}
+	return &o
+}
+
+// Canvas lays out the font image with the profile, using the options with
+// the profile's options and theme applied (see [Profile.Apply]).
+func (p *Profile) Canvas(font *Font, opts *Options) (*canvas.Canvas, error) {
+	return p.layout(font, p.Apply(opts))
+}
+
+// layout lays out the font image with the profile's layout, using the
+// options as is.
+func (p *Profile) layout(font *Font, opts *Options) (*canvas.Canvas, error) {
+	if p.Layout == nil {
+		return font.Canvas(opts)
+	}
+	return p.Layout(font, opts)
+}
+
+// Rasterize rasterizes the font image with the profile. See [Profile.Canvas].
+func (p *Profile) Rasterize(font *Font, opts *Options) (*image.RGBA, error) {
+	opts = p.Apply(opts)
+	c, err := p.layout(font, opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// Output returns the profile's encoder settings, writing to w.
+func (p *Profile) Output(w io.Writer) Output {
+	format := p.Format
+	if format == "" {
+		format = "png"
+	}
+	return Output{
+		W:       w,
+		Format:  format,
+		Width:   p.Width,
+		Height:  p.Height,
+		Quality: p.Quality,
+	}
+}
+
+// Render rasterizes the font image with the profile, encoding it to w with
+// the profile's encoder settings.
+func (p *Profile) Render(w io.Writer, font *Font, opts *Options) error {
+	img, err := p.Rasterize(font, opts)
+	if err != nil {
+		return err
+	}
+	return Encode(img, p.Output(w))
+}
+
+// profiles are the registered profiles.
+var profiles = struct {
+	sync.RWMutex
+	m map[string]*Profile
+}{
+	m: make(map[string]*Profile),
+}
+
+// RegisterProfile registers a rendering profile, replacing any profile
+// previously registered with the same name. Profile names are not case
+// sensitive. The thumbnail, specimen-a4, waterfall-dark, and glyphmap
+// profiles are registered by default.
+func RegisterProfile(p *Profile) error {
+	if p.Name == "" {
+		return fmt.Errorf("profile name not set")
+	}
+	profiles.Lock()
+	defer profiles.Unlock()
+	profiles.m[strings.ToLower(p.Name)] = p
+	return nil
+}
+
+// Profiles returns the registered profiles, sorted by name.
+func Profiles() []*Profile {
+	profiles.RLock()
+	defer profiles.RUnlock()
+	v := make([]*Profile, 0, len(profiles.m))
+	for _, name := range slices.Sorted(maps.Keys(profiles.m)) {
+		v = append(v, profiles.m[name])
+	}
+	return v
+}
+
+// LookupProfile returns the named profile.
+func LookupProfile(name string) (*Profile, error) {
+	profiles.RLock()
+	defer profiles.RUnlock()
+	if p, ok := profiles.m[strings.ToLower(name)]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown profile %q", name)
+}
+
+func init() {
+	for _, p := range []*Profile{
+		{
+			Name:        "thumbnail",
+			Description: "small font image, scaled to fit 256x256",
+			Options: func(opts *Options) {
+				opts.Size, opts.Margin = 24, 2
+			},
+			Theme:  &LightTheme,
+			Width:  256,
+			Height: 256,
+		},
+		{
+			Name:        "specimen-a4",
+			Description: "A4 specimen page with the font image and glyph overview",
+			Layout:      specimenPage,
+			Options: func(opts *Options) {
+				opts.DPI = 150
+			},
+			Theme: &LightTheme,
+		},
+		{
+			Name:        "waterfall-dark",
+			Description: "waterfall of sizes, light text on a dark background",
+			Layout: func(font *Font, opts *Options) (*canvas.Canvas, error) {
+				return font.Waterfall(nil, opts)
+			},
+			Theme: &DarkTheme,
+		},
+		{
+			Name:        "glyphmap",
+			Description: "labeled sheet of all the font's glyphs",
+			Layout: func(font *Font, opts *Options) (*canvas.Canvas, error) {
+				v, err := font.Glyphs()
+				if err != nil {
+					return nil, err
+				}
+				return font.GlyphSheet(v, opts)
+			},
+			Options: func(opts *Options) {
+				opts.Size = 32
+			},
+			Theme: &LightTheme,
+		},
+	} {
+		if err := RegisterProfile(p); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// specimenPage lays out a specimen book page (see [Book]) on an A4 canvas.
+func specimenPage(font *Font, opts *Options) (*canvas.Canvas, error) {
+	page, err := bookPage(font, opts)
+	if err != nil {
+		return nil, err
+	}
+	c := canvas.New(bookWidth, bookHeight)
+	ctx := canvas.NewContext(c)
+	drawBackground(ctx, opts)
+	ctx.Close()
+	renderBookPage(c, page)
+	return c, nil
+}
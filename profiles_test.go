@@ -0,0 +1,79 @@
+package fontimg
+
+import (
+	"bytes"
+	"image/png"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	for _, p := range Profiles() {
+		t.Run(p.Name, func(t *testing.T) {
+			profile, err := LookupProfile(p.Name)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			buf := new(bytes.Buffer)
+			if err := profile.Render(buf, font, nil); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			img, err := png.Decode(buf)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			b := img.Bounds()
+			switch {
+			case b.Dx() == 0 || b.Dy() == 0:
+				t.Errorf("expected non-empty image, got: %v", b)
+			case profile.Width != 0 && profile.Width < b.Dx(), profile.Height != 0 && profile.Height < b.Dy():
+				t.Errorf("expected image within %dx%d, got: %v", profile.Width, profile.Height, b)
+			}
+		})
+	}
+	if _, err := LookupProfile("blah"); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestRegisterProfile(t *testing.T) {
+	p := &Profile{
+		Name: "Test-Dark",
+		Options: func(opts *Options) {
+			opts.Size = 12
+		},
+		Theme: &DarkTheme,
+	}
+	if err := RegisterProfile(p); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	t.Cleanup(func() {
+		profiles.Lock()
+		defer profiles.Unlock()
+		delete(profiles.m, "test-dark")
+	})
+	profile, err := LookupProfile("test-dark")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	opts := DefaultOptions()
+	o := profile.Apply(opts)
+	switch {
+	case o.Size != 12 || o.FG != DarkTheme.FG || o.BG != DarkTheme.BG:
+		t.Errorf("expected profile options and theme applied, got: %d %v %v", o.Size, o.FG, o.BG)
+	case opts.Size != 48:
+		t.Errorf("expected options unchanged, got: %d", opts.Size)
+	}
+	img, err := profile.Rasterize(New(nil, filepath.Join("testdata", "Ubuntu-R.ttf")), nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if c := img.At(0, 0); c != DarkTheme.BG {
+		r, g, b, _ := c.RGBA()
+		t.Errorf("expected dark background, got: %d %d %d", r>>8, g>>8, b>>8)
+	}
+	if err := RegisterProfile(&Profile{}); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,151 @@
+package fontimg
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// Proportions are a font's vertical proportions, relative to the em. Values
+// are measured from the bounds of the font's glyphs (x, H, d, and p), falling
+// back to the OS/2 and hhea metrics when a glyph is not mapped.
+type Proportions struct {
+	XHeight   float64
+	CapHeight float64
+	Ascender  float64
+	// Descender is positive below the baseline.
+	Descender float64
+}
+
+// Proportions returns the font's vertical proportions.
+func (font *Font) Proportions() (_ *Proportions, err error) {
+	defer recoverError(&err)
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	upem := float64(sfnt.Head.UnitsPerEm)
+	// bounds returns the glyph's top (or bottom) for r, or the fallback when
+	// r is not mapped.
+	bounds := func(r rune, top bool, fallback int) float64 {
+		if id := sfnt.GlyphIndex(r); id != 0 {
+			_, ymin, _, ymax := sfnt.GlyphBounds(id)
+			if top {
+				return float64(ymax) / upem
+			}
+			return float64(-ymin) / upem
+		}
+		return float64(fallback) / upem
+	}
+	var xHeight, capHeight, asc, desc int
+	if sfnt.OS2 != nil {
+		xHeight, capHeight = int(sfnt.OS2.SxHeight), int(sfnt.OS2.SCapHeight)
+	}
+	if sfnt.Hhea != nil {
+		asc, desc = int(sfnt.Hhea.Ascender), -int(sfnt.Hhea.Descender)
+	}
+	return &Proportions{
+		XHeight:   bounds('x', true, xHeight),
+		CapHeight: bounds('H', true, capHeight),
+		Ascender:  bounds('d', true, asc),
+		Descender: bounds('p', false, desc),
+	}, nil
+}
+
+// RasterizeProportions rasterizes a proportions comparison chart of the fonts
+// using the options. See [ProportionsChart].
+func RasterizeProportions(fonts []*Font, opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := ProportionsChart(fonts, opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// ProportionsChart lays out a bar chart comparing the vertical proportions of
+// the fonts on a canvas, with a row of x-height, cap height, ascender, and
+// descender bars for each font, for picking fonts with similar proportions
+// (ie, for a fallback stack). The chart is labeled using the embedded label
+// font. Only the options' size, colors, and margin are used. When opts is nil,
+// the default options will be used.
+func ProportionsChart(fonts []*Font, opts *Options) (_ *canvas.Canvas, err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if len(fonts) == 0 {
+		return nil, fmt.Errorf("no fonts")
+	}
+	v := make([]*Proportions, len(fonts))
+	for i, font := range fonts {
+		if v[i], err = font.Proportions(); err != nil {
+			return nil, fmt.Errorf("%s: %v", font.BestName(), err)
+		}
+	}
+	ff, err := LabelFont().Load(canvas.FontRegular)
+	if err != nil {
+		return nil, err
+	}
+	label, p := ff.Face(0.4*float64(opts.Size), opts.FG), labelPrinter(opts.Language)
+	lh := label.Metrics().LineHeight
+	// create canvas and context
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	// font names are in the first column, bars are scaled to 10 em per em
+	var col float64
+	for _, font := range fonts {
+		col = max(col, label.TextWidth(font.BestName()))
+	}
+	col += lh
+	scale := 10 * float64(opts.Size) * 25.4 / 72
+	bar := lh / 2
+	// draw legend
+	x, y := col, float64(0)
+	for i, name := range proportionsNames {
+		name = p.Sprintf(name)
+		ctx.SetFillColor(proportionsColors[i])
+		ctx.DrawPath(x, y-lh/2, canvas.Rectangle(bar, bar))
+		ctx.SetFillColor(opts.FG)
+		ctx.DrawText(x+lh, y-lh/2, canvas.NewTextLine(label, name, canvas.Left))
+		x += lh + label.TextWidth(name) + lh
+	}
+	y -= 2 * lh
+	// draw rows
+	for i, font := range fonts {
+		ctx.SetFillColor(opts.FG)
+		ctx.DrawText(0, y-2*bar, canvas.NewTextLine(label, font.BestName(), canvas.Left))
+		for j, r := range []float64{v[i].XHeight, v[i].CapHeight, v[i].Ascender, v[i].Descender} {
+			ctx.SetFillColor(proportionsColors[j])
+			ctx.DrawPath(col, y-bar, canvas.Rectangle(r*scale, bar))
+			ctx.SetFillColor(opts.FG)
+			ctx.DrawText(col+r*scale+bar, y-bar, canvas.NewTextLine(label, p.Sprintf("%.3f", r), canvas.Left))
+			y -= 1.25 * lh
+		}
+		y -= lh
+	}
+	// fit canvas to context
+	c.Fit(opts.Margin)
+	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
+
+// proportionsNames are the proportions chart legend names.
+var proportionsNames = []string{"x-height", "cap height", "ascender", "descender"}
+
+// proportionsColors are the proportions chart bar colors.
+var proportionsColors = []color.Color{
+	color.NRGBA{R: 0xd0, G: 0x30, B: 0x30, A: 0xff},
+	color.NRGBA{R: 0x30, G: 0x90, B: 0x30, A: 0xff},
+	color.NRGBA{R: 0x30, G: 0x50, B: 0xd0, A: 0xff},
+	color.NRGBA{R: 0xc0, G: 0x80, B: 0x00, A: 0xff},
+}
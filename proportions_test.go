@@ -0,0 +1,30 @@
+package fontimg
+
+import "testing"
+
+func TestProportions(t *testing.T) {
+	var fonts []*Font
+	for _, test := range testFonts(t) {
+		t.Run(test.name, func(t *testing.T) {
+			font := New(nil, test.path)
+			p, err := font.Proportions()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if p.XHeight <= 0 || p.CapHeight <= p.XHeight || p.Ascender <= p.XHeight || p.Descender <= 0 || 1 <= p.Ascender {
+				t.Errorf("expected proportions, got: %+v", p)
+			}
+			fonts = append(fonts, font)
+		})
+	}
+	img, err := RasterizeProportions(fonts, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+		t.Errorf("expected non-empty image, got: %v", b)
+	}
+	if _, err := RasterizeProportions(nil, nil); err == nil {
+		t.Errorf("expected error for no fonts")
+	}
+}
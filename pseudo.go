@@ -0,0 +1,24 @@
+package fontimg
+
+import (
+	"strings"
+)
+
+// PseudoLocalize returns s with ASCII letters replaced by accented
+// equivalents (ie, "Hello" becomes "Ħḗŀŀǿ"), for exposing missing diacritics
+// in fonts claiming Latin Extended support.
+func PseudoLocalize(s string) string {
+	return pseudoReplacer.Replace(s)
+}
+
+// pseudoReplacer replaces ASCII letters with accented equivalents.
+var pseudoReplacer = strings.NewReplacer(
+	"a", "ȧ", "b", "ƀ", "c", "ƈ", "d", "ḓ", "e", "ḗ", "f", "ƒ", "g", "ɠ",
+	"h", "ħ", "i", "ī", "j", "ĵ", "k", "ķ", "l", "ŀ", "m", "ɱ", "n", "ƞ",
+	"o", "ǿ", "p", "ƥ", "q", "ɋ", "r", "ř", "s", "ş", "t", "ŧ", "u", "ŭ",
+	"v", "ṽ", "w", "ẇ", "x", "ẋ", "y", "ẏ", "z", "ẑ",
+	"A", "Ȧ", "B", "Ɓ", "C", "Ƈ", "D", "Ḓ", "E", "Ḗ", "F", "Ƒ", "G", "Ɠ",
+	"H", "Ħ", "I", "Ī", "J", "Ĵ", "K", "Ķ", "L", "Ŀ", "M", "Ḿ", "N", "Ƞ",
+	"O", "Ǿ", "P", "Ƥ", "Q", "Ɋ", "R", "Ř", "S", "Ş", "T", "Ŧ", "U", "Ŭ",
+	"V", "Ṽ", "W", "Ẇ", "X", "Ẋ", "Y", "Ẏ", "Z", "Ẑ",
+)
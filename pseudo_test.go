@@ -0,0 +1,23 @@
+package fontimg
+
+import (
+	"testing"
+)
+
+func TestPseudoLocalize(t *testing.T) {
+	tests := []struct {
+		s, exp string
+	}{
+		{"", ""},
+		{"Hello", "Ħḗŀŀǿ"},
+		{"ABC xyz 123!", "ȦƁƇ ẋẏẑ 123!"},
+		{"日本", "日本"},
+	}
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			if s := PseudoLocalize(test.s); s != test.exp {
+				t.Errorf("expected %q, got: %q", test.exp, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package fontimg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"unicode/utf8"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// PSF magic numbers.
+const (
+	psf1Magic = "\x36\x04"
+	psf2Magic = "\x72\xb5\x4a\x86"
+)
+
+// psfDecoder is the Linux PC Screen Font (PSF) console font decoder.
+type psfDecoder struct{}
+
+// Match satisfies the [Decoder] interface.
+func (psfDecoder) Match(header []byte) bool {
+	return bytes.HasPrefix(header, []byte(psf1Magic)) || bytes.HasPrefix(header, []byte(psf2Magic))
+}
+
+// Decode satisfies the [Decoder] interface.
+func (psfDecoder) Decode(buf []byte) (*BitmapFont, error) {
+	return ParsePSF(buf)
+}
+
+// ParsePSF parses a Linux PC Screen Font (PSF version 1 or 2) console font.
+// Glyphs are mapped to runes using the font's unicode table, or by glyph
+// index when the font has no unicode table. Compressed (.psf.gz) fonts must
+// be decompressed first. Returns [ErrResourceLimit] when the decoded glyphs
+// exceed the [ParseBudget].
+func ParsePSF(b []byte) (*BitmapFont, error) {
+	var n, size, width, height, off int
+	var psf1, table bool
+	switch {
+	case bytes.HasPrefix(b, []byte(psf1Magic)) && 4 <= len(b):
+		mode := b[2]
+		n, size, width, height, off = 256, int(b[3]), 8, int(b[3]), 4
+		if mode&0x01 != 0 {
+			n = 512
+		}
+		psf1, table = true, mode&0x06 != 0
+	case bytes.HasPrefix(b, []byte(psf2Magic)) && 32 <= len(b):
+		r := &leReader{b: b, i: 8}
+		off = int(r.u32())
+		table = r.u32()&0x01 != 0
+		n, size = int(r.u32()), int(r.u32())
+		height, width = int(r.u32()), int(r.u32())
+	default:
+		return nil, fmt.Errorf("PSF: bad header")
+	}
+	stride := (width + 7) / 8
+	switch {
+	case width <= 0 || height <= 0 || size < stride*height:
+		return nil, fmt.Errorf("PSF: bad glyph size %dx%d", width, height)
+	case n <= 0 || len(b) < off || (len(b)-off)/size < n:
+		return nil, fmt.Errorf("PSF: truncated glyph data")
+	}
+	bud := newBudget()
+	if err := bud.alloc(int64(len(b)) + int64(n)*int64(width)*int64(height)); err != nil {
+		return nil, err
+	}
+	s := &Strike{
+		Height: height,
+		Ascent: height,
+		Glyphs: make(map[rune]*image.Alpha),
+		Cells:  make([]Cell, n),
+	}
+	for i := range s.Cells {
+		img := image.NewAlpha(image.Rect(0, 0, width, height))
+		g := b[off+i*size:]
+		for y := range height {
+			for x := range width {
+				if g[y*stride+x/8]&(0x80>>(x%8)) != 0 {
+					img.Pix[y*img.Stride+x] = 0xff
+				}
+			}
+		}
+		s.Cells[i].Glyph = img
+	}
+	if !table {
+		for i := range s.Cells {
+			s.Glyphs[rune(i)] = s.Cells[i].Glyph
+			s.Cells[i].Runes = []rune{rune(i)}
+		}
+		s.Default = '?'
+		return &BitmapFont{Style: "Regular", Strikes: []*Strike{s}}, nil
+	}
+	// unicode table: for each glyph, the runes it represents (as UTF-16 for
+	// PSF1 and UTF-8 for PSF2), ending with a separator, with any multi-rune
+	// sequences (ignored) after a sequence start
+	t, seq := b[off+n*size:], false
+	for i := 0; i < n && 0 < len(t); {
+		var r rune
+		switch {
+		case psf1 && len(t) < 2:
+			t = nil
+			continue
+		case psf1:
+			r, t = rune(binary.LittleEndian.Uint16(t)), t[2:]
+		case t[0] == 0xff || t[0] == 0xfe:
+			r, t = 0xff00|rune(t[0]), t[1:]
+		default:
+			var sz int
+			r, sz = utf8.DecodeRune(t)
+			t = t[sz:]
+		}
+		switch {
+		case r == 0xffff:
+			i, seq = i+1, false
+		case r == 0xfffe:
+			seq = true
+		case !seq:
+			if _, ok := s.Glyphs[r]; !ok {
+				s.Glyphs[r] = s.Cells[i].Glyph
+			}
+			s.Cells[i].Runes = append(s.Cells[i].Runes, r)
+		}
+	}
+	s.Default = '?'
+	if _, ok := s.Glyphs[0xfffd]; ok {
+		s.Default = 0xfffd
+	}
+	return &BitmapFont{Style: "Regular", Strikes: []*Strike{s}}, nil
+}
+
+// RasterizeCellGrid rasterizes the bitmap font's glyph cell grid using the
+// options. See [Font.CellGrid].
+func (font *Font) RasterizeCellGrid(opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := font.CellGrid(opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// CellGrid lays out the glyph cells of a fixed cell bitmap font (ie, a PSF
+// console font) on a canvas, in font order with 16 cells per row, on a grid.
+// Each cell is labeled with the runes mapped to it by the font's unicode
+// table, and each row with the index of its first cell, using the embedded
+// label font. The strike best matching the options' size is used. When opts
+// is nil, the default options will be used.
+func (font *Font) CellGrid(opts *Options) (_ *canvas.Canvas, err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	bf, err := font.Bitmap()
+	if err != nil {
+		return nil, err
+	}
+	strike, scale := bf.strike(int(math.Round(float64(opts.Size) * opts.DPI / 72)))
+	if len(strike.Cells) == 0 {
+		return nil, fmt.Errorf("font has no glyph cells")
+	}
+	lff, err := LabelFont().Load(canvas.FontRegular)
+	if err != nil {
+		return nil, err
+	}
+	label := lff.Face(0.25*float64(opts.Size), opts.FG)
+	lh := label.Metrics().LineHeight
+	// create canvas and context
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	ctx.SetFillColor(opts.FG)
+	// cells fit the glyph and the widest label
+	px := float64(scale) * 25.4 / opts.DPI
+	gw := float64(strike.Cells[0].Glyph.Rect.Dx()) * px
+	gh := float64(strike.Cells[0].Glyph.Rect.Dy()) * px
+	labels := make([]string, len(strike.Cells))
+	cw := gw
+	for i, cell := range strike.Cells {
+		labels[i] = cellLabel(cell.Runes)
+		cw = max(cw, label.TextWidth(labels[i]))
+	}
+	cw, ch := cw+lh, gh+2*lh
+	for i, cell := range strike.Cells {
+		x, y := float64(i%16)*cw, -float64(i/16)*ch
+		if i%16 == 0 {
+			ctx.DrawText(-lh/2, y-ch/2, canvas.NewTextLine(label, fmt.Sprintf("0x%02X", i), canvas.Right))
+		}
+		ctx.DrawPath(x+(cw-gw)/2, y-lh/2, bitmapPath(cell.Glyph).Scale(px, px))
+		ctx.DrawText(x+cw/2, y-ch+lh/2, canvas.NewTextLine(label, labels[i], canvas.Center))
+	}
+	// draw grid
+	line := 0.5 * 25.4 / 72
+	cols, rows := min(16, len(strike.Cells)), (len(strike.Cells)+15)/16
+	ctx.SetFillColor(gridColor)
+	for i := range cols + 1 {
+		ctx.DrawPath(float64(i)*cw-line/2, -float64(rows)*ch, canvas.Rectangle(line, float64(rows)*ch))
+	}
+	for i := range rows + 1 {
+		ctx.DrawPath(0, -float64(i)*ch-line/2, canvas.Rectangle(float64(cols)*cw, line))
+	}
+	// fit canvas to context
+	c.Fit(opts.Margin)
+	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
+
+// cellLabel returns the cell grid label for the runes mapped to a cell.
+func cellLabel(runes []rune) string {
+	switch len(runes) {
+	case 0:
+		return "-"
+	case 1:
+		return fmt.Sprintf("U+%04X", runes[0])
+	}
+	return fmt.Sprintf("U+%04X +%d", runes[0], len(runes)-1)
+}
+
+// gridColor is the cell grid line color.
+var gridColor color.Color = color.NRGBA{R: 0xc0, G: 0xc0, B: 0xc0, A: 0xff}
@@ -0,0 +1,170 @@
+package fontimg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePSF(t *testing.T) {
+	tests := []struct {
+		name   string
+		buf    []byte
+		width  int
+		height int
+		runes  map[rune]int
+	}{
+		{"psf1", testPSF1(false), 8, 16, map[rune]int{'A': 'A', 0xff: 0xff}},
+		{"psf1 table", testPSF1(true), 8, 16, map[rune]int{'A': 1, 'Å': 1, 0xff: 2, 'B': -1}},
+		{"psf2", testPSF2(false), 12, 10, map[rune]int{'A': 'A', 'B': 'B'}},
+		{"psf2 table", testPSF2(true), 12, 10, map[rune]int{'A': 1, '€': 2, 'é': 3, 'e': -1, 'B': -1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !(psfDecoder{}).Match(test.buf) {
+				t.Errorf("expected match")
+			}
+			bf, err := ParsePSF(test.buf)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			s := bf.Strikes[0]
+			if s.Height != test.height {
+				t.Errorf("expected height %d, got: %d", test.height, s.Height)
+			}
+			for r, exp := range test.runes {
+				img, ok := s.Glyphs[r]
+				switch {
+				case exp == -1 && ok:
+					t.Errorf("%q expected no glyph", r)
+				case exp == -1:
+				case !ok:
+					t.Errorf("%q expected glyph %d", r, exp)
+				case img.Rect.Dx() != test.width:
+					t.Errorf("%q expected width %d, got: %d", r, test.width, img.Rect.Dx())
+				case img.AlphaAt(0, 0).A == 0 || img.AlphaAt(test.width-1, 0).A != 0:
+					t.Errorf("%q expected leftmost pixel only on first row", r)
+				default:
+					// second row has the glyph index, in binary
+					for x := range 8 {
+						if set := img.AlphaAt(x, 1).A != 0; set != (exp&(0x80>>x) != 0) {
+							t.Errorf("%q expected glyph %d, pixel %d is %t", r, exp, x, set)
+						}
+					}
+				}
+			}
+		})
+	}
+	if _, err := ParsePSF(testPSF2(true)[:100]); err == nil {
+		t.Errorf("expected error for truncated font")
+	}
+	if _, err := ParsePSF([]byte("\x36\x04\x00\x00")); err == nil {
+		t.Errorf("expected error for empty glyph size")
+	}
+}
+
+// testPSF1 builds a 256 glyph PSF1 font, with the first row of each glyph
+// having the leftmost pixel set, and the second row the glyph index. The
+// unicode table maps glyph 1 to A and Å, and glyph 2 to ÿ.
+func testPSF1(table bool) []byte {
+	b := []byte{0x36, 0x04, 0, 16}
+	if table {
+		b[2] = 0x02
+	}
+	for i := range 256 {
+		g := make([]byte, 16)
+		g[0], g[1] = 0x80, byte(i)
+		b = append(b, g...)
+	}
+	if !table {
+		return b
+	}
+	for i := range 256 {
+		switch i {
+		case 1:
+			b = binary.LittleEndian.AppendUint16(b, 'A')
+			b = binary.LittleEndian.AppendUint16(b, 'Å')
+		case 2:
+			b = binary.LittleEndian.AppendUint16(b, 0xff)
+		}
+		b = binary.LittleEndian.AppendUint16(b, 0xffff)
+	}
+	return b
+}
+
+// testPSF2 builds a 128 glyph, 12x10 PSF2 font, with glyphs as in
+// [testPSF1]. The unicode table maps glyph 1 to A, glyph 2 to €, and glyph 3
+// to é with the sequence e + U+0301.
+func testPSF2(table bool) []byte {
+	const n, width, height = 128, 12, 10
+	b := make([]byte, 32)
+	copy(b, psf2Magic)
+	binary.LittleEndian.PutUint32(b[8:], 32)
+	if table {
+		binary.LittleEndian.PutUint32(b[12:], 1)
+	}
+	binary.LittleEndian.PutUint32(b[16:], n)
+	binary.LittleEndian.PutUint32(b[20:], 2*height)
+	binary.LittleEndian.PutUint32(b[24:], height)
+	binary.LittleEndian.PutUint32(b[28:], width)
+	for i := range n {
+		g := make([]byte, 2*height)
+		g[0], g[2] = 0x80, byte(i)
+		b = append(b, g...)
+	}
+	if !table {
+		return b
+	}
+	for i := range n {
+		switch i {
+		case 1:
+			b = append(b, 'A')
+		case 2:
+			b = append(b, "€"...)
+		case 3:
+			b = append(b, "é\xfeé"...)
+		}
+		b = append(b, 0xff)
+	}
+	return b
+}
+
+func TestCellGrid(t *testing.T) {
+	bf, err := ParsePSF(testPSF1(true))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	cells := bf.Strikes[0].Cells
+	if len(cells) != 256 || fmt.Sprint(cells[1].Runes) != fmt.Sprint([]rune{'A', 'Å'}) || len(cells[3].Runes) != 0 {
+		t.Errorf("expected cell 1 mapped to A and Å, got: %d %v", len(cells), cells[1].Runes)
+	}
+	for _, buf := range [][]byte{testPSF1(true), testPSF2(false)} {
+		img, err := New(buf, "test.psf").RasterizeCellGrid(nil)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if img.Bounds().Dx() < img.Bounds().Dy() {
+			t.Errorf("expected 16 cells per row, got: %v", img.Bounds())
+		}
+	}
+	if _, err := New(testFON(), "test.fon").CellGrid(nil); err == nil {
+		t.Errorf("expected error for font without cells")
+	}
+	if _, err := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf")).CellGrid(nil); err == nil {
+		t.Errorf("expected error for non bitmap font")
+	}
+	tests := []struct {
+		runes []rune
+		exp   string
+	}{
+		{nil, "-"},
+		{[]rune{'A'}, "U+0041"},
+		{[]rune{'A', 'Å', 'a'}, "U+0041 +2"},
+	}
+	for _, test := range tests {
+		if s := cellLabel(test.runes); s != test.exp {
+			t.Errorf("%q expected %q, got: %q", test.runes, test.exp, s)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package fontimg
+
+import (
+	"io"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/svg"
+)
+
+// Render lays out the font image using the options, writing it to w using
+// the canvas writer (ie, a writer from the tdewolff/canvas renderers), for
+// vector output without rasterization. The options' trimming (see
+// [Options.Trim]) only applies to rasterized images. When opts is nil, the
+// default options will be used.
+func (font *Font) Render(w io.Writer, writer canvas.Writer, opts *Options) error {
+	c, err := font.Canvas(opts)
+	if err != nil {
+		return err
+	}
+	return c.Write(w, writer)
+}
+
+// RenderSVG lays out the font image using the options, writing it to w as an
+// SVG image with the font embedded. When opts is nil, the default options
+// will be used.
+func (font *Font) RenderSVG(w io.Writer, opts *Options) error {
+	return font.Render(w, writeSVG, opts)
+}
+
+// writeSVG is a canvas writer for SVG images.
+func writeSVG(w io.Writer, c *canvas.Canvas) error {
+	r := svg.New(w, c.W, c.H, nil)
+	c.RenderTo(r)
+	return r.Close()
+}
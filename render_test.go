@@ -0,0 +1,57 @@
+package fontimg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+)
+
+func TestRenderSVG(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	var buf bytes.Buffer
+	if err := font.RenderSVG(&buf, nil); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s := buf.String()
+	if !strings.HasPrefix(s, "<svg ") || !strings.HasSuffix(s, "</svg>") {
+		t.Errorf("expected svg, got: %q", s[:min(len(s), 64)])
+	}
+	if !strings.Contains(s, "@font-face") {
+		t.Errorf("expected embedded font")
+	}
+	dec := xml.NewDecoder(&buf)
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	var w, h float64
+	err := font.Render(io.Discard, func(_ io.Writer, c *canvas.Canvas) error {
+		w, h = c.Size()
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	c, err := font.Canvas(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if w != c.W || h != c.H {
+		t.Errorf("expected %gx%g, got: %gx%g", c.W, c.H, w, h)
+	}
+	if err := (&Font{Buf: []byte("invalid")}).RenderSVG(io.Discard, nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
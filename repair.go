@@ -0,0 +1,159 @@
+package fontimg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	fontpkg "github.com/tdewolff/font"
+)
+
+// repair is the result of repairing a font's data.
+type repair struct {
+	once    sync.Once
+	buf     []byte
+	repairs []string
+	err     error
+}
+
+// repaired returns the font's repaired data, repairing it on first use.
+func (font *Font) repaired() ([]byte, error) {
+	font.repair.once.Do(func() {
+		buf, err := font.data()
+		if err != nil {
+			font.repair.err = err
+			return
+		}
+		font.repair.buf, font.repair.repairs, font.repair.err = repairSFNT(buf)
+	})
+	return font.repair.buf, font.repair.err
+}
+
+// Repairs returns the defects tolerated when loading a font in lenient mode
+// (see [Font.Lenient]). Returns nil when the font is not lenient or cannot be
+// repaired.
+func (font *Font) Repairs() []string {
+	if !font.Lenient {
+		return nil
+	}
+	if _, err := font.repaired(); err != nil {
+		return nil
+	}
+	return font.repair.repairs
+}
+
+// repairDrop are the optional tables that are dropped when they fail to
+// parse.
+var repairDrop = []string{"kern", "vhea", "vmtx"}
+
+// repairSFNT works around common defects in the font data, returning the
+// rebuilt font data and the tolerated defects. Table records pointing past
+// the end of the data are dropped or truncated, overlapping tables are
+// separated, checksums are recomputed, a bad post table is replaced by one
+// without glyph names, and optional tables that fail to parse are dropped.
+// Font collections are returned unchanged.
+func repairSFNT(b []byte) ([]byte, []string, error) {
+	b, err := toSFNT(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(b) < 12 {
+		return nil, nil, fmt.Errorf("bad SFNT header")
+	}
+	version := string(b[:4])
+	if version == "ttcf" {
+		return b, nil, nil
+	}
+	var repairs []string
+	add := func(format string, v ...any) {
+		repairs = append(repairs, fmt.Sprintf(format, v...))
+	}
+	type record struct {
+		tag            string
+		offset, length int
+	}
+	var records []record
+	tables := make(map[string][]byte)
+	for i := range int(binary.BigEndian.Uint16(b[4:])) {
+		rec := 12 + 16*i
+		if len(b) < rec+16 {
+			add("table directory truncated after %d tables", i)
+			break
+		}
+		tag := string(b[rec : rec+4])
+		checksum := binary.BigEndian.Uint32(b[rec+4:])
+		offset, length := int(binary.BigEndian.Uint32(b[rec+8:])), int(binary.BigEndian.Uint32(b[rec+12:]))
+		switch {
+		case len(b) <= offset:
+			add("%s: offset %d past end of data, dropped", tag, offset)
+			continue
+		case len(b)-offset < length:
+			add("%s: truncated from %d to %d bytes", tag, length, len(b)-offset)
+			length = len(b) - offset
+		}
+		table := b[offset : offset+length : offset+length]
+		if tableChecksum(tag, table) != checksum {
+			add("%s: bad checksum", tag)
+		}
+		for _, r := range records {
+			if r.offset < offset+length && offset < r.offset+r.length {
+				add("%s: overlaps %s", tag, r.tag)
+			}
+		}
+		records = append(records, record{tag, offset, length})
+		tables[tag] = table
+	}
+	if len(tables["head"]) < 54 {
+		return nil, nil, fmt.Errorf("head: bad table")
+	}
+	sfnt := &fontpkg.SFNT{
+		IsCFF:      version == "OTTO",
+		IsTrueType: version != "OTTO",
+		Tables:     tables,
+	}
+	// rebuild until the data parses, working around the failing table
+	for range len(tables) + 1 {
+		buf := sfnt.Write()
+		_, err := fontpkg.ParseSFNT(buf, 0)
+		if err == nil {
+			return buf, repairs, nil
+		}
+		switch tag, _, _ := strings.Cut(err.Error(), ":"); {
+		case tag == "post":
+			tables["post"] = postTable(tables["post"])
+			add("%v, replaced without glyph names", err)
+		case slices.Contains(repairDrop, tag) && tables[tag] != nil:
+			delete(tables, tag)
+			add("%v, dropped", err)
+		default:
+			return nil, nil, unsupported(buf, err)
+		}
+	}
+	return nil, nil, fmt.Errorf("unable to repair font")
+}
+
+// tableChecksum calculates the checksum of the table. The head table's
+// checksum adjustment is excluded.
+func tableChecksum(tag string, b []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(b); i += 4 {
+		var v [4]byte
+		copy(v[:], b[i:])
+		if tag == "head" && i == 8 {
+			continue
+		}
+		sum += binary.BigEndian.Uint32(v[:])
+	}
+	return sum
+}
+
+// postTable returns a version 3.0 post table (without glyph names), keeping
+// the italic angle, underline and fixed pitch fields of b.
+func postTable(b []byte) []byte {
+	post := make([]byte, 32)
+	copy(post[:16], b)
+	binary.BigEndian.PutUint32(post, 0x00030000)
+	return post
+}
@@ -0,0 +1,76 @@
+package fontimg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+)
+
+func TestRepair(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		tag     string
+		f       func(b []byte, rec int)
+		exp     []string
+		invalid bool
+	}{
+		{"", func([]byte, int) {}, nil, false},
+		{"post", func(b []byte, rec int) {
+			// truncate post
+			binary.BigEndian.PutUint32(b[rec+12:], 20)
+		}, []string{"post: bad checksum", "post: bad table, replaced without glyph names"}, true},
+		{"name", func(b []byte, rec int) {
+			// bad checksum
+			binary.BigEndian.PutUint32(b[rec+4:], 0)
+		}, []string{"name: bad checksum"}, false},
+		{"kern", func(b []byte, rec int) {
+			// offset past end of data
+			binary.BigEndian.PutUint32(b[rec+8:], uint32(len(b)))
+		}, []string{fmt.Sprintf("kern: offset %d past end of data, dropped", len(buf))}, true},
+		{"post", func(b []byte, rec int) {
+			// overlap the name table
+			copy(b[rec+8:rec+16], b[tableRecord(b, "name")+8:])
+		}, []string{"post: bad checksum", "post: overlaps name", "post: bad version, replaced without glyph names"}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.tag, func(t *testing.T) {
+			b := append([]byte(nil), buf...)
+			if test.tag != "" {
+				test.f(b, tableRecord(b, test.tag))
+			}
+			if _, err := New(b, "").Load(canvas.FontRegular); (err != nil) != test.invalid {
+				t.Errorf("expected strict load error %t, got: %v", test.invalid, err)
+			}
+			font := New(b, "")
+			font.Lenient = true
+			if _, err := font.Load(canvas.FontRegular); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			repairs := font.Repairs()
+			if strings.Join(repairs, "\n") != strings.Join(test.exp, "\n") {
+				t.Errorf("expected %q, got: %q", test.exp, repairs)
+			}
+			if _, err := font.RasterizeOptions(nil); err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+// tableRecord returns the offset of the table record for tag.
+func tableRecord(b []byte, tag string) int {
+	for i := range int(binary.BigEndian.Uint16(b[4:])) {
+		if rec := 12 + 16*i; string(b[rec:rec+4]) == tag {
+			return rec
+		}
+	}
+	panic("missing table " + tag)
+}
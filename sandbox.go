@@ -0,0 +1,207 @@
+package fontimg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"text/template"
+	"text/template/parse"
+)
+
+// TemplateLimits are the execution limits of a sandboxed template (see
+// [NewSandboxTemplate]).
+//
+// Zero values disable the corresponding limit.
+type TemplateLimits struct {
+	// MaxOutput is the maximum template output, in bytes.
+	MaxOutput int
+	// MaxIterations is the maximum number of range iterations, in total
+	// across all of the template's range actions.
+	MaxIterations int
+	// MaxSize is the maximum font size set with the size func.
+	MaxSize int
+}
+
+// DefaultTemplateLimits returns the default template limits.
+func DefaultTemplateLimits() TemplateLimits {
+	return TemplateLimits{
+		MaxOutput:     4096,
+		MaxIterations: 1000,
+		MaxSize:       256,
+	}
+}
+
+// ErrTemplateLimit is the error returned when executing a sandboxed template
+// exceeds its limits.
+var ErrTemplateLimit = errors.New("template limit exceeded")
+
+// NewSandboxTemplate creates a text template as with [NewTemplate], for
+// template text from untrusted users (ie, custom preview templates accepted
+// by a server). The template's output size, range iterations, and font sizes
+// are bounded by the limits, failing the font image with [ErrTemplateLimit]
+// when exceeded. Templates defining or invoking other templates (ie, with
+// define, block or template) are rejected, and no funcs accessing files or
+// the environment are available. Sandboxed templates are only executed by
+// rendering a font image, and fail when executed directly.
+func NewSandboxTemplate(text string, limits TemplateLimits) (*template.Template, error) {
+	tpl, err := NewTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tpl.Templates()) > 1 {
+		return nil, errors.New("template definitions not allowed")
+	}
+	if tpl.Tree == nil {
+		return tpl, nil
+	}
+	checkSize := func(size int) error {
+		if limits.MaxSize != 0 && limits.MaxSize < size {
+			return fmt.Errorf("size %d: %w", size, ErrTemplateLimit)
+		}
+		return nil
+	}
+	// every range iteration calls sandboxTick
+	funcs := map[string]any{
+		"sandboxTick":   sandboxUnbound,
+		"sandboxOutput": sandboxUnbound,
+		"sandboxSize":   sandboxUnbound,
+		"size": func(size int) (string, error) {
+			if err := checkSize(size); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("\x00%d\x00", size), nil
+		},
+		"waterfall": func(text string, sizes ...int) (string, error) {
+			for _, size := range sizes {
+				if err := checkSize(size); err != nil {
+					return "", err
+				}
+			}
+			return waterfall(text, sizes...), nil
+		},
+	}
+	tick, err := sandboxAction(funcs, fmt.Sprintf("{{ sandboxTick %d }}", limits.MaxIterations))
+	if err != nil {
+		return nil, err
+	}
+	var walk func(parse.Node) error
+	walk = func(n parse.Node) error {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return nil
+			}
+			for _, c := range n.Nodes {
+				if err := walk(c); err != nil {
+					return err
+				}
+			}
+		case *parse.IfNode:
+			return errors.Join(walk(n.List), walk(n.ElseList))
+		case *parse.WithNode:
+			return errors.Join(walk(n.List), walk(n.ElseList))
+		case *parse.RangeNode:
+			if err := errors.Join(walk(n.List), walk(n.ElseList)); err != nil {
+				return err
+			}
+			n.List.Nodes = append([]parse.Node{tick}, n.List.Nodes...)
+		case *parse.TemplateNode:
+			return errors.New("template invocations not allowed")
+		}
+		return nil
+	}
+	if err := walk(tpl.Tree.Root); err != nil {
+		return nil, err
+	}
+	// the first actions set the output and size limits
+	output, err := sandboxAction(funcs, fmt.Sprintf("{{ sandboxOutput %d }}", limits.MaxOutput))
+	if err != nil {
+		return nil, err
+	}
+	size, err := sandboxAction(funcs, fmt.Sprintf("{{ sandboxSize %d }}", limits.MaxSize))
+	if err != nil {
+		return nil, err
+	}
+	tpl.Tree.Root.Nodes = append([]parse.Node{output, size}, tpl.Tree.Root.Nodes...)
+	return tpl.New(sandboxName).Funcs(funcs).AddParseTree(sandboxName, tpl.Tree)
+}
+
+// sandboxName is the name of sandboxed templates.
+const sandboxName = "\x00sandbox"
+
+// sandboxAction parses the text of a single sandbox action.
+func sandboxAction(funcs map[string]any, text string) (parse.Node, error) {
+	tpl, err := template.New("").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return tpl.Tree.Root.Nodes[0], nil
+}
+
+// sandboxUnbound is the sandbox func of sandboxed templates executed outside
+// of [executeTemplate].
+func sandboxUnbound(int) (string, error) {
+	return "", errors.New("sandboxed template executed outside of a font image")
+}
+
+// executeTemplate executes the template with the data, writing to w,
+// enforcing the limits of sandboxed templates (see [NewSandboxTemplate]).
+//
+// As sandboxed templates can write the size markers directly (ie, with
+// printf), the font sizes of the lines in the output are checked before
+// writing the output to w.
+func executeTemplate(w io.Writer, tpl *template.Template, data any) error {
+	if tpl.Name() != sandboxName {
+		return tpl.Execute(w, data)
+	}
+	buf := new(bytes.Buffer)
+	lw, n, maxSize := &limitWriter{w: buf}, 0, 0
+	tpl, err := tpl.Clone()
+	if err != nil {
+		return err
+	}
+	if err := tpl.Funcs(map[string]any{
+		"sandboxTick": func(max int) (string, error) {
+			if n++; max != 0 && max < n {
+				return "", fmt.Errorf("%d iterations: %w", max, ErrTemplateLimit)
+			}
+			return "", nil
+		},
+		"sandboxOutput": func(max int) (string, error) {
+			lw.max = max
+			return "", nil
+		},
+		"sandboxSize": func(max int) (string, error) {
+			maxSize = max
+			return "", nil
+		},
+	}).Execute(lw, data); err != nil {
+		return err
+	}
+	if maxSize != 0 {
+		_, sizes, _ := breakLines(buf.Bytes(), 0)
+		for _, size := range sizes {
+			if maxSize < size {
+				return fmt.Errorf("size %d: %w", size, ErrTemplateLimit)
+			}
+		}
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// limitWriter is a writer returning [ErrTemplateLimit] when more than max
+// bytes are written. When max is zero, writes are not limited.
+type limitWriter struct {
+	w      io.Writer
+	n, max int
+}
+
+// Write satisfies the [io.Writer] interface.
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if w.n += len(p); w.max != 0 && w.max < w.n {
+		return 0, fmt.Errorf("%d bytes output: %w", w.max, ErrTemplateLimit)
+	}
+	return w.w.Write(p)
+}
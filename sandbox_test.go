@@ -0,0 +1,68 @@
+package fontimg
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewSandboxTemplate(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	limits := TemplateLimits{
+		MaxOutput:     256,
+		MaxIterations: 10,
+		MaxSize:       64,
+	}
+	tests := []struct {
+		name  string
+		text  string
+		parse bool
+		exp   error
+	}{
+		{"plain", "{{ size 24 }}{{ .Name }}\n{{ .SampleText }}", true, nil},
+		{"range", "{{ range 3 }}{{ range 2 }}x{{ end }}{{ end }}", true, nil},
+		{"iterations", "{{ range 5 }}{{ range 3 }}x{{ end }}{{ end }}", true, ErrTemplateLimit},
+		{"loop", "{{ range 1000000000 }}{{ end }}", true, ErrTemplateLimit},
+		{"else", "{{ range 0 }}{{ else }}{{ range 11 }}{{ end }}{{ end }}", true, ErrTemplateLimit},
+		{"nested", "{{ if true }}{{ with 1 }}{{ range 11 }}{{ end }}{{ end }}{{ end }}", true, ErrTemplateLimit},
+		{"output", `{{ printf "%300s" "x" }}`, true, ErrTemplateLimit},
+		{"size", "{{ size 65 }}x", true, ErrTemplateLimit},
+		{"waterfall", `{{ waterfall "x" 12 5000 }}`, true, ErrTemplateLimit},
+		{"marker", `{{ printf "%c9999%c" 0 0 }}x`, true, ErrTemplateLimit},
+		{"raw marker", "\x00" + "9999\x00x", true, ErrTemplateLimit},
+		{"marker features", `{{ features "-kern" }}{{ printf "%c9999%c" 0 0 }}x`, true, ErrTemplateLimit},
+		{"marker mid line", `x{{ printf "%c9999%c" 0 0 }}x`, true, nil},
+		{"empty", "", true, nil},
+		{"define", `{{ define "a" }}x{{ end }}{{ template "a" }}`, false, nil},
+		{"block", `{{ block "a" . }}x{{ end }}`, false, nil},
+		{"template", `{{ template "a" }}`, false, nil},
+		{"invalid", "{{ range }}", false, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tpl, err := NewSandboxTemplate(test.text, limits)
+			switch {
+			case !test.parse && err == nil:
+				t.Fatalf("expected error")
+			case !test.parse:
+				return
+			case err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			opts := DefaultOptions()
+			opts.Template, opts.Size = tpl, 12
+			_, err = font.RasterizeOptions(opts)
+			switch {
+			case test.exp == nil && err != nil:
+				t.Errorf("expected no error, got: %v", err)
+			case test.exp != nil && !errors.Is(err, test.exp):
+				t.Errorf("expected %v, got: %v", test.exp, err)
+			}
+			// direct execution fails
+			if err := tpl.Execute(io.Discard, nil); err == nil {
+				t.Errorf("expected error executing directly")
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image/png"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/kenshaw/fontimg"
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// charmap streams the pages of a font's character map as either a zip
+// archive (format=zip, the default) or a multipart response
+// (format=multipart). Pages are rasterized one at a time as they are written,
+// and are not buffered. When format=json, the font's character map is served
+// as JSON (see [fontimg.Font.WriteCmapJSON]).
+//
+// In addition to the preview query parameters, recognizes the cols and rows
+// parameters, controlling the number of runes per line and lines per page.
+func (s *Server) charmap(w http.ResponseWriter, req *http.Request) {
+	font, opts, ok := s.resolve(w, req)
+	if !ok {
+		return
+	}
+	q := req.URL.Query()
+	cols, rows := 16, 16
+	for _, f := range []struct {
+		name string
+		v    *int
+	}{
+		{"cols", &cols},
+		{"rows", &rows},
+	} {
+		if v := q.Get(f.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 || 256 < n {
+				http.Error(w, fmt.Sprintf("invalid %s %q", f.name, v), http.StatusBadRequest)
+				return
+			}
+			*f.v = n
+		}
+	}
+	format := q.Get("format")
+	if format == "" {
+		format = "zip"
+	}
+	switch format {
+	case "zip", "multipart":
+	case "json":
+		var buf bytes.Buffer
+		if err := font.WriteCmapJSON(&buf); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = buf.WriteTo(w)
+		return
+	default:
+		http.Error(w, fmt.Sprintf("invalid format %q", format), http.StatusBadRequest)
+		return
+	}
+	m, err := fontimg.NewCharMap(font, opts, cols, cols*rows)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if s.limits.MaxPages != 0 && s.limits.MaxPages < m.Len() {
+		http.Error(w, fmt.Sprintf("pages exceed maximum %d", s.limits.MaxPages), http.StatusBadRequest)
+		return
+	}
+	name := func(i int) string {
+		return fmt.Sprintf("%s-%03d.png", fontimg.SafeFilename(font.Family), i+1)
+	}
+	// page returns a func that writes page i
+	page := func(i int) func(io.Writer) error {
+		return func(w io.Writer) error {
+			if err := req.Context().Err(); err != nil {
+				return err
+			}
+			start := time.Now()
+			c, err := m.Canvas(i)
+			if err != nil {
+				return err
+			}
+			if width, height := c.Size(); !s.limits.fits(width, height, opts.DPI) {
+				return fmt.Errorf("page %d dimensions exceed limits", i+1)
+			}
+			img := rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace)
+			if s.audit == nil {
+				return png.Encode(w, img)
+			}
+			h := sha256.New()
+			if err := png.Encode(io.MultiWriter(w, h), img); err != nil {
+				return err
+			}
+			return s.log(start, font, opts, name(i), hex.EncodeToString(h.Sum(nil)))
+		}
+	}
+	switch format {
+	case "zip":
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": fontimg.SafeFilename(font.Family) + ".zip",
+		}))
+		err = streamZip(w, m.Len(), name, page)
+	case "multipart":
+		err = streamMultipart(w, m.Len(), name, page)
+	}
+	if err != nil {
+		// headers have already been written, so abort the response
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// streamZip streams n pages as a zip archive to w.
+func streamZip(w http.ResponseWriter, n int, name func(int) string, page func(int) func(io.Writer) error) error {
+	z := zip.NewWriter(w)
+	for i := range n {
+		f, err := z.CreateHeader(&zip.FileHeader{
+			Name:   name(i),
+			Method: zip.Store,
+		})
+		if err != nil {
+			return err
+		}
+		if err := page(i)(f); err != nil {
+			return err
+		}
+		if err := z.Flush(); err != nil {
+			return err
+		}
+		flush(w)
+	}
+	return z.Close()
+}
+
+// streamMultipart streams n pages as a multipart/mixed response to w.
+func streamMultipart(w http.ResponseWriter, n int, name func(int) string, page func(int) func(io.Writer) error) error {
+	mw := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{
+		"boundary": mw.Boundary(),
+	}))
+	for i := range n {
+		f, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type": []string{"image/png"},
+			"Content-Disposition": []string{mime.FormatMediaType("attachment", map[string]string{
+				"filename": name(i),
+			})},
+		})
+		if err != nil {
+			return err
+		}
+		if err := page(i)(f); err != nil {
+			return err
+		}
+		flush(w)
+	}
+	return mw.Close()
+}
+
+// flush flushes w, when supported.
+func flush(w http.ResponseWriter) {
+	_ = http.NewResponseController(w).Flush()
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kenshaw/fontimg"
+)
+
+func TestCharMapZip(t *testing.T) {
+	res := testRequest(t, New(testSystemFonts()), "/charmap?font=Ubuntu&size=12&rows=64", nil)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected %d, got: %d", http.StatusOK, res.Code)
+	}
+	buf := res.Body.Bytes()
+	z, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(z.File) == 0 {
+		t.Fatalf("expected files")
+	}
+	for _, f := range z.File {
+		if !strings.HasPrefix(f.Name, "Ubuntu-") || !strings.HasSuffix(f.Name, ".png") {
+			t.Errorf("expected Ubuntu-*.png, got: %q", f.Name)
+		}
+	}
+}
+
+func TestCharMapMultipart(t *testing.T) {
+	res := testRequest(t, New(testSystemFonts()), "/charmap?font=Ubuntu&size=12&rows=64&format=multipart", nil)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected %d, got: %d", http.StatusOK, res.Code)
+	}
+	typ, params, err := mime.ParseMediaType(res.Header().Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if typ != "multipart/mixed" {
+		t.Fatalf("expected multipart/mixed, got: %q", typ)
+	}
+	r := multipart.NewReader(res.Body, params["boundary"])
+	var n int
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if s := p.Header.Get("Content-Type"); s != "image/png" {
+			t.Errorf("expected image/png, got: %q", s)
+		}
+		n++
+	}
+	if n == 0 {
+		t.Errorf("expected parts")
+	}
+}
+
+func TestCharMapJSON(t *testing.T) {
+	res := testRequest(t, New(testSystemFonts()), "/charmap?font=Ubuntu&format=json", nil)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected %d, got: %d", http.StatusOK, res.Code)
+	}
+	if s := res.Header().Get("Content-Type"); s != "application/json" {
+		t.Errorf("expected application/json, got: %q", s)
+	}
+	var v []fontimg.CmapEntry
+	if err := json.Unmarshal(res.Body.Bytes(), &v); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(v) == 0 {
+		t.Errorf("expected entries")
+	}
+}
+
+func TestCharMapLimits(t *testing.T) {
+	limits := DefaultLimits()
+	limits.MaxPages = 1
+	s := New(testSystemFonts(), WithLimits(limits))
+	if res := testRequest(t, s, "/charmap?font=Ubuntu&rows=1", nil); res.Code != http.StatusBadRequest {
+		t.Errorf("expected %d, got: %d", http.StatusBadRequest, res.Code)
+	}
+	if res := testRequest(t, s, "/charmap?font=Ubuntu&format=tar", nil); res.Code != http.StatusBadRequest {
+		t.Errorf("expected %d, got: %d", http.StatusBadRequest, res.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Limits are the request limits enforced by the server.
+//
+// Zero values disable the corresponding limit.
+type Limits struct {
+	// MaxTextLength is the maximum length (in runes) of the text parameter.
+	MaxTextLength int
+	// MaxSize is the maximum font size.
+	MaxSize int
+	// MaxDPI is the maximum DPI.
+	MaxDPI float64
+	// MaxMargin is the maximum margin.
+	MaxMargin float64
+	// MaxWidth is the maximum width (in pixels) of the rendered image.
+	MaxWidth int
+	// MaxHeight is the maximum height (in pixels) of the rendered image.
+	MaxHeight int
+	// MaxPages is the maximum number of pages of a multi-page response.
+	MaxPages int
+	// MaxTemplateLength is the maximum length (in bytes) of the template
+	// parameter (see [WithTemplates]).
+	MaxTemplateLength int
+	// Fonts are the allowed font family names. When empty, all fonts are
+	// allowed.
+	Fonts []string
+}
+
+// DefaultLimits returns the default limits.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxTextLength:     256,
+		MaxSize:           256,
+		MaxDPI:            600,
+		MaxMargin:         100,
+		MaxWidth:          4096,
+		MaxHeight:         4096,
+		MaxPages:          256,
+		MaxTemplateLength: 1024,
+	}
+}
+
+// allowed returns true when the font name is allowed.
+func (l Limits) allowed(name string) bool {
+	if len(l.Fonts) == 0 {
+		return true
+	}
+	for _, s := range l.Fonts {
+		if strings.EqualFold(strings.TrimSpace(s), strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// fits returns true when a canvas of width and height (in millimeters)
+// rasterized at dpi is within the maximum image dimensions.
+func (l Limits) fits(width, height, dpi float64) bool {
+	w, h := math.Ceil(width*dpi/25.4), math.Ceil(height*dpi/25.4)
+	return (l.MaxWidth == 0 || w <= float64(l.MaxWidth)) &&
+		(l.MaxHeight == 0 || h <= float64(l.MaxHeight))
+}
+
+// RateLimitFunc is a rate limiting hook, called with the remote IP address
+// of each request. Requests are rejected with [http.StatusTooManyRequests]
+// when it returns false.
+type RateLimitFunc func(ip string) bool
+
+// remoteIP returns the remote IP address of the request.
+func remoteIP(req *http.Request) string {
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return host
+	}
+	return req.RemoteAddr
+}
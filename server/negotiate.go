@@ -0,0 +1,113 @@
+package server
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kenshaw/fontimg"
+)
+
+// previewFormat returns the image format of a preview, from the request's
+// format parameter when set, or otherwise negotiated with the request's Accept
+// header (see [negotiate]) from the registered image encoders (see
+// [fontimg.RegisterEncoder]), preferring the preferred format (when not
+// empty), then png.
+func previewFormat(req *http.Request, prefer string) (string, error) {
+	formats := fontimg.EncoderFormats()
+	if prefer = strings.ToLower(prefer); prefer == "jpg" {
+		prefer = "jpeg"
+	}
+	for _, format := range []string{"png", prefer} {
+		if i := slices.Index(formats, format); i != -1 {
+			formats = append([]string{format}, slices.Delete(formats, i, i+1)...)
+		}
+	}
+	if v := req.URL.Query().Get("format"); v != "" {
+		if v = strings.ToLower(v); v == "jpg" {
+			v = "jpeg"
+		}
+		if !slices.Contains(formats, v) {
+			return "", fmt.Errorf("invalid format %q", v)
+		}
+		return v, nil
+	}
+	format, ok := negotiate(req.Header.Get("Accept"), formats)
+	if !ok {
+		return "", errNotAcceptable
+	}
+	return format, nil
+}
+
+// negotiate returns the format most acceptable to the Accept header, matching
+// formats by their image media type (ie, image/png for png). Formats equally
+// acceptable are chosen in order. When the header is empty, the first format
+// is returned. Returns false when no format is acceptable.
+func negotiate(accept string, formats []string) (string, bool) {
+	if strings.TrimSpace(accept) == "" && len(formats) != 0 {
+		return formats[0], true
+	}
+	type mediaRange struct {
+		typ, subtype string
+		q            float64
+	}
+	var ranges []mediaRange
+	for v := range strings.SplitSeq(accept, ",") {
+		typ, params, err := mime.ParseMediaType(v)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil || q < 0 || 1 < q {
+				continue
+			}
+		}
+		r := mediaRange{q: q}
+		r.typ, r.subtype, _ = strings.Cut(typ, "/")
+		ranges = append(ranges, r)
+	}
+	var best string
+	var bestQ float64
+	for _, format := range formats {
+		// the most specific matching range applies
+		q, specificity := 0.0, -1
+		for _, r := range ranges {
+			n := -1
+			switch {
+			case r.typ == "image" && r.subtype == format:
+				n = 2
+			case r.typ == "image" && r.subtype == "*":
+				n = 1
+			case r.typ == "*" && r.subtype == "*":
+				n = 0
+			}
+			if specificity < n {
+				q, specificity = r.q, n
+			}
+		}
+		if bestQ < q {
+			best, bestQ = format, q
+		}
+	}
+	return best, best != ""
+}
+
+// cacheControl returns the Cache-Control header for a preview. The max age
+// is limited to the expiry of signed requests.
+func (s *Server) cacheControl(req *http.Request, now time.Time) string {
+	maxAge := s.maxAge
+	if v := req.URL.Query().Get("exp"); s.key != nil && v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			maxAge = min(maxAge, time.Unix(n, 0).Sub(now))
+		}
+	}
+	if maxAge < time.Second {
+		return "no-cache"
+	}
+	return "public, max-age=" + strconv.FormatInt(int64(maxAge/time.Second), 10)
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNegotiate(t *testing.T) {
+	formats := []string{"png", "gif", "jpeg", "webp"}
+	tests := []struct {
+		accept string
+		exp    string
+	}{
+		{"", "png"},
+		{"*/*", "png"},
+		{"image/*", "png"},
+		{"image/jpeg", "jpeg"},
+		{"text/html, image/gif;q=0.9, */*;q=0.1", "gif"},
+		{"image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8", "png"},
+		{"image/webp, image/*;q=0.5", "webp"},
+		{"image/png;q=0.2, image/jpeg;q=0.4", "jpeg"},
+		{"image/*, image/png;q=0", "gif"},
+		{"IMAGE/JPEG", "jpeg"},
+		{"image/jpeg;q=x, image/gif", "gif"},
+		{"text/html", ""},
+		{"image/*;q=0", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.accept, func(t *testing.T) {
+			format, ok := negotiate(test.accept, formats)
+			if format != test.exp {
+				t.Errorf("expected %q, got: %q", test.exp, format)
+			}
+			if ok != (test.exp != "") {
+				t.Errorf("expected %t, got: %t", test.exp != "", ok)
+			}
+		})
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	key := []byte("key")
+	tests := []struct {
+		opts []Option
+		exp  time.Time
+		v    string
+	}{
+		{nil, time.Time{}, "public, max-age=86400"},
+		{[]Option{WithMaxAge(time.Minute)}, time.Time{}, "public, max-age=60"},
+		{[]Option{WithMaxAge(0)}, time.Time{}, "no-cache"},
+		{[]Option{WithSigningKey(key)}, now.Add(time.Hour), "public, max-age=3600"},
+		{[]Option{WithSigningKey(key)}, now.Add(48 * time.Hour), "public, max-age=86400"},
+		{[]Option{WithSigningKey(key)}, now.Add(-time.Hour), "no-cache"},
+	}
+	for i, test := range tests {
+		s := New(testSystemFonts(), test.opts...)
+		q := Sign(key, url.Values{"font": {"Ubuntu"}}, test.exp)
+		req := httptest.NewRequest("GET", "/preview?"+q.Encode(), nil)
+		if v := s.cacheControl(req, now); v != test.v {
+			t.Errorf("test %d expected %q, got: %q", i, test.v, v)
+		}
+	}
+}
@@ -0,0 +1,533 @@
+// Package server provides a [http.Handler] serving font preview images.
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"maps"
+	"math"
+	"mime"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kenshaw/fontimg"
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+	fontpkg "github.com/tdewolff/font"
+)
+
+// Server is a http handler serving font preview images.
+type Server struct {
+	sysfonts  *fontpkg.SystemFonts
+	opts      fontimg.Options
+	limits    Limits
+	rateLimit RateLimitFunc
+	key       []byte
+	templates *fontimg.TemplateLimits
+	audit     *fontimg.AuditLog
+	maxAge    time.Duration
+	mux       *http.ServeMux
+	refs      struct {
+		sync.Mutex
+		m map[string]fontRef
+	}
+}
+
+// DefaultMaxAge is the default max age of cached preview images.
+const DefaultMaxAge = 24 * time.Hour
+
+// New creates a new font preview image server. When sysfonts is nil, the
+// default system fonts will be used.
+func New(sysfonts *fontpkg.SystemFonts, opts ...Option) *Server {
+	s := &Server{
+		sysfonts: sysfonts,
+		opts:     *fontimg.DefaultOptions(),
+		limits:   DefaultLimits(),
+		maxAge:   DefaultMaxAge,
+		mux:      http.NewServeMux(),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	s.mux.HandleFunc("GET /healthz", s.healthz)
+	s.mux.HandleFunc("GET /readyz", s.readyz)
+	s.mux.HandleFunc("GET /fonts", s.fonts)
+	s.mux.HandleFunc("GET /preview", s.preview)
+	s.mux.HandleFunc("GET /charmap", s.charmap)
+	return s
+}
+
+// ServeHTTP satisfies the [http.Handler] interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mux.ServeHTTP(w, req)
+}
+
+// healthz serves the liveness check.
+func (s *Server) healthz(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, "ok\n")
+}
+
+// readyz serves the readiness check, reporting ready once the system fonts
+// are available.
+func (s *Server) readyz(w http.ResponseWriter, req *http.Request) {
+	if _, err := s.systemFonts(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, "ok\n")
+}
+
+// Family is a font family served by the server.
+type Family struct {
+	Family string   `json:"family"`
+	Styles []string `json:"styles"`
+}
+
+// fonts serves the available font families and styles as JSON.
+func (s *Server) fonts(w http.ResponseWriter, req *http.Request) {
+	sysfonts, err := s.systemFonts()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	families := make([]Family, 0, len(sysfonts.Fonts))
+	for family, m := range sysfonts.Fonts {
+		if !s.limits.allowed(family) {
+			continue
+		}
+		styles := slices.Sorted(maps.Keys(m))
+		v := make([]string, len(styles))
+		for i, style := range styles {
+			v[i] = style.String()
+		}
+		families = append(families, Family{
+			Family: family,
+			Styles: v,
+		})
+	}
+	slices.SortFunc(families, func(a, b Family) int {
+		return strings.Compare(strings.ToLower(a.Family), strings.ToLower(b.Family))
+	})
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(families)
+}
+
+// preview serves a font preview image.
+//
+// Recognized query parameters are font, profile, style, preset, template,
+// text, size, fg, bg, dpi, margin, lang, format and dl. The template
+// parameter is only recognized when the server accepts custom templates (see
+// [WithTemplates]). When profile is set, the named
+// rendering profile's layout, options, theme, and encoder settings are used
+// (see [fontimg.LookupProfile]), with the other parameters applied over the
+// profile's options. When format is not set, the image format is negotiated
+// from the Accept header, defaulting to the profile's format, or png. When
+// dl=1, the image is served as an attachment. When the server has a signing key, the exp and
+// sig parameters must be set (see [Sign]).
+func (s *Server) preview(w http.ResponseWriter, req *http.Request) {
+	font, opts, ok := s.resolve(w, req)
+	if !ok {
+		return
+	}
+	// profile is validated when parsing the request
+	profile := &fontimg.Profile{}
+	if v := req.URL.Query().Get("profile"); v != "" {
+		profile, _ = fontimg.LookupProfile(v)
+	}
+	format, err := previewFormat(req, profile.Format)
+	switch {
+	case err == errNotAcceptable:
+		http.Error(w, err.Error(), http.StatusNotAcceptable)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// set caching headers
+	ref, err := s.ref(font)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	key := fontimg.CacheKeyRef(font, ref, opts)
+	if profile.Name != "" {
+		key += "-" + strings.ToLower(profile.Name)
+	}
+	etag, modtime := `"`+key+"-"+format+`"`, modTime(font)
+	h := w.Header()
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", s.cacheControl(req, time.Now()))
+	h.Set("Vary", "Accept")
+	if !modtime.IsZero() {
+		h.Set("Last-Modified", modtime.UTC().Format(http.TimeFormat))
+	}
+	if notModified(req, etag, modtime) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	// layout and check dimensions
+	start := time.Now()
+	var c *canvas.Canvas
+	if profile.Layout != nil {
+		c, err = profile.Layout(font, opts)
+	} else {
+		c, err = font.CanvasContext(req.Context(), opts)
+	}
+	switch {
+	case errors.Is(err, fontimg.ErrTemplateLimit):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if width, height := c.Size(); !s.limits.fits(width, height, opts.DPI) {
+		http.Error(w, "image dimensions exceed limits", http.StatusBadRequest)
+		return
+	}
+	// rasterize
+	img := rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace)
+	buf := new(bytes.Buffer)
+	out := profile.Output(buf)
+	out.Format = format
+	if err := fontimg.Encode(img, out); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	if err := s.log(start, font, opts, "", hex.EncodeToString(sum[:])); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.Set("Content-Type", "image/"+format)
+	h.Set("Content-Length", strconv.Itoa(buf.Len()))
+	if req.URL.Query().Get("dl") == "1" {
+		h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": fontimg.SafeFilename(font.Family) + "." + format,
+		}))
+	}
+	if req.Method != http.MethodHead {
+		_, _ = w.Write(buf.Bytes())
+	}
+}
+
+// resolve checks the request's rate limit and signature, and parses the font
+// and rasterization options from the request. When the request cannot be
+// handled, an error is written and false is returned.
+func (s *Server) resolve(w http.ResponseWriter, req *http.Request) (*fontimg.Font, *fontimg.Options, bool) {
+	if s.rateLimit != nil && !s.rateLimit(remoteIP(req)) {
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return nil, nil, false
+	}
+	if s.key != nil {
+		if err := verify(s.key, req.URL.Query(), time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return nil, nil, false
+		}
+	}
+	font, opts, err := s.parse(req)
+	switch {
+	case err == errNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, nil, false
+	case err == errNotAllowed:
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return nil, nil, false
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	return font, opts, true
+}
+
+// parse parses the font and rasterization options from the request.
+func (s *Server) parse(req *http.Request) (*fontimg.Font, *fontimg.Options, error) {
+	q, opts := req.URL.Query(), s.opts
+	name := q.Get("font")
+	if name == "" {
+		return nil, nil, fmt.Errorf("missing font")
+	}
+	if !s.limits.allowed(name) {
+		return nil, nil, errNotAllowed
+	}
+	if v := q.Get("profile"); v != "" {
+		profile, err := fontimg.LookupProfile(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = *profile.Apply(&opts)
+	}
+	if v := q.Get("style"); v != "" {
+		style, err := fontimg.ParseStyle(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts.Style = style
+	}
+	if v := q.Get("preset"); v != "" {
+		preset, err := fontimg.LookupPreset(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts.Template = preset.Template
+	}
+	if v := q.Get("template"); v != "" {
+		switch {
+		case s.templates == nil:
+			return nil, nil, fmt.Errorf("custom templates not accepted")
+		case s.limits.MaxTemplateLength != 0 && s.limits.MaxTemplateLength < len(v):
+			return nil, nil, fmt.Errorf("template exceeds maximum length %d", s.limits.MaxTemplateLength)
+		}
+		tpl, err := fontimg.NewSandboxTemplate(v, *s.templates)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid template: %v", err)
+		}
+		opts.Template = tpl
+	}
+	if v := q.Get("text"); v != "" {
+		if s.limits.MaxTextLength != 0 && s.limits.MaxTextLength < utf8.RuneCountInString(v) {
+			return nil, nil, fmt.Errorf("text exceeds maximum length %d", s.limits.MaxTextLength)
+		}
+		opts.Text = v
+	}
+	if v := q.Get("size"); v != "" {
+		size, err := strconv.Atoi(v)
+		switch {
+		case err != nil || size <= 0:
+			return nil, nil, fmt.Errorf("invalid size %q", v)
+		case s.limits.MaxSize != 0 && s.limits.MaxSize < size:
+			return nil, nil, fmt.Errorf("size exceeds maximum %d", s.limits.MaxSize)
+		}
+		opts.Size = size
+	}
+	for _, f := range []struct {
+		name string
+		c    *color.Color
+	}{
+		{"fg", &opts.FG},
+		{"bg", &opts.BG},
+	} {
+		if v := q.Get(f.name); v != "" {
+			c, err := fontimg.ParseColor(v)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s %q", f.name, v)
+			}
+			*f.c = c
+		}
+	}
+	for _, f := range []struct {
+		name string
+		v    *float64
+		max  float64
+	}{
+		{"dpi", &opts.DPI, s.limits.MaxDPI},
+		{"margin", &opts.Margin, s.limits.MaxMargin},
+	} {
+		if v := q.Get(f.name); v != "" {
+			n, err := strconv.ParseFloat(v, 64)
+			switch {
+			case err != nil || n < 0 || math.IsNaN(n) || math.IsInf(n, 0):
+				return nil, nil, fmt.Errorf("invalid %s %q", f.name, v)
+			case f.max != 0 && f.max < n:
+				return nil, nil, fmt.Errorf("%s exceeds maximum %g", f.name, f.max)
+			}
+			*f.v = n
+		}
+	}
+	if v := q.Get("lang"); v != "" {
+		opts.Language = v
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, nil, err
+	}
+	sysfonts, err := s.systemFonts()
+	if err != nil {
+		return nil, nil, err
+	}
+	font := fontimg.Match(name, opts.Style, sysfonts)
+	if font == nil {
+		return nil, nil, errNotFound
+	}
+	return font, &opts, nil
+}
+
+// log writes the audit log record for the generated output with the hash,
+// when the server has an audit log.
+func (s *Server) log(start time.Time, font *fontimg.Font, opts *fontimg.Options, output, hash string) error {
+	if s.audit == nil {
+		return nil
+	}
+	ref, err := s.ref(font)
+	if err != nil {
+		return err
+	}
+	if err := s.audit.Log(fontimg.AuditRecord{
+		Time:        start,
+		Source:      "server",
+		Font:        font.Path,
+		FontHash:    ref.Hash,
+		OptionsHash: fontimg.OptionsHash(opts),
+		Output:      output,
+		OutputHash:  hash,
+		Duration:    time.Since(start),
+	}); err != nil {
+		return fmt.Errorf("unable to write audit log: %v", err)
+	}
+	return nil
+}
+
+// systemFonts returns the server's system fonts.
+func (s *Server) systemFonts() (*fontpkg.SystemFonts, error) {
+	if s.sysfonts != nil {
+		return s.sysfonts, nil
+	}
+	return fontimg.SystemFonts()
+}
+
+// Option is a server option.
+type Option func(*Server)
+
+// WithOptions is a server option to set the default rasterization options.
+func WithOptions(opts *fontimg.Options) Option {
+	return func(s *Server) {
+		s.opts = *opts
+	}
+}
+
+// WithLimits is a server option to set the request limits. See
+// [DefaultLimits] for the limits used when not specified.
+func WithLimits(limits Limits) Option {
+	return func(s *Server) {
+		s.limits = limits
+	}
+}
+
+// WithRateLimit is a server option to set a rate limiting hook.
+func WithRateLimit(f RateLimitFunc) Option {
+	return func(s *Server) {
+		s.rateLimit = f
+	}
+}
+
+// WithSigningKey is a server option to require preview requests be signed
+// with the key. See [Sign].
+func WithSigningKey(key []byte) Option {
+	return func(s *Server) {
+		s.key = key
+	}
+}
+
+// WithTemplates is a server option to accept custom preview templates from
+// the template parameter, executed in a sandbox with the limits (see
+// [fontimg.NewSandboxTemplate] and [fontimg.DefaultTemplateLimits]).
+func WithTemplates(limits fontimg.TemplateLimits) Option {
+	return func(s *Server) {
+		s.templates = &limits
+	}
+}
+
+// WithAuditLog is a server option to append a record to the audit log for
+// each generated image.
+func WithAuditLog(log *fontimg.AuditLog) Option {
+	return func(s *Server) {
+		s.audit = log
+	}
+}
+
+// WithMaxAge is a server option to set the max age of cached preview images
+// (see [DefaultMaxAge]). When less than a second, clients must revalidate
+// cached images.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(s *Server) {
+		s.maxAge = maxAge
+	}
+}
+
+// Errors.
+var (
+	errNotFound      = fmt.Errorf("font not found")
+	errNotAllowed    = fmt.Errorf("font not allowed")
+	errNotAcceptable = fmt.Errorf("no acceptable image format")
+)
+
+// modTime returns the modification time of the font's file.
+// ref returns the font's reference. The hash of the font's content is
+// memoized by the font's path, modification time and size, so that requests
+// for the same font, including conditional requests, do not hash the font
+// again.
+func (s *Server) ref(font *fontimg.Font) (fontimg.Ref, error) {
+	if font.Buf != nil || font.Path == "" {
+		return font.Ref()
+	}
+	fi, err := os.Stat(font.Path)
+	if err != nil {
+		return font.Ref()
+	}
+	s.refs.Lock()
+	r, ok := s.refs.m[font.Path]
+	s.refs.Unlock()
+	if ok && r.modTime.Equal(fi.ModTime()) && r.size == fi.Size() {
+		return r.ref, nil
+	}
+	ref, err := font.Ref()
+	if err != nil {
+		return fontimg.Ref{}, err
+	}
+	s.refs.Lock()
+	defer s.refs.Unlock()
+	if s.refs.m == nil {
+		s.refs.m = make(map[string]fontRef)
+	}
+	s.refs.m[font.Path] = fontRef{ref: ref, modTime: fi.ModTime(), size: fi.Size()}
+	return ref, nil
+}
+
+// fontRef is a memoized font reference.
+type fontRef struct {
+	ref     fontimg.Ref
+	modTime time.Time
+	size    int64
+}
+
+// modTime returns the modification time of the font's file.
+func modTime(font *fontimg.Font) time.Time {
+	if font.Path != "" {
+		if fi, err := os.Stat(font.Path); err == nil {
+			return fi.ModTime()
+		}
+	}
+	return time.Time{}
+}
+
+// notModified returns true when the request's conditional headers match the
+// etag or modification time.
+func notModified(req *http.Request, etag string, modtime time.Time) bool {
+	if s := req.Header.Get("If-None-Match"); s != "" {
+		for v := range strings.SplitSeq(s, ",") {
+			if v = strings.TrimPrefix(strings.TrimSpace(v), "W/"); v == "*" || v == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if s := req.Header.Get("If-Modified-Since"); s != "" && !modtime.IsZero() {
+		if t, err := http.ParseTime(s); err == nil {
+			return !modtime.Truncate(time.Second).After(t)
+		}
+	}
+	return false
+}
@@ -0,0 +1,417 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kenshaw/fontimg"
+	fontpkg "github.com/tdewolff/font"
+)
+
+func TestPreview(t *testing.T) {
+	s := New(testSystemFonts())
+	res := testRequest(t, s, "/preview?font=Ubuntu&size=24&fg=000&bg=fff&dl=1", nil)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected %d, got: %d", http.StatusOK, res.Code)
+	}
+	if s, exp := res.Header().Get("Content-Type"), "image/png"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+	if s, exp := res.Header().Get("Content-Disposition"), `attachment; filename=Ubuntu.png`; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+	etag := res.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected etag")
+	}
+	lastModified := res.Header().Get("Last-Modified")
+	if lastModified == "" {
+		t.Fatalf("expected last modified")
+	}
+	res = testRequest(t, s, "/preview?font=Ubuntu&size=24&fg=000&bg=fff", map[string]string{
+		"If-None-Match": etag,
+	})
+	if res.Code != http.StatusNotModified {
+		t.Errorf("expected %d, got: %d", http.StatusNotModified, res.Code)
+	}
+	res = testRequest(t, s, "/preview?font=Ubuntu&size=24&fg=000&bg=fff", map[string]string{
+		"If-Modified-Since": lastModified,
+	})
+	if res.Code != http.StatusNotModified {
+		t.Errorf("expected %d, got: %d", http.StatusNotModified, res.Code)
+	}
+	res = testRequest(t, s, "/preview?font=Ubuntu&size=32", map[string]string{
+		"If-None-Match": etag,
+	})
+	if res.Code != http.StatusOK {
+		t.Errorf("expected %d, got: %d", http.StatusOK, res.Code)
+	}
+}
+
+func TestPreviewRef(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Test.ttf")
+	copyFont := func(name string) {
+		t.Helper()
+		buf, err := os.ReadFile(filepath.Join("..", "testdata", name))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if err := os.WriteFile(path, buf, 0o644); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	copyFont("Ubuntu-R.ttf")
+	sysfonts := &fontpkg.SystemFonts{
+		Fonts: make(map[string]map[fontpkg.Style]fontpkg.FontMetadata),
+	}
+	sysfonts.Add(fontpkg.FontMetadata{
+		Filename: path,
+		Family:   "Test",
+		Style:    fontpkg.Regular,
+	})
+	s := New(sysfonts)
+	res := testRequest(t, s, "/preview?font=Test", nil)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected %d, got: %d", http.StatusOK, res.Code)
+	}
+	etag := res.Header().Get("ETag")
+	r, ok := s.refs.m[path]
+	if !ok || r.ref.Hash == "" {
+		t.Fatalf("expected memoized font hash")
+	}
+	// conditional requests reuse the memoized hash
+	s.refs.m[path] = fontRef{ref: fontimg.Ref{Source: "file", Path: path, Hash: "memoized"}, modTime: r.modTime, size: r.size}
+	res = testRequest(t, s, "/preview?font=Test", map[string]string{
+		"If-None-Match": etag,
+	})
+	if res.Code != http.StatusOK || res.Header().Get("ETag") == etag {
+		t.Errorf("expected memoized font hash to be used, got: %d", res.Code)
+	}
+	// modified fonts are hashed again
+	s.refs.m[path] = r
+	copyFont("NotoMono-Regular.ttf")
+	res = testRequest(t, s, "/preview?font=Test", map[string]string{
+		"If-None-Match": etag,
+	})
+	switch {
+	case res.Code != http.StatusOK:
+		t.Errorf("expected %d, got: %d", http.StatusOK, res.Code)
+	case res.Header().Get("ETag") == etag:
+		t.Errorf("expected new etag for modified font")
+	case s.refs.m[path].ref.Hash == r.ref.Hash:
+		t.Errorf("expected font to be hashed again")
+	}
+}
+
+func TestPreviewFormat(t *testing.T) {
+	s := New(testSystemFonts())
+	tests := []struct {
+		path   string
+		accept string
+		exp    string
+		decode func(io.Reader) (image.Image, error)
+	}{
+		{"/preview?font=Ubuntu", "", "image/png", png.Decode},
+		{"/preview?font=Ubuntu", "image/avif,image/webp,image/*,*/*;q=0.8", "image/png", png.Decode},
+		{"/preview?font=Ubuntu", "image/jpeg", "image/jpeg", jpeg.Decode},
+		{"/preview?font=Ubuntu&format=gif", "image/jpeg", "image/gif", gif.Decode},
+		{"/preview?font=Ubuntu&format=JPG", "", "image/jpeg", jpeg.Decode},
+	}
+	etags := make(map[string]bool)
+	for _, test := range tests {
+		t.Run(test.path+" "+test.accept, func(t *testing.T) {
+			res := testRequest(t, s, test.path, map[string]string{
+				"Accept": test.accept,
+			})
+			if res.Code != http.StatusOK {
+				t.Fatalf("expected %d, got: %d", http.StatusOK, res.Code)
+			}
+			h := res.Header()
+			if s := h.Get("Content-Type"); s != test.exp {
+				t.Errorf("expected %q, got: %q", test.exp, s)
+			}
+			if s, exp := h.Get("Cache-Control"), "public, max-age=86400"; s != exp {
+				t.Errorf("expected %q, got: %q", exp, s)
+			}
+			if s, exp := h.Get("Vary"), "Accept"; s != exp {
+				t.Errorf("expected %q, got: %q", exp, s)
+			}
+			etags[h.Get("ETag")] = true
+			if _, err := test.decode(res.Body); err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		})
+	}
+	if len(etags) != 3 {
+		t.Errorf("expected an etag per format, got: %v", etags)
+	}
+	for _, test := range []struct {
+		path   string
+		accept string
+		exp    int
+	}{
+		{"/preview?font=Ubuntu", "text/html", http.StatusNotAcceptable},
+		{"/preview?font=Ubuntu", "image/*;q=0", http.StatusNotAcceptable},
+		{"/preview?font=Ubuntu&format=bmp", "", http.StatusBadRequest},
+	} {
+		res := testRequest(t, s, test.path, map[string]string{
+			"Accept": test.accept,
+		})
+		if res.Code != test.exp {
+			t.Errorf("%s %q expected %d, got: %d", test.path, test.accept, test.exp, res.Code)
+		}
+	}
+}
+
+func TestPreviewProfile(t *testing.T) {
+	s := New(testSystemFonts())
+	etags := make(map[string]bool)
+	for _, path := range []string{
+		"/preview?font=Ubuntu&size=24",
+		"/preview?font=Ubuntu&size=24&profile=waterfall-dark",
+		"/preview?font=Ubuntu&size=24&profile=thumbnail",
+	} {
+		res := testRequest(t, s, path, nil)
+		if res.Code != http.StatusOK {
+			t.Fatalf("%s expected %d, got: %d", path, http.StatusOK, res.Code)
+		}
+		img, err := png.Decode(res.Body)
+		if err != nil {
+			t.Fatalf("%s expected no error, got: %v", path, err)
+		}
+		if b := img.Bounds(); strings.Contains(path, "thumbnail") && (256 < b.Dx() || 256 < b.Dy()) {
+			t.Errorf("%s expected image within 256x256, got: %v", path, b)
+		}
+		etags[res.Header().Get("ETag")] = true
+	}
+	if len(etags) != 3 {
+		t.Errorf("expected an etag per profile, got: %v", etags)
+	}
+}
+
+func TestPreviewTemplate(t *testing.T) {
+	limits := fontimg.DefaultTemplateLimits()
+	limits.MaxIterations = 10
+	s := New(testSystemFonts(), WithTemplates(limits))
+	tests := []struct {
+		template string
+		exp      int
+	}{
+		{"{{ size 24 }}{{ .Name }}", http.StatusOK},
+		{"{{ range 3 }}x{{ end }}", http.StatusOK},
+		{"{{ range 1000000 }}x{{ end }}", http.StatusBadRequest},
+		{"{{ size 1000 }}x", http.StatusBadRequest},
+		{`{{ define "a" }}x{{ end }}`, http.StatusBadRequest},
+		{"{{ range }}", http.StatusBadRequest},
+		{strings.Repeat("x", 1025), http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.template[:min(len(test.template), 32)], func(t *testing.T) {
+			path := "/preview?font=Ubuntu&size=12&template=" + url.QueryEscape(test.template)
+			if res := testRequest(t, s, path, nil); res.Code != test.exp {
+				t.Errorf("expected %d, got: %d", test.exp, res.Code)
+			}
+		})
+	}
+	// not accepted by default
+	if res := testRequest(t, New(testSystemFonts()), "/preview?font=Ubuntu&template=x", nil); res.Code != http.StatusBadRequest {
+		t.Errorf("expected %d, got: %d", http.StatusBadRequest, res.Code)
+	}
+}
+
+func TestPreviewErrors(t *testing.T) {
+	s := New(testSystemFonts())
+	tests := []struct {
+		path string
+		exp  int
+	}{
+		{"/preview", http.StatusBadRequest},
+		{"/preview?font=Ubuntu&size=a", http.StatusBadRequest},
+		{"/preview?font=Ubuntu&fg=xyz", http.StatusBadRequest},
+		{"/preview?font=Ubuntu&style=Blah", http.StatusBadRequest},
+		{"/preview?font=Ubuntu&preset=blah", http.StatusBadRequest},
+		{"/preview?font=Ubuntu&profile=blah", http.StatusBadRequest},
+		{"/preview?font=Missing", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if res := testRequest(t, s, test.path, nil); res.Code != test.exp {
+				t.Errorf("expected %d, got: %d", test.exp, res.Code)
+			}
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	limits := DefaultLimits()
+	limits.MaxTextLength = 5
+	limits.MaxWidth, limits.MaxHeight = 400, 400
+	limits.Fonts = []string{"ubuntu"}
+	var calls int
+	s := New(testSystemFonts(), WithLimits(limits), WithRateLimit(func(ip string) bool {
+		calls++
+		return ip != "10.0.0.1"
+	}))
+	tests := []struct {
+		path string
+		exp  int
+	}{
+		{"/preview?font=Ubuntu&text=abc&size=12", http.StatusOK},
+		{"/preview?font=Ubuntu&preset=card&size=12", http.StatusOK},
+		{"/preview?font=Ubuntu&text=abcdef", http.StatusBadRequest},
+		{"/preview?font=Ubuntu&size=1000", http.StatusBadRequest},
+		{"/preview?font=Ubuntu&dpi=1000", http.StatusBadRequest},
+		{"/preview?font=Ubuntu&dpi=0", http.StatusBadRequest},
+		{"/preview?font=Ubuntu&text=abc&size=200", http.StatusBadRequest},
+		{"/preview?font=Noto+Mono&text=abc", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if res := testRequest(t, s, test.path, nil); res.Code != test.exp {
+				t.Errorf("expected %d, got: %d", test.exp, res.Code)
+			}
+		})
+	}
+	if calls != len(tests) {
+		t.Errorf("expected %d rate limit calls, got: %d", len(tests), calls)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/preview?font=Ubuntu&text=abc", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	res := httptest.NewRecorder()
+	s.ServeHTTP(res, req)
+	if res.Code != http.StatusTooManyRequests {
+		t.Errorf("expected %d, got: %d", http.StatusTooManyRequests, res.Code)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	s := New(testSystemFonts())
+	for _, path := range []string{"/healthz", "/readyz"} {
+		t.Run(path, func(t *testing.T) {
+			res := testRequest(t, s, path, nil)
+			if res.Code != http.StatusOK {
+				t.Fatalf("expected %d, got: %d", http.StatusOK, res.Code)
+			}
+			if s, exp := res.Body.String(), "ok\n"; s != exp {
+				t.Errorf("expected %q, got: %q", exp, s)
+			}
+		})
+	}
+}
+
+func TestFonts(t *testing.T) {
+	sysfonts := testSystemFonts()
+	sysfonts.Add(fontpkg.FontMetadata{
+		Filename: "../testdata/Ubuntu-R.ttf",
+		Family:   "Ubuntu",
+		Style:    fontpkg.Bold | fontpkg.Italic,
+	})
+	res := testRequest(t, New(sysfonts), "/fonts", nil)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected %d, got: %d", http.StatusOK, res.Code)
+	}
+	if s, exp := res.Header().Get("Content-Type"), "application/json"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+	var families []Family
+	if err := json.NewDecoder(res.Body).Decode(&families); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := []Family{
+		{Family: "Noto Mono", Styles: []string{"Regular"}},
+		{Family: "Ubuntu", Styles: []string{"Regular", "Bold Italic"}},
+	}
+	if !reflect.DeepEqual(families, exp) {
+		t.Errorf("expected %v, got: %v", exp, families)
+	}
+}
+
+func testRequest(t *testing.T, h http.Handler, path string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func testSystemFonts() *fontpkg.SystemFonts {
+	sysfonts := &fontpkg.SystemFonts{
+		Fonts: make(map[string]map[fontpkg.Style]fontpkg.FontMetadata),
+	}
+	sysfonts.Add(fontpkg.FontMetadata{
+		Filename: "../testdata/Ubuntu-R.ttf",
+		Family:   "Ubuntu",
+		Style:    fontpkg.Regular,
+	})
+	sysfonts.Add(fontpkg.FontMetadata{
+		Filename: "../testdata/NotoMono-Regular.ttf",
+		Family:   "Noto Mono",
+		Style:    fontpkg.Regular,
+	})
+	return sysfonts
+}
+
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	s := New(testSystemFonts(), WithAuditLog(fontimg.NewAuditLog(&buf)))
+	res := testRequest(t, s, "/preview?font=Ubuntu&size=24", nil)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected %d, got: %d", http.StatusOK, res.Code)
+	}
+	sum := sha256.Sum256(res.Body.Bytes())
+	res = testRequest(t, s, "/charmap?font=Ubuntu&size=12&rows=64", nil)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected %d, got: %d", http.StatusOK, res.Code)
+	}
+	z, err := zip.NewReader(bytes.NewReader(res.Body.Bytes()), int64(res.Body.Len()))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := []string{"", hex.EncodeToString(sum[:])}
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, r)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		exp = append(exp, f.Name, hex.EncodeToString(h.Sum(nil)))
+	}
+	var v []string
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var rec fontimg.AuditRecord
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if rec.Source != "server" || rec.FontHash == "" || rec.OptionsHash == "" {
+			t.Errorf("unexpected record %+v", rec)
+		}
+		v = append(v, rec.Output, rec.OutputHash)
+	}
+	if !reflect.DeepEqual(v, exp) {
+		t.Errorf("expected %v, got: %v", exp, v)
+	}
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Sign signs the query parameters with key, returning a copy of the query
+// parameters with the exp and sig parameters set. When expires is the zero
+// time, the signature does not expire.
+//
+// Servers created with [WithSigningKey] reject preview requests whose
+// parameters were not signed with the same key.
+func Sign(key []byte, q url.Values, expires time.Time) url.Values {
+	v := make(url.Values, len(q)+2)
+	for k, s := range q {
+		v[k] = append([]string(nil), s...)
+	}
+	v.Del("sig")
+	v.Del("exp")
+	if !expires.IsZero() {
+		v.Set("exp", strconv.FormatInt(expires.Unix(), 10))
+	}
+	v.Set("sig", signature(key, v))
+	return v
+}
+
+// verify verifies the signature and expiry of the query parameters.
+func verify(key []byte, q url.Values, now time.Time) error {
+	sig, err := hex.DecodeString(q.Get("sig"))
+	if err != nil || len(sig) == 0 {
+		return errInvalidSignature
+	}
+	v := make(url.Values, len(q))
+	for k, s := range q {
+		if k != "sig" {
+			v[k] = s
+		}
+	}
+	exp, _ := hex.DecodeString(signature(key, v))
+	if !hmac.Equal(sig, exp) {
+		return errInvalidSignature
+	}
+	if s := q.Get("exp"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return errInvalidSignature
+		}
+		if now.After(time.Unix(n, 0)) {
+			return fmt.Errorf("signature expired")
+		}
+	}
+	return nil
+}
+
+// signature returns the hex encoded HMAC-SHA256 signature of the encoded
+// query parameters.
+func signature(key []byte, q url.Values) string {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(q.Encode()))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// errInvalidSignature is the invalid signature error.
+var errInvalidSignature = fmt.Errorf("invalid signature")
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	key := []byte("secret")
+	s := New(testSystemFonts(), WithSigningKey(key))
+	q := url.Values{
+		"font": []string{"Ubuntu"},
+		"text": []string{"abc"},
+		"size": []string{"12"},
+	}
+	future, past := time.Now().Add(time.Hour), time.Now().Add(-time.Hour)
+	tamper := Sign(key, q, future)
+	tamper.Set("size", "24")
+	tests := []struct {
+		name string
+		q    url.Values
+		exp  int
+	}{
+		{"valid", Sign(key, q, future), http.StatusOK},
+		{"no expiry", Sign(key, q, time.Time{}), http.StatusOK},
+		{"unsigned", q, http.StatusForbidden},
+		{"expired", Sign(key, q, past), http.StatusForbidden},
+		{"wrong key", Sign([]byte("other"), q, future), http.StatusForbidden},
+		{"tampered", tamper, http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if res := testRequest(t, s, "/preview?"+test.q.Encode(), nil); res.Code != test.exp {
+				t.Errorf("expected %d, got: %d", test.exp, res.Code)
+			}
+		})
+	}
+	if q.Get("sig") != "" {
+		t.Errorf("expected query to not be modified")
+	}
+}
@@ -0,0 +1,69 @@
+package fontimg
+
+// Glyph is a shaped glyph. Advances and offsets are in font units.
+type Glyph struct {
+	// ID is the glyph ID.
+	ID uint16
+	// Cluster is the byte offset of the glyph's cluster in the shaped text.
+	Cluster int
+	// Text is the text of the glyph's cluster.
+	Text string
+	// XAdvance is the horizontal advance of the glyph, in font units.
+	XAdvance int
+	// YAdvance is the vertical advance of the glyph, in font units.
+	YAdvance int
+	// XOffset is the horizontal offset of the glyph, in font units.
+	XOffset int
+	// YOffset is the vertical offset of the glyph, in font units.
+	YOffset int
+}
+
+// Shaping is the result of shaping text with a font.
+type Shaping struct {
+	// UnitsPerEm is the font's units per em, for converting glyph advances and
+	// offsets to em.
+	UnitsPerEm int
+	// Glyphs are the shaped glyphs, in visual order.
+	Glyphs []Glyph
+}
+
+// Advance returns the total horizontal advance of the shaped glyphs, in font
+// units.
+func (s *Shaping) Advance() int {
+	var n int
+	for _, g := range s.Glyphs {
+		n += g.XAdvance
+	}
+	return n
+}
+
+// Shape shapes the text using the font with the options' style, variant, and
+// size, and the OpenType features (see [NewTemplate]). When opts is nil, the
+// default options will be used.
+func (font *Font) Shape(text, features string, opts *Options) (_ *Shaping, err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	ff, err := font.Load(opts.Style)
+	if err != nil {
+		return nil, err
+	}
+	ff.SetFeatures(features)
+	face := ff.Face(float64(opts.Size), opts.FG, opts.Style, opts.Variant)
+	s := &Shaping{
+		UnitsPerEm: int(face.Font.SFNT.Head.UnitsPerEm),
+	}
+	for _, g := range face.Glyphs(text) {
+		s.Glyphs = append(s.Glyphs, Glyph{
+			ID:       g.ID,
+			Cluster:  int(g.Cluster),
+			Text:     g.Text,
+			XAdvance: int(g.XAdvance),
+			YAdvance: int(g.YAdvance),
+			XOffset:  int(g.XOffset),
+			YOffset:  int(g.YOffset),
+		})
+	}
+	return s, nil
+}
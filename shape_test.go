@@ -0,0 +1,52 @@
+package fontimg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestShape(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	tests := []struct {
+		text, features string
+		exp            int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"office", "", 4},
+		{"office", "-liga", 6},
+	}
+	for _, test := range tests {
+		s, err := font.Shape(test.text, test.features, nil)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if s.UnitsPerEm == 0 {
+			t.Errorf("expected units per em")
+		}
+		if len(s.Glyphs) != test.exp {
+			t.Errorf("%q %q expected %d glyphs, got: %d", test.text, test.features, test.exp, len(s.Glyphs))
+		}
+		var clusters int
+		for _, g := range s.Glyphs {
+			if g.ID == 0 || g.XAdvance <= 0 {
+				t.Errorf("%q expected glyph ID and advance, got: %+v", test.text, g)
+			}
+			if g.Cluster < clusters || len(test.text) <= g.Cluster {
+				t.Errorf("%q expected increasing cluster, got: %+v", test.text, g)
+			}
+			clusters = g.Cluster
+		}
+	}
+	kern, err := font.Shape("AV", "", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	nokern, err := font.Shape("AV", "-kern", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if kern.Advance() >= nokern.Advance() {
+		t.Errorf("expected kerned advance %d < %d", kern.Advance(), nokern.Advance())
+	}
+}
@@ -0,0 +1,131 @@
+package fontimg
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// Social card dimensions, in pixels, as used by OpenGraph and Twitter
+// (summary_large_image) share images.
+const (
+	SocialCardWidth  = 1200
+	SocialCardHeight = 630
+)
+
+// SocialCardOptions are the social card options.
+type SocialCardOptions struct {
+	// Title is the card's title. When empty, the font's name is used.
+	Title string
+	// Subtitle is the card's subtitle. When empty, the font's style and
+	// version are used.
+	Subtitle string
+	// Sample is the sample text, sized to fill the card. When empty, the
+	// options' text, or "Aa Bb Cc" is used.
+	Sample string
+	// Band is the color of the brand band along the card's bottom edge. When
+	// nil, the options' foreground color is used.
+	Band color.Color
+}
+
+// RasterizeSocialCard rasterizes a social card image using the options. See
+// [SocialCard].
+func RasterizeSocialCard(font *Font, card *SocialCardOptions, opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := SocialCard(font, card, opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// SocialCard lays out a social card (ie, an OpenGraph or Twitter card share
+// image) on a canvas, sized to [SocialCardWidth] by [SocialCardHeight] pixels
+// at the options' DPI, showing the card's title and subtitle above a large
+// sample set in the font, with a brand color band along the bottom edge. All
+// text is kept within safe margins of 60 pixels, so that it is not cropped
+// when displayed. The options' size and margin are not used. When card or
+// opts is nil, the defaults will be used.
+func SocialCard(font *Font, card *SocialCardOptions, opts *Options) (_ *canvas.Canvas, err error) {
+	defer recoverError(&err)
+	if card == nil {
+		card = new(SocialCardOptions)
+	}
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	// load font family
+	ff, err := font.Load(opts.Style)
+	if err != nil {
+		return nil, err
+	}
+	title, subtitle, sample, band := card.Title, card.Subtitle, card.Sample, card.Band
+	if title == "" {
+		title = font.BestName()
+	}
+	if subtitle == "" {
+		subtitle = font.Style
+		if font.Version != "" {
+			subtitle += "  ·  v" + font.Version
+		}
+	}
+	switch {
+	case sample == "" && opts.Text != "":
+		sample = opts.Text
+	case sample == "":
+		sample = "Aa Bb Cc"
+	}
+	if band == nil {
+		band = opts.FG
+	}
+	// pixel size in millimeters, and face sizes in points
+	px := 25.4 / opts.DPI
+	face := func(size float64) *canvas.FontFace {
+		return ff.Face(size*72/opts.DPI, opts.FG, opts.Style, opts.Variant)
+	}
+	// create canvas and context
+	w, h := SocialCardWidth*px, SocialCardHeight*px
+	c := canvas.New(w, h)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	// draw band
+	ctx.SetFillColor(band)
+	ctx.DrawPath(0, 0, canvas.Rectangle(w, 16*px))
+	ctx.SetFillColor(opts.FG)
+	// draw title and subtitle, from the top safe margin
+	margin, y := 60*px, h-60*px
+	for _, v := range []struct {
+		text string
+		size float64
+	}{
+		{title, 64},
+		{subtitle, 32},
+	} {
+		if v.text == "" {
+			continue
+		}
+		txt := canvas.NewTextBox(face(v.size), v.text, w-2*margin, 0, canvas.Left, canvas.Top, nil)
+		ctx.DrawText(margin, y, txt)
+		b := txt.Bounds()
+		y -= b.H() + 16*px
+	}
+	// draw sample, scaled to fill the remaining space above the band
+	bottom := 16*px + margin
+	if avail := y - 16*px - bottom; 0 < avail {
+		b := canvas.NewTextLine(face(100), sample, canvas.Left).Bounds()
+		if scale := min((w-2*margin)/b.W(), avail/b.H()); 0 < scale {
+			txt := canvas.NewTextLine(face(100*scale), sample, canvas.Left)
+			b = txt.Bounds()
+			ctx.DrawText(margin-b.X0, bottom-b.Y0, txt)
+		}
+	}
+	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
@@ -0,0 +1,47 @@
+package fontimg
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestRasterizeSocialCard(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	band := color.RGBA{0xff, 0, 0, 0xff}
+	tests := []struct {
+		name string
+		card *SocialCardOptions
+		dpi  float64
+	}{
+		{"default", nil, 100},
+		{"band", &SocialCardOptions{Title: "A very long title wrapping onto a second line of the card", Band: band}, 100},
+		{"high dpi", &SocialCardOptions{Sample: "Hamburgefonstiv"}, 300},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.DPI = test.dpi
+			img, err := RasterizeSocialCard(font, test.card, opts)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != SocialCardWidth || b.Dy() != SocialCardHeight {
+				t.Fatalf("expected %dx%d, got: %dx%d", SocialCardWidth, SocialCardHeight, b.Dx(), b.Dy())
+			}
+			// safe margins are background only, except for the band
+			r := contentBounds(img.SubImage(image.Rect(0, 0, SocialCardWidth, SocialCardHeight-16)), opts.BG)
+			if r.Min.X < 60 || SocialCardWidth-60 < r.Max.X || r.Min.Y < 60 || SocialCardHeight-16-60 < r.Max.Y {
+				t.Errorf("expected content within safe margins, got: %v", r)
+			}
+			exp := color.RGBAModel.Convert(opts.FG)
+			if test.card != nil && test.card.Band != nil {
+				exp = band
+			}
+			if c := img.At(SocialCardWidth/2, SocialCardHeight-1); c != exp {
+				t.Errorf("expected band color %v, got: %v", exp, c)
+			}
+		})
+	}
+}
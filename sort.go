@@ -0,0 +1,112 @@
+package fontimg
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tdewolff/canvas"
+)
+
+// SortFonts sorts the fonts by family using natural ordering (ie, "Font 2"
+// before "Font 10"), case insensitively, then by numeric weight (ie, 100 for
+// thin to 900 for black), upright before italic, and then by file name. The
+// style is the font's style, or parsed from the trailing words of the family
+// (ie, "Roboto Bold Italic") when not set. The sort is stable.
+func SortFonts(fonts []*Font) {
+	type key struct {
+		family string
+		weight int
+		italic bool
+		name   string
+	}
+	keys := make(map[*Font]key, len(fonts))
+	for _, font := range fonts {
+		family, style := splitStyle(font)
+		keys[font] = key{
+			family: family,
+			weight: style.CSS(),
+			italic: style.Italic(),
+			name:   filepath.Base(font.Path),
+		}
+	}
+	slices.SortStableFunc(fonts, func(a, b *Font) int {
+		x, y := keys[a], keys[b]
+		if n := naturalCompare(x.family, y.family); n != 0 {
+			return n
+		}
+		if n := cmp.Compare(x.weight, y.weight); n != 0 {
+			return n
+		}
+		if x.italic != y.italic {
+			if x.italic {
+				return 1
+			}
+			return -1
+		}
+		return naturalCompare(x.name, y.name)
+	})
+}
+
+// splitStyle returns the font's family and style. When the font's style is
+// not set, the style is parsed from the longest trailing words of the family
+// that are a valid style, and removed from the family.
+func splitStyle(font *Font) (string, canvas.FontStyle) {
+	if font.Style != "" {
+		style, err := ParseStyle(font.Style)
+		if err != nil {
+			style = canvas.FontRegular
+		}
+		return font.Family, style
+	}
+	words := strings.Fields(font.Family)
+	for i := 1; i < len(words); i++ {
+		if style, err := ParseStyle(strings.Join(words[i:], " ")); err == nil {
+			return strings.Join(words[:i], " "), style
+		}
+	}
+	return font.Family, canvas.FontRegular
+}
+
+// naturalCompare compares a and b case insensitively, comparing runs of
+// digits numerically.
+func naturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if isDigit(ra) && isDigit(rb) {
+			da, db := digits(a), digits(b)
+			a, b = a[len(da):], b[len(db):]
+			ta, tb := strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+			if n := cmp.Compare(len(ta), len(tb)); n != 0 {
+				return n
+			}
+			if n := strings.Compare(ta, tb); n != 0 {
+				return n
+			}
+			continue
+		}
+		if n := cmp.Compare(unicode.ToLower(ra), unicode.ToLower(rb)); n != 0 {
+			return n
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// digits returns the leading ASCII digits of s.
+func digits(s string) string {
+	i := 0
+	for i < len(s) && isDigit(rune(s[i])) {
+		i++
+	}
+	return s[:i]
+}
+
+// isDigit returns true when r is an ASCII digit.
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
@@ -0,0 +1,62 @@
+package fontimg
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSortFonts(t *testing.T) {
+	fonts := []*Font{
+		{Path: "Roboto-BoldItalic.ttf", Family: "Roboto Bold Italic"},
+		{Path: "font_10.ttf", Family: "font"},
+		{Path: "Roboto-Regular.ttf", Family: "Roboto Regular"},
+		{Path: "Font 2.ttf", Family: "Font"},
+		{Path: "Roboto-Thin.ttf", Family: "Roboto Thin"},
+		{Path: "roboto-italic.ttf", Family: "roboto Italic"},
+		{Path: "a.ttf", Family: "Noto Sans 12"},
+		{Path: "b.ttf", Family: "Noto Sans 9"},
+		{Path: "c.ttf", Family: "Roboto", Style: "Bold"},
+		{Path: "Roboto-Black.ttf", Family: "Roboto Black"},
+	}
+	SortFonts(fonts)
+	var v []string
+	for _, font := range fonts {
+		v = append(v, font.Path)
+	}
+	exp := []string{
+		"Font 2.ttf",
+		"font_10.ttf",
+		"b.ttf",
+		"a.ttf",
+		"Roboto-Thin.ttf",
+		"Roboto-Regular.ttf",
+		"roboto-italic.ttf",
+		"c.ttf",
+		"Roboto-BoldItalic.ttf",
+		"Roboto-Black.ttf",
+	}
+	if !slices.Equal(v, exp) {
+		t.Errorf("expected %q, got: %q", exp, v)
+	}
+}
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		exp  int
+	}{
+		{"Font 2", "Font 10", -1},
+		{"Font 10", "Font 2", 1},
+		{"font 2", "Font 2", 0},
+		{"Font 02", "Font 2", 0},
+		{"Font", "Font 2", -1},
+		{"a1b2", "a1b10", -1},
+		{"Zeta", "alpha", 1},
+		{"", "", 0},
+	}
+	for _, test := range tests {
+		if n := naturalCompare(test.a, test.b); n != test.exp {
+			t.Errorf("%q %q: expected %d, got: %d", test.a, test.b, test.exp, n)
+		}
+	}
+}
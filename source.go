@@ -0,0 +1,463 @@
+package fontimg
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tdewolff/canvas"
+	fontpkg "github.com/tdewolff/font"
+)
+
+// Ref is a reference to a font in a [Source], identifying the font
+// independently of the [Font]'s fields. Refs are comparable, and can be used
+// as map keys.
+type Ref struct {
+	// Source is the kind of source (ie, "dir", "system", "zip", "http").
+	Source string `json:"source" yaml:"source"`
+	// Path is the font's path in the source (ie, a file path, a path in an
+	// archive, or a URL).
+	Path string `json:"path" yaml:"path"`
+	// Index is the font's index in a font collection.
+	Index int `json:"index,omitempty" yaml:"index,omitempty"`
+	// Hash is the hex encoded SHA-256 hash of the font's content, when known
+	// (see [Font.Hash]).
+	Hash string `json:"hash,omitempty" yaml:"hash,omitempty"`
+}
+
+// String satisfies the [fmt.Stringer] interface.
+func (ref Ref) String() string {
+	s := ref.Source + ":" + ref.Path
+	if ref.Index != 0 {
+		s += "#" + strconv.Itoa(ref.Index)
+	}
+	if ref.Hash != "" {
+		s += "@" + ref.Hash
+	}
+	return s
+}
+
+// Ref returns the font's reference. Fonts not opened from a [Source] are
+// referenced by their path as a "file" source. The reference's hash is
+// calculated from the font's content when not already known.
+func (font *Font) Ref() (Ref, error) {
+	ref := font.ref
+	if ref.Source == "" && font.Path != "" {
+		ref.Source, ref.Path = "file", font.Path
+	}
+	if ref.Hash == "" {
+		var err error
+		if ref.Hash, err = font.Hash(); err != nil {
+			return Ref{}, err
+		}
+	}
+	return ref, nil
+}
+
+// Source is a source of fonts (ie, a directory, the system fonts, an
+// archive, or a URL). See [NewSource] and [OpenSource].
+type Source interface {
+	// List lists the fonts in the source.
+	List(ctx context.Context) ([]Ref, error)
+	// Open opens the referenced font.
+	Open(ctx context.Context, ref Ref) (*Font, error)
+}
+
+// NewSource returns the source for the font name, as used by [Open]:
+// standard input when name is "-", a [HTTPSource] for http and https URLs, a
+// [DirSource] for directories, a [ZipSource] for zip archives, a
+// [FileSource] for other files, and otherwise a [SystemSource] matching the
+// name and style.
+func NewSource(name string, style canvas.FontStyle, sysfonts *fontpkg.SystemFonts) Source {
+	if name == "-" {
+		return stdinSource{}
+	}
+	if strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://") {
+		return HTTPSource{URL: name}
+	}
+	switch fi, err := os.Stat(name); {
+	case err == nil && fi.IsDir():
+		return DirSource{Dir: name}
+	case err == nil && strings.EqualFold(filepath.Ext(name), ".zip"):
+		return ZipSource{Path: name}
+	case err == nil:
+		return FileSource{Path: name}
+	}
+	return SystemSource{Fonts: sysfonts, Name: name, Style: style}
+}
+
+// OpenSource opens the fonts of the source. Fonts that fail to open do not
+// prevent the remaining fonts from being opened: the successfully opened
+// fonts are returned along with an [Errors] for the failed fonts. The fonts
+// are sorted using [SortFonts].
+func OpenSource(ctx context.Context, src Source) ([]*Font, error) {
+	refs, err := src.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var v []*Font
+	var errs Errors
+	for _, ref := range refs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		font, err := src.Open(ctx, ref)
+		if err != nil {
+			errs = append(errs, fontError(ref, err))
+			continue
+		}
+		v = append(v, font)
+	}
+	SortFonts(v)
+	return v, errs.Err()
+}
+
+// fontError wraps the error for the referenced font as a [*FontError].
+func fontError(ref Ref, err error) *FontError {
+	if ferr, ok := err.(*FontError); ok {
+		return ferr
+	}
+	return &FontError{Path: ref.Path, Err: err}
+}
+
+// FileSource is a font file.
+type FileSource struct {
+	Path string
+}
+
+// List satisfies the [Source] interface.
+func (src FileSource) List(context.Context) ([]Ref, error) {
+	return []Ref{{Source: "file", Path: src.Path}}, nil
+}
+
+// Open satisfies the [Source] interface.
+func (src FileSource) Open(_ context.Context, ref Ref) (*Font, error) {
+	font := New(nil, ref.Path)
+	font.ref = ref
+	return font, nil
+}
+
+// DirSource is a directory of font files. Files with unrecognized extensions
+// are listed only when in a registered decoder's format (see
+// [RegisterDecoder]). Subdirectories are not listed.
+type DirSource struct {
+	Dir string
+}
+
+// List satisfies the [Source] interface.
+func (src DirSource) List(ctx context.Context) ([]Ref, error) {
+	names, err := listFS(ctx, os.DirFS(src.Dir), ".")
+	switch {
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case err != nil:
+		return nil, fmt.Errorf("unable to open directory %q: %v", src.Dir, err)
+	}
+	refs := make([]Ref, len(names))
+	for i, name := range names {
+		refs[i] = Ref{Source: "dir", Path: filepath.Join(src.Dir, filepath.FromSlash(name))}
+	}
+	return refs, nil
+}
+
+// Open satisfies the [Source] interface. Files without a recognized font
+// header are returned as a [*FontError].
+func (src DirSource) Open(_ context.Context, ref Ref) (*Font, error) {
+	if err := sniff(ref.Path); err != nil {
+		return nil, &FontError{Path: ref.Path, Err: err}
+	}
+	font := New(nil, ref.Path)
+	font.ref = ref
+	return font, nil
+}
+
+// FSSource is a font file, or a directory of font files, in a [fs.FS] (ie,
+// an embed.FS, or a [zip.Reader]). Directories are listed as with
+// [DirSource]. The fonts are read into memory when opened, subject to the
+// [ParseBudget].
+type FSSource struct {
+	FS fs.FS
+	// Path is the slash-separated path of the file or directory in the file
+	// system (ie, "fonts", or "." for the root).
+	Path string
+}
+
+// List satisfies the [Source] interface.
+func (src FSSource) List(ctx context.Context) ([]Ref, error) {
+	fi, err := fs.Stat(src.FS, src.Path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []Ref{{Source: "fs", Path: src.Path}}, nil
+	}
+	names, err := listFS(ctx, src.FS, src.Path)
+	switch {
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case err != nil:
+		return nil, fmt.Errorf("unable to open directory %q: %v", src.Path, err)
+	}
+	refs := make([]Ref, len(names))
+	for i, name := range names {
+		refs[i] = Ref{Source: "fs", Path: name}
+	}
+	return refs, nil
+}
+
+// Open satisfies the [Source] interface. The font's path is its path in the
+// file system.
+func (src FSSource) Open(ctx context.Context, ref Ref) (*Font, error) {
+	f, err := src.FS.Open(ref.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	font, err := newReader(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	font.Path, font.ref = ref.Path, ref
+	return font, nil
+}
+
+// listFS lists the font files in the directory of the file system, returning
+// their slash-separated paths. Files with unrecognized extensions are listed
+// only when in a registered decoder's format (see [RegisterDecoder]).
+// Subdirectories are not listed.
+func listFS(ctx context.Context, fsys fs.FS, dir string) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if entry.IsDir() {
+			continue
+		}
+		name := path.Join(dir, entry.Name())
+		if !extRE.MatchString(entry.Name()) {
+			// other formats, when recognized by a registered decoder
+			if buf, err := readHeaderFS(fsys, name); err != nil || decoder(buf) == nil {
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// SystemSource is the system fonts. When Name is set, only the font matching
+// the name and style is listed (see [Match]), otherwise all system fonts are
+// listed. When Watcher is set, the watcher's current system fonts are used,
+// otherwise when Fonts is nil, the default system fonts will be loaded (see
+// [SystemFonts]).
+type SystemSource struct {
+	Fonts   *fontpkg.SystemFonts
+	Watcher *SystemWatcher
+	Name    string
+	Style   canvas.FontStyle
+}
+
+// List satisfies the [Source] interface.
+func (src SystemSource) List(context.Context) ([]Ref, error) {
+	sysfonts, err := src.fonts()
+	if err != nil {
+		return nil, err
+	}
+	if src.Name != "" {
+		font := Match(src.Name, src.Style, sysfonts)
+		if font == nil {
+			return nil, fmt.Errorf("unable to locate font %q", src.Name)
+		}
+		return []Ref{{Source: "system", Path: font.Path}}, nil
+	}
+	var refs []Ref
+	for _, styles := range sysfonts.Fonts {
+		for _, md := range styles {
+			refs = append(refs, Ref{Source: "system", Path: md.Filename})
+		}
+	}
+	slices.SortFunc(refs, func(a, b Ref) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return refs, nil
+}
+
+// Open satisfies the [Source] interface.
+func (src SystemSource) Open(_ context.Context, ref Ref) (*Font, error) {
+	sysfonts, err := src.fonts()
+	if err != nil {
+		return nil, err
+	}
+	md, ok := lookupSystemFont(sysfonts, ref.Path)
+	if !ok {
+		return nil, fmt.Errorf("unable to locate font %q", ref.Path)
+	}
+	font := NewFont(md)
+	font.ref = ref
+	return font, nil
+}
+
+// systemIndex is the filename index of the most recently opened system
+// fonts.
+var systemIndex struct {
+	sync.Mutex
+	sysfonts *fontpkg.SystemFonts
+	m        map[string]fontpkg.FontMetadata
+}
+
+// lookupSystemFont returns the metadata of the system font with the
+// filename. The system fonts' filenames are indexed once, and indexed again
+// when the system fonts change.
+func lookupSystemFont(sysfonts *fontpkg.SystemFonts, filename string) (fontpkg.FontMetadata, bool) {
+	systemIndex.Lock()
+	defer systemIndex.Unlock()
+	if systemIndex.sysfonts == sysfonts {
+		if md, ok := systemIndex.m[filename]; ok && sysfonts.Fonts[md.Family][md.Style] == md {
+			return md, true
+		}
+	}
+	m := make(map[string]fontpkg.FontMetadata)
+	for _, styles := range sysfonts.Fonts {
+		for _, md := range styles {
+			m[md.Filename] = md
+		}
+	}
+	systemIndex.sysfonts, systemIndex.m = sysfonts, m
+	md, ok := m[filename]
+	return md, ok
+}
+
+// fonts returns the source's system fonts.
+func (src SystemSource) fonts() (*fontpkg.SystemFonts, error) {
+	if src.Watcher != nil {
+		return src.Watcher.Fonts(), nil
+	}
+	if src.Fonts != nil {
+		return src.Fonts, nil
+	}
+	return SystemFonts()
+}
+
+// ZipSource is a zip archive of font files. The fonts are read into memory
+// when opened, subject to the [ParseBudget].
+type ZipSource struct {
+	Path string
+}
+
+// List satisfies the [Source] interface.
+func (src ZipSource) List(context.Context) ([]Ref, error) {
+	z, err := zip.OpenReader(src.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open archive %q: %v", src.Path, err)
+	}
+	defer z.Close()
+	var refs []Ref
+	for _, f := range z.File {
+		if !f.FileInfo().IsDir() && extRE.MatchString(f.Name) {
+			refs = append(refs, Ref{Source: "zip", Path: f.Name})
+		}
+	}
+	return refs, nil
+}
+
+// Open satisfies the [Source] interface. The font's path is the archive's
+// path joined with the font's name in the archive.
+func (src ZipSource) Open(ctx context.Context, ref Ref) (*Font, error) {
+	z, err := zip.OpenReader(src.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open archive %q: %v", src.Path, err)
+	}
+	defer z.Close()
+	i := slices.IndexFunc(z.File, func(f *zip.File) bool {
+		return f.Name == ref.Path
+	})
+	if i == -1 {
+		return nil, fmt.Errorf("%q not in archive %q", ref.Path, src.Path)
+	}
+	f, err := z.File[i].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	font, err := newReader(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	font.Path, font.ref = filepath.Join(src.Path, filepath.FromSlash(ref.Path)), ref
+	return font, nil
+}
+
+// HTTPSource is a font file at a http or https URL. The font is read into
+// memory when opened, subject to the [ParseBudget]. When Client is nil, a
+// client with a 1 minute timeout is used.
+type HTTPSource struct {
+	URL    string
+	Client *http.Client
+}
+
+// List satisfies the [Source] interface.
+func (src HTTPSource) List(context.Context) ([]Ref, error) {
+	return []Ref{{Source: "http", Path: src.URL}}, nil
+}
+
+// Open satisfies the [Source] interface. The font's path is the URL.
+func (src HTTPSource) Open(ctx context.Context, ref Ref) (*Font, error) {
+	cl := src.Client
+	if cl == nil {
+		cl = httpClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ref.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cl.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to retrieve %q: %s", ref.Path, res.Status)
+	}
+	font, err := newReader(ctx, res.Body)
+	if err != nil {
+		return nil, err
+	}
+	font.Path, font.ref = ref.Path, ref
+	return font, nil
+}
+
+// httpClient is the client used by [HTTPSource] when its client is not set.
+var httpClient = &http.Client{Timeout: time.Minute}
+
+// stdinSource is a font read from standard input.
+type stdinSource struct{}
+
+// List satisfies the [Source] interface.
+func (stdinSource) List(context.Context) ([]Ref, error) {
+	return []Ref{{Source: "stdin", Path: "-"}}, nil
+}
+
+// Open satisfies the [Source] interface.
+func (stdinSource) Open(ctx context.Context, ref Ref) (*Font, error) {
+	font, err := newReader(ctx, stdin)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read font from stdin: %v", err)
+	}
+	font.ref = ref
+	return font, nil
+}
@@ -0,0 +1,356 @@
+package fontimg
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/tdewolff/canvas"
+	fontpkg "github.com/tdewolff/font"
+)
+
+func TestNewSource(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.ttf", "a.zip"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	tests := []struct {
+		name string
+		exp  Source
+	}{
+		{"-", stdinSource{}},
+		{"https://example.com/a.ttf", HTTPSource{URL: "https://example.com/a.ttf"}},
+		{dir, DirSource{Dir: dir}},
+		{filepath.Join(dir, "a.zip"), ZipSource{Path: filepath.Join(dir, "a.zip")}},
+		{filepath.Join(dir, "a.ttf"), FileSource{Path: filepath.Join(dir, "a.ttf")}},
+		{"sans-serif", SystemSource{Name: "sans-serif", Style: canvas.FontBold}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if src := NewSource(test.name, canvas.FontBold, nil); !reflect.DeepEqual(src, test.exp) {
+				t.Errorf("expected %#v, got: %#v", test.exp, src)
+			}
+		})
+	}
+}
+
+func TestSources(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// zip archive
+	archive := filepath.Join(t.TempDir(), "fonts.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	w := zip.NewWriter(f)
+	for name, b := range map[string][]byte{
+		"fonts/Ubuntu-R.ttf": buf,
+		"fonts/bad.ttf":      []byte("not a font"),
+		"README":             []byte("fonts"),
+	} {
+		zw, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if _, err := zw.Write(b); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// http
+	s := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/Ubuntu-R.ttf" {
+			http.NotFound(res, req)
+			return
+		}
+		res.Write(buf)
+	}))
+	defer s.Close()
+	// system fonts
+	sysfonts := &fontpkg.SystemFonts{
+		Fonts: map[string]map[fontpkg.Style]fontpkg.FontMetadata{
+			"Ubuntu": {
+				fontpkg.Regular: {Filename: filepath.Join("testdata", "Ubuntu-R.ttf"), Family: "Ubuntu", Style: fontpkg.Regular},
+			},
+		},
+	}
+	tests := []struct {
+		name string
+		src  Source
+		exp  int
+		errs int
+	}{
+		{"zip", ZipSource{Path: archive}, 1, 1},
+		{"http", HTTPSource{URL: s.URL + "/Ubuntu-R.ttf"}, 1, 0},
+		{"http not found", HTTPSource{URL: s.URL + "/missing.ttf"}, 0, 1},
+		{"system", SystemSource{Fonts: sysfonts}, 1, 0},
+		{"system match", SystemSource{Fonts: sysfonts, Name: "Ubuntu"}, 1, 0},
+		{"custom", testSource{"a": buf, "b": []byte("not a font")}, 1, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fonts, err := OpenSource(context.Background(), test.src)
+			var errs Errors
+			switch {
+			case test.errs == 0 && err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case test.errs != 0 && !errors.As(err, &errs):
+				t.Fatalf("expected Errors, got: %v", err)
+			case len(errs) != test.errs:
+				t.Errorf("expected %d errors, got: %v", test.errs, errs)
+			}
+			if len(fonts) != test.exp {
+				t.Fatalf("expected %d fonts, got: %d", test.exp, len(fonts))
+			}
+			for _, font := range fonts {
+				if _, err := font.RasterizeOptions(nil); err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+			}
+		})
+	}
+	if _, err := OpenSource(context.Background(), SystemSource{Fonts: sysfonts, Name: "missing"}); err == nil {
+		t.Errorf("expected error for missing system font")
+	}
+}
+
+func TestHTTPSourceTimeout(t *testing.T) {
+	defer func(cl *http.Client) { httpClient = cl }(httpClient)
+	httpClient = &http.Client{Timeout: 100 * time.Millisecond}
+	s := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		<-req.Context().Done()
+	}))
+	defer s.Close()
+	start := time.Now()
+	if _, err := (HTTPSource{URL: s.URL + "/Ubuntu-R.ttf"}).Open(context.Background(), Ref{Source: "http", Path: s.URL + "/Ubuntu-R.ttf"}); err == nil {
+		t.Errorf("expected timeout error")
+	}
+	if d := time.Since(start); 10*time.Second < d {
+		t.Errorf("expected request to time out, took: %v", d)
+	}
+}
+
+func TestSystemSourceOpen(t *testing.T) {
+	regular := fontpkg.FontMetadata{Filename: filepath.Join("testdata", "Ubuntu-R.ttf"), Family: "Ubuntu", Style: fontpkg.Regular}
+	mono := fontpkg.FontMetadata{Filename: filepath.Join("testdata", "NotoMono-Regular.ttf"), Family: "Noto Mono", Style: fontpkg.Regular}
+	sysfonts := &fontpkg.SystemFonts{
+		Fonts: make(map[string]map[fontpkg.Style]fontpkg.FontMetadata),
+	}
+	sysfonts.Add(regular)
+	src := SystemSource{Fonts: sysfonts}
+	open := func(src SystemSource, md fontpkg.FontMetadata) error {
+		t.Helper()
+		font, err := src.Open(context.Background(), Ref{Source: "system", Path: md.Filename})
+		switch {
+		case err != nil:
+			return err
+		case font.Path != md.Filename || font.Family != md.Family:
+			t.Errorf("expected %v, got: %q %q", md, font.Path, font.Family)
+		}
+		return nil
+	}
+	if err := open(src, regular); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if m := systemIndex.m; len(m) != 1 {
+		t.Errorf("expected 1 indexed font, got: %d", len(m))
+	}
+	// added fonts are indexed
+	sysfonts.Add(mono)
+	if err := open(src, mono); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if m := systemIndex.m; len(m) != 2 {
+		t.Errorf("expected 2 indexed fonts, got: %d", len(m))
+	}
+	// removed fonts are not found
+	delete(sysfonts.Fonts, mono.Family)
+	if err := open(src, mono); err == nil {
+		t.Errorf("expected error for removed font")
+	}
+	// other system fonts
+	other := &fontpkg.SystemFonts{
+		Fonts: make(map[string]map[fontpkg.Style]fontpkg.FontMetadata),
+	}
+	other.Add(mono)
+	if err := open(SystemSource{Fonts: other}, regular); err == nil {
+		t.Errorf("expected error for font not in system fonts")
+	}
+	if err := open(SystemSource{Fonts: other}, mono); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func TestScanSource(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	src := testSource{"a": buf, "b": buf, "c": buf}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var n int
+	for _, err := range ScanSource(ctx, src) {
+		if n++; n == 2 {
+			cancel()
+			continue
+		}
+		if n == 3 && !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got: %v", err)
+		}
+	}
+	if n != 3 {
+		t.Errorf("expected 3, got: %d", n)
+	}
+}
+
+func TestRef(t *testing.T) {
+	path := filepath.Join("testdata", "Ubuntu-R.ttf")
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	hash, err := New(buf, "").Hash()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fonts, err := OpenSource(context.Background(), DirSource{Dir: "testdata"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	refs := make(map[Ref]*Font)
+	for _, font := range fonts {
+		ref, err := font.Ref()
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if ref.Source != "dir" || ref.Path != font.Path || ref.Hash == "" {
+			t.Errorf("expected dir ref for %s, got: %v", font.Path, ref)
+		}
+		refs[ref] = font
+	}
+	exp := Ref{Source: "dir", Path: path, Hash: hash}
+	font, ok := refs[exp]
+	if !ok {
+		t.Fatalf("expected %v in refs", exp)
+	}
+	// identity is independent of the font's fields
+	clone := font.Clone()
+	clone.Family, clone.Style = "Other", "Bold"
+	if ref, err := clone.Ref(); err != nil || ref != exp {
+		t.Errorf("expected %v, got: %v (%v)", exp, ref, err)
+	}
+	if s, exp := exp.String(), "dir:"+path+"@"+hash; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+	// fonts not from a source
+	ref, err := New(nil, path).Ref()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exp := (Ref{Source: "file", Path: path, Hash: hash}); ref != exp {
+		t.Errorf("expected %v, got: %v", exp, ref)
+	}
+	if _, err := (&Font{}).Ref(); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+// testSource is a source of in-memory fonts.
+type testSource map[string][]byte
+
+// List satisfies the [Source] interface.
+func (src testSource) List(context.Context) ([]Ref, error) {
+	var refs []Ref
+	for name := range src {
+		refs = append(refs, Ref{Source: "test", Path: name})
+	}
+	return refs, nil
+}
+
+// Open satisfies the [Source] interface.
+func (src testSource) Open(_ context.Context, ref Ref) (*Font, error) {
+	if _, err := Sniff(src[ref.Path]); err != nil {
+		return nil, err
+	}
+	return New(src[ref.Path], ref.Path), nil
+}
+
+func TestOpenFS(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fsys := fstest.MapFS{
+		"fonts/Ubuntu-R.ttf":      {Data: buf},
+		"fonts/bad.ttf":           {Data: []byte("not a font")},
+		"fonts/README":            {Data: []byte("fonts")},
+		"fonts/more/Ubuntu-R.ttf": {Data: buf},
+	}
+	// directory
+	fonts, err := OpenFS(fsys, "fonts")
+	var errs Errors
+	switch {
+	case !errors.As(err, &errs) || len(errs) != 1:
+		t.Errorf("expected 1 error, got: %v", err)
+	case len(fonts) != 1:
+		t.Fatalf("expected 1 font, got: %d", len(fonts))
+	case fonts[0].Path != "fonts/Ubuntu-R.ttf" || fonts[0].Family != "Ubuntu":
+		t.Errorf("expected fonts/Ubuntu-R.ttf (Ubuntu), got: %s (%s)", fonts[0].Path, fonts[0].Family)
+	}
+	if ref, err := fonts[0].Ref(); err != nil || ref.Source != "fs" || ref.Path != "fonts/Ubuntu-R.ttf" {
+		t.Errorf("expected fs ref, got: %v (%v)", ref, err)
+	}
+	if _, err := fonts[0].RasterizeContext(context.Background()); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	// file
+	if fonts, err := OpenFS(fsys, "fonts/more/Ubuntu-R.ttf"); err != nil || len(fonts) != 1 {
+		t.Errorf("expected 1 font, got: %d (%v)", len(fonts), err)
+	}
+	// zip archive
+	var zbuf bytes.Buffer
+	w := zip.NewWriter(&zbuf)
+	zw, err := w.Create("Ubuntu-R.ttf")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := zw.Write(buf); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	z, err := zip.NewReader(bytes.NewReader(zbuf.Bytes()), int64(zbuf.Len()))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if fonts, err := OpenFS(z, "."); err != nil || len(fonts) != 1 {
+		t.Errorf("expected 1 font, got: %d (%v)", len(fonts), err)
+	}
+	// errors
+	for _, name := range []string{"missing", "/fonts", "fonts/../fonts", "fonts/more/"} {
+		if _, err := OpenFS(fsys, name); err == nil {
+			t.Errorf("%q expected error, got nil", name)
+		}
+	}
+}
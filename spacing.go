@@ -0,0 +1,215 @@
+package fontimg
+
+import (
+	"image"
+	"image/color"
+	"unicode"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// Spacing is a report of a font's advance widths, for evaluating body-text
+// economy. Widths are in em.
+type Spacing struct {
+	// Histogram is the number of graphic runes mapped by the font with advance
+	// widths in each bucket, where bucket i holds widths in [i*BucketWidth,
+	// (i+1)*BucketWidth). Wider runes are counted in the last bucket.
+	Histogram []int
+	// BucketWidth is the width of each histogram bucket.
+	BucketWidth float64
+	// AverageWidth is the average advance width of the graphic runes mapped by
+	// the font.
+	AverageWidth float64
+	// LowercaseWidth is the average advance width of the basic Latin lowercase
+	// letters (a-z) mapped by the font.
+	LowercaseWidth float64
+	// SpaceWidth is the advance width of the space, or 0 when not mapped.
+	SpaceWidth float64
+}
+
+// Spacing histogram buckets.
+const (
+	spacingBuckets     = 20
+	spacingBucketWidth = 0.1
+)
+
+// Spacing reports the font's advance widths. Runes with a zero advance width
+// (ie, combining marks) and spaces are not included in the histogram or
+// averages.
+func (font *Font) Spacing() (_ *Spacing, err error) {
+	defer recoverError(&err)
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	runes, err := font.Runes()
+	if err != nil {
+		return nil, err
+	}
+	upem := float64(sfnt.Head.UnitsPerEm)
+	width := func(r rune) float64 {
+		return float64(sfnt.GlyphAdvance(sfnt.GlyphIndex(r))) / upem
+	}
+	sp := &Spacing{
+		Histogram:   make([]int, spacingBuckets),
+		BucketWidth: spacingBucketWidth,
+	}
+	var total, lower float64
+	var n, nlower int
+	for _, r := range runes {
+		w := width(r)
+		switch {
+		case r == ' ':
+			sp.SpaceWidth = w
+			continue
+		case w == 0 || !unicode.IsGraphic(r) || unicode.IsSpace(r):
+			continue
+		case 'a' <= r && r <= 'z':
+			lower, nlower = lower+w, nlower+1
+		}
+		sp.Histogram[min(int(w/spacingBucketWidth), spacingBuckets-1)]++
+		total, n = total+w, n+1
+	}
+	if n != 0 {
+		sp.AverageWidth = total / float64(n)
+	}
+	if nlower != 0 {
+		sp.LowercaseWidth = lower / float64(nlower)
+	}
+	return sp, nil
+}
+
+// RasterizeSpacing rasterizes the font's spacing chart using the options. See
+// [Font.SpacingChart].
+func (font *Font) RasterizeSpacing(opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := font.SpacingChart(opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// SpacingChart lays out a chart of the font's spacing on a canvas, showing the
+// advance width histogram with the average and space widths marked, labeled
+// using the embedded label font. Only the options' size, colors, and margin
+// are used. When opts is nil, the default options will be used.
+func (font *Font) SpacingChart(opts *Options) (_ *canvas.Canvas, err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	sp, err := font.Spacing()
+	if err != nil {
+		return nil, err
+	}
+	ff, err := LabelFont().Load(canvas.FontRegular)
+	if err != nil {
+		return nil, err
+	}
+	face, p := ff.Face(0.5*float64(opts.Size), opts.FG), labelPrinter(opts.Language)
+	metrics := face.Metrics()
+	// create canvas and context
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	ctx.SetFillColor(opts.FG)
+	// chart is 10 em wide and 4 em high, with 2 em on the x axis
+	em := float64(opts.Size) * 25.4 / 72
+	width, height := 10*em, 4*em
+	scale := width / (spacingBuckets * spacingBucketWidth)
+	peak := 1
+	for _, n := range sp.Histogram {
+		peak = max(peak, n)
+	}
+	bar := width / spacingBuckets
+	for i, n := range sp.Histogram {
+		if n != 0 {
+			ctx.DrawPath(float64(i)*bar, 0, canvas.Rectangle(0.9*bar, height*float64(n)/float64(peak)))
+		}
+	}
+	// draw axis
+	line := 0.5 * 25.4 / 72
+	ctx.DrawPath(0, -line, canvas.Rectangle(width, line))
+	for i, label := range []string{"0", "1 em", "2 em"} {
+		x := float64(i) * width / 2
+		ctx.DrawPath(x-line/2, -em/4, canvas.Rectangle(line, em/4))
+		ctx.DrawText(x, -em/4-metrics.LineHeight, canvas.NewTextLine(face, label, canvas.Center))
+	}
+	// mark average and space widths, staggering the labels
+	for i, m := range []struct {
+		label string
+		w     float64
+	}{
+		{"avg", sp.AverageWidth},
+		{"space", sp.SpaceWidth},
+	} {
+		if m.w == 0 {
+			continue
+		}
+		x := min(m.w*scale, width)
+		y := height + em/4 + float64(i)*metrics.LineHeight
+		ctx.DrawPath(x-line/2, 0, canvas.Rectangle(line, y))
+		ctx.DrawText(x, y+em/8, canvas.NewTextLine(face, p.Sprintf(m.label), canvas.Center))
+	}
+	// draw summary
+	summary := p.Sprintf("avg %.2f em, a-z %.2f em, space %.2f em", sp.AverageWidth, sp.LowercaseWidth, sp.SpaceWidth)
+	ctx.DrawText(0, -em/2-2*metrics.LineHeight, canvas.NewTextBox(face, summary, 0, 0, canvas.Left, canvas.Top, nil))
+	// fit canvas to context
+	c.Fit(opts.Margin)
+	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
+
+// drawSpacing draws the advance box, side bearings, and origin of each glyph
+// of the text drawn at x, y, under the text.
+func drawSpacing(ctx *canvas.Context, txt *canvas.Text, x0, y float64) {
+	ctx.Push()
+	defer ctx.Pop()
+	ctx.SetZIndex(0)
+	line := 0.25 * 25.4 / 72
+	txt.WalkSpans(func(x, dy float64, span canvas.TextSpan) {
+		if !span.IsText() {
+			return
+		}
+		face, sfnt := span.Face, span.Face.Font.SFNT
+		metrics := face.Metrics()
+		top, height := y+dy+metrics.Ascent, metrics.Ascent+metrics.Descent
+		for _, g := range span.Glyphs {
+			adv := float64(g.XAdvance) * face.MmPerEm
+			ox := x0 + x + float64(g.XOffset)*face.MmPerEm
+			// side bearings
+			if xmin, _, xmax, _ := sfnt.GlyphBounds(g.ID); xmin != xmax {
+				lsb, rsb := float64(xmin)*face.MmPerEm, adv-float64(xmax)*face.MmPerEm
+				ctx.SetFillColor(spacingColors[0])
+				ctx.DrawPath(ox, top-height, canvas.Rectangle(lsb, height))
+				ctx.SetFillColor(spacingColors[1])
+				ctx.DrawPath(ox+adv-rsb, top-height, canvas.Rectangle(rsb, height))
+			}
+			// advance box
+			ctx.SetFillColor(spacingColors[2])
+			ctx.DrawPath(ox, top-height, canvas.Rectangle(adv, height).Append(
+				canvas.Rectangle(adv-2*line, height-2*line).Translate(line, line).Reverse(),
+			))
+			// origin
+			ctx.SetFillColor(spacingColors[3])
+			ctx.DrawPath(ox, y+dy, canvas.Circle(3*line))
+			x += adv
+		}
+	})
+}
+
+// spacingColors are the left side bearing, right side bearing, advance box,
+// and origin colors.
+var spacingColors = []color.Color{
+	color.NRGBA{R: 0x30, G: 0x90, B: 0x30, A: 0x40},
+	color.NRGBA{R: 0xd0, G: 0x30, B: 0x30, A: 0x40},
+	color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xc0},
+	color.NRGBA{R: 0x30, G: 0x50, B: 0xd0, A: 0xff},
+}
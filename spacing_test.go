@@ -0,0 +1,63 @@
+package fontimg
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestSpacing(t *testing.T) {
+	for _, test := range testFonts(t) {
+		t.Run(test.name, func(t *testing.T) {
+			font := New(nil, test.path)
+			sp, err := font.Spacing()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if len(sp.Histogram) != spacingBuckets || sp.BucketWidth != spacingBucketWidth {
+				t.Errorf("expected %d buckets, got: %d", spacingBuckets, len(sp.Histogram))
+			}
+			var n int
+			for _, v := range sp.Histogram {
+				n += v
+			}
+			if n == 0 {
+				t.Errorf("expected histogram counts")
+			}
+			for _, w := range []float64{sp.AverageWidth, sp.LowercaseWidth, sp.SpaceWidth} {
+				if w <= 0 || 2 <= w {
+					t.Errorf("expected width in (0, 2) em, got: %+v", sp)
+				}
+			}
+			img, err := font.RasterizeSpacing(nil)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+				t.Errorf("expected non-empty image, got: %v", b)
+			}
+		})
+	}
+}
+
+func TestShowSpacing(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	opts := DefaultOptions()
+	opts.Text = "Typography"
+	a, err := font.RasterizeOptions(opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	key := CacheKey(font, opts)
+	opts.ShowSpacing = true
+	b, err := font.RasterizeOptions(opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if bytes.Equal(a.Pix, b.Pix) {
+		t.Errorf("expected spacing to be drawn")
+	}
+	if key == CacheKey(font, opts) {
+		t.Errorf("expected cache key to change")
+	}
+}
@@ -0,0 +1,90 @@
+package fontimg
+
+import (
+	"context"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/tdewolff/canvas"
+)
+
+// Stats are font image rendering statistics.
+type Stats struct {
+	// Glyphs is the number of glyphs drawn, excluding whitespace.
+	Glyphs int
+	// Lines is the number of non-empty lines of text drawn, after wrapping.
+	Lines int
+	// EmojiRuns is the number of runs of emoji missing from the font drawn
+	// with the platform emoji font (see [Options.EmojiFallback]).
+	EmojiRuns int
+	// Layout is the time taken to shape and lay out the text.
+	Layout time.Duration
+	// Raster is the time taken to rasterize the image.
+	Raster time.Duration
+	// Width and Height are the image's dimensions, in pixels.
+	Width, Height int
+	// Scale is the factor the content was scaled by to fit the options' max
+	// dimension (see [Options.MaxDimension]), or 1 when not scaled.
+	Scale float64
+}
+
+// RasterizeStats rasterizes the font image using the options, as with
+// [Font.RasterizeOptions], returning the rendering statistics along with
+// the image. When opts is nil, the default options will be used.
+func (font *Font) RasterizeStats(opts *Options) (*image.RGBA, *Stats, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	st := &Stats{Scale: 1}
+	start := time.Now()
+	c, release, err := font.layout(context.Background(), trimOptions(opts), st)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+	st.Layout = time.Since(start)
+	st.Glyphs += countGlyphs(c)
+	start = time.Now()
+	img := rasterizeInto(nil, c, opts)
+	st.Raster = time.Since(start)
+	st.Width, st.Height = img.Rect.Dx(), img.Rect.Dy()
+	return img, st, nil
+}
+
+// countGlyphs returns the number of non-whitespace glyphs of the canvas'
+// text.
+func countGlyphs(c *canvas.Canvas) int {
+	r := &glyphCounter{w: c.W, h: c.H}
+	c.RenderTo(r)
+	return r.n
+}
+
+// glyphCounter is a renderer counting the non-whitespace glyphs of rendered
+// text.
+type glyphCounter struct {
+	w, h float64
+	n    int
+}
+
+// Size satisfies the [canvas.Renderer] interface.
+func (r *glyphCounter) Size() (float64, float64) {
+	return r.w, r.h
+}
+
+// RenderPath satisfies the [canvas.Renderer] interface.
+func (r *glyphCounter) RenderPath(*canvas.Path, canvas.Style, canvas.Matrix) {}
+
+// RenderText satisfies the [canvas.Renderer] interface.
+func (r *glyphCounter) RenderText(text *canvas.Text, _ canvas.Matrix) {
+	text.WalkSpans(func(_, _ float64, span canvas.TextSpan) {
+		for _, g := range span.Glyphs {
+			if strings.TrimSpace(g.Text) != "" {
+				r.n++
+			}
+		}
+	})
+}
+
+// RenderImage satisfies the [canvas.Renderer] interface.
+func (r *glyphCounter) RenderImage(image.Image, canvas.Matrix) {}
@@ -0,0 +1,91 @@
+package fontimg
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestRasterizeStats(t *testing.T) {
+	tpl, err := NewTemplate("{{ .SampleText }}")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	path := filepath.Join("testdata", "Ubuntu-R.ttf")
+	tests := []struct {
+		name   string
+		font   *Font
+		text   string
+		para   *Paragraph
+		glyphs int
+		lines  int
+	}{
+		{"lines", New(nil, path), "ab c\n\nde", nil, 5, 2},
+		{"paragraph", New(nil, path), "aaa bbb ccc ddd", &Paragraph{Width: 20}, 12, 4},
+		{"bitmap", &Font{Buf: testPSF2(true)}, "A €\né", nil, 3, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Template, opts.Text, opts.Paragraph = tpl, test.text, test.para
+			img, st, err := test.font.RasterizeStats(opts)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if st.Glyphs != test.glyphs {
+				t.Errorf("expected %d glyphs, got: %d", test.glyphs, st.Glyphs)
+			}
+			if st.Lines != test.lines {
+				t.Errorf("expected %d lines, got: %d", test.lines, st.Lines)
+			}
+			if st.Layout <= 0 || st.Raster <= 0 {
+				t.Errorf("expected layout and raster times, got: %v %v", st.Layout, st.Raster)
+			}
+			if b := img.Bounds(); st.Width != b.Dx() || st.Height != b.Dy() {
+				t.Errorf("expected %dx%d, got: %dx%d", b.Dx(), b.Dy(), st.Width, st.Height)
+			}
+			exp, err := test.font.RasterizeOptions(opts)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !bytes.Equal(img.Pix, exp.Pix) {
+				t.Errorf("expected image to match rasterized image")
+			}
+		})
+	}
+}
+
+func TestMaxDimension(t *testing.T) {
+	path := filepath.Join("testdata", "Ubuntu-R.ttf")
+	tests := []struct {
+		name  string
+		font  *Font
+		max   int
+		scale bool
+	}{
+		{"not scaled", New(nil, path), 4096, false},
+		{"scaled", New(nil, path), 300, true},
+		{"disabled", New(nil, path), 0, false},
+		{"bitmap", &Font{Buf: testPSF2(true)}, 100, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Text, opts.MaxDimension = "A €\né", test.max
+			img, st, err := test.font.RasterizeStats(opts)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if test.scale != (st.Scale < 1) {
+				t.Errorf("expected scaled %t, got scale: %g", test.scale, st.Scale)
+			}
+			b := img.Bounds()
+			if test.max != 0 && (test.max < b.Dx() || test.max < b.Dy()) {
+				t.Errorf("expected at most %dx%d, got: %dx%d", test.max, test.max, b.Dx(), b.Dy())
+			}
+			if test.scale && b.Dx() != test.max && b.Dy() != test.max {
+				t.Errorf("expected a dimension of %d, got: %dx%d", test.max, b.Dx(), b.Dy())
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package fontimg
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+// RuneRange is an inclusive range of runes.
+type RuneRange struct {
+	Lo, Hi rune
+}
+
+// String satisfies the [fmt.Stringer] interface, formatting the range as a
+// CSS unicode-range value (ie, U+0000-00FF).
+func (r RuneRange) String() string {
+	if r.Lo == r.Hi {
+		return fmt.Sprintf("U+%04X", r.Lo)
+	}
+	return fmt.Sprintf("U+%04X-%04X", r.Lo, r.Hi)
+}
+
+// Subset is a subset of a font's coverage.
+type Subset struct {
+	// Name is the subset name (ie, latin, cyrillic).
+	Name string
+	// Ranges are the subset's ranges, clipped to the font's coverage.
+	Ranges []RuneRange
+	// Runes is the number of runes in the subset mapped by the font.
+	Runes int
+}
+
+// UnicodeRange returns the subset's CSS unicode-range descriptor value.
+func (s Subset) UnicodeRange() string {
+	v := make([]string, len(s.Ranges))
+	for i, r := range s.Ranges {
+		v[i] = r.String()
+	}
+	return strings.Join(v, ", ")
+}
+
+// Subsets returns the subsets covered by the font, following Google Fonts'
+// subset conventions, with each subset's ranges clipped to the runes mapped
+// by the font. Subsets are in order of precedence (ie, the order their
+// @font-face rules should be declared), and only subsets the font maps runes
+// of are returned.
+func (font *Font) Subsets() ([]Subset, error) {
+	runes, err := font.Runes()
+	if err != nil {
+		return nil, err
+	}
+	var subsets []Subset
+	for _, def := range subsetDefs {
+		s := Subset{Name: def.name}
+		for _, r := range def.ranges {
+			// clip range to the first and last mapped runes
+			i, _ := slices.BinarySearch(runes, r.Lo)
+			j, _ := slices.BinarySearch(runes, r.Hi+1)
+			if i == j {
+				continue
+			}
+			s.Ranges = append(s.Ranges, RuneRange{runes[i], runes[j-1]})
+			s.Runes += j - i
+		}
+		if s.Runes != 0 {
+			subsets = append(subsets, s)
+		}
+	}
+	return subsets, nil
+}
+
+// WriteFontFace writes CSS @font-face rules for each of the font's subsets
+// (see [Font.Subsets]) to w, with the unicode-range descriptor set to the
+// subset's ranges. Any "{subset}" in the url is replaced with the subset's
+// name.
+func (font *Font) WriteFontFace(w io.Writer, url string) error {
+	subsets, err := font.Subsets()
+	if err != nil {
+		return err
+	}
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return err
+	}
+	style, weight := "normal", 400
+	if sfnt.OS2 != nil {
+		weight = int(sfnt.OS2.UsWeightClass)
+		if sfnt.OS2.FsSelection&1 != 0 {
+			style = "italic"
+		}
+	}
+	format := "truetype"
+	switch strings.ToLower(path.Ext(url)) {
+	case ".woff2":
+		format = "woff2"
+	case ".woff":
+		format = "woff"
+	case ".otf":
+		format = "opentype"
+	case ".eot":
+		format = "embedded-opentype"
+	}
+	for _, s := range subsets {
+		if _, err := fmt.Fprintf(w, tplFontFace,
+			s.Name, font.BestName(), style, weight,
+			strings.ReplaceAll(url, "{subset}", s.Name), format,
+			s.UnicodeRange(),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tplFontFace is the @font-face rule template.
+const tplFontFace = `/* %s */
+@font-face {
+  font-family: %q;
+  font-style: %s;
+  font-weight: %d;
+  font-display: swap;
+  src: url(%q) format(%q);
+  unicode-range: %s;
+}
+`
+
+// subsetDefs are the subset definitions, following Google Fonts' subset
+// conventions.
+var subsetDefs = []struct {
+	name   string
+	ranges []RuneRange
+}{
+	{"cyrillic-ext", []RuneRange{
+		{0x0460, 0x052f}, {0x1c80, 0x1c88}, {0x20b4, 0x20b4}, {0x2de0, 0x2dff},
+		{0xa640, 0xa69f}, {0xfe2e, 0xfe2f},
+	}},
+	{"cyrillic", []RuneRange{
+		{0x0301, 0x0301}, {0x0400, 0x045f}, {0x0490, 0x0491}, {0x04b0, 0x04b1},
+		{0x2116, 0x2116},
+	}},
+	{"greek-ext", []RuneRange{
+		{0x1f00, 0x1fff},
+	}},
+	{"greek", []RuneRange{
+		{0x0370, 0x0377}, {0x037a, 0x037f}, {0x0384, 0x038a}, {0x038c, 0x038c},
+		{0x038e, 0x03a1}, {0x03a3, 0x03ff},
+	}},
+	{"vietnamese", []RuneRange{
+		{0x0102, 0x0103}, {0x0110, 0x0111}, {0x0128, 0x0129}, {0x0168, 0x0169},
+		{0x01a0, 0x01a1}, {0x01af, 0x01b0}, {0x0300, 0x0301}, {0x0303, 0x0304},
+		{0x0308, 0x0309}, {0x0323, 0x0323}, {0x0329, 0x0329}, {0x1ea0, 0x1ef9},
+		{0x20ab, 0x20ab},
+	}},
+	{"latin-ext", []RuneRange{
+		{0x0100, 0x02ba}, {0x02bd, 0x02c5}, {0x02c7, 0x02cc}, {0x02ce, 0x02d7},
+		{0x02dd, 0x02ff}, {0x0304, 0x0304}, {0x0308, 0x0308}, {0x0329, 0x0329},
+		{0x1d00, 0x1dbf}, {0x1e00, 0x1e9f}, {0x1ef2, 0x1eff}, {0x2020, 0x2020},
+		{0x20a0, 0x20ab}, {0x20ad, 0x20c0}, {0x2113, 0x2113}, {0x2c60, 0x2c7f},
+		{0xa720, 0xa7ff},
+	}},
+	{"latin", []RuneRange{
+		{0x0000, 0x00ff}, {0x0131, 0x0131}, {0x0152, 0x0153}, {0x02bb, 0x02bc},
+		{0x02c6, 0x02c6}, {0x02da, 0x02da}, {0x02dc, 0x02dc}, {0x0304, 0x0304},
+		{0x0308, 0x0308}, {0x0329, 0x0329}, {0x2000, 0x206f}, {0x20ac, 0x20ac},
+		{0x2122, 0x2122}, {0x2191, 0x2191}, {0x2193, 0x2193}, {0x2212, 0x2212},
+		{0x2215, 0x2215}, {0xfeff, 0xfeff}, {0xfffd, 0xfffd},
+	}},
+}
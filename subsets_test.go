@@ -0,0 +1,64 @@
+package fontimg
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSubsets(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	subsets, err := font.Subsets()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	m := make(map[string]Subset)
+	for _, s := range subsets {
+		if s.Runes == 0 || len(s.Ranges) == 0 {
+			t.Errorf("expected subset %s to have runes", s.Name)
+		}
+		m[s.Name] = s
+	}
+	for _, name := range []string{"latin", "latin-ext", "cyrillic", "greek"} {
+		if _, ok := m[name]; !ok {
+			t.Errorf("expected subset %s", name)
+		}
+	}
+	if s, exp := m["cyrillic"].UnicodeRange(), "U+0400-045F, U+0490-0491, U+04B0-04B1, U+2116"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+	var buf bytes.Buffer
+	if err := font.WriteFontFace(&buf, "/fonts/ubuntu-{subset}.woff2"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s := buf.String()
+	if n := strings.Count(s, "@font-face {"); n != len(subsets) {
+		t.Errorf("expected %d rules, got: %d", len(subsets), n)
+	}
+	for _, exp := range []string{
+		`font-family: "Ubuntu";`,
+		`src: url("/fonts/ubuntu-latin.woff2") format("woff2");`,
+		"unicode-range: U+0400-045F, U+0490-0491, U+04B0-04B1, U+2116;",
+	} {
+		if !strings.Contains(s, exp) {
+			t.Errorf("expected %q in:\n%s", exp, s)
+		}
+	}
+}
+
+func TestRuneRange(t *testing.T) {
+	tests := []struct {
+		r   RuneRange
+		exp string
+	}{
+		{RuneRange{0, 0xff}, "U+0000-00FF"},
+		{RuneRange{0x2116, 0x2116}, "U+2116"},
+		{RuneRange{0x1f600, 0x1f64f}, "U+1F600-1F64F"},
+	}
+	for _, test := range tests {
+		if s := test.r.String(); s != test.exp {
+			t.Errorf("expected %q, got: %q", test.exp, s)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package fontimg
+
+import (
+	"text/template"
+	"unicode"
+
+	fontpkg "github.com/tdewolff/font"
+)
+
+// IsSymbol returns true when the font is a symbol or icon font, ie, a font
+// whose character map does not map any basic Latin letters.
+func (font *Font) IsSymbol() (_ bool, err error) {
+	defer recoverError(&err)
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return false, err
+	}
+	return isSymbol(sfnt), nil
+}
+
+// isSymbol returns true when the font does not map any basic Latin letters.
+func isSymbol(sfnt *fontpkg.SFNT) bool {
+	for _, r := range "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz" {
+		if sfnt.GlyphIndex(r) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// symbolText returns the first n graphic or private use runes mapped by the
+// font, for use as the sample text of symbol fonts.
+func symbolText(sfnt *fontpkg.SFNT, n int) string {
+	var v []rune
+	for _, r := range sfntRunes(sfnt) {
+		if len(v) == n {
+			break
+		}
+		if (unicode.IsGraphic(r) || unicode.Is(unicode.Co, r)) && !unicode.IsSpace(r) && sfnt.GlyphIndex(r) != 0 {
+			v = append(v, r)
+		}
+	}
+	return string(v)
+}
+
+// tplSymbol is the symbol font template.
+var tplSymbol = template.Must(NewTemplate(`{{ size (inc .Size 12) }}{{ .SampleText }}`))
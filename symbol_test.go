@@ -0,0 +1,104 @@
+package fontimg
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	fontpkg "github.com/tdewolff/font"
+)
+
+func TestIsSymbol(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		name string
+		font *Font
+		exp  bool
+	}{
+		{"ttf", New(buf, ""), false},
+		{"symbol", New(testSymbol(t, buf, 40), ""), true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			symbol, err := test.font.IsSymbol()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if symbol != test.exp {
+				t.Errorf("expected %t, got: %t", test.exp, symbol)
+			}
+		})
+	}
+}
+
+func TestSymbolText(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	font := New(testSymbol(t, buf, 40), "")
+	tests := []struct {
+		n   int
+		exp int
+	}{
+		{0, 16},
+		{8, 8},
+		{100, 40},
+	}
+	for _, test := range tests {
+		opts := DefaultOptions()
+		opts.SymbolGlyphs = test.n
+		sfnt, err := font.sfnt()
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		b, err := font.text(opts, sfnt)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		lines, _, _ := breakLines(b, opts.Size)
+		if len(lines) != 1 {
+			t.Fatalf("expected 1 line, got: %d", len(lines))
+		}
+		if n := len([]rune(strings.TrimSpace(lines[0]))); n != test.exp {
+			t.Errorf("expected %d glyphs, got: %d", test.exp, n)
+		}
+		if n, err := font.Notdefs(opts); err != nil || n != 0 {
+			t.Errorf("expected no notdefs, got: %d %v", n, err)
+		}
+	}
+}
+
+// testSymbol rebuilds the font with a character map only mapping n private
+// use area runes, starting at U+E000, to the glyphs following the first.
+func testSymbol(tb testing.TB, buf []byte, n int) []byte {
+	tb.Helper()
+	sfnt, err := fontpkg.ParseSFNT(buf, 0)
+	if err != nil {
+		tb.Fatalf("expected no error, got: %v", err)
+	}
+	tables := make(map[string][]byte)
+	for tag, b := range sfnt.Tables {
+		tables[tag] = b
+	}
+	// format 4 subtable, with a segment for the runes and the final segment
+	sub := make([]byte, 32)
+	for i, v := range []uint16{
+		4, uint16(len(sub)), 0, 4, 4, 1, 0, // header
+		0xe000 + uint16(n-1), 0xffff, // end codes
+		0,              // reserved pad
+		0xe000, 0xffff, // start codes
+		0x2001, 1, // id deltas (glyph 1 - 0xe000)
+		0, 0, // id range offsets
+	} {
+		binary.BigEndian.PutUint16(sub[2*i:], v)
+	}
+	cmap := []byte{0, 0, 0, 1, 0, 3, 0, 1, 0, 0, 0, 12}
+	tables["cmap"] = append(cmap, sub...)
+	return (&fontpkg.SFNT{IsTrueType: true, Tables: tables}).Write()
+}
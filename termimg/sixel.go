@@ -0,0 +1,173 @@
+package termimg
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// sixelColors is the maximum number of colors in a sixel palette.
+const sixelColors = 256
+
+// encodeSixel writes the image as sixel graphics. Transparent pixels are not
+// drawn, leaving the terminal's background.
+func encodeSixel(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	pal, pix := quantize(img)
+	bw := bufio.NewWriter(w)
+	// pixel aspect ratio 1:1, transparent background, then raster attributes
+	fmt.Fprintf(bw, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	for i, c := range pal {
+		n := c.(color.NRGBA)
+		fmt.Fprintf(bw, "#%d;2;%d;%d;%d", i, percent(n.R), percent(n.G), percent(n.B))
+	}
+	band := make(map[int][]byte)
+	for y0 := 0; y0 < height; y0 += 6 {
+		clear(band)
+		for y := y0; y < min(y0+6, height); y++ {
+			for x := range width {
+				i := pix[y*width+x]
+				if i < 0 {
+					continue
+				}
+				if band[i] == nil {
+					band[i] = make([]byte, width)
+				}
+				band[i][x] |= 1 << (y - y0)
+			}
+		}
+		for n, i := range slices.Sorted(maps.Keys(band)) {
+			if n != 0 {
+				// return to the start of the band
+				bw.WriteByte('$')
+			}
+			fmt.Fprintf(bw, "#%d", i)
+			writeSixels(bw, band[i])
+		}
+		bw.WriteByte('-')
+	}
+	bw.WriteString("\x1b\\")
+	return bw.Flush()
+}
+
+// writeSixels writes the sixels, run length encoded, omitting trailing empty
+// sixels.
+func writeSixels(w *bufio.Writer, sixels []byte) {
+	end := len(sixels)
+	for end != 0 && sixels[end-1] == 0 {
+		end--
+	}
+	for x := 0; x < end; {
+		n := 1
+		for x+n < end && sixels[x+n] == sixels[x] {
+			n++
+		}
+		c := '?' + sixels[x]
+		if n > 3 {
+			fmt.Fprintf(w, "!%d%c", n, c)
+		} else {
+			w.WriteString(strings.Repeat(string(c), n))
+		}
+		x += n
+	}
+}
+
+// quantize returns a palette of at most sixelColors opaque colors for the
+// image, and the palette index of each pixel, or -1 for transparent pixels.
+// When the image has more colors than fit the palette, the most frequent
+// colors, reduced to 5 bits per channel, are used.
+func quantize(img image.Image) (color.Palette, []int) {
+	b := img.Bounds()
+	pix := make([]color.NRGBA, 0, b.Dx()*b.Dy())
+	counts := make(map[color.NRGBA]int)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 0x80 {
+				c = color.NRGBA{}
+			} else {
+				c.A = 0xff
+				counts[c]++
+			}
+			pix = append(pix, c)
+		}
+	}
+	var colors []color.NRGBA
+	if len(counts) <= sixelColors {
+		colors = byCount(counts)
+	} else {
+		colors = reduce(counts)
+	}
+	pal := make(color.Palette, len(colors))
+	for i, c := range colors {
+		pal[i] = c
+	}
+	index, cache := make([]int, len(pix)), make(map[color.NRGBA]int)
+	for i, c := range pix {
+		if c.A == 0 {
+			index[i] = -1
+			continue
+		}
+		n, ok := cache[c]
+		if !ok {
+			n = pal.Index(c)
+			cache[c] = n
+		}
+		index[i] = n
+	}
+	return pal, index
+}
+
+// reduce returns the average colors of the most frequent colors, reduced to 5
+// bits per channel, most frequent first.
+func reduce(counts map[color.NRGBA]int) []color.NRGBA {
+	type bucket struct {
+		r, g, b, n int
+	}
+	buckets := make(map[color.NRGBA]*bucket)
+	reduced := make(map[color.NRGBA]int)
+	for c, n := range counts {
+		k := color.NRGBA{c.R &^ 7, c.G &^ 7, c.B &^ 7, 0xff}
+		if buckets[k] == nil {
+			buckets[k] = new(bucket)
+		}
+		bk := buckets[k]
+		bk.r, bk.g, bk.b, bk.n = bk.r+int(c.R)*n, bk.g+int(c.G)*n, bk.b+int(c.B)*n, bk.n+n
+		reduced[k] += n
+	}
+	keys := byCount(reduced)
+	colors := make([]color.NRGBA, min(len(keys), sixelColors))
+	for i, k := range keys[:len(colors)] {
+		// the average remains within the bucket, so colors are distinct
+		bk := buckets[k]
+		colors[i] = color.NRGBA{uint8(bk.r / bk.n), uint8(bk.g / bk.n), uint8(bk.b / bk.n), 0xff}
+	}
+	return colors
+}
+
+// byCount returns the colors sorted by count, most frequent first.
+func byCount(counts map[color.NRGBA]int) []color.NRGBA {
+	return slices.SortedFunc(maps.Keys(counts), func(a, b color.NRGBA) int {
+		if n := cmp.Compare(counts[b], counts[a]); n != 0 {
+			return n
+		}
+		return cmp.Compare(rgb(a), rgb(b))
+	})
+}
+
+// rgb returns the color's red, green and blue channels as an integer.
+func rgb(c color.NRGBA) uint32 {
+	return uint32(c.R)<<16 | uint32(c.G)<<8 | uint32(c.B)
+}
+
+// percent returns the color channel as a percentage.
+func percent(v uint8) int {
+	return (int(v)*100 + 127) / 255
+}
@@ -0,0 +1,57 @@
+package termimg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestEncodeSixel(t *testing.T) {
+	red, blue := color.RGBA{0xff, 0, 0, 0xff}, color.RGBA{0, 0, 0xff, 0xff}
+	img := image.NewRGBA(image.Rect(0, 0, 2, 7))
+	for y := range 7 {
+		img.Set(0, y, red)
+		if y < 6 {
+			img.Set(1, y, blue)
+		}
+	}
+	var buf bytes.Buffer
+	if err := Encode(&buf, img, Sixel); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := "\x1bP0;1;0q\"1;1;2;7#0;2;100;0;0#1;2;0;0;100" +
+		// first band: red in the first column, then blue in the second
+		"#0~$#1?~-" +
+		// second band: red in the first row of the first column
+		"#0@-" +
+		"\x1b\\"
+	if s := buf.String(); s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+}
+
+func TestEncodeSixelColors(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 256, 64))
+	for y := range 64 {
+		for x := range 256 {
+			img.Set(x, y, color.NRGBA{uint8(x), uint8(y * 4), 0x80, 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	if err := Encode(&buf, img, Sixel); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s := buf.String()
+	if n := len(regexp.MustCompile(`#\d+;2;`).FindAllString(s, -1)); n != sixelColors {
+		t.Errorf("expected %d palette colors, got: %d", sixelColors, n)
+	}
+	if n := strings.Count(s, "-"); n != 64/6+1 {
+		t.Errorf("expected %d bands, got: %d", 64/6+1, n)
+	}
+	if !strings.HasSuffix(s, "\x1b\\") {
+		t.Errorf("expected string terminator")
+	}
+}
@@ -0,0 +1,158 @@
+// Package termimg writes images as terminal inline image escape sequences,
+// using the sixel, kitty graphics, or iTerm2 inline image protocols, for
+// previewing fonts directly in a terminal.
+package termimg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+)
+
+// Protocol is a terminal inline image protocol.
+type Protocol int
+
+// Protocols.
+const (
+	// None is no inline image support.
+	None Protocol = iota
+	// Sixel is the DEC sixel graphics protocol.
+	Sixel
+	// Kitty is the kitty terminal graphics protocol.
+	Kitty
+	// ITerm2 is the iTerm2 inline images protocol.
+	ITerm2
+)
+
+// String satisfies the [fmt.Stringer] interface.
+func (p Protocol) String() string {
+	switch p {
+	case None:
+		return "none"
+	case Sixel:
+		return "sixel"
+	case Kitty:
+		return "kitty"
+	case ITerm2:
+		return "iterm2"
+	}
+	return fmt.Sprintf("Protocol(%d)", int(p))
+}
+
+// ParseProtocol parses a protocol name (ie, "sixel", "kitty", "iterm2").
+func ParseProtocol(name string) (Protocol, error) {
+	switch strings.ToLower(name) {
+	case "none":
+		return None, nil
+	case "sixel":
+		return Sixel, nil
+	case "kitty":
+		return Kitty, nil
+	case "iterm2", "iterm":
+		return ITerm2, nil
+	}
+	return None, fmt.Errorf("invalid protocol %q", name)
+}
+
+// Detect detects the inline image protocol supported by the terminal, from
+// the environment.
+func Detect() Protocol {
+	return DetectEnv(os.Getenv)
+}
+
+// DetectEnv detects the inline image protocol supported by the terminal, from
+// the environment variables returned by getenv. Terminals are identified by
+// the TERM, TERM_PROGRAM, LC_TERMINAL and KITTY_WINDOW_ID variables. Returns
+// [None] when the terminal is not known to support inline images.
+func DetectEnv(getenv func(string) string) Protocol {
+	term, program := getenv("TERM"), getenv("TERM_PROGRAM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "",
+		term == "xterm-kitty",
+		term == "xterm-ghostty",
+		program == "ghostty":
+		return Kitty
+	case program == "iTerm.app",
+		program == "WezTerm",
+		program == "mintty",
+		getenv("LC_TERMINAL") == "iTerm2":
+		return ITerm2
+	case strings.Contains(term, "sixel"),
+		strings.HasPrefix(term, "foot"),
+		strings.HasPrefix(term, "mlterm"),
+		strings.HasPrefix(term, "yaft"),
+		strings.HasPrefix(term, "contour"):
+		return Sixel
+	}
+	return None
+}
+
+// Encode writes the image to w as escape sequences for the protocol.
+func Encode(w io.Writer, img image.Image, p Protocol) error {
+	switch p {
+	case Sixel:
+		return encodeSixel(w, img)
+	case Kitty:
+		return encodeKitty(w, img)
+	case ITerm2:
+		return encodeITerm2(w, img)
+	}
+	return fmt.Errorf("unsupported protocol %v", p)
+}
+
+// kittyChunk is the maximum size of a kitty graphics protocol payload chunk.
+const kittyChunk = 4096
+
+// encodeKitty writes the image using the kitty graphics protocol, as a
+// directly transmitted png, split into chunks.
+func encodeKitty(w io.Writer, img image.Image) error {
+	buf, err := encodePNG(img)
+	if err != nil {
+		return err
+	}
+	data := base64.StdEncoding.EncodeToString(buf)
+	var b strings.Builder
+	for i := 0; i == 0 || i < len(data); i += kittyChunk {
+		chunk, more := data[i:min(i+kittyChunk, len(data))], 0
+		if i+kittyChunk < len(data) {
+			more = 1
+		}
+		b.WriteString("\x1b_G")
+		if i == 0 {
+			// transmit and display a png, suppressing responses
+			b.WriteString("a=T,f=100,q=2,")
+		}
+		fmt.Fprintf(&b, "m=%d;%s\x1b\\", more, chunk)
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// encodeITerm2 writes the image using the iTerm2 inline images protocol, as
+// a png.
+func encodeITerm2(w io.Writer, img image.Image) error {
+	buf, err := encodePNG(img)
+	if err != nil {
+		return err
+	}
+	b := img.Bounds()
+	_, err = fmt.Fprintf(
+		w, "\x1b]1337;File=inline=1;size=%d;width=%dpx;height=%dpx;preserveAspectRatio=1:%s\a",
+		len(buf), b.Dx(), b.Dy(), base64.StdEncoding.EncodeToString(buf),
+	)
+	return err
+}
+
+// encodePNG encodes the image as a png.
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
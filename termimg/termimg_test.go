@@ -0,0 +1,117 @@
+package termimg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/kenshaw/fontimg"
+)
+
+func TestParseProtocol(t *testing.T) {
+	for _, p := range []Protocol{None, Sixel, Kitty, ITerm2} {
+		switch v, err := ParseProtocol(strings.ToUpper(p.String())); {
+		case err != nil:
+			t.Errorf("expected no error, got: %v", err)
+		case v != p:
+			t.Errorf("expected %v, got: %v", p, v)
+		}
+	}
+	if _, err := ParseProtocol("png"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestDetectEnv(t *testing.T) {
+	tests := []struct {
+		env map[string]string
+		exp Protocol
+	}{
+		{nil, None},
+		{map[string]string{"TERM": "xterm-256color"}, None},
+		{map[string]string{"TERM": "xterm-kitty"}, Kitty},
+		{map[string]string{"TERM": "xterm-256color", "KITTY_WINDOW_ID": "1"}, Kitty},
+		{map[string]string{"TERM_PROGRAM": "ghostty"}, Kitty},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, ITerm2},
+		{map[string]string{"TERM": "screen", "LC_TERMINAL": "iTerm2"}, ITerm2},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, ITerm2},
+		{map[string]string{"TERM": "foot"}, Sixel},
+		{map[string]string{"TERM": "xterm-sixel"}, Sixel},
+		{map[string]string{"TERM": "mlterm"}, Sixel},
+	}
+	for i, test := range tests {
+		if p := DetectEnv(func(name string) string { return test.env[name] }); p != test.exp {
+			t.Errorf("test %d expected %v, got: %v", i, test.exp, p)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	img, err := fontimg.New(nil, filepath.Join("..", "testdata", "Ubuntu-R.ttf")).RasterizeOptions(fontimg.DefaultOptions())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	kitty := regexp.MustCompile(`\x1b_G(a=T,f=100,q=2,)?m=([01]);([A-Za-z0-9+/=]*)\x1b\\`)
+	iterm2 := regexp.MustCompile(`^\x1b]1337;File=inline=1;size=(\d+);width=(\d+)px;height=(\d+)px;preserveAspectRatio=1:([A-Za-z0-9+/=]+)\a$`)
+	tests := []struct {
+		p      Protocol
+		decode func(*testing.T, string) string
+	}{
+		{Kitty, func(t *testing.T, s string) string {
+			matches := kitty.FindAllStringSubmatch(s, -1)
+			if n := len(kitty.ReplaceAllString(s, "")); n != 0 {
+				t.Errorf("expected only kitty escape sequences, got %d other bytes", n)
+			}
+			var data strings.Builder
+			for i, m := range matches {
+				switch {
+				case (i == 0) != (m[1] != ""):
+					t.Errorf("chunk %d: expected control data only on the first chunk", i)
+				case (i == len(matches)-1) != (m[2] == "0"):
+					t.Errorf("chunk %d: expected m=0 only on the last chunk", i)
+				case len(m[3]) > kittyChunk:
+					t.Errorf("chunk %d: expected at most %d bytes, got: %d", i, kittyChunk, len(m[3]))
+				}
+				data.WriteString(m[3])
+			}
+			if len(matches) < 2 {
+				t.Errorf("expected multiple chunks, got: %d", len(matches))
+			}
+			return data.String()
+		}},
+		{ITerm2, func(t *testing.T, s string) string {
+			m := iterm2.FindStringSubmatch(s)
+			if m == nil {
+				t.Fatalf("expected iterm2 escape sequence, got: %q", s[:min(len(s), 64)])
+			}
+			return m[4]
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.p.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, img, test.p); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			data, err := base64.StdEncoding.DecodeString(test.decode(t, buf.String()))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			v, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if v.Bounds() != img.Bounds() {
+				t.Errorf("expected bounds %v, got: %v", img.Bounds(), v.Bounds())
+			}
+		})
+	}
+	if err := Encode(new(bytes.Buffer), image.NewRGBA(image.Rect(0, 0, 1, 1)), None); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
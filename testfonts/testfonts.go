@@ -0,0 +1,62 @@
+// Package testfonts provides a small set of freely licensed fonts for
+// writing deterministic tests against fontimg, without depending on the
+// system fonts.
+//
+// The Go fonts, created by the Bigelow & Holmes foundry for the Go project,
+// are distributed under the BSD style license in the package's LICENSE file.
+// The variable font is the Go font with width variations, and the color
+// font is an ASCII subset of the Go font with a COLR table drawing uppercase
+// letters in red, lowercase letters in blue, and digits in green, both under
+// the same license. The CJK font is a subset of the M+ 1p font, covering
+// ASCII, the basic hiragana and katakana, and a few kanji (ie, "日本語"),
+// distributed under the license in the package's LICENSE-MPLUS file.
+package testfonts
+
+import (
+	"embed"
+	"os"
+	"path/filepath"
+)
+
+// Font names.
+const (
+	Regular    = "Go-Regular.ttf"
+	Bold       = "Go-Bold.ttf"
+	Italic     = "Go-Italic.ttf"
+	BoldItalic = "Go-Bold-Italic.ttf"
+	Mono       = "Go-Mono.ttf"
+	Variable   = "GoVF-Regular.ttf"
+	Color      = "GoColor-Regular.ttf"
+	CJK        = "Mplus1p-CJK-Subset.ttf"
+)
+
+// FS is the embedded font files.
+//
+//go:embed *.ttf
+var FS embed.FS
+
+// Names returns the font names.
+func Names() []string {
+	return []string{Regular, Bold, Italic, BoldItalic, Mono, Variable, Color, CJK}
+}
+
+// Bytes returns the named font's data. It panics when the name is not one of
+// the font names.
+func Bytes(name string) []byte {
+	buf, err := FS.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
+
+// WriteDir writes the fonts to the directory, for use with tests of font
+// directories (ie, [github.com/kenshaw/fontimg.Open]).
+func WriteDir(dir string) error {
+	for _, name := range Names() {
+		if err := os.WriteFile(filepath.Join(dir, name), Bytes(name), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package testfonts_test
+
+import (
+	"testing"
+
+	"github.com/kenshaw/fontimg"
+	"github.com/kenshaw/fontimg/testfonts"
+	"github.com/tdewolff/canvas"
+)
+
+func TestFonts(t *testing.T) {
+	tests := []struct {
+		name  string
+		style string
+	}{
+		{testfonts.Regular, "Regular"},
+		{testfonts.Bold, "Bold"},
+		{testfonts.Italic, "Regular Italic"},
+		{testfonts.BoldItalic, "Bold Italic"},
+		{testfonts.Mono, "Regular"},
+		{testfonts.Variable, "Regular"},
+		{testfonts.Color, "Regular"},
+		{testfonts.CJK, "Regular"},
+	}
+	if n := len(testfonts.Names()); n != len(tests) {
+		t.Fatalf("expected %d fonts, got: %d", len(tests), n)
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			md, err := fontimg.ParseMetadata(testfonts.Bytes(test.name))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if md.Style != test.style {
+				t.Errorf("expected style %q, got: %q", test.style, md.Style)
+			}
+			if _, err := fontimg.New(testfonts.Bytes(test.name), test.name).RasterizeOptions(nil); err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	axes, err := fontimg.New(testfonts.Bytes(testfonts.Variable), testfonts.Variable).Axes()
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case len(axes) == 0:
+		t.Errorf("expected variable font axes")
+	}
+	formats, err := fontimg.New(testfonts.Bytes(testfonts.Color), testfonts.Color).ColorFormats()
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case len(formats) != 1 || formats[0] != "COLR":
+		t.Errorf("expected COLR, got: %v", formats)
+	}
+	opts := fontimg.DefaultOptions()
+	opts.Text, opts.Strict = "日本語 あア", true
+	if _, err := fontimg.New(testfonts.Bytes(testfonts.CJK), testfonts.CJK).RasterizeOptions(opts); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestWriteDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := testfonts.WriteDir(dir); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fonts, err := fontimg.Open(dir, canvas.FontRegular, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(fonts) != len(testfonts.Names()) {
+		t.Errorf("expected %d fonts, got: %d", len(testfonts.Names()), len(fonts))
+	}
+}
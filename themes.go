@@ -0,0 +1,82 @@
+package fontimg
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Theme is a font image color theme.
+type Theme struct {
+	// FG is the foreground (text) color.
+	FG color.Color
+	// BG is the background color.
+	BG color.Color
+}
+
+// Themes.
+var (
+	// LightTheme is the light theme, black text on a white background.
+	LightTheme = Theme{FG: color.Black, BG: color.White}
+	// DarkTheme is the dark theme, white text on a near black background.
+	DarkTheme = Theme{FG: color.White, BG: color.RGBA{0x12, 0x12, 0x12, 0xff}}
+)
+
+// RasterizeThemes rasterizes the font image using the options in each of the
+// themes, from a single layout and rasterization of the text, returning an
+// image per theme. When no themes are provided, the light and dark themes are
+// used (see [LightTheme] and [DarkTheme]). The options' colors are ignored,
+// and all drawing, including decorations (ie, [Options.ShowSpacing]), is done
+// in the theme's foreground color. When opts is nil, the default options will
+// be used.
+func (font *Font) RasterizeThemes(opts *Options, themes ...Theme) ([]*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if len(themes) == 0 {
+		themes = []Theme{LightTheme, DarkTheme}
+	}
+	for i, theme := range themes {
+		if theme.FG == nil || (theme.BG == nil && !opts.NoBackground) {
+			return nil, fmt.Errorf("theme %d: fg or bg not set", i)
+		}
+	}
+	// rasterize the text's coverage once, as opaque white on transparent
+	o := *opts
+	o.FG, o.NoBackground = color.White, true
+	c, err := font.Canvas(&o)
+	if err != nil {
+		return nil, err
+	}
+	mask := rasterizeInto(nil, c, &o)
+	imgs := make([]*image.RGBA, len(themes))
+	for i, theme := range themes {
+		img := image.NewRGBA(mask.Rect)
+		if !opts.NoBackground {
+			draw.Draw(img, img.Rect, image.NewUniform(theme.BG), image.Point{}, draw.Src)
+		}
+		draw.DrawMask(img, img.Rect, image.NewUniform(theme.FG), image.Point{}, mask, mask.Rect.Min, draw.Over)
+		imgs[i] = img
+	}
+	return imgs, nil
+}
+
+// Stack stacks the images vertically, left aligned, for side-by-side
+// comparisons (ie, of the images returned by [Font.RasterizeThemes]). Space
+// to the right of narrower images is left transparent.
+func Stack(imgs ...image.Image) *image.RGBA {
+	var w, h int
+	for _, img := range imgs {
+		b := img.Bounds()
+		w, h = max(w, b.Dx()), h+b.Dy()
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	y := 0
+	for _, img := range imgs {
+		b := img.Bounds()
+		draw.Draw(dst, image.Rect(0, y, b.Dx(), y+b.Dy()), img, b.Min, draw.Src)
+		y += b.Dy()
+	}
+	return dst
+}
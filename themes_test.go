@@ -0,0 +1,52 @@
+package fontimg
+
+import (
+	"image"
+	"path/filepath"
+	"testing"
+
+	"github.com/kenshaw/fontimg/fontimgtest"
+)
+
+func TestRasterizeThemes(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	themes := []Theme{LightTheme, DarkTheme}
+	imgs, err := font.RasterizeThemes(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(imgs) != len(themes) {
+		t.Fatalf("expected %d images, got: %d", len(themes), len(imgs))
+	}
+	for i, theme := range themes {
+		opts := DefaultOptions()
+		opts.FG, opts.BG = theme.FG, theme.BG
+		exp, err := font.RasterizeOptions(opts)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		diff, err := fontimgtest.Compare(exp, imgs[i], fontimgtest.DefaultTolerance)
+		if err != nil {
+			t.Errorf("theme %d expected no error, got: %v (%v)", i, err, diff)
+		}
+	}
+	if _, err := font.RasterizeThemes(nil, Theme{FG: LightTheme.FG}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestStack(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 10, 5))
+	b := image.NewRGBA(image.Rect(5, 5, 25, 12))
+	a.Pix[3], b.Pix[len(b.Pix)-1] = 0xff, 0xff
+	img := Stack(a, b)
+	if exp := image.Rect(0, 0, 20, 12); img.Rect != exp {
+		t.Fatalf("expected %v, got: %v", exp, img.Rect)
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a == 0 {
+		t.Errorf("expected first image at top left")
+	}
+	if _, _, _, a := img.At(19, 11).RGBA(); a == 0 {
+		t.Errorf("expected second image at bottom left")
+	}
+}
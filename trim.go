@@ -0,0 +1,83 @@
+package fontimg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Trim crops the image to the bounds of its content, the pixels not matching
+// the background color. When the image has no content, an empty image is
+// returned. Images supporting SubImage (ie, [*image.RGBA]) share their
+// pixels with the returned image, otherwise the content is copied.
+func Trim(img image.Image, bg color.Color) image.Image {
+	r := contentBounds(img, bg)
+	if v, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return v.SubImage(r)
+	}
+	dst := image.NewRGBA(r)
+	draw.Draw(dst, r, img, r.Min, draw.Src)
+	return dst
+}
+
+// contentBounds returns the bounds of the image's pixels not matching the
+// background color.
+func contentBounds(img image.Image, bg color.Color) image.Rectangle {
+	b := img.Bounds()
+	br, bgg, bb, ba := bg.RGBA()
+	match := func(x, y int) bool {
+		r, g, b, a := img.At(x, y).RGBA()
+		return r == br && g == bgg && b == bb && a == ba
+	}
+	if rgba, ok := img.(*image.RGBA); ok {
+		c := color.RGBAModel.Convert(bg).(color.RGBA)
+		match = func(x, y int) bool {
+			i := rgba.PixOffset(x, y)
+			p := rgba.Pix[i : i+4 : i+4]
+			return p[0] == c.R && p[1] == c.G && p[2] == c.B && p[3] == c.A
+		}
+	}
+	r := image.Rectangle{Min: b.Max, Max: b.Min}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if match(x, y) {
+				continue
+			}
+			r.Min.X, r.Min.Y = min(r.Min.X, x), min(r.Min.Y, y)
+			r.Max.X, r.Max.Y = max(r.Max.X, x+1), max(r.Max.Y, y+1)
+		}
+	}
+	if r.Empty() {
+		return image.Rectangle{Min: b.Min, Max: b.Min}
+	}
+	return r
+}
+
+// trimOptions returns the options used to lay out a trimmed font image,
+// skipping the background, as the background's edges are not aligned to the
+// pixel grid and are filled by [trim] instead.
+func trimOptions(opts *Options) *Options {
+	if !opts.Trim || opts.NoBackground {
+		return opts
+	}
+	o := *opts
+	o.NoBackground = true
+	return &o
+}
+
+// trim crops the rasterized font image, laid out without a background (see
+// [trimOptions]), to its content plus the options' margin, filling the
+// options' background, and returning a new image.
+func trim(img *image.RGBA, opts *Options) *image.RGBA {
+	r := contentBounds(img, color.Transparent)
+	pad := int(math.Round(opts.Margin * opts.DPI / 25.4))
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx()+2*pad, r.Dy()+2*pad))
+	if !opts.NoBackground {
+		draw.Draw(dst, dst.Rect, image.NewUniform(opts.BG), image.Point{}, draw.Src)
+	}
+	draw.Draw(dst, dst.Rect.Inset(pad), img, r.Min, draw.Over)
+	return dst
+}
@@ -0,0 +1,74 @@
+package fontimg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrim(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	draw.Draw(img, img.Rect, image.White, image.Point{}, draw.Src)
+	img.Set(3, 2, color.Black)
+	img.Set(12, 7, color.Gray{0x80})
+	tests := []struct {
+		name string
+		img  image.Image
+		bg   color.Color
+		exp  image.Rectangle
+	}{
+		{"rgba", img, color.White, image.Rect(3, 2, 13, 8)},
+		{"gray", testGray(img), color.White, image.Rect(3, 2, 13, 8)},
+		{"empty", image.NewRGBA(image.Rect(0, 0, 5, 5)), color.Transparent, image.Rectangle{}},
+		{"no background", img, color.Black, img.Rect},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if b := Trim(test.img, test.bg).Bounds(); b != test.exp {
+				t.Errorf("expected %v, got: %v", test.exp, b)
+			}
+		})
+	}
+}
+
+func TestTrimOption(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	for _, noBackground := range []bool{false, true} {
+		opts := DefaultOptions()
+		opts.Trim, opts.NoBackground = true, noBackground
+		img, err := font.RasterizeOptions(opts)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		var bg color.Color = color.Transparent
+		if !noBackground {
+			bg = opts.BG
+		}
+		// content is inset by the margin on all sides
+		pad := int(opts.Margin*opts.DPI/25.4 + 0.5)
+		if r, exp := contentBounds(img, bg), img.Rect.Inset(pad); r != exp {
+			t.Errorf("expected content bounds %v, got: %v", exp, r)
+		}
+		untrimmed, err := font.RasterizeOptions(nil)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if b := untrimmed.Bounds(); b.Dx() <= img.Rect.Dx() && b.Dy() <= img.Rect.Dy() {
+			t.Errorf("expected trimmed image smaller than %v, got: %v", b, img.Rect)
+		}
+	}
+	opts := DefaultOptions()
+	opts.Trim = true
+	if CacheKey(font, opts) == CacheKey(font, nil) {
+		t.Errorf("expected trim to produce a different key")
+	}
+}
+
+// testGray converts the image to grayscale.
+func testGray(img image.Image) *image.Gray {
+	dst := image.NewGray(img.Bounds())
+	draw.Draw(dst, dst.Rect, img, img.Bounds().Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,121 @@
+package fontimg
+
+import (
+	"fmt"
+	"strings"
+
+	fontpkg "github.com/tdewolff/font"
+)
+
+// Issue is an issue detected when validating a font.
+type Issue struct {
+	// Check is the name of the check that detected the issue.
+	Check string
+	// Message describes the issue.
+	Message string
+}
+
+// String satisfies the [fmt.Stringer] interface.
+func (issue Issue) String() string {
+	return issue.Check + ": " + issue.Message
+}
+
+// Validate checks the font for common defects, returning the detected
+// issues.
+func (font *Font) Validate() ([]Issue, error) {
+	var v []Issue
+	vm, err := font.VerticalMetrics()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range vm.Issues {
+		v = append(v, Issue{Check: "vertical_metrics", Message: s})
+	}
+	sf, err := font.StyleFlags()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sf.Issues {
+		v = append(v, Issue{Check: "style_flags", Message: s})
+	}
+	return v, nil
+}
+
+// StyleFlags is a report of a font's bold and italic style flags, as set in
+// the OS/2 fsSelection field, the head macStyle field, and the subfamily
+// name. Style matching (see [Match]) and applications building RIBBI style
+// groups rely on these agreeing.
+type StyleFlags struct {
+	// Subfamily is the subfamily name (name ID 2).
+	Subfamily string
+	// Bold, Italic and Regular are the OS/2 fsSelection BOLD, ITALIC and
+	// REGULAR flags.
+	Bold, Italic, Regular bool
+	// MacBold and MacItalic are the head macStyle bold and italic flags.
+	MacBold, MacItalic bool
+	// Issues are the detected inconsistencies.
+	Issues []string
+}
+
+// StyleFlags reports the font's style flags, checking them for
+// inconsistencies with each other and the subfamily name.
+func (font *Font) StyleFlags() (_ *StyleFlags, err error) {
+	defer recoverError(&err)
+	sfnt, err := font.sfnt()
+	if err != nil {
+		return nil, err
+	}
+	sf := &StyleFlags{
+		MacBold:   sfnt.Head.MacStyle[0],
+		MacItalic: sfnt.Head.MacStyle[1],
+	}
+	if v := sfnt.Name.Get(fontpkg.NameFontSubfamily); 0 < len(v) {
+		sf.Subfamily = v[0].String()
+	}
+	if sfnt.OS2 == nil {
+		sf.Issues = append(sf.Issues, "missing OS/2 table")
+		return sf, nil
+	}
+	sf.Italic = sfnt.OS2.FsSelection&(1<<0) != 0
+	sf.Bold = sfnt.OS2.FsSelection&(1<<5) != 0
+	sf.Regular = sfnt.OS2.FsSelection&(1<<6) != 0
+	sf.check()
+	return sf, nil
+}
+
+// check checks the flags for inconsistencies.
+func (sf *StyleFlags) check() {
+	add := func(format string, v ...any) {
+		sf.Issues = append(sf.Issues, fmt.Sprintf(format, v...))
+	}
+	if sf.Bold != sf.MacBold {
+		add("fsSelection bold %t differs from macStyle bold %t", sf.Bold, sf.MacBold)
+	}
+	if sf.Italic != sf.MacItalic {
+		add("fsSelection italic %t differs from macStyle italic %t", sf.Italic, sf.MacItalic)
+	}
+	switch {
+	case sf.Regular && (sf.Bold || sf.Italic):
+		add("fsSelection regular is set with bold or italic")
+	case !sf.Regular && !sf.Bold && !sf.Italic:
+		add("fsSelection regular, bold and italic are not set")
+	}
+	if sf.Subfamily == "" {
+		return
+	}
+	var bold, italic bool
+	for _, s := range strings.Fields(strings.ToLower(sf.Subfamily)) {
+		switch s {
+		case "bold":
+			bold = true
+		case "italic", "oblique":
+			italic = true
+		}
+	}
+	if bold != sf.Bold {
+		add("subfamily %q does not match fsSelection bold %t", sf.Subfamily, sf.Bold)
+	}
+	if italic != sf.Italic {
+		add("subfamily %q does not match fsSelection italic %t", sf.Subfamily, sf.Italic)
+	}
+}
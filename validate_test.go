@@ -0,0 +1,72 @@
+package fontimg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	for _, test := range testFonts(t) {
+		t.Run(test.name, func(t *testing.T) {
+			font := New(nil, test.path)
+			sf, err := font.StyleFlags()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if len(sf.Issues) != 0 {
+				t.Errorf("expected no style flags issues, got: %v", sf.Issues)
+			}
+			if !sf.Regular || sf.Bold || sf.Italic {
+				t.Errorf("expected regular, got: %+v", sf)
+			}
+			issues, err := font.Validate()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			for _, issue := range issues {
+				t.Logf("issue: %s", issue)
+			}
+		})
+	}
+}
+
+func TestStyleFlagsCheck(t *testing.T) {
+	tests := []struct {
+		sf  StyleFlags
+		exp []string
+	}{
+		{StyleFlags{Subfamily: "Regular", Regular: true}, nil},
+		{StyleFlags{Subfamily: "Bold Italic", Bold: true, Italic: true, MacBold: true, MacItalic: true}, nil},
+		{StyleFlags{Subfamily: "Oblique", Italic: true, MacItalic: true}, nil},
+		{
+			StyleFlags{Subfamily: "Bold", Regular: true},
+			[]string{
+				`subfamily "Bold" does not match fsSelection bold false`,
+			},
+		},
+		{
+			StyleFlags{Subfamily: "Bold", Bold: true},
+			[]string{
+				"fsSelection bold true differs from macStyle bold false",
+			},
+		},
+		{
+			StyleFlags{Subfamily: "Italic", Regular: true, Italic: true, MacItalic: true},
+			[]string{
+				"fsSelection regular is set with bold or italic",
+			},
+		},
+		{
+			StyleFlags{},
+			[]string{
+				"fsSelection regular, bold and italic are not set",
+			},
+		},
+	}
+	for i, test := range tests {
+		test.sf.check()
+		if !reflect.DeepEqual(test.sf.Issues, test.exp) {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, test.sf.Issues)
+		}
+	}
+}
@@ -0,0 +1,530 @@
+package fontimg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	gofont "github.com/go-text/typesetting/font"
+	ot "github.com/go-text/typesetting/font/opentype"
+	"github.com/go-text/typesetting/font/opentype/tables"
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+	fontpkg "github.com/tdewolff/font"
+)
+
+// Axis is a variable font's variation axis.
+type Axis struct {
+	// Tag is the axis' tag (ie, "wght", "wdth", "slnt", or a custom tag).
+	Tag string
+	// Name is the axis' name.
+	Name string
+	// Min, Default, and Max are the axis' range, in design units.
+	Min, Default, Max float64
+	// Hidden is whether the axis should be hidden from users.
+	Hidden bool
+}
+
+// Instance is a variable font's named instance.
+type Instance struct {
+	// Name is the instance's subfamily name (ie, "Bold").
+	Name string
+	// Coords are the instance's axis coordinates, by axis tag.
+	Coords map[string]float64
+}
+
+// Axes returns the font's variation axes, read from the font's fvar table.
+// Returns nil when the font is not a variable font.
+func (font *Font) Axes() ([]Axis, error) {
+	axes, _, err := font.fvar()
+	return axes, err
+}
+
+// Instances returns the font's named instances, read from the font's fvar
+// table. Returns nil when the font is not a variable font.
+func (font *Font) Instances() ([]Instance, error) {
+	_, instances, err := font.fvar()
+	return instances, err
+}
+
+// fvar reads the font's variation axes and named instances.
+func (font *Font) fvar() ([]Axis, []Instance, error) {
+	ld, err := font.loader()
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := ld.RawTable(ot.MustNewTag("fvar"))
+	if err != nil {
+		return nil, nil, nil
+	}
+	if len(b) < 16 {
+		return nil, nil, fmt.Errorf("fvar: bad table")
+	}
+	var names tables.Name
+	if nb, err := ld.RawTable(ot.MustNewTag("name")); err == nil {
+		names, _, _ = tables.ParseName(nb)
+	}
+	offset := int(binary.BigEndian.Uint16(b[4:]))
+	axisCount, axisSize := int(binary.BigEndian.Uint16(b[8:])), int(binary.BigEndian.Uint16(b[10:]))
+	instanceCount, instanceSize := int(binary.BigEndian.Uint16(b[12:])), int(binary.BigEndian.Uint16(b[14:]))
+	if axisSize < 20 || instanceSize < 4+4*axisCount || len(b) < offset+axisCount*axisSize+instanceCount*instanceSize {
+		return nil, nil, fmt.Errorf("fvar: bad table")
+	}
+	fixed := func(b []byte) float64 {
+		return float64(int32(binary.BigEndian.Uint32(b))) / 65536
+	}
+	axes := make([]Axis, axisCount)
+	for i := range axes {
+		rec := b[offset+i*axisSize:]
+		axes[i] = Axis{
+			Tag:     string(rec[:4]),
+			Name:    names.Name(tables.NameID(binary.BigEndian.Uint16(rec[18:]))),
+			Min:     fixed(rec[4:]),
+			Default: fixed(rec[8:]),
+			Max:     fixed(rec[12:]),
+			Hidden:  binary.BigEndian.Uint16(rec[16:])&1 != 0,
+		}
+		if axes[i].Name == "" {
+			axes[i].Name = axes[i].Tag
+		}
+	}
+	instances := make([]Instance, instanceCount)
+	for i := range instances {
+		rec := b[offset+axisCount*axisSize+i*instanceSize:]
+		instances[i] = Instance{
+			Name:   names.Name(tables.NameID(binary.BigEndian.Uint16(rec))),
+			Coords: make(map[string]float64, axisCount),
+		}
+		for j, axis := range axes {
+			instances[i].Coords[axis.Tag] = fixed(rec[4+4*j:])
+		}
+	}
+	return axes, instances, nil
+}
+
+// loader returns a loader for the font's data. Only the first font of a font
+// collection is loaded.
+func (font *Font) loader() (*ot.Loader, error) {
+	buf, err := font.data()
+	if err != nil {
+		return nil, err
+	}
+	if buf, err = toSFNT(buf); err != nil {
+		return nil, err
+	}
+	lds, err := ot.NewLoaders(bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	return lds[0], nil
+}
+
+// Instantiate returns a static instance of the variable font at the axis
+// coordinates, by axis tag, in design units. Axes not in coords are at their
+// default, and coordinates are clamped to the axis' range. The instance's
+// glyph outlines and advances have the font's variations applied, and are
+// converted to TrueType outlines (cubic curves are approximated by quadratic
+// curves). The instance's hinting, and variations of the layout tables, are
+// dropped. The instance's style is the name of the named instance at the
+// coordinates, if any.
+func (font *Font) Instantiate(coords map[string]float64) (*Font, error) {
+	axes, instances, err := font.fvar()
+	switch {
+	case err != nil:
+		return nil, err
+	case len(axes) == 0:
+		return nil, fmt.Errorf("not a variable font")
+	}
+	clamped := make(map[string]float64, len(coords))
+	vars := make([]gofont.Variation, 0, len(coords))
+	for _, tag := range slices.Sorted(maps.Keys(coords)) {
+		i := slices.IndexFunc(axes, func(axis Axis) bool { return axis.Tag == tag })
+		if i == -1 {
+			return nil, fmt.Errorf("unknown axis %q", tag)
+		}
+		clamped[tag] = min(max(coords[tag], axes[i].Min), axes[i].Max)
+		vars = append(vars, gofont.Variation{Tag: ot.MustNewTag(tag), Value: float32(clamped[tag])})
+	}
+	ld, err := font.loader()
+	if err != nil {
+		return nil, err
+	}
+	buf, err := instantiate(ld, vars)
+	if err != nil {
+		return nil, err
+	}
+	inst := &Font{
+		Buf:    buf,
+		Path:   font.Path,
+		Family: font.Family,
+	}
+	if _, err := inst.Load(canvas.FontRegular); err != nil {
+		return nil, err
+	}
+	// name the instance after the matching named instance
+	for _, instance := range instances {
+		if instance.Name != "" && sameCoords(axes, instance.Coords, clamped) {
+			inst.Style = instance.Name
+		}
+	}
+	return inst, nil
+}
+
+// instance returns the options' instance of the variable font.
+func (font *Font) instance(opts *Options) (*Font, error) {
+	coords := make(map[string]float64)
+	if opts.Instance != "" {
+		instances, err := font.Instances()
+		if err != nil {
+			return nil, err
+		}
+		i := slices.IndexFunc(instances, func(instance Instance) bool {
+			return strings.EqualFold(instance.Name, opts.Instance)
+		})
+		if i == -1 {
+			return nil, fmt.Errorf("instance %q not found", opts.Instance)
+		}
+		for tag, v := range instances[i].Coords {
+			coords[tag] = v
+		}
+	}
+	for tag, v := range opts.Variations {
+		coords[tag] = v
+	}
+	return font.Instantiate(coords)
+}
+
+// RasterizeInstances rasterizes the named instances image using the options.
+// See [Font.InstanceStrip].
+func (font *Font) RasterizeInstances(opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := font.InstanceStrip(opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// InstanceStrip lays out a strip of the variable font's named instances on a
+// canvas, with a line per instance showing the sample text set in the
+// instance, labeled with the instance's name. The options' text (when not
+// empty) is used as the sample text, and the options' template, instance,
+// and variations are not used. When opts is nil, the default options will be
+// used.
+func (font *Font) InstanceStrip(opts *Options) (_ *canvas.Canvas, err error) {
+	defer recoverError(&err)
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	instances, err := font.Instances()
+	switch {
+	case err != nil:
+		return nil, err
+	case len(instances) == 0:
+		return nil, fmt.Errorf("no named instances")
+	}
+	text := opts.Text
+	if text == "" {
+		text = font.SampleText
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		text = "The quick brown fox jumps over the lazy dog."
+	}
+	lff, err := LabelFont().Load(canvas.FontRegular)
+	if err != nil {
+		return nil, err
+	}
+	label := lff.Face(0.3*float64(opts.Size), opts.FG)
+	// create canvas and context
+	c := canvas.New(100, 100)
+	ctx := canvas.NewContext(c)
+	ctx.SetZIndex(1)
+	ctx.SetFillColor(opts.FG)
+	y := float64(0)
+	for _, instance := range instances {
+		inst, err := font.Instantiate(instance.Coords)
+		if err != nil {
+			return nil, fmt.Errorf("instance %q: %v", instance.Name, err)
+		}
+		ff, err := inst.Load(canvas.FontRegular)
+		if err != nil {
+			return nil, fmt.Errorf("instance %q: %v", instance.Name, err)
+		}
+		ctx.DrawText(0, y, canvas.NewTextLine(label, instance.Name, canvas.Left))
+		y -= label.Metrics().LineHeight
+		face := ff.Face(float64(opts.Size), opts.FG, canvas.FontRegular, opts.Variant)
+		txt := canvas.NewTextBox(face, text, 0, 0, canvas.Left, canvas.Top, nil)
+		b := txt.Bounds()
+		ctx.DrawText(0, y, txt)
+		y += b.Y0 - b.Y1 - label.Metrics().LineHeight
+	}
+	// fit canvas to context
+	fitCanvas(c, opts, nil)
+	// draw background
+	drawBackground(ctx, opts)
+	// close drawing context
+	ctx.Close()
+	return c, nil
+}
+
+// sameCoords returns whether the coordinates are the same, with missing axes
+// at their default.
+func sameCoords(axes []Axis, a, b map[string]float64) bool {
+	for _, axis := range axes {
+		x, ok := a[axis.Tag]
+		if !ok {
+			x = axis.Default
+		}
+		y, ok := b[axis.Tag]
+		if !ok {
+			y = axis.Default
+		}
+		if math.Abs(x-y) > 1e-3 {
+			return false
+		}
+	}
+	return true
+}
+
+// instantiateKeep are the tables kept when instantiating a variable font.
+var instantiateKeep = []string{"OS/2", "cmap", "head", "hhea", "name", "post", "GDEF", "GPOS", "GSUB", "kern"}
+
+// instantiateLayout are the layout tables, dropped when they fail to parse
+// after instantiating a variable font.
+var instantiateLayout = []string{"GDEF", "GPOS", "GSUB", "kern"}
+
+// instantiate builds a static TrueType font from the loaded variable font
+// with the variations applied.
+func instantiate(ld *ot.Loader, vars []gofont.Variation) ([]byte, error) {
+	f, err := gofont.NewFont(ld)
+	if err != nil {
+		return nil, err
+	}
+	face := gofont.NewFace(f)
+	face.SetVariations(vars)
+	tbls := make(map[string][]byte)
+	for _, tag := range instantiateKeep {
+		if b, err := ld.RawTable(ot.MustNewTag(tag)); err == nil {
+			tbls[tag] = slices.Clone(b)
+		}
+	}
+	maxp, err := ld.RawTable(ot.MustNewTag("maxp"))
+	switch {
+	case err != nil:
+		return nil, err
+	case len(maxp) < 6 || len(tbls["head"]) < 54 || len(tbls["hhea"]) < 36:
+		return nil, fmt.Errorf("bad font tables")
+	}
+	// glyphs
+	n := int(binary.BigEndian.Uint16(maxp[4:]))
+	var glyf, hmtx []byte
+	loca := make([]byte, 4*(n+1))
+	xMin, yMin, xMax, yMax := math.MaxInt16, math.MaxInt16, math.MinInt16, math.MinInt16
+	advMax, lsbMin, rsbMin, extMax := 0, math.MaxInt16, math.MaxInt16, math.MinInt16
+	pointsMax, contoursMax := 0, 0
+	for id := range n {
+		var g glyfGlyph
+		if outline, ok := face.GlyphDataOutline(tables.GlyphID(id)); ok {
+			g = newGlyfGlyph(outline.Segments)
+		}
+		adv := int(math.Round(float64(face.HorizontalAdvance(gofont.GID(id)))))
+		hmtx = binary.BigEndian.AppendUint16(hmtx, uint16(max(adv, 0)))
+		hmtx = binary.BigEndian.AppendUint16(hmtx, uint16(int16(g.xMin)))
+		advMax = max(advMax, adv)
+		if 0 < len(g.ends) {
+			xMin, yMin, xMax, yMax = min(xMin, g.xMin), min(yMin, g.yMin), max(xMax, g.xMax), max(yMax, g.yMax)
+			lsbMin, rsbMin, extMax = min(lsbMin, g.xMin), min(rsbMin, adv-g.xMax), max(extMax, g.xMax)
+			pointsMax, contoursMax = max(pointsMax, len(g.points)), max(contoursMax, len(g.ends))
+		}
+		glyf = g.append(glyf)
+		binary.BigEndian.PutUint32(loca[4*id+4:], uint32(len(glyf)))
+	}
+	if xMax < xMin {
+		return nil, fmt.Errorf("unable to load glyph outlines")
+	}
+	tbls["glyf"], tbls["loca"], tbls["hmtx"] = glyf, loca, hmtx
+	// head: bounding box and long loca offsets
+	head := tbls["head"]
+	binary.BigEndian.PutUint16(head[36:], uint16(int16(xMin)))
+	binary.BigEndian.PutUint16(head[38:], uint16(int16(yMin)))
+	binary.BigEndian.PutUint16(head[40:], uint16(int16(xMax)))
+	binary.BigEndian.PutUint16(head[42:], uint16(int16(yMax)))
+	binary.BigEndian.PutUint16(head[50:], 1)
+	// hhea: horizontal metrics
+	hhea := tbls["hhea"]
+	binary.BigEndian.PutUint16(hhea[10:], uint16(advMax))
+	binary.BigEndian.PutUint16(hhea[12:], uint16(int16(lsbMin)))
+	binary.BigEndian.PutUint16(hhea[14:], uint16(int16(rsbMin)))
+	binary.BigEndian.PutUint16(hhea[16:], uint16(int16(extMax)))
+	binary.BigEndian.PutUint16(hhea[34:], uint16(n))
+	// maxp: version 1.0, without hinting
+	maxp = make([]byte, 32)
+	binary.BigEndian.PutUint32(maxp, 0x00010000)
+	binary.BigEndian.PutUint16(maxp[4:], uint16(n))
+	binary.BigEndian.PutUint16(maxp[6:], uint16(pointsMax))
+	binary.BigEndian.PutUint16(maxp[8:], uint16(contoursMax))
+	binary.BigEndian.PutUint16(maxp[14:], 2)
+	tbls["maxp"] = maxp
+	// OS/2: weight class
+	for _, v := range vars {
+		if os2 := tbls["OS/2"]; v.Tag == ot.MustNewTag("wght") && 6 <= len(os2) {
+			binary.BigEndian.PutUint16(os2[4:], uint16(min(max(math.Round(float64(v.Value)), 1), 1000)))
+		}
+	}
+	// post: CFF2 fonts have no glyph names
+	if post := tbls["post"]; 32 <= len(post) && binary.BigEndian.Uint32(post) == 0x00020000 && !ld.HasTable(ot.MustNewTag("glyf")) {
+		tbls["post"] = postTable(post)
+	}
+	// write until the data parses, dropping the failing layout table
+	sfnt := &fontpkg.SFNT{
+		IsTrueType: true,
+		Tables:     tbls,
+	}
+	for range len(instantiateLayout) + 1 {
+		buf := sfnt.Write()
+		_, err := fontpkg.ParseSFNT(buf, 0)
+		if err == nil {
+			return buf, nil
+		}
+		tag, _, _ := strings.Cut(err.Error(), ":")
+		if !slices.Contains(instantiateLayout, tag) || tbls[tag] == nil {
+			return nil, err
+		}
+		delete(tbls, tag)
+	}
+	return nil, fmt.Errorf("unable to instantiate font")
+}
+
+// glyfGlyph is a simple TrueType glyph.
+type glyfGlyph struct {
+	points                 []glyfPoint
+	ends                   []int
+	xMin, yMin, xMax, yMax int
+}
+
+// glyfPoint is a TrueType glyph point.
+type glyfPoint struct {
+	x, y    int
+	onCurve bool
+}
+
+// newGlyfGlyph converts the outline's segments to a simple TrueType glyph,
+// approximating cubic curves by quadratic curves.
+func newGlyfGlyph(segments []ot.Segment) glyfGlyph {
+	var g glyfGlyph
+	start := 0
+	add := func(p ot.SegmentPoint, onCurve bool) {
+		g.points = append(g.points, glyfPoint{
+			x:       int(math.Round(float64(p.X))),
+			y:       int(math.Round(float64(p.Y))),
+			onCurve: onCurve,
+		})
+	}
+	closeContour := func() {
+		// the closing point duplicates the start point
+		if n := len(g.points); start < n-1 && g.points[n-1] == g.points[start] {
+			g.points = g.points[:n-1]
+		}
+		if start < len(g.points) {
+			g.ends = append(g.ends, len(g.points)-1)
+		}
+		start = len(g.points)
+	}
+	var cur ot.SegmentPoint
+	for _, seg := range segments {
+		switch seg.Op {
+		case ot.SegmentOpMoveTo:
+			closeContour()
+			add(seg.Args[0], true)
+		case ot.SegmentOpLineTo:
+			add(seg.Args[0], true)
+		case ot.SegmentOpQuadTo:
+			add(seg.Args[0], false)
+			add(seg.Args[1], true)
+		case ot.SegmentOpCubeTo:
+			for _, q := range cubicToQuads(cur, seg.Args[0], seg.Args[1], seg.Args[2], 0) {
+				add(q[0], false)
+				add(q[1], true)
+			}
+		}
+		args := seg.ArgsSlice()
+		cur = args[len(args)-1]
+	}
+	closeContour()
+	if len(g.points) == 0 {
+		return g
+	}
+	g.xMin, g.yMin, g.xMax, g.yMax = g.points[0].x, g.points[0].y, g.points[0].x, g.points[0].y
+	for _, p := range g.points {
+		g.xMin, g.yMin, g.xMax, g.yMax = min(g.xMin, p.x), min(g.yMin, p.y), max(g.xMax, p.x), max(g.yMax, p.y)
+	}
+	return g
+}
+
+// append appends the glyph's glyf table data to b, padded to 4 bytes. Empty
+// glyphs have no data.
+func (g glyfGlyph) append(b []byte) []byte {
+	if len(g.ends) == 0 {
+		return b
+	}
+	b = binary.BigEndian.AppendUint16(b, uint16(len(g.ends)))
+	for _, v := range []int{g.xMin, g.yMin, g.xMax, g.yMax} {
+		b = binary.BigEndian.AppendUint16(b, uint16(int16(v)))
+	}
+	for _, end := range g.ends {
+		b = binary.BigEndian.AppendUint16(b, uint16(end))
+	}
+	// no instructions
+	b = binary.BigEndian.AppendUint16(b, 0)
+	for _, p := range g.points {
+		var flag byte
+		if p.onCurve {
+			flag = 1
+		}
+		b = append(b, flag)
+	}
+	x, y := 0, 0
+	for _, p := range g.points {
+		b = binary.BigEndian.AppendUint16(b, uint16(int16(p.x-x)))
+		x = p.x
+	}
+	for _, p := range g.points {
+		b = binary.BigEndian.AppendUint16(b, uint16(int16(p.y-y)))
+		y = p.y
+	}
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// cubicToQuads approximates the cubic curve by quadratic curves, returning
+// the control and end points of each quadratic curve. The curve is split in
+// half until the approximation is within a quarter font unit.
+func cubicToQuads(p0, p1, p2, p3 ot.SegmentPoint, depth int) [][2]ot.SegmentPoint {
+	// the error of the single quadratic approximation is bounded by
+	// sqrt(3)/36 * |p3 - 3p2 + 3p1 - p0|
+	dx, dy := p3.X-3*p2.X+3*p1.X-p0.X, p3.Y-3*p2.Y+3*p1.Y-p0.Y
+	if depth == 8 || math.Sqrt(3)/36*math.Hypot(float64(dx), float64(dy)) <= 0.25 {
+		c := ot.SegmentPoint{
+			X: (3*(p1.X+p2.X) - p0.X - p3.X) / 4,
+			Y: (3*(p1.Y+p2.Y) - p0.Y - p3.Y) / 4,
+		}
+		return [][2]ot.SegmentPoint{{c, p3}}
+	}
+	mid := func(a, b ot.SegmentPoint) ot.SegmentPoint {
+		return ot.SegmentPoint{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+	}
+	p01, p12, p23 := mid(p0, p1), mid(p1, p2), mid(p2, p3)
+	p012, p123 := mid(p01, p12), mid(p12, p23)
+	m := mid(p012, p123)
+	return append(cubicToQuads(p0, p01, p012, m, depth+1), cubicToQuads(m, p123, p23, p3, depth+1)...)
+}
@@ -0,0 +1,147 @@
+package fontimg
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kenshaw/fontimg/testfonts"
+	"github.com/tdewolff/canvas"
+)
+
+func TestAxes(t *testing.T) {
+	font := New(testfonts.Bytes(testfonts.Variable), testfonts.Variable)
+	axes, err := font.Axes()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := []Axis{{Tag: "wdth", Name: "Width", Min: 100, Default: 100, Max: 200}}
+	if !slices.Equal(axes, exp) {
+		t.Errorf("expected %v, got: %v", exp, axes)
+	}
+	instances, err := font.Instances()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var names []string
+	for _, instance := range instances {
+		names = append(names, instance.Name)
+	}
+	if exp := []string{"Regular", "Expanded", "Ultra Expanded"}; !slices.Equal(names, exp) {
+		t.Errorf("expected %v, got: %v", exp, names)
+	}
+	// static fonts
+	static := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	switch axes, err := static.Axes(); {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case axes != nil:
+		t.Errorf("expected no axes, got: %v", axes)
+	}
+	if _, err := static.Instantiate(nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	font := New(testfonts.Bytes(testfonts.Variable), testfonts.Variable)
+	tests := []struct {
+		coords map[string]float64
+		style  string
+		scale  float64
+	}{
+		{nil, "Regular", 1},
+		{map[string]float64{"wdth": 150}, "Expanded", 1.5},
+		{map[string]float64{"wdth": 200}, "Ultra Expanded", 2},
+		{map[string]float64{"wdth": 175}, "Regular", 1.75},
+		{map[string]float64{"wdth": 500}, "Ultra Expanded", 2},
+	}
+	var width float64
+	for _, test := range tests {
+		t.Run(test.style, func(t *testing.T) {
+			inst, err := font.Instantiate(test.coords)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if inst.Style != test.style {
+				t.Errorf("expected style %q, got: %q", test.style, inst.Style)
+			}
+			ff, err := inst.Load(canvas.FontRegular)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			w := ff.Face(48, canvas.Black).TextWidth("Hello")
+			if width == 0 {
+				width = w
+			}
+			if exp := width * test.scale; w < exp-0.5 || exp+0.5 < w {
+				t.Errorf("expected width %f, got: %f", exp, w)
+			}
+		})
+	}
+	if _, err := font.Instantiate(map[string]float64{"wght": 700}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestRasterizeVariations(t *testing.T) {
+	font := New(testfonts.Bytes(testfonts.Variable), testfonts.Variable)
+	tests := []struct {
+		instance   string
+		variations map[string]float64
+		err        bool
+	}{
+		{"", nil, false},
+		{"expanded", nil, false},
+		{"", map[string]float64{"wdth": 200}, false},
+		{"Regular", map[string]float64{"wdth": 200}, false},
+		{"Bold", nil, true},
+		{"", map[string]float64{"wght": 700}, true},
+	}
+	keys := make(map[string]bool)
+	var widths []int
+	for _, test := range tests {
+		opts := DefaultOptions()
+		opts.Text = "Hello"
+		opts.Instance, opts.Variations = test.instance, test.variations
+		img, err := font.RasterizeOptions(opts)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("%q %v: expected error, got nil", test.instance, test.variations)
+			continue
+		case test.err:
+			continue
+		case err != nil:
+			t.Fatalf("%q %v: expected no error, got: %v", test.instance, test.variations, err)
+		}
+		keys[CacheKey(font, opts)] = true
+		widths = append(widths, img.Bounds().Dx())
+	}
+	switch {
+	case len(keys) != 4:
+		t.Errorf("expected 4 distinct cache keys, got: %d", len(keys))
+	case widths[2] <= widths[1] || widths[1] <= widths[0] || widths[3] != widths[2]:
+		t.Errorf("expected wider images for wider instances, got: %v", widths)
+	}
+}
+
+func TestInstanceStrip(t *testing.T) {
+	font := New(testfonts.Bytes(testfonts.Variable), testfonts.Variable)
+	c, err := font.InstanceStrip(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := countGlyphs(c); n == 0 {
+		t.Errorf("expected glyphs")
+	}
+	img, err := font.RasterizeInstances(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+		t.Errorf("expected non-empty image, got: %v", b)
+	}
+	if _, err := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf")).InstanceStrip(nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -0,0 +1,201 @@
+package fontimg
+
+import (
+	"context"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	fontpkg "github.com/tdewolff/font"
+)
+
+// FontOp is a system font change operation.
+type FontOp int
+
+// Font change operations.
+const (
+	// FontAdded is a font file being installed.
+	FontAdded FontOp = iota
+	// FontRemoved is a font file being removed.
+	FontRemoved
+	// FontChanged is a font file being modified.
+	FontChanged
+)
+
+// String satisfies the [fmt.Stringer] interface.
+func (op FontOp) String() string {
+	switch op {
+	case FontAdded:
+		return "added"
+	case FontRemoved:
+		return "removed"
+	case FontChanged:
+		return "changed"
+	}
+	return "unknown"
+}
+
+// FontEvent is a system font change event.
+type FontEvent struct {
+	// Op is the change operation.
+	Op FontOp
+	// Path is the font file path.
+	Path string
+	// Family and Style are the font's family and style, from the refreshed
+	// system fonts, or the previous system fonts for removed fonts.
+	Family, Style string
+}
+
+// SystemWatcher is a system fonts index that is refreshed when font files
+// in its font directories are installed, removed, or changed, for
+// long-running services. Set it on a [SystemSource] to use the current
+// system fonts.
+type SystemWatcher struct {
+	dirs   []string
+	mu     sync.Mutex
+	fonts  *fontpkg.SystemFonts
+	stamps map[string]fileStamp
+}
+
+// fileStamp is a file's size and modification time.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// NewSystemWatcher creates a system fonts watcher for the font directories,
+// indexing the fonts. When no directories are provided, the default font
+// directories are used.
+func NewSystemWatcher(dirs ...string) (*SystemWatcher, error) {
+	if len(dirs) == 0 {
+		dirs = fontpkg.DefaultFontDirs()
+	}
+	w := &SystemWatcher{dirs: dirs}
+	if _, err := w.Refresh(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Fonts returns the current system fonts. The returned system fonts are
+// replaced, not modified, when refreshed.
+func (w *SystemWatcher) Fonts() *fontpkg.SystemFonts {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.fonts
+}
+
+// Refresh checks the font directories for changed files, re-indexing the
+// system fonts when any have changed, and returns the change events of the
+// fonts, sorted by path.
+func (w *SystemWatcher) Refresh() ([]FontEvent, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	stamps := make(map[string]fileStamp)
+	for _, dir := range w.dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			switch {
+			case err != nil && path == dir && os.IsNotExist(err):
+				return filepath.SkipDir
+			case err != nil:
+				return err
+			case !d.Type().IsRegular():
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			stamps[path] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if w.fonts != nil && maps.Equal(stamps, w.stamps) {
+		return nil, nil
+	}
+	var existing []string
+	for _, dir := range w.dirs {
+		if _, err := os.Stat(dir); err == nil {
+			existing = append(existing, dir)
+		}
+	}
+	sysfonts, err := fontpkg.FindSystemFonts(existing)
+	if err != nil {
+		return nil, err
+	}
+	prev, next := fontFiles(w.fonts), fontFiles(sysfonts)
+	var events []FontEvent
+	for path, md := range next {
+		switch old, ok := prev[path]; {
+		case !ok:
+			events = append(events, FontEvent{Op: FontAdded, Path: path, Family: md.Family, Style: md.Style.String()})
+		case stamps[path] != w.stamps[path] || old != md:
+			events = append(events, FontEvent{Op: FontChanged, Path: path, Family: md.Family, Style: md.Style.String()})
+		}
+	}
+	for path, md := range prev {
+		if _, ok := next[path]; !ok {
+			events = append(events, FontEvent{Op: FontRemoved, Path: path, Family: md.Family, Style: md.Style.String()})
+		}
+	}
+	slices.SortFunc(events, func(a, b FontEvent) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	// the first index has no events
+	if w.fonts == nil {
+		events = nil
+	}
+	w.fonts, w.stamps = sysfonts, stamps
+	return events, nil
+}
+
+// Watch refreshes the system fonts at the interval until ctx is done,
+// sending the change events on the returned channel, which is closed when ctx
+// is done. Refreshing waits for the events to be received. Errors refreshing
+// are ignored, and retried at the next interval.
+func (w *SystemWatcher) Watch(ctx context.Context, interval time.Duration) <-chan FontEvent {
+	ch := make(chan FontEvent)
+	go func() {
+		defer close(ch)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+			events, _ := w.Refresh()
+			for _, ev := range events {
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- ev:
+				}
+			}
+		}
+	}()
+	return ch
+}
+
+// fontFiles returns the system fonts' metadata by file path.
+func fontFiles(sysfonts *fontpkg.SystemFonts) map[string]fontpkg.FontMetadata {
+	m := make(map[string]fontpkg.FontMetadata)
+	if sysfonts == nil {
+		return m
+	}
+	for _, styles := range sysfonts.Fonts {
+		for _, md := range styles {
+			m[md.Filename] = md
+		}
+	}
+	return m
+}
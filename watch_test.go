@@ -0,0 +1,112 @@
+package fontimg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSystemWatcher(t *testing.T) {
+	dir := t.TempDir()
+	copyFile(t, filepath.Join("testdata", "Ubuntu-R.ttf"), filepath.Join(dir, "Ubuntu-R.ttf"))
+	w, err := NewSystemWatcher(dir, filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, ok := w.Fonts().Fonts["Ubuntu"]; !ok {
+		t.Fatalf("expected Ubuntu to be indexed")
+	}
+	// no changes
+	events, err := w.Refresh()
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case len(events) != 0:
+		t.Errorf("expected no events, got: %v", events)
+	}
+	// non-font files
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("readme"), 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	events, err = w.Refresh()
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case len(events) != 0:
+		t.Errorf("expected no events, got: %v", events)
+	}
+	// added
+	copyFile(t, filepath.Join("testdata", "NotoMono-Regular.ttf"), filepath.Join(dir, "NotoMono-Regular.ttf"))
+	events, err = w.Refresh()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := []FontEvent{{FontAdded, filepath.Join(dir, "NotoMono-Regular.ttf"), "Noto Mono", "Regular"}}
+	if !slices.Equal(events, exp) {
+		t.Errorf("expected %v, got: %v", exp, events)
+	}
+	refs, err := SystemSource{Watcher: w}.List(context.Background())
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case len(refs) != 2:
+		t.Errorf("expected 2 refs, got: %d", len(refs))
+	}
+	// removed
+	if err := os.Remove(filepath.Join(dir, "Ubuntu-R.ttf")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	events, err = w.Refresh()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp = []FontEvent{{FontRemoved, filepath.Join(dir, "Ubuntu-R.ttf"), "Ubuntu", "Regular"}}
+	if !slices.Equal(events, exp) {
+		t.Errorf("expected %v, got: %v", exp, events)
+	}
+	if _, ok := w.Fonts().Fonts["Ubuntu"]; ok {
+		t.Errorf("expected Ubuntu to not be indexed")
+	}
+}
+
+func TestSystemWatcherWatch(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewSystemWatcher(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ch := w.Watch(ctx, 10*time.Millisecond)
+	copyFile(t, filepath.Join("testdata", "Ubuntu-R.ttf"), filepath.Join(dir, "Ubuntu-R.ttf"))
+	select {
+	case ev := <-ch:
+		if ev.Op != FontAdded || ev.Family != "Ubuntu" {
+			t.Errorf("expected Ubuntu added, got: %v", ev)
+		}
+	case <-ctx.Done():
+		t.Fatalf("expected event")
+	}
+	cancel()
+	for range ch {
+	}
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+	buf, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// write to a temporary name and rename, so the font is never seen partially
+	// written
+	if err := os.WriteFile(dst+".tmp", buf, 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := os.Rename(dst+".tmp", dst); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
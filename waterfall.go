@@ -0,0 +1,77 @@
+package fontimg
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// DefaultWaterfallSizes are the default waterfall sizes.
+var DefaultWaterfallSizes = []int{8, 10, 12, 14, 18, 24, 36, 48, 72}
+
+// RasterizeWaterfall rasterizes a waterfall image using the options. See
+// [Font.Waterfall].
+func (font *Font) RasterizeWaterfall(sizes []int, opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	c, err := font.Waterfall(sizes, opts)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizer.Draw(c, canvas.DPI(opts.DPI), canvas.DefaultColorSpace), nil
+}
+
+// Waterfall lays out a waterfall on a canvas, with the font's name and style
+// above the sample text repeated on a line per size, for evaluating the face
+// across sizes. When sizes is empty, [DefaultWaterfallSizes] are used. The
+// options' text (when not empty) is used as the sample text, and the options'
+// template is not used. When opts is nil, the default options will be used.
+//
+// Set the options' [Options.OpticalMargin] and [Options.BaselineGrid] to align
+// the lines on a common left margin and baseline grid, for use as a precise
+// size selection reference.
+func (font *Font) Waterfall(sizes []int, opts *Options) (*canvas.Canvas, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if len(sizes) == 0 {
+		sizes = DefaultWaterfallSizes
+	}
+	v := make([]string, len(sizes))
+	for i, size := range sizes {
+		if size <= 0 {
+			return nil, fmt.Errorf("invalid waterfall size %d: must be greater than 0", size)
+		}
+		v[i] = strconv.Itoa(size)
+	}
+	tpl, err := NewTemplate(`{{ size (inc .Size 2) }}{{ .Name }}, {{ .Style }}
+{{ waterfall .SampleText ` + strings.Join(v, " ") + ` }}`)
+	if err != nil {
+		return nil, err
+	}
+	o := *opts
+	o.Template = tpl
+	return font.Canvas(&o)
+}
+
+// waterfall returns the text repeated on a line per size, with line breaks
+// in the text replaced by spaces. When text is empty, a pangram is used.
+func waterfall(text string, sizes ...int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		text = "The quick brown fox jumps over the lazy dog."
+	}
+	var sb strings.Builder
+	for i, size := range sizes {
+		if i != 0 {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "\x00%d\x00%s", size, text)
+	}
+	return sb.String()
+}
@@ -0,0 +1,146 @@
+package fontimg
+
+import (
+	"image"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+)
+
+func TestWaterfall(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	tests := []struct {
+		name  string
+		sizes []int
+	}{
+		{"default", nil},
+		{"sizes", []int{12, 24, 48}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := font.Waterfall(test.sizes, nil)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if n := countGlyphs(c); n == 0 {
+				t.Errorf("expected glyphs")
+			}
+			img, err := font.RasterizeWaterfall(test.sizes, nil)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+				t.Errorf("expected non-empty image, got: %v", b)
+			}
+		})
+	}
+	if _, err := font.Waterfall([]int{12, 0}, nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	small, err := font.RasterizeWaterfall([]int{12}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	large, err := font.RasterizeWaterfall([]int{12, 72}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if large.Rect.Dy() <= small.Rect.Dy() {
+		t.Errorf("expected more sizes to produce a taller image, got: %v <= %v", large.Rect, small.Rect)
+	}
+}
+
+func TestWaterfallTemplate(t *testing.T) {
+	tpl, err := NewTemplate(`{{ waterfall .SampleText 8 16 }}`)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		text string
+		exp  string
+	}{
+		{"Hamburg\nfonts", "\x008\x00Hamburg fonts\n\x0016\x00Hamburg fonts"},
+		{"", "\x008\x00The quick brown fox jumps over the lazy dog.\n\x0016\x00The quick brown fox jumps over the lazy dog."},
+	}
+	for _, test := range tests {
+		var sb strings.Builder
+		if err := tpl.Execute(&sb, TemplateData{SampleText: test.text}); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if s := sb.String(); s != test.exp {
+			t.Errorf("expected %q, got: %q", test.exp, s)
+		}
+		lines, sizes, _ := breakLines([]byte(sb.String()), 48)
+		if len(lines) != 2 || sizes[0] != 8 || sizes[1] != 16 {
+			t.Errorf("expected 2 lines at sizes 8 and 16, got: %q %v", lines, sizes)
+		}
+	}
+}
+
+func TestWaterfallAlignment(t *testing.T) {
+	font := New(nil, filepath.Join("testdata", "Ubuntu-R.ttf"))
+	opts := DefaultOptions()
+	opts.Text = "Wavy jumps"
+	opts.OpticalMargin, opts.BaselineGrid = true, 2.5
+	c, err := font.Waterfall([]int{8, 13, 29, 72}, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	r := &lineRecorder{w: c.W, h: c.H}
+	c.RenderTo(r)
+	if len(r.lefts) != 5 {
+		t.Fatalf("expected 5 lines, got: %d", len(r.lefts))
+	}
+	for i := 1; i < len(r.lefts); i++ {
+		if d := r.lefts[i] - r.lefts[0]; 1e-6 < math.Abs(d) {
+			t.Errorf("line %d: expected ink aligned with the first line, got offset %g", i, d)
+		}
+		d := (r.baselines[i-1] - r.baselines[i]) / opts.BaselineGrid
+		if 1e-6 < math.Abs(d-math.Round(d)) {
+			t.Errorf("line %d: expected baseline on the %g grid, got: %g", i, opts.BaselineGrid, d)
+		}
+	}
+	opts.OpticalMargin, opts.BaselineGrid = false, 0
+	if c, err = font.Waterfall([]int{8, 13, 29, 72}, opts); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	r = &lineRecorder{w: c.W, h: c.H}
+	c.RenderTo(r)
+	if r.lefts[1] == r.lefts[4] {
+		t.Errorf("expected unaligned ink without an optical margin")
+	}
+	opts.BaselineGrid = -1
+	if err := opts.Validate(); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+// lineRecorder is a renderer recording the left ink edge and first baseline
+// of each rendered text.
+type lineRecorder struct {
+	w, h      float64
+	lefts     []float64
+	baselines []float64
+}
+
+func (r *lineRecorder) Size() (float64, float64) {
+	return r.w, r.h
+}
+
+func (r *lineRecorder) RenderPath(*canvas.Path, canvas.Style, canvas.Matrix) {}
+
+func (r *lineRecorder) RenderText(text *canvas.Text, m canvas.Matrix) {
+	baseline := math.NaN()
+	text.WalkSpans(func(_, y float64, _ canvas.TextSpan) {
+		if math.IsNaN(baseline) {
+			baseline = y
+		}
+	})
+	r.lefts = append(r.lefts, m.Dot(canvas.Point{X: text.OutlineBounds().X0}).X)
+	r.baselines = append(r.baselines, m.Dot(canvas.Point{Y: baseline}).Y)
+}
+
+func (r *lineRecorder) RenderImage(image.Image, canvas.Matrix) {}
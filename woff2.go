@@ -0,0 +1,194 @@
+package fontimg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/andybalholm/brotli"
+)
+
+// padWOFF2 returns the WOFF2 font data repackaged for the font parser, which
+// rejects data read up to its end as truncated: the compressed data, the
+// streams of a transformed glyf table, and the hhea table (read when
+// reconstructing a transformed hmtx table) are padded with zeros. Returns
+// whether the hhea table was padded, for trimming it from the decompressed
+// data with [trimHhea]. The font data is returned unchanged when nothing
+// needs padding, or when malformed, leaving it for the font parser to
+// report. The repackaged data is charged to the budget. The decompressed size
+// must already be charged (see [checkWOFF2]).
+func padWOFF2(b []byte, bud *budget) ([]byte, bool, error) {
+	if len(b) < 48 || string(b[4:8]) == "ttcf" {
+		return b, false, nil
+	}
+	tables, offset, ok := readWOFF2Directory(b)
+	length := int64(binary.BigEndian.Uint32(b[20:]))
+	if !ok || int64(len(b)-offset) < length {
+		return b, false, nil
+	}
+	glyf, hhea, hmtx := -1, -1, false
+	for i, t := range tables {
+		switch {
+		case t.tag == "glyf" && t.transformed:
+			glyf = i
+		case t.tag == "hhea" && t.orig == 36:
+			hhea = i
+		case t.tag == "hmtx" && t.transformed:
+			hmtx = true
+		}
+	}
+	if !hmtx {
+		hhea = -1
+	}
+	if glyf == -1 && hhea == -1 {
+		if int64(offset)+length != int64(len(b)) {
+			return b, false, nil
+		}
+		// pad a copy
+		if err := bud.alloc(int64(len(b)) + 4); err != nil {
+			return nil, false, err
+		}
+		buf := append(b[:len(b):len(b)], 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(buf[8:], uint32(len(buf)))
+		return buf, false, nil
+	}
+	// decompress
+	var size int64
+	for _, t := range tables {
+		size += t.n()
+	}
+	data, err := io.ReadAll(io.LimitReader(brotli.NewReader(bytes.NewReader(b[offset:int64(offset)+length])), size+1))
+	if err != nil || int64(len(data)) != size {
+		return b, false, nil
+	}
+	// split tables
+	v := make([][]byte, len(tables))
+	var start int64
+	for i, t := range tables {
+		v[i], start = data[start:start+t.n()], start+t.n()
+	}
+	sfntSize := int64(binary.BigEndian.Uint32(b[16:]))
+	if glyf != -1 {
+		if v[glyf], ok = padGlyfStreams(v[glyf]); !ok {
+			return b, false, nil
+		}
+		tables[glyf].trans = int64(len(v[glyf]))
+	}
+	if hhea != -1 {
+		v[hhea] = append(v[hhea][:36:36], 0, 0, 0, 0)
+		tables[hhea].orig, sfntSize = 40, sfntSize+4
+	}
+	// recompress
+	var z bytes.Buffer
+	w := brotli.NewWriterLevel(&z, brotli.BestSpeed)
+	for _, table := range v {
+		if _, err := w.Write(table); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, false, err
+	}
+	// write header, without the metadata and private data blocks, and
+	// directory
+	buf := make([]byte, 48, offset+z.Len()+4)
+	copy(buf, b[:28])
+	binary.BigEndian.PutUint32(buf[16:], uint32(sfntSize))
+	binary.BigEndian.PutUint32(buf[20:], uint32(z.Len()))
+	for _, t := range tables {
+		buf = append(buf, t.flags)
+		if t.flags&0x3f == 63 {
+			buf = append(buf, t.tag...)
+		}
+		buf = appendBase128(buf, t.orig)
+		if t.transformed {
+			buf = appendBase128(buf, t.trans)
+		}
+	}
+	buf = append(append(buf, z.Bytes()...), 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(buf[8:], uint32(len(buf)))
+	if err := bud.alloc(size + int64(len(buf)) + 4); err != nil {
+		return nil, false, err
+	}
+	return buf, hhea != -1, nil
+}
+
+// padGlyfStreams returns the transformed glyf table with its streams padded
+// with zeros, except the contour count stream (which has a fixed size), and
+// with trailing zeros. Returns false when the table is malformed.
+func padGlyfStreams(table []byte) ([]byte, bool) {
+	const headerSize = 36
+	if len(table) < headerSize {
+		return nil, false
+	}
+	numGlyphs := int64(binary.BigEndian.Uint16(table[4:]))
+	bitmapSize := (numGlyphs + 31) >> 5 << 2
+	// contour count, point count, flag, glyph, composite, bbox and
+	// instruction streams
+	var sizes [7]int64
+	n := int64(headerSize)
+	for i := range sizes {
+		sizes[i] = int64(binary.BigEndian.Uint32(table[8+4*i:]))
+		n += sizes[i]
+	}
+	if binary.BigEndian.Uint16(table[2:])&1 != 0 {
+		// overlap simple bitmap
+		n += bitmapSize
+	}
+	if int64(len(table)) < n || sizes[5] < bitmapSize {
+		return nil, false
+	}
+	buf := make([]byte, headerSize, int64(len(table))+4*int64(len(sizes)))
+	copy(buf, table[:headerSize])
+	i := int64(headerSize)
+	for j, size := range sizes {
+		buf = append(buf, table[i:i+size]...)
+		if j != 0 {
+			buf = append(buf, 0, 0, 0, 0)
+			binary.BigEndian.PutUint32(buf[8+4*j:], uint32(size+4))
+		}
+		i += size
+	}
+	return append(append(buf, table[i:n]...), 0, 0, 0, 0), true
+}
+
+// trimHhea trims the hhea table padded by [padWOFF2] from the SFNT font data
+// back to its length, updating the checksum adjustment.
+func trimHhea(b []byte) {
+	if len(b) < 12 {
+		return
+	}
+	head := -1
+	for i := range int(binary.BigEndian.Uint16(b[4:])) {
+		rec := b[12+16*i:]
+		if len(rec) < 16 {
+			return
+		}
+		switch string(rec[:4]) {
+		case "head":
+			head = int(binary.BigEndian.Uint32(rec[8:]))
+		case "hhea":
+			if binary.BigEndian.Uint32(rec[12:]) == 40 {
+				// zero padding does not change the checksum
+				binary.BigEndian.PutUint32(rec[12:], 36)
+			}
+		}
+	}
+	if head == -1 || len(b) < head+12 {
+		return
+	}
+	binary.BigEndian.PutUint32(b[head+8:], 0)
+	binary.BigEndian.PutUint32(b[head+8:], 0xB1B0AFBA-tableChecksum("", b))
+}
+
+// appendBase128 appends the UIntBase128 encoding of v to b.
+func appendBase128(b []byte, v int64) []byte {
+	var tmp [5]byte
+	i := len(tmp) - 1
+	tmp[i] = byte(v & 0x7f)
+	for v >>= 7; v != 0; v >>= 7 {
+		i--
+		tmp[i] = byte(v&0x7f) | 0x80
+	}
+	return append(b, tmp[i:]...)
+}
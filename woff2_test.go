@@ -0,0 +1,129 @@
+package fontimg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	fontpkg "github.com/tdewolff/font"
+)
+
+func TestPadWOFF2(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "Ubuntu-R.ttf"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	sfnt, err := fontpkg.ParseSFNT(testNoCompositeInstructions(buf), 0)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// transformed glyf and loca tables
+	glyf, err := sfnt.WriteWOFF2()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		name string
+		buf  []byte
+		exp  string
+	}{
+		{"cff2", testUnpadWOFF2(testWOFF2(t, testCFF2(t, buf))), "CFF2"},
+		{"glyf", glyf, "glyf"},
+		{"glyf unpadded", testUnpadWOFF2(glyf), "glyf"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := DecompressWOFF2(test.buf)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !hasTable(b, test.exp) {
+				t.Errorf("expected %s table", test.exp)
+			}
+			if test.exp != "glyf" {
+				return
+			}
+			s, err := fontpkg.ParseSFNT(b, 0)
+			switch {
+			case err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			case s.NumGlyphs() != sfnt.NumGlyphs():
+				t.Errorf("expected %d glyphs, got: %d", sfnt.NumGlyphs(), s.NumGlyphs())
+			}
+		})
+	}
+	// open and load from a file
+	name := filepath.Join(t.TempDir(), "Ubuntu-R.woff2")
+	if err := os.WriteFile(name, glyf, 0o644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fonts, err := Open(name, canvas.FontRegular, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := fonts[0].Load(canvas.FontRegular); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := fonts[0].BestName(); s != "Ubuntu" {
+		t.Errorf("expected %q, got: %q", "Ubuntu", s)
+	}
+}
+
+// testUnpadWOFF2 returns the WOFF2 font data without trailing padding,
+// ending with the compressed data.
+func testUnpadWOFF2(b []byte) []byte {
+	_, offset, _ := readWOFF2Directory(b)
+	b = b[:offset+int(binary.BigEndian.Uint32(b[20:]))]
+	binary.BigEndian.PutUint32(b[8:], uint32(len(b)))
+	return b
+}
+
+// testNoCompositeInstructions returns a copy of the TrueType font data with
+// the instructions flag of composite glyphs cleared, as the WOFF2 writer
+// misplaces composite glyph instructions in the glyph stream.
+func testNoCompositeInstructions(buf []byte) []byte {
+	buf = bytes.Clone(buf)
+	tables := make(map[string][]byte)
+	for i := range int(binary.BigEndian.Uint16(buf[4:])) {
+		rec := buf[12+16*i:]
+		offset, length := binary.BigEndian.Uint32(rec[8:]), binary.BigEndian.Uint32(rec[12:])
+		tables[string(rec[:4])] = buf[offset : offset+length]
+	}
+	long := binary.BigEndian.Uint16(tables["head"][50:]) == 1
+	glyf, loca := tables["glyf"], tables["loca"]
+	for i := range int(binary.BigEndian.Uint16(tables["maxp"][4:])) {
+		var start, end int
+		if long {
+			start, end = int(binary.BigEndian.Uint32(loca[4*i:])), int(binary.BigEndian.Uint32(loca[4*i+4:]))
+		} else {
+			start, end = 2*int(binary.BigEndian.Uint16(loca[2*i:])), 2*int(binary.BigEndian.Uint16(loca[2*i+2:]))
+		}
+		if end-start < 10 || int16(binary.BigEndian.Uint16(glyf[start:])) >= 0 {
+			continue
+		}
+		for j := start + 10; ; {
+			flags := binary.BigEndian.Uint16(glyf[j:])
+			binary.BigEndian.PutUint16(glyf[j:], flags&^0x0100)
+			// flags, glyph index and arguments
+			j += 6
+			if flags&0x0001 != 0 {
+				j += 2
+			}
+			switch {
+			case flags&0x0008 != 0:
+				j += 2
+			case flags&0x0040 != 0:
+				j += 4
+			case flags&0x0080 != 0:
+				j += 8
+			}
+			if flags&0x0020 == 0 {
+				break
+			}
+		}
+	}
+	return buf
+}